@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/encounter"
+	domain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+)
+
+// calcOutput is the JSON result of the calc command
+type calcOutput struct {
+	Ruleset         string                         `json:"ruleset"`
+	Difficulty      string                         `json:"difficulty"`
+	CharacterLevels []int                          `json:"character_levels"`
+	Budget          int                            `json:"budget"`
+	MonsterCount    int                            `json:"monster_count,omitempty"`
+	Multiplier      float64                        `json:"multiplier,omitempty"`
+	XPPerMonster    int                            `json:"xp_per_monster,omitempty"`
+	Composition     *encounter.CompositionResponse `json:"composition,omitempty"`
+}
+
+// monsterPick is one id[:qty] entry of --monsters
+type monsterPick struct {
+	id       string
+	quantity int
+}
+
+func (c *cli) calc(args []string) error {
+	fs, format := c.newFlagSet("calc")
+	ruleset := fs.String("ruleset", "2024", "regole: 2024 o 2014")
+	levels := fs.String("levels", "", "livelli dei personaggi separati da virgola, es. 5,5,6,4")
+	difficulty := fs.String("difficulty", "", "difficoltà: Low/Moderate/High (2024) o Facile/Media/Difficile/Letale (2014)")
+	monsters := fs.String("monsters", "", "numero di mostri, oppure elenco id[:quantità] separati da virgola")
+	countWeak := fs.Bool("count-weak", false, "conta anche i mostri molto più deboli nel moltiplicatore (2014)")
+	if err := parseFlags(fs, format, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError{fmt.Errorf("argomento inatteso %q", fs.Arg(0))}
+	}
+	if *levels == "" || *difficulty == "" {
+		return usageError{fmt.Errorf("--levels e --difficulty sono obbligatori")}
+	}
+
+	characterLevels, err := parseInts(*levels)
+	if err != nil {
+		return fmt.Errorf("livelli non validi: %w", err)
+	}
+
+	result, err := c.service.CalculateXP(encounter.CalculateXPRequest{
+		Ruleset:         *ruleset,
+		PartyMode:       string(domain.PartyModeDifferent),
+		Difficulty:      *difficulty,
+		CharacterLevels: characterLevels,
+	})
+	if err != nil {
+		return err
+	}
+
+	out := calcOutput{
+		Ruleset:         *ruleset,
+		Difficulty:      *difficulty,
+		CharacterLevels: characterLevels,
+		Budget:          result.TotalXP,
+	}
+
+	if *monsters != "" {
+		if count, err := strconv.Atoi(*monsters); err == nil {
+			if err := c.splitBudget(&out, count); err != nil {
+				return err
+			}
+		} else {
+			picks, err := parsePicks(*monsters)
+			if err != nil {
+				return fmt.Errorf("mostri non validi: %w", err)
+			}
+			composition, err := c.compose(out, picks, *countWeak)
+			if err != nil {
+				return err
+			}
+			out.Composition = composition
+		}
+	}
+
+	if *format == formatJSON {
+		return writeJSON(c.stdout, out)
+	}
+	return c.printCalc(out)
+}
+
+// splitBudget fills in how much XP each of count monsters can be worth, applying the 2014 multiplier
+func (c *cli) splitBudget(out *calcOutput, count int) error {
+	if count < 1 {
+		return fmt.Errorf("il numero di mostri deve essere almeno 1")
+	}
+	out.MonsterCount = count
+
+	raw := float64(out.Budget)
+	if out.Ruleset == string(domain.Ruleset2014) {
+		step, err := c.queries.GetMultiplier(count, len(out.CharacterLevels))
+		if err != nil {
+			return err
+		}
+		out.Multiplier = step.Multiplier
+		raw /= step.Multiplier
+	}
+	out.XPPerMonster = int(raw) / count
+	return nil
+}
+
+// compose builds a composition with the chosen monsters against the calculated budget
+func (c *cli) compose(out calcOutput, picks []monsterPick, countWeak bool) (*encounter.CompositionResponse, error) {
+	composition, err := c.compositions.Create(encounter.CreateCompositionRequest{
+		Ruleset:         out.Ruleset,
+		Difficulty:      out.Difficulty,
+		CharacterLevels: out.CharacterLevels,
+		Budget:          out.Budget,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range picks {
+		if composition, err = c.compositions.AddMonster(composition.ID, p.id, p.quantity); err != nil {
+			return nil, err
+		}
+	}
+	if countWeak {
+		return c.compositions.SetCountWeakMonsters(composition.ID, true)
+	}
+	return composition, nil
+}
+
+// parsePicks parses a comma-separated list of id[:qty] entries
+func parsePicks(s string) ([]monsterPick, error) {
+	var picks []monsterPick
+	for _, part := range strings.Split(s, ",") {
+		id, qty, hasQty := strings.Cut(strings.TrimSpace(part), ":")
+		if id == "" {
+			return nil, fmt.Errorf("id mancante in %q", part)
+		}
+		pick := monsterPick{id: id, quantity: 1}
+		if hasQty {
+			n, err := strconv.Atoi(qty)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("quantità non valida in %q", part)
+			}
+			pick.quantity = n
+		}
+		picks = append(picks, pick)
+	}
+	return picks, nil
+}
+
+func (c *cli) printCalc(out calcOutput) error {
+	t := newTable(c.stdout)
+	t.row("Regole", out.Ruleset)
+	t.row("Difficoltà", out.Difficulty)
+	t.row("Livelli", joinInts(out.CharacterLevels))
+	t.row("Budget PE", strconv.Itoa(out.Budget))
+	if out.MonsterCount > 0 {
+		t.row("Mostri", strconv.Itoa(out.MonsterCount))
+		if out.Multiplier > 0 {
+			t.row("Moltiplicatore", formatMultiplier(out.Multiplier))
+		}
+		t.row("PE per mostro", strconv.Itoa(out.XPPerMonster))
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	comp := out.Composition
+	if comp == nil {
+		return nil
+	}
+
+	fmt.Fprintln(c.stdout)
+	t = newTable(c.stdout)
+	t.row("ID", "NOME", "GS", "PE", "QTÀ", "TOTALE")
+	for _, m := range comp.Monsters {
+		t.row(m.ID, m.Name, m.CR, strconv.Itoa(m.XP), strconv.Itoa(m.Quantity), strconv.Itoa(m.TotalXP))
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.stdout)
+	t = newTable(c.stdout)
+	t.row("PE usati", strconv.Itoa(comp.XPUsed))
+	t.row("PE rimanenti", strconv.Itoa(comp.XPRemaining))
+	if comp.Multiplier > 0 {
+		t.row("Moltiplicatore", formatMultiplier(comp.Multiplier))
+		t.row("PE aggiustati", strconv.Itoa(comp.AdjustedXP))
+	}
+	if comp.ResultingDifficulty != "" {
+		t.row("Difficoltà risultante", comp.ResultingDifficulty.String())
+	}
+	return t.flush()
+}
@@ -0,0 +1,178 @@
+// Command encounters-cli calculates encounter budgets and looks up monsters
+// from the embedded dataset, without starting the HTTP server.
+//
+// Exit codes: 0 success, 1 invalid values (e.g. an unknown difficulty),
+// 2 unknown command or malformed flags, 3 no monster found.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/encounter"
+	monsterApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/config"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/monsterimport"
+)
+
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 3
+)
+
+const usage = `Uso: encounters-cli <comando> [opzioni]
+
+Comandi:
+  calc              Calcola il budget PE di un incontro
+  monsters search   Cerca mostri nel dataset
+  monsters show     Mostra la scheda di un mostro
+  monsters import   Verifica file di mostri SRD o 5etools e mostra cosa non è stato importato
+  monsters actions  Mostra quanti attacchi, tiri salvezza e limiti d'uso delle azioni sono stati riconosciuti
+
+Esempi:
+  encounters-cli calc --ruleset 2014 --levels 5,5,6,4 --difficulty Difficile --monsters 3
+  encounters-cli calc --ruleset 2024 --levels 3,3,3 --difficulty High --monsters ogre:1,goblin-guerriero:4
+  encounters-cli monsters search --type Drago --cr-max 5
+  encounters-cli monsters search --cr-min 3 --cr-max 3 --sort dpr_desc
+  encounters-cli monsters show aboleth --format json
+  encounters-cli monsters import bestiary-mm.json
+  encounters-cli monsters actions --format json
+
+Ogni comando accetta --format table|json; usa -h dopo il comando per le sue opzioni.
+I file elencati in MONSTER_IMPORT_PATHS (separati da virgola) si aggiungono ai mostri incorporati.
+Codici di uscita: 0 ok, 1 valori non validi, 2 uso errato, 3 mostro non trovato.
+`
+
+// cli holds the application services shared by every command
+type cli struct {
+	stdout       io.Writer
+	stderr       io.Writer
+	service      *encounter.Service
+	queries      *encounter.QueryHandler
+	compositions *encounter.CompositionService
+	monsters     *monsterApp.Service
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command in args and returns the process exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	logger := slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	repo := memory.NewEncounterRepository()
+	monsterRepo := memory.NewMonsterRepository()
+	for _, path := range config.NewConfig().MonsterImports {
+		result, err := monsterimport.ImportFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "errore: %v\n", err)
+			return exitError
+		}
+		monsterRepo.Merge(result.Monsters)
+	}
+
+	c := &cli{
+		stdout:       stdout,
+		stderr:       stderr,
+		service:      encounter.NewService(logger, repo),
+		queries:      encounter.NewQueryHandler(logger, repo),
+		compositions: encounter.NewCompositionService(logger, repo, memory.NewCompositionRepository(), monsterRepo),
+		monsters:     monsterApp.NewService(monsterRepo),
+	}
+
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	var err error
+	switch args[0] {
+	case "calc":
+		err = c.calc(args[1:])
+	case "monsters":
+		err = c.monstersCommand(args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "comando sconosciuto %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	return c.exitCode(err)
+}
+
+func (c *cli) monstersCommand(args []string) error {
+	if len(args) == 0 {
+		return usageError{fmt.Errorf("manca il sottocomando: search, show, import o actions")}
+	}
+	switch args[0] {
+	case "search":
+		return c.searchMonsters(args[1:])
+	case "show":
+		return c.showMonster(args[1:])
+	case "import":
+		return c.importMonsters(args[1:])
+	case "actions":
+		return c.actionCoverage(args[1:])
+	default:
+		return usageError{fmt.Errorf("sottocomando sconosciuto %q: usa search, show, import o actions", args[0])}
+	}
+}
+
+// usageError marks failures that exit with exitUsage
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+
+// exitCode reports err on stderr and maps it to an exit code
+func (c *cli) exitCode(err error) int {
+	var usageErr usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(c.stderr, "errore: %v\n", err)
+		return exitUsage
+	case errors.Is(err, monster.ErrNotFound):
+		fmt.Fprintf(c.stderr, "errore: %v\n", err)
+		return exitNotFound
+	default:
+		fmt.Fprintf(c.stderr, "errore: %v\n", err)
+		return exitError
+	}
+}
+
+// newFlagSet returns a flag set that reports problems as usage errors instead of exiting
+func (c *cli) newFlagSet(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	format := fs.String("format", formatTable, "formato di output: table o json")
+	return fs, format
+}
+
+// parseFlags parses args, wrapping malformed flags and unknown formats as usage errors
+func parseFlags(fs *flag.FlagSet, format *string, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{err}
+	}
+	if *format != formatTable && *format != formatJSON {
+		return usageError{fmt.Errorf("formato %q non valido: usa table o json", *format)}
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout []string
+		wantStderr string
+	}{
+		{
+			name:       "calc 2014 splits the budget with the multiplier",
+			args:       []string{"calc", "--ruleset", "2014", "--levels", "5,5,6,4", "--difficulty", "Difficile", "--monsters", "3"},
+			wantCode:   exitOK,
+			wantStdout: []string{"Budget PE", "2775", "×2", "PE per mostro", "462"},
+		},
+		{
+			name:       "calc 2024 without multiplier",
+			args:       []string{"calc", "--levels", "5,5,5,5", "--difficulty", "Moderate", "--monsters", "4"},
+			wantCode:   exitOK,
+			wantStdout: []string{"3000", "750"},
+		},
+		{
+			name:       "calc with chosen monsters",
+			args:       []string{"calc", "--levels", "3,3,3", "--difficulty", "High", "--monsters", "ogre:1,goblin-guerriero:4"},
+			wantCode:   exitOK,
+			wantStdout: []string{"ogre", "goblin-guerriero", "PE usati", "650"},
+		},
+		{
+			name:       "calc with unknown monster",
+			args:       []string{"calc", "--levels", "3", "--difficulty", "High", "--monsters", "tarrasque-rosa"},
+			wantCode:   exitNotFound,
+			wantStderr: "tarrasque-rosa",
+		},
+		{
+			name:       "calc with invalid difficulty",
+			args:       []string{"calc", "--ruleset", "2014", "--levels", "5", "--difficulty", "High"},
+			wantCode:   exitError,
+			wantStderr: "invalid difficulty",
+		},
+		{
+			name:       "calc with invalid levels",
+			args:       []string{"calc", "--levels", "5,x", "--difficulty", "High"},
+			wantCode:   exitError,
+			wantStderr: "livelli non validi",
+		},
+		{
+			name:     "calc without required flags",
+			args:     []string{"calc", "--levels", "5"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "calc with unknown flag",
+			args:     []string{"calc", "--party", "5"},
+			wantCode: exitUsage,
+		},
+		{
+			name:       "search by movement and senses",
+			args:       []string{"monsters", "search", "--fly", "--blindsight", "--pp-max", "11"},
+			wantCode:   exitOK,
+			wantStdout: []string{"NOME", "sciame-di-pipistrelli"},
+		},
+		{
+			name:       "search by defenses",
+			args:       []string{"monsters", "search", "--type", "Drago", "--no-immune", "fuoco", "--immune", "cold"},
+			wantCode:   exitOK,
+			wantStdout: []string{"drago-bianco-cucciolo", "drago-dargento-antico"},
+		},
+		{
+			name:     "search with unknown damage type",
+			args:     []string{"monsters", "search", "--no-immune", "sonic"},
+			wantCode: exitUsage,
+		},
+		{
+			name:       "search by damage per round",
+			args:       []string{"monsters", "search", "--cr-min", "3", "--cr-max", "3", "--target-ac", "13", "--dpr-min", "20", "--sort", "dpr_desc"},
+			wantCode:   exitOK,
+			wantStdout: []string{"DPR", "mummia"},
+		},
+		{
+			name:     "search with unknown sort",
+			args:     []string{"monsters", "search", "--sort", "name"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "search with invalid target AC",
+			args:     []string{"monsters", "search", "--target-ac", "0"},
+			wantCode: exitUsage,
+		},
+		{
+			name:       "search by type and max CR",
+			args:       []string{"monsters", "search", "--type", "Drago", "--cr-max", "5"},
+			wantCode:   exitOK,
+			wantStdout: []string{"NOME", "pseudodrago"},
+		},
+		{
+			name:       "search without results",
+			args:       []string{"monsters", "search", "-q", "nessun mostro si chiama così"},
+			wantCode:   exitNotFound,
+			wantStderr: "nessun risultato",
+		},
+		{
+			name:       "show a monster",
+			args:       []string{"monsters", "show", "aboleth"},
+			wantCode:   exitOK,
+			wantStdout: []string{"Aboleth", "Aberrazione", "Azioni", "Tentacolo"},
+		},
+		{
+			name:       "show with flags before the id",
+			args:       []string{"monsters", "show", "--format", "json", "aboleth"},
+			wantCode:   exitOK,
+			wantStdout: []string{`"id": "aboleth"`},
+		},
+		{
+			name:       "show unknown monster",
+			args:       []string{"monsters", "show", "tarrasque-rosa"},
+			wantCode:   exitNotFound,
+			wantStderr: "monster not found",
+		},
+		{
+			name:     "show without id",
+			args:     []string{"monsters", "show"},
+			wantCode: exitUsage,
+		},
+		{
+			name:       "import reports unmapped fields",
+			args:       []string{"monsters", "import", "../../internal/infrastructure/persistence/monsterimport/testdata/srd.json"},
+			wantCode:   exitOK,
+			wantStdout: []string{"IMPORTATI", "Gargoyle", "damage_vulnerabilities", "Mystery Beast"},
+		},
+		{
+			name:       "import unreadable file",
+			args:       []string{"monsters", "import", "missing.json"},
+			wantCode:   exitError,
+			wantStderr: "missing.json",
+		},
+		{
+			name:     "import without files",
+			args:     []string{"monsters", "import"},
+			wantCode: exitUsage,
+		},
+		{
+			name:       "action coverage",
+			args:       []string{"monsters", "actions"},
+			wantCode:   exitOK,
+			wantStdout: []string{"Attacchi", "Tiri salvezza", "Limiti d'uso"},
+		},
+		{
+			name:       "action coverage as json",
+			args:       []string{"monsters", "actions", "--format", "json"},
+			wantCode:   exitOK,
+			wantStdout: []string{`"monsters": 330`, `"missed": []`},
+		},
+		{
+			name:     "unknown format",
+			args:     []string{"monsters", "show", "aboleth", "--format", "yaml"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"roll"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "no command",
+			args:     nil,
+			wantCode: exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+
+			if code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d\nstdout: %s\nstderr: %s", code, tt.wantCode, stdout.String(), stderr.String())
+			}
+			for _, want := range tt.wantStdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout does not contain %q:\n%s", want, stdout.String())
+				}
+			}
+			if tt.wantStderr != "" && !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr does not contain %q:\n%s", tt.wantStderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRun_CalcJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"calc", "--ruleset", "2014", "--levels", "5,5,6,4", "--difficulty", "Difficile", "--monsters", "3", "--format", "json"}, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr: %s", code, stderr.String())
+	}
+
+	var out calcOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if out.Budget != 2775 || out.MonsterCount != 3 || out.Multiplier != 2 || out.XPPerMonster != 462 {
+		t.Errorf("got %+v", out)
+	}
+}
+
+func TestRun_SearchJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"monsters", "search", "--type", "Drago", "--cr-max", "5", "--format", "json"}, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr: %s", code, stderr.String())
+	}
+
+	var out searchOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if out.Count == 0 || out.Count != len(out.Monsters) {
+		t.Fatalf("count = %d, monsters = %d", out.Count, len(out.Monsters))
+	}
+	for _, m := range out.Monsters {
+		if m.Type != "Drago" {
+			t.Errorf("%s has type %q, want Drago", m.ID, m.Type)
+		}
+	}
+}
+
+func TestRun_SearchDamageMatchesJSON(t *testing.T) {
+	// The pseudodrago DPR sits on a rounding boundary, so table and JSON must round the same way
+	args := []string{"monsters", "search", "-q", "pseudodrago"}
+
+	var table, stderr bytes.Buffer
+	if code := run(args, &table, &stderr); code != exitOK {
+		t.Fatalf("exit code = %d, stderr: %s", code, stderr.String())
+	}
+	var stdout bytes.Buffer
+	if code := run(append(args, "--format", "json"), &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code = %d, stderr: %s", code, stderr.String())
+	}
+
+	var out searchOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if len(out.Monsters) != 1 {
+		t.Fatalf("expected only the pseudodrago, got %d monsters", len(out.Monsters))
+	}
+	want := strings.Replace(strconv.FormatFloat(out.Monsters[0].DamagePerRound, 'f', 1, 64), ".", ",", 1)
+	if fields := strings.Fields(strings.Split(table.String(), "\n")[1]); fields[len(fields)-1] != want {
+		t.Errorf("table shows DPR %s, JSON %s", fields[len(fields)-1], want)
+	}
+}
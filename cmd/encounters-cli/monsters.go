@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	monsterApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/monsterimport"
+)
+
+// searchOutput is the JSON result of monsters search
+type searchOutput struct {
+	Count    int                         `json:"count"`
+	Monsters []monsterApp.MonsterSummary `json:"monsters"`
+}
+
+// damageTypesFlag appends the damage types of a comma separated flag value to target
+func damageTypesFlag(target *[]monster.DamageType) func(string) error {
+	return func(v string) error {
+		types, err := monster.ParseDamageTypes(v)
+		*target = append(*target, types...)
+		return err
+	}
+}
+
+// conditionsFlag appends the conditions of a comma separated flag value to target
+func conditionsFlag(target *[]monster.Condition) func(string) error {
+	return func(v string) error {
+		conditions, err := monster.ParseConditions(v)
+		*target = append(*target, conditions...)
+		return err
+	}
+}
+
+func (c *cli) searchMonsters(args []string) error {
+	fs, format := c.newFlagSet("monsters search")
+	var filters monster.SearchFilters
+	fs.StringVar(&filters.Query, "query", "", "testo da cercare in nome, tipo, tratti e azioni")
+	fs.StringVar(&filters.Query, "q", "", "abbreviazione di --query")
+	fs.StringVar(&filters.Type, "type", "", "tipo, es. Drago")
+	fs.StringVar(&filters.Size, "size", "", "taglia, es. Grande")
+	fs.StringVar(&filters.CRMin, "cr-min", "", "grado di sfida minimo, es. 1/2")
+	fs.StringVar(&filters.CRMax, "cr-max", "", "grado di sfida massimo")
+	fs.IntVar(&filters.MaxXP, "max-xp", 0, "PE massimi")
+	fs.BoolVar(&filters.CanFly, "fly", false, "solo mostri che volano")
+	fs.Float64Var(&filters.MinSwim, "swim-min", 0, "velocità di nuoto minima in metri")
+	fs.BoolVar(&filters.HasBlindsight, "blindsight", false, "solo mostri con vista cieca")
+	fs.IntVar(&filters.MaxPassivePerception, "pp-max", 0, "Percezione passiva massima")
+	fs.Func("resist", "resistente a tutti questi danni, es. fire,freddo", damageTypesFlag(&filters.WithResistances))
+	fs.Func("no-resist", "non resistente a nessuno di questi danni", damageTypesFlag(&filters.WithoutResistances))
+	fs.Func("immune", "immune a tutti questi danni", damageTypesFlag(&filters.WithImmunities))
+	fs.Func("no-immune", "non immune a nessuno di questi danni", damageTypesFlag(&filters.WithoutImmunities))
+	fs.Func("condition-immune", "immune a tutte queste condizioni, es. charmed", conditionsFlag(&filters.WithConditionImmunities))
+	fs.Func("no-condition-immune", "non immune a nessuna di queste condizioni", conditionsFlag(&filters.WithoutConditionImmunities))
+	fs.IntVar(&filters.Target.AC, "target-ac", monster.DefaultTarget.AC, "CA del bersaglio per stimare i danni per round")
+	fs.IntVar(&filters.Target.SaveBonus, "target-save", monster.DefaultTarget.SaveBonus, "bonus ai tiri salvezza del bersaglio")
+	fs.Float64Var(&filters.MinDamagePerRound, "dpr-min", 0, "danni per round stimati minimi")
+	fs.Float64Var(&filters.MaxDamagePerRound, "dpr-max", 0, "danni per round stimati massimi")
+	fs.Func("sort", "ordina per danni per round: dpr_desc o dpr_asc", func(v string) (err error) {
+		filters.Sort, err = monster.ParseSortOrder(v)
+		return err
+	})
+	if err := parseFlags(fs, format, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError{fmt.Errorf("argomento inatteso %q: usa --query per cercare per nome", fs.Arg(0))}
+	}
+	if filters.Target.AC < 1 {
+		return usageError{fmt.Errorf("--target-ac deve essere almeno 1")}
+	}
+
+	results := c.monsters.SearchMonstersRanked(filters)
+	if len(results) == 0 {
+		return fmt.Errorf("%w: nessun risultato per i filtri indicati", monster.ErrNotFound)
+	}
+
+	if *format == formatJSON {
+		out := searchOutput{Count: len(results), Monsters: make([]monsterApp.MonsterSummary, len(results))}
+		for i, found := range results {
+			out.Monsters[i] = monsterApp.NewSearchSummary(found)
+		}
+		return writeJSON(c.stdout, out)
+	}
+
+	t := newTable(c.stdout)
+	t.row("ID", "NOME", "TIPO", "TAGLIA", "GS", "PE", "DPR")
+	for _, found := range results {
+		m := found.Monster
+		t.row(m.ID, m.Name, m.Type, m.Size, m.CR, strconv.Itoa(m.XP), formatDamage(found.Offense.DamagePerRound))
+	}
+	return t.flush()
+}
+
+func (c *cli) showMonster(args []string) error {
+	fs, format := c.newFlagSet("monsters show")
+
+	// Accept the ID either before or after the flags
+	var id string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, args = args[0], args[1:]
+	}
+	if err := parseFlags(fs, format, args); err != nil {
+		return err
+	}
+	if id == "" && fs.NArg() > 0 {
+		id = fs.Arg(0)
+	}
+	if id == "" || fs.NArg() > 1 {
+		return usageError{fmt.Errorf("indica un solo id, es. monsters show aboleth")}
+	}
+
+	m, ok := c.monsters.GetMonster(id)
+	if !ok {
+		return fmt.Errorf("%w: %s", monster.ErrNotFound, id)
+	}
+
+	if *format == formatJSON {
+		return writeJSON(c.stdout, monsterApp.NewMonsterDetail(m))
+	}
+	return c.printMonster(m)
+}
+
+func (c *cli) printMonster(m monster.Monster) error {
+	fmt.Fprintln(c.stdout, m.Name)
+
+	t := newTable(c.stdout)
+	for _, field := range []struct{ label, value string }{
+		{"Tipo", strings.TrimSpace(m.Size + " " + m.Type)},
+		{"Allineamento", m.Alignment},
+		{"GS", fmt.Sprintf("%s (%d PE)", m.CR, m.XP)},
+		{"CA", m.AC},
+		{"PF", m.HP},
+		{"Iniziativa", m.Initiative},
+		{"Velocità", m.Speed},
+		{"Danni per round", formatOffense(m.Offense(monster.DefaultTarget))},
+		{"Tiri salvezza", formatSaves(m.SavingThrows)},
+		{"Abilità", m.Skills},
+		{"Resistenze", m.Resistances},
+		{"Immunità ai danni", m.DamageImmunities},
+		{"Immunità alle condizioni", m.ConditionImmunities},
+		{"Sensi", m.Senses},
+		{"Linguaggi", m.Languages},
+		{"Equipaggiamento", m.Equipment},
+		{"Fonte", m.Source},
+	} {
+		if field.value != "" {
+			t.row(field.label, field.value)
+		}
+	}
+	t.row("FOR DES COS INT SAG CAR", fmt.Sprintf("%d %d %d %d %d %d",
+		m.AbilityScores.Strength, m.AbilityScores.Dexterity, m.AbilityScores.Constitution,
+		m.AbilityScores.Intelligence, m.AbilityScores.Wisdom, m.AbilityScores.Charisma))
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	for _, section := range []struct {
+		title   string
+		entries []monster.NamedDescription
+	}{
+		{"Tratti", m.Traits},
+		{"Azioni", m.Actions},
+		{"Azioni bonus", m.BonusActions},
+		{"Reazioni", m.Reactions},
+		{"Azioni leggendarie", m.LegendaryActions},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(c.stdout, "\n%s\n", section.title)
+		for _, e := range section.entries {
+			fmt.Fprintf(c.stdout, "  %s. %s\n", e.Name, e.Description)
+		}
+	}
+	return nil
+}
+
+// formatOffense describes the estimated damage per round and the routine behind it
+func formatOffense(p monster.OffensiveProfile) string {
+	if p.DamagePerRound == 0 {
+		return ""
+	}
+	out := fmt.Sprintf("%s contro CA %d e TS %+d", formatDamage(p.DamagePerRound), p.Target.AC, p.Target.SaveBonus)
+	var routine []string
+	for _, a := range p.Routine {
+		if a.Count > 1 {
+			routine = append(routine, fmt.Sprintf("%d× %s", a.Count, a.Name))
+		} else {
+			routine = append(routine, a.Name)
+		}
+	}
+	if p.Limited != "" {
+		routine = append(routine, p.Limited+" quando disponibile")
+	}
+	if p.BonusAction != "" {
+		routine = append(routine, p.BonusAction+" come azione bonus")
+	}
+	if len(routine) > 0 {
+		out += " (" + strings.Join(routine, ", ") + ")"
+	}
+	return out
+}
+
+// formatSaves lists the non-empty saving throw modifiers
+func formatSaves(s monster.SavingThrows) string {
+	var parts []string
+	for _, save := range []struct{ label, value string }{
+		{"FOR", s.Strength}, {"DES", s.Dexterity}, {"COS", s.Constitution},
+		{"INT", s.Intelligence}, {"SAG", s.Wisdom}, {"CAR", s.Charisma},
+	} {
+		if save.value != "" {
+			parts = append(parts, save.label+" "+save.value)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// importMonsters maps the given files without merging them and reports what could not be imported
+func (c *cli) importMonsters(args []string) error {
+	fs, format := c.newFlagSet("monsters import")
+	if err := parseFlags(fs, format, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usageError{fmt.Errorf("indica almeno un file, es. monsters import bestiary.json")}
+	}
+
+	reports := make([]monsterimport.Report, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		result, err := monsterimport.ImportFile(path)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, result.Report)
+	}
+
+	if *format == formatJSON {
+		return writeJSON(c.stdout, reports)
+	}
+
+	t := newTable(c.stdout)
+	t.row("FONTE", "FORMATO", "IMPORTATI", "SCARTATI", "PROBLEMI")
+	for _, r := range reports {
+		t.row(r.Source, string(r.Format), strconv.Itoa(r.Imported), strconv.Itoa(r.Skipped), strconv.Itoa(len(r.Issues)))
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	for _, r := range reports {
+		if len(r.Issues) == 0 {
+			continue
+		}
+		fmt.Fprintf(c.stdout, "\n%s\n", r.Source)
+		t = newTable(c.stdout)
+		t.row("MOSTRO", "CAMPO", "VALORE", "MOTIVO")
+		for _, issue := range r.Issues {
+			t.row(issue.Monster, issue.Field, issue.Value, issue.Reason)
+		}
+		if err := t.flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// actionCoverage reports how many attacks, saving throws and usage limits of the monster
+// actions were understood, listing the ones that were not
+func (c *cli) actionCoverage(args []string) error {
+	fs, format := c.newFlagSet("monsters actions")
+	if err := parseFlags(fs, format, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError{fmt.Errorf("argomento inatteso %q", fs.Arg(0))}
+	}
+
+	coverage := c.monsters.ActionCoverage()
+	if *format == formatJSON {
+		return writeJSON(c.stdout, coverage)
+	}
+
+	t := newTable(c.stdout)
+	t.row("VOCE", "TROVATI", "RICONOSCIUTI")
+	t.row("Mostri", strconv.Itoa(coverage.Monsters), "")
+	t.row("Azioni", strconv.Itoa(coverage.Actions), "")
+	t.row("Attacchi", strconv.Itoa(coverage.Attacks), strconv.Itoa(coverage.ParsedAttacks))
+	t.row("Tiri salvezza", strconv.Itoa(coverage.Saves), strconv.Itoa(coverage.ParsedSaves))
+	t.row("Limiti d'uso", strconv.Itoa(coverage.Usages), strconv.Itoa(coverage.ParsedUsages))
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	if len(coverage.Missed) == 0 {
+		return nil
+	}
+	fmt.Fprintln(c.stdout)
+	t = newTable(c.stdout)
+	t.row("MOSTRO", "AZIONE", "NON RICONOSCIUTO")
+	for _, m := range coverage.Missed {
+		t.row(m.MonsterID, m.Action, m.Missing)
+	}
+	return t.flush()
+}
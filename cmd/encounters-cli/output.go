@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	monsterApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/monster"
+)
+
+// Output formats accepted by --format
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// table writes tab-separated rows as aligned columns
+type table struct {
+	tw *tabwriter.Writer
+}
+
+func newTable(w io.Writer) *table {
+	return &table{tw: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}
+}
+
+func (t *table) row(cells ...string) {
+	fmt.Fprintln(t.tw, strings.Join(cells, "\t"))
+}
+
+func (t *table) flush() error {
+	return t.tw.Flush()
+}
+
+// joinInts formats integers as a comma-separated list
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseInts parses a comma-separated list of integers
+func parseInts(s string) ([]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, fmt.Errorf("lista vuota")
+	}
+	parts := strings.Split(s, ",")
+	values := make([]int, len(parts))
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return nil, fmt.Errorf("%q non è un numero", p)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// formatMultiplier formats an encounter multiplier with the Italian decimal comma
+func formatMultiplier(m float64) string {
+	return "×" + strings.Replace(strconv.FormatFloat(m, 'f', -1, 64), ".", ",", 1)
+}
+
+// formatDamage formats an estimated damage with one decimal and the Italian decimal comma,
+// rounded like the JSON output
+func formatDamage(d float64) string {
+	return strings.Replace(strconv.FormatFloat(monsterApp.RoundDamage(d), 'f', 1, 64), ".", ",", 1)
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -16,10 +18,15 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 
+	campaignApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/campaign"
+	combatApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/combat"
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/encounter"
 	monsterApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/monster"
+	campaignDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/config"
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/monsterimport"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/sqlite"
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/web/handlers"
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/web/templates"
 )
@@ -28,13 +35,119 @@ type ctxKey string
 
 // App represents the main application with all dependencies
 type App struct {
-	router           chi.Router
-	config           *config.Config
-	logger           *slog.Logger
-	encounterService *encounter.Service
-	encounterHandler *handlers.EncounterHandler
-	monsterHandler   *handlers.MonsterHandler
-	queryHandler     *encounter.QueryHandler
+	router             chi.Router
+	config             *config.Config
+	logger             *slog.Logger
+	encounterService   *encounter.Service
+	encounterHandler   *handlers.EncounterHandler
+	monsterHandler     *handlers.MonsterHandler
+	compositionHandler *handlers.CompositionHandler
+	dayPlanHandler     *handlers.DayPlanHandler
+	campaignHandler    *handlers.CampaignHandler
+	shareHandler       *handlers.ShareHandler
+	combatHandler      *handlers.CombatHandler
+	apiHandler         *handlers.APIHandler
+	queryHandler       *encounter.QueryHandler
+	db                 *sql.DB
+}
+
+// campaignRepositories holds the storage of saved parties, encounters and campaigns
+type campaignRepositories struct {
+	parties    campaignDomain.PartyRepository
+	encounters campaignDomain.EncounterRepository
+	campaigns  campaignDomain.CampaignRepository
+	db         *sql.DB
+}
+
+// newCampaignRepositories opens the storage backend selected by the configuration
+func newCampaignRepositories(cfg *config.Config) (*campaignRepositories, error) {
+	switch cfg.StorageBackend {
+	case config.StorageMemory:
+		return &campaignRepositories{
+			parties:    memory.NewPartyRepository(),
+			encounters: memory.NewSavedEncounterRepository(),
+			campaigns:  memory.NewCampaignRepository(),
+		}, nil
+	case config.StorageSQLite:
+		db, err := sqlite.Open(cfg.DatabasePath)
+		if err != nil {
+			return nil, err
+		}
+		return &campaignRepositories{
+			parties:    sqlite.NewPartyRepository(db),
+			encounters: sqlite.NewSavedEncounterRepository(db),
+			campaigns:  sqlite.NewCampaignRepository(db),
+			db:         db,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.StorageBackend)
+	}
+}
+
+// importMonsters merges the monster files listed in the configuration into the embedded dataset
+func importMonsters(cfg *config.Config, logger *slog.Logger, repo *memory.MonsterRepository) error {
+	for _, path := range cfg.MonsterImports {
+		result, err := monsterimport.ImportFile(path)
+		if err != nil {
+			return err
+		}
+
+		report := result.Report
+		for _, issue := range report.Issues {
+			logger.Debug("Monster field not imported",
+				"source", report.Source,
+				"monster", issue.Monster,
+				"field", issue.Field,
+				"value", issue.Value,
+				"reason", issue.Reason,
+			)
+		}
+		duplicates := repo.Merge(result.Monsters)
+
+		logger.Info("Monsters imported",
+			"path", path,
+			"format", report.Format,
+			"imported", report.Imported-len(duplicates),
+			"skipped", report.Skipped,
+			"duplicates", len(duplicates),
+			"issues", len(report.Issues),
+		)
+	}
+	return nil
+}
+
+// reportParseErrors logs the statblock values that could not be parsed, which are left zero
+func reportParseErrors(logger *slog.Logger, repo *memory.MonsterRepository) {
+	errs := repo.ParseErrors()
+	for _, e := range errs {
+		logger.Warn("Monster field not parsed",
+			"monster", e.MonsterID,
+			"field", e.Field,
+			"value", e.Value,
+			"error", e.Err,
+		)
+	}
+	if len(errs) > 0 {
+		logger.Warn("Some monster fields could not be parsed and are treated as zero", "count", len(errs))
+	}
+}
+
+// shareKey returns the key that signs share links. Without SHARE_SECRET a random key is
+// generated, so links stop opening when the server restarts; production requires the secret.
+func shareKey(cfg *config.Config, logger *slog.Logger) ([]byte, error) {
+	if cfg.ShareSecret != "" {
+		return []byte(cfg.ShareSecret), nil
+	}
+	if cfg.IsProduction() {
+		return nil, errors.New("SHARE_SECRET must be set in production")
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate share key: %w", err)
+	}
+	logger.Warn("SHARE_SECRET not set: share links are signed with a random key and stop working on restart")
+	return key, nil
 }
 
 // NewApp creates a new application instance with all dependencies
@@ -42,23 +155,56 @@ func NewApp(cfg *config.Config, logger *slog.Logger) (*App, error) {
 	// Initialize repositories
 	repo := memory.NewEncounterRepository()
 	monsterRepo := memory.NewMonsterRepository()
+	if err := importMonsters(cfg, logger, monsterRepo); err != nil {
+		return nil, fmt.Errorf("failed to import monsters: %w", err)
+	}
+	reportParseErrors(logger, monsterRepo)
+	compositionRepo := memory.NewCompositionRepository()
+	combatRepo := memory.NewCombatRepository()
+	campaignRepos, err := newCampaignRepositories(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage: %w", err)
+	}
+	key, err := shareKey(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize application services
 	encounterService := encounter.NewService(logger, repo)
 	queryHandler := encounter.NewQueryHandler(logger, repo)
+	compositionService := encounter.NewCompositionService(logger, repo, compositionRepo, monsterRepo)
+	generatorService := encounter.NewGeneratorService(logger, repo, monsterRepo)
+	dayPlanService := encounter.NewDayPlanService(logger, repo)
+	shareService := encounter.NewShareService(logger, encounterService, compositionService, compositionRepo, key)
 	monsterService := monsterApp.NewService(monsterRepo)
+	combatService := combatApp.NewService(logger, combatRepo, compositionRepo)
+	campaignService := campaignApp.NewService(logger, campaignRepos.parties, campaignRepos.encounters, campaignRepos.campaigns, compositionRepo, monsterRepo, repo)
 
 	// Initialize HTTP handlers
-	encounterHandler := handlers.NewEncounterHandler(encounterService, queryHandler, monsterService, logger)
+	encounterHandler := handlers.NewEncounterHandler(encounterService, queryHandler, compositionService, generatorService, monsterService, logger)
 	monsterHandler := handlers.NewMonsterHandler(monsterService, logger)
+	compositionHandler := handlers.NewCompositionHandler(compositionService, logger)
+	dayPlanHandler := handlers.NewDayPlanHandler(dayPlanService, queryHandler, logger)
+	campaignHandler := handlers.NewCampaignHandler(campaignService, logger)
+	shareHandler := handlers.NewShareHandler(shareService, queryHandler, monsterService, logger)
+	combatHandler := handlers.NewCombatHandler(combatService, logger)
+	apiHandler := handlers.NewAPIHandler(encounterService, queryHandler, monsterService, logger)
 
 	app := &App{
-		config:           cfg,
-		logger:           logger,
-		encounterService: encounterService,
-		encounterHandler: encounterHandler,
-		monsterHandler:   monsterHandler,
-		queryHandler:     queryHandler,
+		config:             cfg,
+		logger:             logger,
+		encounterService:   encounterService,
+		encounterHandler:   encounterHandler,
+		monsterHandler:     monsterHandler,
+		compositionHandler: compositionHandler,
+		dayPlanHandler:     dayPlanHandler,
+		campaignHandler:    campaignHandler,
+		shareHandler:       shareHandler,
+		combatHandler:      combatHandler,
+		apiHandler:         apiHandler,
+		queryHandler:       queryHandler,
+		db:                 campaignRepos.db,
 	}
 
 	app.setupRouter()
@@ -102,6 +248,9 @@ func (app *App) setupRouter() {
 	// Serve static files with no-cache headers
 	r.Handle("/static/*", noCacheMiddleware(http.StripPrefix("/static/", http.FileServer(http.Dir("internal/infrastructure/static")))))
 
+	// Versioned JSON API
+	r.Mount("/api/v1", app.apiHandler.Routes())
+
 	// Application routes
 	r.Route("/", func(r chi.Router) {
 		r.Get("/", app.indexHandler)
@@ -109,6 +258,70 @@ func (app *App) setupRouter() {
 		r.Get("/party-input", app.encounterHandler.PartyInputHandler)
 		r.Get("/api/difficulties", app.encounterHandler.GetDifficultiesHandler)
 		r.Get("/api/monsters", app.monsterHandler.SearchHandler)
+		r.Get("/api/monsters/suggest", app.monsterHandler.SuggestHandler)
+		r.Get("/api/monsters/challenge", app.monsterHandler.ChallengeAPIHandler)
+		r.Get("/api/monsters/{monsterID}/scale", app.monsterHandler.ScaleAPIHandler)
+		r.Get("/api/encounters/generate", app.encounterHandler.GenerateHandler)
+		r.Get("/api/encounters/archetypes", app.encounterHandler.GetArchetypesHandler)
+		r.Get("/api/day-plan", app.dayPlanHandler.APIHandler)
+
+		r.Route("/api/parties", func(r chi.Router) {
+			r.Get("/", app.campaignHandler.ListPartiesHandler)
+			r.Post("/", app.campaignHandler.CreatePartyHandler)
+			r.Get("/{partyID}", app.campaignHandler.GetPartyHandler)
+			r.Delete("/{partyID}", app.campaignHandler.DeletePartyHandler)
+		})
+		r.Route("/api/saved-encounters", func(r chi.Router) {
+			r.Get("/", app.campaignHandler.ListEncountersHandler)
+			r.Get("/{encounterID}", app.campaignHandler.GetEncounterHandler)
+			r.Delete("/{encounterID}", app.campaignHandler.DeleteEncounterHandler)
+		})
+		r.Route("/api/campaigns", func(r chi.Router) {
+			r.Get("/", app.campaignHandler.ListCampaignsHandler)
+			r.Post("/", app.campaignHandler.CreateCampaignHandler)
+			r.Get("/{campaignID}", app.campaignHandler.GetCampaignHandler)
+			r.Delete("/{campaignID}", app.campaignHandler.DeleteCampaignHandler)
+			r.Post("/{campaignID}/parties", app.campaignHandler.AddPartyHandler)
+			r.Post("/{campaignID}/encounters", app.campaignHandler.AddEncounterHandler)
+		})
+
+		r.Get("/day-planner", app.dayPlanHandler.PageHandler)
+		r.Post("/day-planner", app.dayPlanHandler.PlanHandler)
+		r.Get("/day-planner/encounters", app.dayPlanHandler.EncounterFieldsHandler)
+
+		r.Get("/homebrew", app.monsterHandler.HomebrewPageHandler)
+		r.Post("/homebrew", app.monsterHandler.HomebrewHandler)
+		r.Get("/monsters/{monsterID}/scale", app.monsterHandler.ScalePageHandler)
+		r.Get("/monsters/{monsterID}/foundry.json", app.monsterHandler.FoundryHandler)
+		r.Get("/e/{code}", app.shareHandler.OpenHandler)
+		r.Post("/e/{code}", app.shareHandler.EditHandler)
+
+		r.Route("/compositions/{compositionID}", func(r chi.Router) {
+			r.Get("/", app.compositionHandler.GetHandler)
+			r.Post("/monsters", app.compositionHandler.AddMonsterHandler)
+			r.Put("/monsters/{monsterID}", app.compositionHandler.SetQuantityHandler)
+			r.Delete("/monsters/{monsterID}", app.compositionHandler.RemoveMonsterHandler)
+			r.Put("/monsters/{monsterID}/lair", app.compositionHandler.InLairHandler)
+			r.Put("/count-weak-monsters", app.compositionHandler.CountWeakMonstersHandler)
+			r.Post("/generate", app.compositionHandler.GenerateHandler)
+			r.Post("/save", app.campaignHandler.SaveCompositionHandler)
+			r.Post("/share", app.shareHandler.ShareHandler)
+			r.Get("/foundry.zip", app.compositionHandler.FoundryHandler)
+			r.Post("/combat", app.combatHandler.StartHandler)
+		})
+
+		r.Route("/combats/{combatID}", func(r chi.Router) {
+			r.Get("/", app.combatHandler.PageHandler)
+			r.Post("/next", app.combatHandler.NextHandler)
+			r.Post("/previous", app.combatHandler.PreviousHandler)
+			r.Post("/roll-initiative", app.combatHandler.RollInitiativeHandler)
+			r.Post("/roll-hit-points", app.combatHandler.RollHitPointsHandler)
+			r.Put("/combatants/{combatantID}/initiative", app.combatHandler.InitiativeHandler)
+			r.Post("/combatants/{combatantID}/damage", app.combatHandler.DamageHandler)
+			r.Post("/combatants/{combatantID}/heal", app.combatHandler.HealHandler)
+			r.Put("/combatants/{combatantID}/temp-hp", app.combatHandler.TempHPHandler)
+			r.Put("/combatants/{combatantID}/hit-points", app.combatHandler.HitPointsHandler)
+		})
 	})
 
 	app.router = r
@@ -202,6 +415,7 @@ func (app *App) readinessHandler(w http.ResponseWriter, r *http.Request) {
 	}{
 		{"encounter_service", app.encounterService != nil},
 		{"repository", true}, // Memory repo is always available
+		{"storage", app.db == nil || app.db.PingContext(r.Context()) == nil},
 	}
 
 	allReady := true
@@ -252,6 +466,7 @@ func (app *App) Server() *http.Server {
 // Run starts the application server with graceful shutdown
 func (app *App) Run() error {
 	server := app.Server()
+	defer app.closeStorage()
 
 	// Channel to capture server errors
 	serverErrors := make(chan error, 1)
@@ -304,6 +519,16 @@ func (app *App) Run() error {
 	}
 }
 
+// closeStorage closes the SQLite database, if one is open
+func (app *App) closeStorage() {
+	if app.db == nil {
+		return
+	}
+	if err := app.db.Close(); err != nil {
+		app.logger.Error("Failed to close database", "error", err)
+	}
+}
+
 // setupLogger creates and configures the application logger
 func setupLogger(cfg *config.Config) *slog.Logger {
 	var level slog.Level
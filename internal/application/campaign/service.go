@@ -0,0 +1,405 @@
+package campaign
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// ErrInvalidRequest wraps the validation errors of the parties, encounters and campaigns built from a request
+var ErrInvalidRequest = errors.New("invalid request")
+
+// Service provides use cases for saved parties, saved encounters and campaigns
+type Service struct {
+	logger       *slog.Logger
+	parties      campaign.PartyRepository
+	encounters   campaign.EncounterRepository
+	campaigns    campaign.CampaignRepository
+	compositions encounter.CompositionRepository
+	monsters     monster.Repository
+	thresholds   encounter.Repository
+}
+
+// NewService creates a new campaign application service
+func NewService(logger *slog.Logger, parties campaign.PartyRepository, encounters campaign.EncounterRepository, campaigns campaign.CampaignRepository, compositions encounter.CompositionRepository, monsters monster.Repository, thresholds encounter.Repository) *Service {
+	return &Service{
+		logger:       logger,
+		parties:      parties,
+		encounters:   encounters,
+		campaigns:    campaigns,
+		compositions: compositions,
+		monsters:     monsters,
+		thresholds:   thresholds,
+	}
+}
+
+// PartyMember represents a named character in requests and responses
+type PartyMember struct {
+	Name  string `json:"name"`
+	Level int    `json:"level"`
+}
+
+// CreatePartyRequest represents a request to save a party
+type CreatePartyRequest struct {
+	Name    string        `json:"name"`
+	Members []PartyMember `json:"members"`
+}
+
+// PartyResponse represents a saved party
+type PartyResponse struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name"`
+	Members []PartyMember `json:"members"`
+	Levels  []int         `json:"levels"`
+}
+
+// SavedMonster represents a monster group of a saved encounter
+type SavedMonster struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	CR       string `json:"cr"`
+	XP       int    `json:"xp"`
+	Quantity int    `json:"quantity"`
+	InLair   bool   `json:"in_lair"`
+	TotalXP  int    `json:"total_xp"`
+}
+
+// SavedEncounterResponse represents a saved encounter with its monsters resolved.
+// AdjustedXP and ResultingDifficulty are evaluated against the saved party, as for a composition,
+// and are left empty for encounters saved without one.
+type SavedEncounterResponse struct {
+	ID                  string               `json:"id"`
+	Name                string               `json:"name"`
+	Ruleset             encounter.Ruleset    `json:"ruleset"`
+	Difficulty          encounter.Difficulty `json:"difficulty"`
+	Levels              []int                `json:"levels,omitempty"`
+	CountWeakMonsters   bool                 `json:"count_weak_monsters"`
+	Monsters            []SavedMonster       `json:"monsters"`
+	XPTotal             int                  `json:"xp_total"`
+	AdjustedXP          int                  `json:"adjusted_xp,omitempty"`
+	ResultingDifficulty encounter.Difficulty `json:"resulting_difficulty,omitempty"`
+}
+
+// CampaignResponse represents a campaign with its parties and encounters resolved
+type CampaignResponse struct {
+	ID         string                   `json:"id"`
+	Name       string                   `json:"name"`
+	Parties    []PartyResponse          `json:"parties"`
+	Encounters []SavedEncounterResponse `json:"encounters"`
+}
+
+// CreateParty saves a new party
+func (s *Service) CreateParty(req CreatePartyRequest) (*PartyResponse, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]campaign.Member, len(req.Members))
+	for i, m := range req.Members {
+		members[i] = campaign.Member{Name: m.Name, Level: m.Level}
+	}
+
+	party, err := campaign.NewParty(id, req.Name, members)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid party: %w", ErrInvalidRequest, err)
+	}
+	if err := s.parties.Save(party); err != nil {
+		return nil, fmt.Errorf("failed to save party: %w", err)
+	}
+
+	s.logger.Debug("Party saved", "party_id", id)
+
+	response := toPartyResponse(*party)
+	return &response, nil
+}
+
+// GetParty returns a saved party
+func (s *Service) GetParty(id string) (*PartyResponse, error) {
+	party, err := s.parties.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	response := toPartyResponse(*party)
+	return &response, nil
+}
+
+// ListParties returns every saved party
+func (s *Service) ListParties() ([]PartyResponse, error) {
+	parties, err := s.parties.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]PartyResponse, len(parties))
+	for i, p := range parties {
+		responses[i] = toPartyResponse(p)
+	}
+	return responses, nil
+}
+
+// DeleteParty deletes a saved party and removes it from every campaign
+func (s *Service) DeleteParty(id string) error {
+	if err := s.parties.Delete(id); err != nil {
+		return err
+	}
+	return s.unlink(func(c *campaign.Campaign) bool { return c.RemoveParty(id) })
+}
+
+// SaveComposition saves the party, monsters, ruleset, difficulty and weak monster setting of a composition under a name
+func (s *Service) SaveComposition(compositionID, name string) (*SavedEncounterResponse, error) {
+	composition, err := s.compositions.FindByID(compositionID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := campaign.SaveComposition(id, name, composition)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid encounter: %w", ErrInvalidRequest, err)
+	}
+	if err := s.encounters.Save(saved); err != nil {
+		return nil, fmt.Errorf("failed to save encounter: %w", err)
+	}
+
+	s.logger.Debug("Encounter saved", "encounter_id", id, "composition_id", compositionID)
+
+	return s.toEncounterResponse(*saved)
+}
+
+// GetEncounter returns a saved encounter
+func (s *Service) GetEncounter(id string) (*SavedEncounterResponse, error) {
+	saved, err := s.encounters.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toEncounterResponse(*saved)
+}
+
+// ListEncounters returns every saved encounter
+func (s *Service) ListEncounters() ([]SavedEncounterResponse, error) {
+	encounters, err := s.encounters.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]SavedEncounterResponse, len(encounters))
+	for i, e := range encounters {
+		response, err := s.toEncounterResponse(e)
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = *response
+	}
+	return responses, nil
+}
+
+// DeleteEncounter deletes a saved encounter and removes it from every campaign
+func (s *Service) DeleteEncounter(id string) error {
+	if err := s.encounters.Delete(id); err != nil {
+		return err
+	}
+	return s.unlink(func(c *campaign.Campaign) bool { return c.RemoveEncounter(id) })
+}
+
+// CreateCampaign creates a new empty campaign
+func (s *Service) CreateCampaign(name string) (*CampaignResponse, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := campaign.NewCampaign(id, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid campaign: %w", ErrInvalidRequest, err)
+	}
+	if err := s.campaigns.Save(c); err != nil {
+		return nil, fmt.Errorf("failed to save campaign: %w", err)
+	}
+
+	s.logger.Debug("Campaign created", "campaign_id", id)
+
+	return s.toCampaignResponse(c)
+}
+
+// GetCampaign returns a campaign with its parties and encounters
+func (s *Service) GetCampaign(id string) (*CampaignResponse, error) {
+	c, err := s.campaigns.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toCampaignResponse(c)
+}
+
+// ListCampaigns returns every campaign with its parties and encounters
+func (s *Service) ListCampaigns() ([]CampaignResponse, error) {
+	campaigns, err := s.campaigns.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]CampaignResponse, 0, len(campaigns))
+	for _, c := range campaigns {
+		response, err := s.toCampaignResponse(&c)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// DeleteCampaign deletes a campaign, keeping its parties and encounters
+func (s *Service) DeleteCampaign(id string) error {
+	return s.campaigns.Delete(id)
+}
+
+// AddParty adds a saved party to a campaign
+func (s *Service) AddParty(campaignID, partyID string) (*CampaignResponse, error) {
+	if _, err := s.parties.FindByID(partyID); err != nil {
+		return nil, err
+	}
+	return s.updateCampaign(campaignID, func(c *campaign.Campaign) { c.AddParty(partyID) })
+}
+
+// AddEncounter adds a saved encounter to a campaign
+func (s *Service) AddEncounter(campaignID, encounterID string) (*CampaignResponse, error) {
+	if _, err := s.encounters.FindByID(encounterID); err != nil {
+		return nil, err
+	}
+	return s.updateCampaign(campaignID, func(c *campaign.Campaign) { c.AddEncounter(encounterID) })
+}
+
+func (s *Service) updateCampaign(id string, change func(c *campaign.Campaign)) (*CampaignResponse, error) {
+	c, err := s.campaigns.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	change(c)
+
+	if err := s.campaigns.Save(c); err != nil {
+		return nil, fmt.Errorf("failed to save campaign: %w", err)
+	}
+	return s.toCampaignResponse(c)
+}
+
+// unlink saves every campaign that remove changed
+func (s *Service) unlink(remove func(c *campaign.Campaign) bool) error {
+	campaigns, err := s.campaigns.FindAll()
+	if err != nil {
+		return err
+	}
+	for _, c := range campaigns {
+		if remove(&c) {
+			if err := s.campaigns.Save(&c); err != nil {
+				return fmt.Errorf("failed to save campaign: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) toCampaignResponse(c *campaign.Campaign) (*CampaignResponse, error) {
+	response := &CampaignResponse{
+		ID:         c.ID,
+		Name:       c.Name,
+		Parties:    []PartyResponse{},
+		Encounters: []SavedEncounterResponse{},
+	}
+
+	for _, id := range c.PartyIDs {
+		party, err := s.parties.FindByID(id)
+		if errors.Is(err, campaign.ErrPartyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		response.Parties = append(response.Parties, toPartyResponse(*party))
+	}
+	for _, id := range c.EncounterIDs {
+		saved, err := s.encounters.FindByID(id)
+		if errors.Is(err, campaign.ErrEncounterNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		encounterResponse, err := s.toEncounterResponse(*saved)
+		if err != nil {
+			return nil, err
+		}
+		response.Encounters = append(response.Encounters, *encounterResponse)
+	}
+	return response, nil
+}
+
+// toEncounterResponse resolves the monsters of a saved encounter and evaluates it against its party;
+// monsters no longer in the catalogue are listed by ID with no XP and left out of the evaluation
+func (s *Service) toEncounterResponse(e campaign.SavedEncounter) (*SavedEncounterResponse, error) {
+	response := &SavedEncounterResponse{
+		ID:                e.ID,
+		Name:              e.Name,
+		Ruleset:           e.Ruleset,
+		Difficulty:        e.Difficulty,
+		Levels:            e.Levels,
+		CountWeakMonsters: e.CountWeakMonsters,
+		Monsters:          make([]SavedMonster, len(e.Monsters)),
+	}
+	var groups []encounter.MonsterGroup
+	for i, em := range e.Monsters {
+		saved := SavedMonster{ID: em.MonsterID, Name: em.MonsterID, Quantity: em.Quantity, InLair: em.InLair}
+		if m, ok := s.monsters.FindByID(em.MonsterID); ok {
+			saved.Name = m.Name
+			saved.CR = m.CR
+			saved.XP = m.EncounterXP(em.InLair)
+			saved.TotalXP = saved.XP * em.Quantity
+			groups = append(groups, encounter.MonsterGroup{Monster: m, Quantity: em.Quantity, InLair: em.InLair})
+		}
+		response.Monsters[i] = saved
+		response.XPTotal += saved.TotalXP
+	}
+
+	if len(e.Levels) == 0 {
+		return response, nil
+	}
+	party, err := encounter.NewParty(e.Levels)
+	if err != nil {
+		return nil, fmt.Errorf("invalid party of encounter %s: %w", e.ID, err)
+	}
+	composition := encounter.NewComposition(e.ID, party, e.Ruleset, e.Difficulty, 0)
+	composition.Groups = groups
+	composition.CountWeakMonsters = e.CountWeakMonsters
+	evaluation, err := composition.Evaluate(s.thresholds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate encounter %s: %w", e.ID, err)
+	}
+	response.AdjustedXP = evaluation.AdjustedXP
+	response.ResultingDifficulty = evaluation.Difficulty
+	return response, nil
+}
+
+func toPartyResponse(p campaign.Party) PartyResponse {
+	members := make([]PartyMember, len(p.Members))
+	for i, m := range p.Members {
+		members[i] = PartyMember{Name: m.Name, Level: m.Level}
+	}
+	return PartyResponse{ID: p.ID, Name: p.Name, Members: members, Levels: p.Levels()}
+}
+
+// newID returns a random hex identifier
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,238 @@
+package campaign
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/sqlite"
+)
+
+func newTestService(t *testing.T) (*Service, *memory.CompositionRepository) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	compositions := memory.NewCompositionRepository()
+	service := NewService(logger, memory.NewPartyRepository(), memory.NewSavedEncounterRepository(), memory.NewCampaignRepository(), compositions, memory.NewMonsterRepository(), memory.NewEncounterRepository())
+	return service, compositions
+}
+
+func TestService_CreateParty(t *testing.T) {
+	service, _ := newTestService(t)
+
+	tests := []struct {
+		name        string
+		request     CreatePartyRequest
+		expectError bool
+	}{
+		{name: "valid party", request: CreatePartyRequest{Name: "La Compagnia", Members: []PartyMember{{"Aria", 5}, {"Borin", 5}}}},
+		{name: "missing name", request: CreatePartyRequest{Members: []PartyMember{{"Aria", 5}}}, expectError: true},
+		{name: "invalid level", request: CreatePartyRequest{Name: "X", Members: []PartyMember{{"Aria", 0}}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			party, err := service.CreateParty(tt.request)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			found, err := service.GetParty(party.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if found.Name != tt.request.Name || len(found.Levels) != len(tt.request.Members) {
+				t.Errorf("unexpected party %+v", found)
+			}
+		})
+	}
+}
+
+func TestService_SaveComposition(t *testing.T) {
+	service, compositions := newTestService(t)
+
+	party, _ := encounter.NewParty([]int{3, 3, 3})
+	composition := encounter.NewComposition("c1", party, encounter.Ruleset2014, encounter.DifficultyHard, 1000)
+	ogre, _ := memory.NewMonsterRepository().FindByID("ogre")
+	if err := composition.AddMonster(ogre, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := compositions.Save(composition); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	saved, err := service.SaveComposition("c1", "Ogre al ponte")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.Ruleset != encounter.Ruleset2014 || saved.Difficulty != encounter.DifficultyHard {
+		t.Errorf("expected 2014 Difficile, got %s %s", saved.Ruleset, saved.Difficulty)
+	}
+	if len(saved.Monsters) != 1 || saved.Monsters[0].Name != "Ogre" || saved.XPTotal != 900 {
+		t.Errorf("expected two ogres worth 900 XP, got %+v", saved)
+	}
+
+	// A monster in its lair is saved there and keeps its lair XP
+	aboleth, _ := memory.NewMonsterRepository().FindByID("aboleth")
+	if err := composition.AddMonster(aboleth, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := composition.SetInLair("aboleth", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := compositions.Save(composition); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	saved, err = service.SaveComposition("c1", "Ogre e aboleth")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reloaded, err := service.GetEncounter(saved.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m := reloaded.Monsters[1]; !m.InLair || m.XP != aboleth.LairXP {
+		t.Errorf("expected the aboleth in its lair worth %d XP, got %+v", aboleth.LairXP, m)
+	}
+	if reloaded.XPTotal != 900+aboleth.LairXP {
+		t.Errorf("expected %d XP, got %d", 900+aboleth.LairXP, reloaded.XPTotal)
+	}
+
+	if _, err := service.SaveComposition("missing", "X"); !errors.Is(err, encounter.ErrCompositionNotFound) {
+		t.Errorf("expected ErrCompositionNotFound, got %v", err)
+	}
+}
+
+func TestService_Campaign(t *testing.T) {
+	service, compositions := newTestService(t)
+
+	party, err := service.CreateParty(CreatePartyRequest{Name: "La Compagnia", Members: []PartyMember{{"Aria", 4}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	encounterParty, _ := encounter.NewParty([]int{4})
+	if err := compositions.Save(encounter.NewComposition("c1", encounterParty, encounter.Ruleset2024, encounter.DifficultyLow, 100)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	saved, err := service.SaveComposition("c1", "Imboscata")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	created, err := service.CreateCampaign("La Maledizione")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.AddParty(created.ID, party.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := service.AddEncounter(created.ID, saved.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Parties) != 1 || len(result.Encounters) != 1 {
+		t.Errorf("expected one party and one encounter, got %+v", result)
+	}
+
+	if _, err := service.AddParty(created.ID, "missing"); !errors.Is(err, campaign.ErrPartyNotFound) {
+		t.Errorf("expected ErrPartyNotFound, got %v", err)
+	}
+	if _, err := service.AddEncounter("missing", saved.ID); !errors.Is(err, campaign.ErrCampaignNotFound) {
+		t.Errorf("expected ErrCampaignNotFound, got %v", err)
+	}
+
+	// Deleting a party or an encounter removes it from the campaign
+	if err := service.DeleteParty(party.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := service.DeleteEncounter(saved.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err = service.GetCampaign(created.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Parties) != 0 || len(result.Encounters) != 0 {
+		t.Errorf("expected an empty campaign, got %+v", result)
+	}
+
+	if err := service.DeleteCampaign(created.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	campaigns, _ := service.ListCampaigns()
+	if len(campaigns) != 0 {
+		t.Errorf("expected no campaigns, got %+v", campaigns)
+	}
+}
+
+func TestService_SavedEncounterKeepsDifficulty(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repositories := map[string]campaign.EncounterRepository{
+		"memory": memory.NewSavedEncounterRepository(),
+		"sqlite": sqlite.NewSavedEncounterRepository(db),
+	}
+	for name, encounters := range repositories {
+		t.Run(name, func(t *testing.T) {
+			thresholds := memory.NewEncounterRepository()
+			monsters := memory.NewMonsterRepository()
+			compositions := memory.NewCompositionRepository()
+			service := NewService(logger, memory.NewPartyRepository(), encounters, memory.NewCampaignRepository(), compositions, monsters, thresholds)
+
+			// The goblins only count for the multiplier because the weak monster rule is off
+			party, _ := encounter.NewParty([]int{3, 3, 4})
+			composition := encounter.NewComposition("c1", party, encounter.Ruleset2014, encounter.DifficultyHard, 1300)
+			ogre, _ := monsters.FindByID("ogre")
+			goblin, _ := monsters.FindByID("goblin-guerriero")
+			if err := composition.AddMonster(ogre, 1); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := composition.AddMonster(goblin, 6); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			composition.CountWeakMonsters = true
+			if err := compositions.Save(composition); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			before, err := composition.Evaluate(thresholds)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			composition.CountWeakMonsters = false
+			if withoutWeak, _ := composition.Evaluate(thresholds); withoutWeak.AdjustedXP == before.AdjustedXP {
+				t.Fatal("expected the weak monster setting to change the adjusted XP")
+			}
+
+			saved, err := service.SaveComposition("c1", "Ogre e goblin")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			reloaded, err := service.GetEncounter(saved.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reloaded.CountWeakMonsters || len(reloaded.Levels) != 3 {
+				t.Errorf("expected the party and weak monster setting to be saved, got %+v", reloaded)
+			}
+			if reloaded.AdjustedXP != before.AdjustedXP || reloaded.ResultingDifficulty != before.Difficulty {
+				t.Errorf("expected %d XP (%s), got %d XP (%s)", before.AdjustedXP, before.Difficulty, reloaded.AdjustedXP, reloaded.ResultingDifficulty)
+			}
+		})
+	}
+}
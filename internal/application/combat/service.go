@@ -0,0 +1,213 @@
+package combat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	mathrand "math/rand/v2"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/combat"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+)
+
+// ErrInvalidRequest wraps the errors of combat changes rejected for their values, such as negative damage
+var ErrInvalidRequest = errors.New("invalid request")
+
+// Service provides use cases for running an encounter in the combat tracker
+type Service struct {
+	logger       *slog.Logger
+	combats      combat.Repository
+	compositions encounter.CompositionRepository
+}
+
+// NewService creates a new combat application service
+func NewService(logger *slog.Logger, combats combat.Repository, compositions encounter.CompositionRepository) *Service {
+	return &Service{
+		logger:       logger,
+		combats:      combats,
+		compositions: compositions,
+	}
+}
+
+// CombatantResponse represents a combatant in initiative order
+type CombatantResponse struct {
+	ID                 string      `json:"id"`
+	Name               string      `json:"name"`
+	Kind               combat.Kind `json:"kind"`
+	Level              int         `json:"level,omitempty"`
+	MonsterID          string      `json:"monster_id,omitempty"`
+	Initiative         int         `json:"initiative"`
+	InitiativeModifier int         `json:"initiative_modifier"`
+	HasInitiative      bool        `json:"has_initiative"`
+	HP                 int         `json:"hp"`
+	MaxHP              int         `json:"max_hp"`
+	TempHP             int         `json:"temp_hp"`
+	Defeated           bool        `json:"defeated"`
+	Current            bool        `json:"current"`
+}
+
+// CombatResponse represents the current state of a combat
+type CombatResponse struct {
+	ID            string              `json:"id"`
+	CompositionID string              `json:"composition_id"`
+	Round         int                 `json:"round"`
+	Combatants    []CombatantResponse `json:"combatants"`
+}
+
+// Start begins a combat between the party and the monsters of a composition
+func (s *Service) Start(compositionID string) (*CombatResponse, error) {
+	composition, err := s.compositions.FindByID(compositionID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newCombatID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate combat ID: %w", err)
+	}
+
+	c, err := combat.NewCombat(id, composition.ID, composition.Party, composition.Groups)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.combats.Save(c); err != nil {
+		return nil, fmt.Errorf("failed to save combat: %w", err)
+	}
+
+	s.logger.Debug("Combat started", "combat_id", id, "composition_id", compositionID, "combatants", len(c.Combatants))
+
+	return toResponse(c), nil
+}
+
+// Get returns the current state of a combat
+func (s *Service) Get(id string) (*CombatResponse, error) {
+	c, err := s.combats.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toResponse(c), nil
+}
+
+// Next passes the turn to the following combatant
+func (s *Service) Next(id string) (*CombatResponse, error) {
+	return s.update(id, func(c *combat.Combat) error {
+		c.Next()
+		return nil
+	})
+}
+
+// Previous gives the turn back to the preceding combatant
+func (s *Service) Previous(id string) (*CombatResponse, error) {
+	return s.update(id, func(c *combat.Combat) error {
+		c.Previous()
+		return nil
+	})
+}
+
+// RollInitiative rolls the initiative of every monster of a combat
+func (s *Service) RollInitiative(id string) (*CombatResponse, error) {
+	return s.update(id, func(c *combat.Combat) error {
+		c.RollInitiative(newRand())
+		return nil
+	})
+}
+
+// RollHitPoints rolls the hit points of every monster of a combat
+func (s *Service) RollHitPoints(id string) (*CombatResponse, error) {
+	return s.update(id, func(c *combat.Combat) error {
+		c.RollHitPoints(newRand())
+		return nil
+	})
+}
+
+// SetInitiative enters the initiative of a combatant
+func (s *Service) SetInitiative(id, combatantID string, initiative int) (*CombatResponse, error) {
+	return s.update(id, func(c *combat.Combat) error {
+		return c.SetInitiative(combatantID, initiative)
+	})
+}
+
+// Damage takes hit points from a combatant
+func (s *Service) Damage(id, combatantID string, amount int) (*CombatResponse, error) {
+	return s.update(id, func(c *combat.Combat) error {
+		return c.Damage(combatantID, amount)
+	})
+}
+
+// Heal restores hit points to a combatant
+func (s *Service) Heal(id, combatantID string, amount int) (*CombatResponse, error) {
+	return s.update(id, func(c *combat.Combat) error {
+		return c.Heal(combatantID, amount)
+	})
+}
+
+// SetTempHP gives temporary hit points to a combatant
+func (s *Service) SetTempHP(id, combatantID string, amount int) (*CombatResponse, error) {
+	return s.update(id, func(c *combat.Combat) error {
+		return c.SetTempHP(combatantID, amount)
+	})
+}
+
+// SetHitPoints enters the current and maximum hit points of a combatant
+func (s *Service) SetHitPoints(id, combatantID string, hp, maxHP int) (*CombatResponse, error) {
+	return s.update(id, func(c *combat.Combat) error {
+		return c.SetHitPoints(combatantID, hp, maxHP)
+	})
+}
+
+// update applies change to a stored combat. Errors of change come from the values of the request.
+func (s *Service) update(id string, change func(c *combat.Combat) error) (*CombatResponse, error) {
+	c, err := s.combats.Update(id, func(c *combat.Combat) error {
+		if err := change(c); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toResponse(c), nil
+}
+
+func toResponse(c *combat.Combat) *CombatResponse {
+	combatants := make([]CombatantResponse, len(c.Combatants))
+	for i, cb := range c.Combatants {
+		combatants[i] = CombatantResponse{
+			ID:                 cb.ID,
+			Name:               cb.Name,
+			Kind:               cb.Kind,
+			Level:              cb.Level,
+			MonsterID:          cb.MonsterID,
+			Initiative:         cb.Initiative,
+			InitiativeModifier: cb.InitiativeModifier,
+			HasInitiative:      cb.HasInitiative,
+			HP:                 cb.HP,
+			MaxHP:              cb.MaxHP,
+			TempHP:             cb.TempHP,
+			Defeated:           cb.Defeated(),
+			Current:            i == c.Turn,
+		}
+	}
+	return &CombatResponse{
+		ID:            c.ID,
+		CompositionID: c.CompositionID,
+		Round:         c.Round,
+		Combatants:    combatants,
+	}
+}
+
+// newRand returns a randomly seeded generator for the rolls of the tracker
+func newRand() *mathrand.Rand {
+	return mathrand.New(mathrand.NewPCG(mathrand.Uint64(), mathrand.Uint64()))
+}
+
+// newCombatID returns a random hex identifier
+func newCombatID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
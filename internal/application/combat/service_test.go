@@ -0,0 +1,188 @@
+package combat
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/combat"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
+)
+
+func newTestService(t *testing.T) (*Service, string) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	monsters := memory.NewMonsterRepository()
+	compositions := memory.NewCompositionRepository()
+
+	party, err := encounter.NewParty([]int{5, 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	composition := encounter.NewComposition("composition-id", party, encounter.Ruleset2024, encounter.DifficultyModerate, 1000)
+	for id, quantity := range map[string]int{"ogre": 1, "goblin-guerriero": 3} {
+		m, _ := monsters.FindByID(id)
+		if err := composition.AddMonster(m, quantity); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := compositions.Save(composition); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return NewService(logger, memory.NewCombatRepository(), compositions), composition.ID
+}
+
+func TestService_Start(t *testing.T) {
+	service, compositionID := newTestService(t)
+
+	started, err := service.Start(compositionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(started.Combatants) != 6 || started.Round != 1 || !started.Combatants[0].Current {
+		t.Fatalf("expected 6 combatants in round 1 with the first one acting, got %+v", started)
+	}
+
+	for _, cb := range started.Combatants {
+		if cb.MonsterID == "ogre" && (cb.MaxHP != 68 || cb.Initiative != 9) {
+			t.Errorf("expected the ogre statblock HP and initiative score, got %+v", cb)
+		}
+	}
+
+	// A reload sees the same fight
+	fetched, err := service.Get(started.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fetched.Combatants) != 6 || fetched.CompositionID != compositionID {
+		t.Errorf("expected the stored combat, got %+v", fetched)
+	}
+
+	if _, err := service.Start("missing"); !errors.Is(err, encounter.ErrCompositionNotFound) {
+		t.Errorf("expected ErrCompositionNotFound, got %v", err)
+	}
+	if _, err := service.Get("missing"); !errors.Is(err, combat.ErrCombatNotFound) {
+		t.Errorf("expected ErrCombatNotFound, got %v", err)
+	}
+}
+
+func TestService_RunsTheFight(t *testing.T) {
+	service, compositionID := newTestService(t)
+	started, err := service.Start(compositionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	id := started.ID
+
+	if _, err := service.SetInitiative(id, "pg-1", 25); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.SetHitPoints(id, "pg-1", 38, 38); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.SetTempHP(id, "pg-1", 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := service.Damage(id, "pg-1", 8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pc := result.Combatants[0]
+	if pc.ID != "pg-1" || !pc.Current || pc.HP != 35 || pc.TempHP != 0 {
+		t.Errorf("expected pg-1 first with 35 HP, got %+v", pc)
+	}
+
+	if result, err = service.Next(id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Combatants[0].Current || !result.Combatants[1].Current {
+		t.Errorf("expected the turn to pass to the second combatant, got %+v", result.Combatants[:2])
+	}
+	if result, err = service.Previous(id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Combatants[0].Current {
+		t.Errorf("expected the turn back to pg-1, got %+v", result.Combatants[:2])
+	}
+
+	if result, err = service.Heal(id, "pg-1", 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Combatants[0].HP != 38 {
+		t.Errorf("expected healing up to 38 HP, got %d", result.Combatants[0].HP)
+	}
+
+	if result, err = service.RollInitiative(id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Combatants[0].ID != "pg-1" {
+		t.Errorf("expected pg-1 to stay first with 25, got %+v", result.Combatants[0])
+	}
+
+	if result, err = service.RollHitPoints(id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, cb := range result.Combatants {
+		if cb.MonsterID == "ogre" && (cb.MaxHP < 32 || cb.MaxHP > 104 || cb.HP != cb.MaxHP) {
+			t.Errorf("expected the ogre hit dice 8d10 + 24 to be rolled, got %+v", cb)
+		}
+	}
+
+	if _, err := service.Damage(id, "nobody", 1); !errors.Is(err, combat.ErrCombatantNotFound) {
+		t.Errorf("expected ErrCombatantNotFound, got %v", err)
+	}
+}
+
+func TestService_ConcurrentUpdates(t *testing.T) {
+	service, compositionID := newTestService(t)
+	started, err := service.Start(compositionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.SetHitPoints(started.ID, "pg-1", 38, 38); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Damage and turn changes racing on the same combat must all be applied
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			if _, err := service.Damage(started.ID, "pg-1", 1); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+		wg.Go(func() {
+			if _, err := service.Next(started.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	c, err := service.Get(started.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, cb := range c.Combatants {
+		if cb.ID == "pg-1" && cb.HP != 18 {
+			t.Errorf("expected 20 damage out of 38 HP, got %d", cb.HP)
+		}
+	}
+	if turns := (c.Round-1)*len(c.Combatants) + currentIndex(c); turns != 20 {
+		t.Errorf("expected 20 turns to pass, got %d", turns)
+	}
+}
+
+func currentIndex(c *CombatResponse) int {
+	for i, cb := range c.Combatants {
+		if cb.Current {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,247 @@
+package encounter
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// CompositionService provides use cases for building an encounter from chosen monsters
+type CompositionService struct {
+	logger       *slog.Logger
+	repository   encounter.Repository
+	compositions encounter.CompositionRepository
+	monsters     monster.Repository
+}
+
+// NewCompositionService creates a new composition application service
+func NewCompositionService(logger *slog.Logger, repository encounter.Repository, compositions encounter.CompositionRepository, monsters monster.Repository) *CompositionService {
+	return &CompositionService{
+		logger:       logger,
+		repository:   repository,
+		compositions: compositions,
+		monsters:     monsters,
+	}
+}
+
+// CreateCompositionRequest represents a request to start a new encounter composition
+type CreateCompositionRequest struct {
+	Ruleset         string
+	Difficulty      string
+	CharacterLevels []int
+	Budget          int
+}
+
+// CompositionMonster represents a monster group in a composition response.
+// XP is the lair XP when the group is in its lair; LairXP is zero for monsters without a lair.
+type CompositionMonster struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	CR       string `json:"cr"`
+	XP       int    `json:"xp"`
+	Quantity int    `json:"quantity"`
+	TotalXP  int    `json:"total_xp"`
+	LairXP   int    `json:"lair_xp,omitempty"`
+	InLair   bool   `json:"in_lair"`
+}
+
+// CompositionResponse represents the current state of a composition
+type CompositionResponse struct {
+	ID                  string               `json:"id"`
+	Ruleset             encounter.Ruleset    `json:"ruleset"`
+	Difficulty          encounter.Difficulty `json:"difficulty"`
+	Budget              int                  `json:"budget"`
+	Monsters            []CompositionMonster `json:"monsters"`
+	XPUsed              int                  `json:"xp_used"`
+	XPRemaining         int                  `json:"xp_remaining"`
+	MonsterCount        int                  `json:"monster_count"`
+	Multiplier          float64              `json:"multiplier,omitempty"`
+	BaseMultiplier      float64              `json:"base_multiplier,omitempty"`
+	MultiplierShift     int                  `json:"multiplier_shift,omitempty"`
+	AdjustedXP          int                  `json:"adjusted_xp,omitempty"`
+	ResultingDifficulty encounter.Difficulty `json:"resulting_difficulty,omitempty"`
+
+	// 2014 weak monster rule: how many monsters were counted for the multiplier and which were left out
+	CountWeakMonsters bool                        `json:"count_weak_monsters"`
+	CountedMonsters   int                         `json:"counted_monsters,omitempty"`
+	ExcludedMonsters  []encounter.ExcludedMonster `json:"excluded_monsters,omitempty"`
+
+	// Seed is set when the monsters were picked by the generator, to reproduce the result
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// Create starts a new empty composition for the given party and budget
+func (s *CompositionService) Create(req CreateCompositionRequest) (*CompositionResponse, error) {
+	id, err := newCompositionID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate composition ID: %w", err)
+	}
+
+	composition, err := newComposition(id, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.compositions.Save(composition); err != nil {
+		return nil, fmt.Errorf("failed to save composition: %w", err)
+	}
+
+	s.logger.Debug("Composition created", "composition_id", id, "budget", req.Budget)
+
+	return s.toResponse(composition)
+}
+
+// newComposition validates a request and builds the empty composition it describes, without storing it
+func newComposition(id string, req CreateCompositionRequest) (*encounter.Composition, error) {
+	ruleset, err := encounter.NewRuleset(req.Ruleset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ruleset: %w", ErrInvalidRequest, err)
+	}
+
+	difficulty, err := encounter.NewDifficulty(req.Difficulty, ruleset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid difficulty: %w", ErrInvalidRequest, err)
+	}
+
+	party, err := encounter.NewParty(req.CharacterLevels)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid party: %w", ErrInvalidRequest, err)
+	}
+
+	if req.Budget < 0 {
+		return nil, fmt.Errorf("%w: budget cannot be negative", ErrInvalidRequest)
+	}
+
+	return encounter.NewComposition(id, party, ruleset, difficulty, req.Budget), nil
+}
+
+// Get returns the current state of a composition
+func (s *CompositionService) Get(id string) (*CompositionResponse, error) {
+	composition, err := s.compositions.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(composition)
+}
+
+// Groups returns the monster groups of a composition, with the full statblock of each monster
+func (s *CompositionService) Groups(id string) ([]encounter.MonsterGroup, error) {
+	composition, err := s.compositions.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return composition.Groups, nil
+}
+
+// AddMonster adds quantity monsters with the given ID to a composition
+func (s *CompositionService) AddMonster(id, monsterID string, quantity int) (*CompositionResponse, error) {
+	m, ok := s.monsters.FindByID(monsterID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", monster.ErrNotFound, monsterID)
+	}
+
+	return s.update(id, func(c *encounter.Composition) error {
+		return invalidRequest(c.AddMonster(m, quantity))
+	})
+}
+
+// RemoveMonster removes a monster group from a composition
+func (s *CompositionService) RemoveMonster(id, monsterID string) (*CompositionResponse, error) {
+	return s.update(id, func(c *encounter.Composition) error {
+		return c.RemoveMonster(monsterID)
+	})
+}
+
+// SetQuantity changes the quantity of a monster group in a composition
+func (s *CompositionService) SetQuantity(id, monsterID string, quantity int) (*CompositionResponse, error) {
+	return s.update(id, func(c *encounter.Composition) error {
+		return invalidRequest(c.SetQuantity(monsterID, quantity))
+	})
+}
+
+// SetInLair places a monster group of a composition in its lair (true) or outside it (false)
+func (s *CompositionService) SetInLair(id, monsterID string, inLair bool) (*CompositionResponse, error) {
+	return s.update(id, func(c *encounter.Composition) error {
+		return invalidRequest(c.SetInLair(monsterID, inLair))
+	})
+}
+
+// SetCountWeakMonsters turns the 2014 weak monster rule off (true) or on (false) for a composition
+func (s *CompositionService) SetCountWeakMonsters(id string, countWeak bool) (*CompositionResponse, error) {
+	return s.update(id, func(c *encounter.Composition) error {
+		c.CountWeakMonsters = countWeak
+		return nil
+	})
+}
+
+// Generate replaces the monsters of a composition with a random encounter that fits its budget
+func (s *CompositionService) Generate(id string, opts GenerateOptions) (*CompositionResponse, error) {
+	var used GenerateOptions
+	response, err := s.update(id, func(c *encounter.Composition) error {
+		var err error
+		used, err = generate(c, s.repository, s.monsters, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("Composition generated", "composition_id", id, "seed", used.Seed)
+
+	response.Seed = used.Seed
+	return response, nil
+}
+
+func (s *CompositionService) update(id string, change func(c *encounter.Composition) error) (*CompositionResponse, error) {
+	composition, err := s.compositions.Update(id, change)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(composition)
+}
+
+func (s *CompositionService) toResponse(composition *encounter.Composition) (*CompositionResponse, error) {
+	evaluation, err := composition.Evaluate(s.repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate composition: %w", err)
+	}
+
+	return &CompositionResponse{
+		ID:                  composition.ID,
+		Ruleset:             composition.Ruleset,
+		Difficulty:          composition.Difficulty,
+		Budget:              composition.Budget,
+		Monsters:            compositionMonsters(composition.Groups),
+		XPUsed:              evaluation.XPUsed,
+		XPRemaining:         evaluation.XPRemaining,
+		MonsterCount:        evaluation.MonsterCount,
+		Multiplier:          evaluation.Multiplier,
+		BaseMultiplier:      evaluation.MultiplierStep.BaseMultiplier,
+		MultiplierShift:     evaluation.MultiplierStep.Shift,
+		AdjustedXP:          evaluation.AdjustedXP,
+		ResultingDifficulty: evaluation.Difficulty,
+		CountWeakMonsters:   composition.CountWeakMonsters,
+		CountedMonsters:     evaluation.CountedMonsters,
+		ExcludedMonsters:    evaluation.ExcludedMonsters,
+	}, nil
+}
+
+// invalidRequest wraps a domain error caused by the values of a request with ErrInvalidRequest
+func invalidRequest(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+}
+
+// newCompositionID returns a random hex identifier
+func newCompositionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
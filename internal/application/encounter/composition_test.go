@@ -0,0 +1,321 @@
+package encounter
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
+)
+
+func newTestCompositionService() *CompositionService {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	return NewCompositionService(logger, memory.NewEncounterRepository(), memory.NewCompositionRepository(), memory.NewMonsterRepository())
+}
+
+func TestCompositionService_Create(t *testing.T) {
+	service := newTestCompositionService()
+
+	tests := []struct {
+		name        string
+		request     CreateCompositionRequest
+		expectError bool
+	}{
+		{
+			name: "valid 2024 composition",
+			request: CreateCompositionRequest{
+				Ruleset:         "2024",
+				Difficulty:      "Moderate",
+				CharacterLevels: []int{5, 5, 5, 5},
+				Budget:          3000,
+			},
+		},
+		{
+			name: "invalid difficulty",
+			request: CreateCompositionRequest{
+				Ruleset:         "2024",
+				Difficulty:      "Media",
+				CharacterLevels: []int{5},
+				Budget:          750,
+			},
+			expectError: true,
+		},
+		{
+			name: "empty party",
+			request: CreateCompositionRequest{
+				Ruleset:    "2014",
+				Difficulty: "Media",
+				Budget:     500,
+			},
+			expectError: true,
+		},
+		{
+			name: "negative budget",
+			request: CreateCompositionRequest{
+				Ruleset:         "2024",
+				Difficulty:      "Low",
+				CharacterLevels: []int{1},
+				Budget:          -1,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.Create(tt.request)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.ID == "" {
+				t.Error("expected composition ID to be set")
+			}
+			if result.XPRemaining != tt.request.Budget {
+				t.Errorf("expected XP remaining %d, got %d", tt.request.Budget, result.XPRemaining)
+			}
+			if len(result.Monsters) != 0 {
+				t.Errorf("expected empty composition, got %d monsters", len(result.Monsters))
+			}
+		})
+	}
+}
+
+func TestCompositionService_AddChangeRemove(t *testing.T) {
+	service := newTestCompositionService()
+
+	created, err := service.Create(CreateCompositionRequest{
+		Ruleset:         "2024",
+		Difficulty:      "Moderate",
+		CharacterLevels: []int{5, 5, 5, 5},
+		Budget:          3000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Aboleth is 5900 XP
+	result, err := service.AddMonster(created.ID, "aboleth", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.XPUsed != 5900 {
+		t.Errorf("expected 5900 XP used, got %d", result.XPUsed)
+	}
+	if result.XPRemaining != -2900 {
+		t.Errorf("expected -2900 XP remaining, got %d", result.XPRemaining)
+	}
+	if result.ResultingDifficulty != encounter.DifficultyHigh {
+		t.Errorf("expected difficulty High, got %s", result.ResultingDifficulty)
+	}
+
+	result, err = service.SetQuantity(created.ID, "aboleth", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MonsterCount != 2 || result.XPUsed != 11800 {
+		t.Errorf("expected 2 monsters for 11800 XP, got %d for %d", result.MonsterCount, result.XPUsed)
+	}
+
+	// Changes must be persisted between calls
+	fetched, err := service.Get(created.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fetched.XPUsed != 11800 {
+		t.Errorf("expected persisted 11800 XP used, got %d", fetched.XPUsed)
+	}
+
+	groups, err := service.Groups(created.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Monster.Name != "Aboleth" || groups[0].Quantity != 2 {
+		t.Errorf("expected 2 Aboleth with their statblock, got %+v", groups)
+	}
+
+	result, err = service.RemoveMonster(created.ID, "aboleth")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MonsterCount != 0 || result.XPRemaining != 3000 {
+		t.Errorf("expected empty composition with full budget, got %d monsters and %d XP remaining", result.MonsterCount, result.XPRemaining)
+	}
+}
+
+func TestCompositionService_NotFound(t *testing.T) {
+	service := newTestCompositionService()
+
+	if _, err := service.Get("missing"); !errors.Is(err, encounter.ErrCompositionNotFound) {
+		t.Errorf("expected ErrCompositionNotFound, got %v", err)
+	}
+	if _, err := service.Groups("missing"); !errors.Is(err, encounter.ErrCompositionNotFound) {
+		t.Errorf("expected ErrCompositionNotFound from Groups, got %v", err)
+	}
+
+	created, err := service.Create(CreateCompositionRequest{
+		Ruleset:         "2024",
+		Difficulty:      "Low",
+		CharacterLevels: []int{1},
+		Budget:          50,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.AddMonster(created.ID, "not-a-monster", 1); !errors.Is(err, monster.ErrNotFound) {
+		t.Errorf("expected monster.ErrNotFound, got %v", err)
+	}
+	if _, err := service.RemoveMonster(created.ID, "aboleth"); !errors.Is(err, encounter.ErrMonsterNotInComposition) {
+		t.Errorf("expected ErrMonsterNotInComposition, got %v", err)
+	}
+}
+
+func TestCompositionService_SetInLair(t *testing.T) {
+	service := newTestCompositionService()
+
+	created, err := service.Create(CreateCompositionRequest{
+		Ruleset:         "2024",
+		Difficulty:      "High",
+		CharacterLevels: []int{10, 10, 10, 10},
+		Budget:          15600,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.AddMonster(created.ID, "aboleth", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.AddMonster(created.ID, "ogre", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := service.SetInLair(created.ID, "aboleth", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	aboleth := result.Monsters[0]
+	if !aboleth.InLair || aboleth.XP != 7200 || aboleth.LairXP != 7200 || aboleth.TotalXP != 7200 {
+		t.Errorf("expected the aboleth to be worth its lair XP, got %+v", aboleth)
+	}
+	if result.XPUsed != 7650 {
+		t.Errorf("expected 7650 XP used, got %d", result.XPUsed)
+	}
+
+	if _, err := service.SetInLair(created.ID, "ogre", true); !errors.Is(err, encounter.ErrNoLairVariant) {
+		t.Errorf("expected ErrNoLairVariant, got %v", err)
+	}
+
+	result, err = service.SetInLair(created.ID, "aboleth", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Monsters[0].InLair || result.XPUsed != 6350 {
+		t.Errorf("expected 6350 XP used outside the lair, got %d", result.XPUsed)
+	}
+}
+
+func TestCompositionService_CountWeakMonsters(t *testing.T) {
+	service := newTestCompositionService()
+
+	created, err := service.Create(CreateCompositionRequest{
+		Ruleset:         "2014",
+		Difficulty:      "Media",
+		CharacterLevels: []int{5, 5, 5, 5},
+		Budget:          2000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.AddMonster(created.ID, "ogre", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := service.AddMonster(created.ID, "goblin-guerriero", 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Goblins (CR 1/4) are much weaker than the ogre (CR 2) and are not counted
+	if result.CountedMonsters != 1 || result.AdjustedXP != 650 {
+		t.Errorf("expected 1 counted monster and 650 adjusted XP, got %d and %d", result.CountedMonsters, result.AdjustedXP)
+	}
+	if len(result.ExcludedMonsters) != 1 || result.ExcludedMonsters[0].MonsterID != "goblin-guerriero" {
+		t.Errorf("expected goblins to be excluded, got %+v", result.ExcludedMonsters)
+	}
+
+	if _, err := service.SetCountWeakMonsters(created.ID, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fetched, err := service.Get(created.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !fetched.CountWeakMonsters {
+		t.Error("expected the option to be persisted")
+	}
+	if fetched.CountedMonsters != 5 || fetched.AdjustedXP != 1300 {
+		t.Errorf("expected 5 counted monsters and 1300 adjusted XP, got %d and %d", fetched.CountedMonsters, fetched.AdjustedXP)
+	}
+	if len(fetched.ExcludedMonsters) != 0 {
+		t.Errorf("expected no excluded monsters, got %+v", fetched.ExcludedMonsters)
+	}
+}
+
+// slowCompositions returns what it read after a delay, like a remote store,
+// widening the window in which a read-modify-write loses concurrent updates
+type slowCompositions struct {
+	*memory.CompositionRepository
+}
+
+func (s slowCompositions) FindByID(id string) (*encounter.Composition, error) {
+	composition, err := s.CompositionRepository.FindByID(id)
+	time.Sleep(time.Millisecond)
+	return composition, err
+}
+
+func TestCompositionService_ConcurrentAddMonster(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	service := NewCompositionService(logger, memory.NewEncounterRepository(), slowCompositions{memory.NewCompositionRepository()}, memory.NewMonsterRepository())
+	composition, err := service.Create(CreateCompositionRequest{
+		Ruleset:         "2024",
+		Difficulty:      "High",
+		CharacterLevels: []int{5, 5, 5, 5},
+		Budget:          4400,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Ogres added at the same time must all land in the group
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Go(func() {
+			if _, err := service.AddMonster(composition.ID, "ogre", 1); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	result, err := service.Get(composition.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Monsters) != 1 || result.Monsters[0].Quantity != 100 {
+		t.Errorf("expected one group of 100 ogres, got %+v", result.Monsters)
+	}
+}
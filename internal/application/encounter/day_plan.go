@@ -0,0 +1,113 @@
+package encounter
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+)
+
+// DayPlanService provides the adventuring day planning use case
+type DayPlanService struct {
+	logger     *slog.Logger
+	repository encounter.Repository
+}
+
+// NewDayPlanService creates a new adventuring day plan service
+func NewDayPlanService(logger *slog.Logger, repository encounter.Repository) *DayPlanService {
+	return &DayPlanService{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// PlanDayRequest represents a request to plan an adventuring day
+type PlanDayRequest struct {
+	Ruleset         string
+	CharacterLevels []int
+	Difficulties    []string
+}
+
+// DayPlanEncounter represents one planned encounter in a day plan response
+type DayPlanEncounter struct {
+	Number       int                  `json:"number"`
+	Difficulty   encounter.Difficulty `json:"difficulty"`
+	XP           int                  `json:"xp"`
+	CumulativeXP int                  `json:"cumulative_xp"`
+	// DayShare is the fraction of the day budget used by this encounter
+	DayShare float64 `json:"day_share"`
+}
+
+// DayPlanResponse represents a planned adventuring day
+type DayPlanResponse struct {
+	Ruleset         encounter.Ruleset  `json:"ruleset"`
+	CharacterLevels []int              `json:"character_levels"`
+	DayBudget       int                `json:"day_budget"`
+	Encounters      []DayPlanEncounter `json:"encounters"`
+	XPPlanned       int                `json:"xp_planned"`
+	XPRemaining     int                `json:"xp_remaining"`
+	BudgetUsed      float64            `json:"budget_used"`
+	EvenShare       int                `json:"even_share"`
+}
+
+// PlanDay computes the day budget of the party and the XP of each encounter of the chosen difficulties
+func (s *DayPlanService) PlanDay(req PlanDayRequest) (*DayPlanResponse, error) {
+	ruleset, err := encounter.NewRuleset(req.Ruleset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ruleset: %w", ErrInvalidRequest, err)
+	}
+
+	party, err := encounter.NewParty(req.CharacterLevels)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid party: %w", ErrInvalidRequest, err)
+	}
+
+	if len(req.Difficulties) > encounter.MaxDayEncounters {
+		return nil, fmt.Errorf("%w: a day cannot have more than %d encounters", ErrInvalidRequest, encounter.MaxDayEncounters)
+	}
+
+	difficulties := make([]encounter.Difficulty, len(req.Difficulties))
+	for i, d := range req.Difficulties {
+		difficulties[i], err = encounter.NewDifficulty(d, ruleset)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid difficulty for encounter %d: %w", ErrInvalidRequest, i+1, err)
+		}
+	}
+
+	plan, err := encounter.NewDayPlan(party, ruleset, difficulties, s.repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan day: %w", err)
+	}
+
+	response := &DayPlanResponse{
+		Ruleset:         plan.Ruleset,
+		CharacterLevels: party.Levels(),
+		DayBudget:       plan.DayBudget,
+		Encounters:      make([]DayPlanEncounter, len(plan.Encounters)),
+		XPPlanned:       plan.XPPlanned(),
+		XPRemaining:     plan.XPRemaining(),
+		BudgetUsed:      plan.BudgetUsed(),
+		EvenShare:       plan.EvenShare(),
+	}
+
+	cumulative := 0
+	for i, e := range plan.Encounters {
+		cumulative += e.XP
+		response.Encounters[i] = DayPlanEncounter{
+			Number:       i + 1,
+			Difficulty:   e.Difficulty,
+			XP:           e.XP,
+			CumulativeXP: cumulative,
+			DayShare:     float64(e.XP) / float64(plan.DayBudget),
+		}
+	}
+
+	s.logger.Debug("Day planned",
+		"ruleset", ruleset,
+		"party_size", party.Size(),
+		"day_budget", plan.DayBudget,
+		"encounters", len(plan.Encounters),
+	)
+
+	return response, nil
+}
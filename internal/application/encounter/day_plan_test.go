@@ -0,0 +1,109 @@
+package encounter
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
+)
+
+func TestDayPlanService_PlanDay(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	service := NewDayPlanService(logger, memory.NewEncounterRepository())
+
+	tests := []struct {
+		name               string
+		request            PlanDayRequest
+		expectedBudget     int
+		expectedPlanned    int
+		expectedCumulative []int
+		expectError        bool
+	}{
+		{
+			name: "2014 four level 5 characters",
+			request: PlanDayRequest{
+				Ruleset:         "2014",
+				CharacterLevels: []int{5, 5, 5, 5},
+				Difficulties:    []string{"Media", "Media", "Difficile", "Letale"},
+			},
+			expectedBudget:     14000, // 4 * 3500
+			expectedPlanned:    11400, // 2000 + 2000 + 3000 + 4400
+			expectedCumulative: []int{2000, 4000, 7000, 11400},
+		},
+		{
+			name: "2014 mixed levels",
+			request: PlanDayRequest{
+				Ruleset:         "2014",
+				CharacterLevels: []int{1, 3, 20},
+				Difficulties:    []string{"Facile"},
+			},
+			expectedBudget:     41500, // 300 + 1200 + 40000
+			expectedPlanned:    2900,  // 25 + 75 + 2800
+			expectedCumulative: []int{2900},
+		},
+		{
+			name: "2024 several encounters",
+			request: PlanDayRequest{
+				Ruleset:         "2024",
+				CharacterLevels: []int{5, 5, 5, 5},
+				Difficulties:    []string{"Low", "Moderate", "High"},
+			},
+			expectedBudget:     14000,
+			expectedPlanned:    9400, // 2000 + 3000 + 4400
+			expectedCumulative: []int{2000, 5000, 9400},
+		},
+		{
+			name: "difficulty from the other ruleset",
+			request: PlanDayRequest{
+				Ruleset:         "2024",
+				CharacterLevels: []int{5},
+				Difficulties:    []string{"Media"},
+			},
+			expectError: true,
+		},
+		{
+			name: "empty party",
+			request: PlanDayRequest{
+				Ruleset:      "2014",
+				Difficulties: []string{"Media"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.PlanDay(tt.request)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.DayBudget != tt.expectedBudget {
+				t.Errorf("expected day budget %d, got %d", tt.expectedBudget, result.DayBudget)
+			}
+			if result.XPPlanned != tt.expectedPlanned {
+				t.Errorf("expected %d XP planned, got %d", tt.expectedPlanned, result.XPPlanned)
+			}
+			if result.XPRemaining != tt.expectedBudget-tt.expectedPlanned {
+				t.Errorf("expected %d XP remaining, got %d", tt.expectedBudget-tt.expectedPlanned, result.XPRemaining)
+			}
+			for i, e := range result.Encounters {
+				if e.Number != i+1 {
+					t.Errorf("expected encounter number %d, got %d", i+1, e.Number)
+				}
+				if e.CumulativeXP != tt.expectedCumulative[i] {
+					t.Errorf("encounter %d: expected cumulative XP %d, got %d", i+1, tt.expectedCumulative[i], e.CumulativeXP)
+				}
+			}
+		})
+	}
+}
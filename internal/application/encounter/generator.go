@@ -0,0 +1,351 @@
+package encounter
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// Defaults and limits for the random encounter generator
+const (
+	DefaultGenerationTolerance = 0.1 // fraction of the budget the generated XP may differ by
+	DefaultMaxGroups           = 3
+	MaxGeneratedGroups         = 6
+	maxGeneratedMonsters       = 20
+	generationAttempts         = 200
+	archetypeSpread            = 0.25    // how far a monster's XP may be from its archetype role target
+	maxSeed                    = 1 << 53 // seeds stay exact as JSON numbers
+)
+
+// ErrNoEncounterGenerated is returned when no combination of monsters fits the budget
+var ErrNoEncounterGenerated = errors.New("no encounter found within the XP tolerance")
+
+// GenerateOptions holds the parameters that shape a random encounter
+type GenerateOptions struct {
+	Filters   monster.SearchFilters
+	Tolerance float64 // 0 uses DefaultGenerationTolerance
+	MaxGroups int     // 0 uses DefaultMaxGroups, ignored with an archetype
+	Seed      int64   // 0 picks a random seed, returned in the response
+	Archetype string  // optional archetype ID shaping the groups, see encounter.Archetype
+}
+
+// GenerateRequest represents a request to generate a random encounter for a party
+type GenerateRequest struct {
+	Ruleset           string
+	CharacterLevels   []int
+	Budget            int
+	CountWeakMonsters bool
+	GenerateOptions
+}
+
+// GenerateResponse represents a generated encounter
+type GenerateResponse struct {
+	Seed         int64                `json:"seed"`
+	Archetype    string               `json:"archetype,omitempty"`
+	Ruleset      encounter.Ruleset    `json:"ruleset"`
+	Budget       int                  `json:"budget"`
+	Tolerance    float64              `json:"tolerance"`
+	Monsters     []CompositionMonster `json:"monsters"`
+	XPUsed       int                  `json:"xp_used"`
+	MonsterCount int                  `json:"monster_count"`
+	Multiplier   float64              `json:"multiplier,omitempty"`
+	AdjustedXP   int                  `json:"adjusted_xp,omitempty"`
+	Difficulty   encounter.Difficulty `json:"difficulty,omitempty"`
+}
+
+// GeneratorService provides the random encounter generation use case
+type GeneratorService struct {
+	logger     *slog.Logger
+	repository encounter.Repository
+	monsters   monster.Repository
+}
+
+// NewGeneratorService creates a new encounter generator service
+func NewGeneratorService(logger *slog.Logger, repository encounter.Repository, monsters monster.Repository) *GeneratorService {
+	return &GeneratorService{
+		logger:     logger,
+		repository: repository,
+		monsters:   monsters,
+	}
+}
+
+// Generate picks random monster groups whose XP lands within the tolerance of the budget.
+// For the 2014 ruleset the budget is compared against the multiplied XP.
+func (s *GeneratorService) Generate(req GenerateRequest) (*GenerateResponse, error) {
+	ruleset, err := encounter.NewRuleset(req.Ruleset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ruleset: %w", ErrInvalidRequest, err)
+	}
+
+	party, err := encounter.NewParty(req.CharacterLevels)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid party: %w", ErrInvalidRequest, err)
+	}
+
+	composition := encounter.NewComposition("generated", party, ruleset, "", req.Budget)
+	composition.CountWeakMonsters = req.CountWeakMonsters
+
+	opts, err := generate(composition, s.repository, s.monsters, req.GenerateOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluation, err := composition.Evaluate(s.repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate generated encounter: %w", err)
+	}
+
+	s.logger.Debug("Encounter generated",
+		"seed", opts.Seed,
+		"budget", req.Budget,
+		"xp_used", evaluation.XPUsed,
+		"adjusted_xp", evaluation.AdjustedXP,
+	)
+
+	return &GenerateResponse{
+		Seed:         opts.Seed,
+		Archetype:    opts.Archetype,
+		Ruleset:      ruleset,
+		Budget:       req.Budget,
+		Tolerance:    opts.Tolerance,
+		Monsters:     compositionMonsters(composition.Groups),
+		XPUsed:       evaluation.XPUsed,
+		MonsterCount: evaluation.MonsterCount,
+		Multiplier:   evaluation.Multiplier,
+		AdjustedXP:   evaluation.AdjustedXP,
+		Difficulty:   evaluation.Difficulty,
+	}, nil
+}
+
+// generate replaces the groups of the composition with random monsters matching the
+// filters, and returns the options actually used so the seed can be reported
+func generate(c *encounter.Composition, repo encounter.Repository, monsters monster.Repository, opts GenerateOptions) (GenerateOptions, error) {
+	if c.Budget <= 0 {
+		return opts, fmt.Errorf("%w: budget must be positive", ErrInvalidRequest)
+	}
+	if opts.Tolerance == 0 {
+		opts.Tolerance = DefaultGenerationTolerance
+	}
+	if opts.Tolerance < 0 || opts.Tolerance > 1 {
+		return opts, fmt.Errorf("%w: tolerance must be between 0 and 1, got %g", ErrInvalidRequest, opts.Tolerance)
+	}
+	if opts.MaxGroups == 0 {
+		opts.MaxGroups = DefaultMaxGroups
+	}
+	if opts.MaxGroups < 1 || opts.MaxGroups > MaxGeneratedGroups {
+		return opts, fmt.Errorf("%w: max groups must be between 1 and %d", ErrInvalidRequest, MaxGeneratedGroups)
+	}
+	if opts.Seed == 0 {
+		opts.Seed = rand.Int64N(maxSeed-1) + 1
+	}
+
+	var archetype *encounter.Archetype
+	if opts.Archetype != "" {
+		a, err := repo.GetArchetype(opts.Archetype)
+		if err != nil {
+			return opts, err
+		}
+		if err := a.Validate(); err != nil {
+			return opts, err
+		}
+		archetype = &a
+	}
+
+	lower := int(math.Ceil(float64(c.Budget) * (1 - opts.Tolerance)))
+	upper := int(math.Floor(float64(c.Budget) * (1 + opts.Tolerance)))
+
+	// The generator only needs the monster's own XP bound; the multiplier is checked below
+	filters := opts.Filters
+	filters.MaxXP = 0
+	var candidates []monster.Monster
+	for _, m := range monsters.SearchWithFilters(filters) {
+		if m.XP == 0 {
+			continue
+		}
+		xp, err := generatedXP(c, []encounter.MonsterGroup{{Monster: m, Quantity: 1}}, repo)
+		if err != nil {
+			return opts, err
+		}
+		if xp <= upper {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return opts, fmt.Errorf("%w: no monster matches the filters", ErrNoEncounterGenerated)
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(opts.Seed), 0))
+	for range generationAttempts {
+		var groups []encounter.MonsterGroup
+		var err error
+		if archetype != nil {
+			groups, err = archetypeGroups(c, *archetype, candidates, repo, rng, lower, upper)
+		} else {
+			groups, err = randomGroups(c, candidates, repo, rng, opts.MaxGroups, lower, upper)
+		}
+		if err != nil {
+			return opts, err
+		}
+		if groups != nil {
+			c.Groups = groups
+			return opts, nil
+		}
+	}
+
+	return opts, fmt.Errorf("%w: budget %d, tolerance %g", ErrNoEncounterGenerated, c.Budget, opts.Tolerance)
+}
+
+// randomGroups picks up to maxGroups distinct monsters and adds one monster at a time
+// until the XP reaches the lower bound, or returns nil when it cannot without passing upper
+func randomGroups(c *encounter.Composition, candidates []monster.Monster, repo encounter.Repository, rng *rand.Rand, maxGroups, lower, upper int) ([]encounter.MonsterGroup, error) {
+	numGroups := min(rng.IntN(maxGroups)+1, len(candidates))
+	groups := make([]encounter.MonsterGroup, 0, numGroups)
+	for _, i := range rng.Perm(len(candidates))[:numGroups] {
+		groups = append(groups, encounter.MonsterGroup{Monster: candidates[i], Quantity: 1})
+	}
+
+	xp, err := generatedXP(c, groups, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	for count := numGroups; xp < lower; count++ {
+		if count >= maxGeneratedMonsters {
+			return nil, nil
+		}
+
+		// Try the groups in random order and keep the first increment that fits
+		grown := false
+		for _, i := range rng.Perm(len(groups)) {
+			groups[i].Quantity++
+			next, err := generatedXP(c, groups, repo)
+			if err != nil {
+				return nil, err
+			}
+			if next <= upper {
+				xp = next
+				grown = true
+				break
+			}
+			groups[i].Quantity--
+		}
+		if !grown {
+			return nil, nil
+		}
+	}
+
+	if xp > upper {
+		return nil, nil
+	}
+	return groups, nil
+}
+
+// archetypeGroups picks a count for every role of the archetype and a monster whose XP is
+// close to the role's share of the budget, or returns nil when the total misses the bounds
+func archetypeGroups(c *encounter.Composition, archetype encounter.Archetype, candidates []monster.Monster, repo encounter.Repository, rng *rand.Rand, lower, upper int) ([]encounter.MonsterGroup, error) {
+	counts := make([]int, len(archetype.Roles))
+	total := 0
+	for i, role := range archetype.Roles {
+		counts[i] = role.MinCount + rng.IntN(role.MaxCount-role.MinCount+1)
+		total += counts[i]
+	}
+
+	// For 2014 the shares apply to the multiplied XP, so each role gets its share of the raw budget
+	multiplier := 1.0
+	if c.Ruleset == encounter.Ruleset2014 {
+		step, err := repo.GetMultiplierFor2014(total, c.Party.Size())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get multiplier for %d monsters: %w", total, err)
+		}
+		multiplier = step.Multiplier
+	}
+	rawBudget := float64(c.Budget) / multiplier
+
+	groups := make([]encounter.MonsterGroup, 0, len(archetype.Roles))
+	used := make(map[string]bool)
+	for i, role := range archetype.Roles {
+		target := rawBudget * role.Share / float64(counts[i])
+		m, ok := pickNear(candidates, target, used, rng)
+		if !ok {
+			return nil, nil
+		}
+		used[m.ID] = true
+		groups = append(groups, encounter.MonsterGroup{Monster: m, Quantity: counts[i]})
+	}
+
+	xp, err := generatedXP(c, groups, repo)
+	if err != nil {
+		return nil, err
+	}
+	if xp < lower || xp > upper {
+		return nil, nil
+	}
+	return groups, nil
+}
+
+// pickNear returns a random unused monster whose XP is within archetypeSpread of the target,
+// falling back to the unused monster with the closest XP
+func pickNear(candidates []monster.Monster, target float64, used map[string]bool, rng *rand.Rand) (monster.Monster, bool) {
+	var pool []monster.Monster
+	var nearest monster.Monster
+	found := false
+	for _, m := range candidates {
+		if used[m.ID] {
+			continue
+		}
+		xp := float64(m.XP)
+		if xp >= target*(1-archetypeSpread) && xp <= target*(1+archetypeSpread) {
+			pool = append(pool, m)
+		}
+		if !found || math.Abs(xp-target) < math.Abs(float64(nearest.XP)-target) {
+			nearest = m
+			found = true
+		}
+	}
+
+	if len(pool) > 0 {
+		return pool[rng.IntN(len(pool))], true
+	}
+	return nearest, found
+}
+
+// generatedXP returns the XP compared against the budget: the raw sum for 2024,
+// the multiplied XP for 2014
+func generatedXP(c *encounter.Composition, groups []encounter.MonsterGroup, repo encounter.Repository) (int, error) {
+	if c.Ruleset != encounter.Ruleset2014 {
+		xp := 0
+		for _, g := range groups {
+			xp += g.XP()
+		}
+		return xp, nil
+	}
+
+	adjusted, err := encounter.CalculateAdjustedXP2014(c.Party, groups, c.CountWeakMonsters, repo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to calculate adjusted XP: %w", err)
+	}
+	return adjusted.AdjustedXP, nil
+}
+
+// compositionMonsters converts monster groups to their response representation
+func compositionMonsters(groups []encounter.MonsterGroup) []CompositionMonster {
+	monsters := make([]CompositionMonster, len(groups))
+	for i, g := range groups {
+		monsters[i] = CompositionMonster{
+			ID:       g.Monster.ID,
+			Name:     g.Monster.Name,
+			CR:       g.Monster.CR,
+			XP:       g.Monster.EncounterXP(g.InLair),
+			Quantity: g.Quantity,
+			TotalXP:  g.XP(),
+			LairXP:   g.Monster.LairXP,
+			InLair:   g.InLair,
+		}
+	}
+	return monsters
+}
@@ -0,0 +1,304 @@
+package encounter
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
+)
+
+func newTestGeneratorService() *GeneratorService {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	return NewGeneratorService(logger, memory.NewEncounterRepository(), memory.NewMonsterRepository())
+}
+
+func TestGeneratorService_Generate(t *testing.T) {
+	service := newTestGeneratorService()
+
+	tests := []struct {
+		name    string
+		request GenerateRequest
+	}{
+		{
+			name: "2024 moderate budget for four level 5 characters",
+			request: GenerateRequest{
+				Ruleset:         "2024",
+				CharacterLevels: []int{5, 5, 5, 5},
+				Budget:          3000,
+				GenerateOptions: GenerateOptions{Seed: 42},
+			},
+		},
+		{
+			name: "2014 budget is compared against the multiplied XP",
+			request: GenerateRequest{
+				Ruleset:         "2014",
+				CharacterLevels: []int{5, 5, 5, 5},
+				Budget:          2000,
+				GenerateOptions: GenerateOptions{Seed: 7, MaxGroups: 2},
+			},
+		},
+		{
+			name: "type and CR filters are respected",
+			request: GenerateRequest{
+				Ruleset:         "2024",
+				CharacterLevels: []int{3, 3, 3},
+				Budget:          900,
+				GenerateOptions: GenerateOptions{
+					Seed:    1,
+					Filters: monster.SearchFilters{Type: "Non morto", CRMin: "1/4", CRMax: "2"},
+				},
+			},
+		},
+		{
+			name: "tight tolerance",
+			request: GenerateRequest{
+				Ruleset:         "2024",
+				CharacterLevels: []int{10, 10, 10, 10},
+				Budget:          15600,
+				GenerateOptions: GenerateOptions{Seed: 3, Tolerance: 0.02},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.Generate(tt.request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(result.Monsters) == 0 {
+				t.Fatal("expected at least one monster group")
+			}
+			maxGroups := tt.request.MaxGroups
+			if maxGroups == 0 {
+				maxGroups = DefaultMaxGroups
+			}
+			if len(result.Monsters) > maxGroups {
+				t.Errorf("expected at most %d groups, got %d", maxGroups, len(result.Monsters))
+			}
+
+			xp := result.XPUsed
+			if result.Ruleset == "2014" {
+				xp = result.AdjustedXP
+			}
+			tolerance := float64(result.Budget) * result.Tolerance
+			if diff := float64(xp - result.Budget); diff < -tolerance || diff > tolerance {
+				t.Errorf("expected XP %d within %.0f of budget %d", xp, tolerance, result.Budget)
+			}
+
+			filters := tt.request.Filters
+			for _, m := range result.Monsters {
+				found, ok := memory.NewMonsterRepository().FindByID(m.ID)
+				if !ok {
+					t.Fatalf("generated unknown monster %s", m.ID)
+				}
+				if filters.Type != "" && found.Type != filters.Type {
+					t.Errorf("monster %s has type %s, expected %s", m.ID, found.Type, filters.Type)
+				}
+				if filters.CRMin != "" && found.CRValue() < monster.ParseCR(filters.CRMin) {
+					t.Errorf("monster %s has CR %s below %s", m.ID, found.CR, filters.CRMin)
+				}
+				if filters.CRMax != "" && found.CRValue() > monster.ParseCR(filters.CRMax) {
+					t.Errorf("monster %s has CR %s above %s", m.ID, found.CR, filters.CRMax)
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratorService_GenerateIsReproducible(t *testing.T) {
+	service := newTestGeneratorService()
+	request := GenerateRequest{
+		Ruleset:         "2014",
+		CharacterLevels: []int{4, 4, 4},
+		Budget:          1500,
+	}
+
+	first, err := service.Generate(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Seed == 0 {
+		t.Fatal("expected a random seed to be reported")
+	}
+
+	request.Seed = first.Seed
+	second, err := service.Generate(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(first.Monsters, second.Monsters) {
+		t.Errorf("expected the same encounter for seed %d, got %+v and %+v", first.Seed, first.Monsters, second.Monsters)
+	}
+}
+
+func TestGeneratorService_GenerateErrors(t *testing.T) {
+	service := newTestGeneratorService()
+
+	tests := []struct {
+		name        string
+		request     GenerateRequest
+		expectNoFit bool
+	}{
+		{
+			name:        "no monster matches the filters",
+			request:     GenerateRequest{Ruleset: "2024", CharacterLevels: []int{1}, Budget: 20, GenerateOptions: GenerateOptions{Filters: monster.SearchFilters{Type: "Drago"}}},
+			expectNoFit: true,
+		},
+		{
+			name:    "zero budget",
+			request: GenerateRequest{Ruleset: "2024", CharacterLevels: []int{1}, Budget: 0},
+		},
+		{
+			name:    "invalid tolerance",
+			request: GenerateRequest{Ruleset: "2024", CharacterLevels: []int{1}, Budget: 50, GenerateOptions: GenerateOptions{Tolerance: 2}},
+		},
+		{
+			name:    "too many groups",
+			request: GenerateRequest{Ruleset: "2024", CharacterLevels: []int{1}, Budget: 50, GenerateOptions: GenerateOptions{MaxGroups: MaxGeneratedGroups + 1}},
+		},
+		{
+			name:    "invalid ruleset",
+			request: GenerateRequest{Ruleset: "3000", CharacterLevels: []int{1}, Budget: 50},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Generate(tt.request)
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if tt.expectNoFit != errors.Is(err, ErrNoEncounterGenerated) {
+				t.Errorf("unexpected error kind: %v", err)
+			}
+		})
+	}
+}
+
+func TestCompositionService_Generate(t *testing.T) {
+	service := newTestCompositionService()
+
+	created, err := service.Create(CreateCompositionRequest{
+		Ruleset:         "2024",
+		Difficulty:      "Moderate",
+		CharacterLevels: []int{5, 5, 5, 5},
+		Budget:          3000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.AddMonster(created.ID, "aboleth", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := service.Generate(created.ID, GenerateOptions{Seed: 99})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Seed != 99 {
+		t.Errorf("expected seed 99, got %d", result.Seed)
+	}
+	if result.XPUsed < 2700 || result.XPUsed > 3300 {
+		t.Errorf("expected XP used within 10%% of 3000, got %d", result.XPUsed)
+	}
+
+	fetched, err := service.Get(created.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(fetched.Monsters, result.Monsters) {
+		t.Errorf("expected generated monsters to replace the composition, got %+v", fetched.Monsters)
+	}
+}
+
+func TestGeneratorService_GenerateArchetypes(t *testing.T) {
+	service := newTestGeneratorService()
+	monsters := memory.NewMonsterRepository()
+
+	tests := []struct {
+		name      string
+		archetype string
+		ruleset   string
+		levels    []int
+		budget    int
+		filters   monster.SearchFilters
+	}{
+		{name: "solo boss", archetype: "solo-boss", ruleset: "2024", levels: []int{5, 5, 5, 5}, budget: 3000},
+		{name: "solo boss 2014", archetype: "solo-boss", ruleset: "2014", levels: []int{8, 8, 8, 8}, budget: 3600},
+		{name: "undead boss and minions", archetype: "boss-minions", ruleset: "2024", levels: []int{5, 5, 5, 5}, budget: 3000, filters: monster.SearchFilters{Type: "Non morto"}},
+		{name: "boss and minions 2014", archetype: "boss-minions", ruleset: "2014", levels: []int{6, 6, 6, 6}, budget: 2400, filters: monster.SearchFilters{Type: "Umanoide"}},
+		{name: "beast horde", archetype: "horde", ruleset: "2024", levels: []int{3, 3, 3, 3}, budget: 900, filters: monster.SearchFilters{Type: "Bestia"}},
+		{name: "horde 2014", archetype: "horde", ruleset: "2014", levels: []int{4, 4, 4, 4}, budget: 2000},
+		{name: "elite pair", archetype: "elite-pair", ruleset: "2024", levels: []int{10, 10, 10, 10}, budget: 8000},
+		{name: "dragon elite pair", archetype: "elite-pair", ruleset: "2014", levels: []int{12, 12, 12, 12}, budget: 12000, filters: monster.SearchFilters{Type: "Drago"}},
+	}
+
+	archetypes := memory.NewEncounterRepository()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archetype, err := archetypes.GetArchetype(tt.archetype)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Every seed must give a coherent encounter, not just a lucky one
+			for seed := int64(1); seed <= 20; seed++ {
+				result, err := service.Generate(GenerateRequest{
+					Ruleset:         tt.ruleset,
+					CharacterLevels: tt.levels,
+					Budget:          tt.budget,
+					GenerateOptions: GenerateOptions{Seed: seed, Archetype: tt.archetype, Filters: tt.filters},
+				})
+				if err != nil {
+					t.Fatalf("seed %d: unexpected error: %v", seed, err)
+				}
+
+				if len(result.Monsters) != len(archetype.Roles) {
+					t.Fatalf("seed %d: expected %d groups, got %d", seed, len(archetype.Roles), len(result.Monsters))
+				}
+				for i, role := range archetype.Roles {
+					m := result.Monsters[i]
+					if m.Quantity < role.MinCount || m.Quantity > role.MaxCount {
+						t.Errorf("seed %d: role %s has %d monsters, expected %d-%d", seed, role.Name, m.Quantity, role.MinCount, role.MaxCount)
+					}
+					found, _ := monsters.FindByID(m.ID)
+					if tt.filters.Type != "" && found.Type != tt.filters.Type {
+						t.Errorf("seed %d: monster %s has type %s, expected %s", seed, m.ID, found.Type, tt.filters.Type)
+					}
+				}
+				if len(result.Monsters) == 2 && result.Monsters[0].XP <= result.Monsters[1].XP {
+					t.Errorf("seed %d: expected the boss %s to be stronger than the minions %s", seed, result.Monsters[0].ID, result.Monsters[1].ID)
+				}
+
+				xp := result.XPUsed
+				if tt.ruleset == "2014" {
+					xp = result.AdjustedXP
+				}
+				if xp < tt.budget*9/10 || xp > tt.budget*11/10 {
+					t.Errorf("seed %d: expected XP within 10%% of %d, got %d", seed, tt.budget, xp)
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratorService_GenerateUnknownArchetype(t *testing.T) {
+	service := newTestGeneratorService()
+
+	_, err := service.Generate(GenerateRequest{
+		Ruleset:         "2024",
+		CharacterLevels: []int{5},
+		Budget:          750,
+		GenerateOptions: GenerateOptions{Archetype: "missing"},
+	})
+	if !errors.Is(err, encounter.ErrArchetypeNotFound) {
+		t.Errorf("expected ErrArchetypeNotFound, got %v", err)
+	}
+}
@@ -3,6 +3,8 @@ package encounter
 import (
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
 )
@@ -33,6 +35,13 @@ type DifficultyOption struct {
 	Label string `json:"label"`
 }
 
+// ArchetypeOption represents an encounter archetype option for UI
+type ArchetypeOption struct {
+	Value       string `json:"value"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
 // LevelOption represents a level option for UI
 type LevelOption struct {
 	Value int    `json:"value"`
@@ -89,6 +98,30 @@ func (q *QueryHandler) GetLevelOptions() []LevelOption {
 	return options
 }
 
+// GetArchetypeOptions returns the encounter archetypes available to the generator
+func (q *QueryHandler) GetArchetypeOptions() []ArchetypeOption {
+	archetypes := q.repository.GetAllArchetypes()
+	options := make([]ArchetypeOption, len(archetypes))
+
+	for i, a := range archetypes {
+		roles := make([]string, len(a.Roles))
+		for j, r := range a.Roles {
+			count := strconv.Itoa(r.MinCount)
+			if r.MaxCount != r.MinCount {
+				count += "-" + strconv.Itoa(r.MaxCount)
+			}
+			roles[j] = fmt.Sprintf("%s %.0f%% ×%s", r.Name, r.Share*100, count)
+		}
+		options[i] = ArchetypeOption{
+			Value:       a.ID,
+			Label:       a.Name,
+			Description: strings.Join(roles, ", "),
+		}
+	}
+
+	return options
+}
+
 // GetPartyModeOptions returns available party mode options
 func (q *QueryHandler) GetPartyModeOptions() []struct {
 	Value string `json:"value"`
@@ -125,17 +158,21 @@ func (q *QueryHandler) GetXPThreshold(level int, difficulty string, ruleset stri
 	}
 }
 
+// GetMultiplier returns the 2014 encounter multiplier for a number of monsters and a party size
+func (q *QueryHandler) GetMultiplier(numMonsters, partySize int) (encounter.MultiplierStep, error) {
+	return q.repository.GetMultiplierFor2014(numMonsters, partySize)
+}
+
 // GetMultiplierRanges returns encounter multiplier ranges (2014 rules)
 func (q *QueryHandler) GetMultiplierRanges() []encounter.MultiplierRange {
 	// Return hardcoded ranges for now, could be moved to repository
 	return []encounter.MultiplierRange{
 		{MaxMonsters: 1, Multiplier: 1.0},
 		{MaxMonsters: 2, Multiplier: 1.5},
-		{MaxMonsters: 3, Multiplier: 2.0},
-		{MaxMonsters: 7, Multiplier: 2.5},
-		{MaxMonsters: 11, Multiplier: 3.0},
-		{MaxMonsters: 15, Multiplier: 4.0},
-		{MaxMonsters: 99, Multiplier: 5.0},
+		{MaxMonsters: 6, Multiplier: 2.0},
+		{MaxMonsters: 10, Multiplier: 2.5},
+		{MaxMonsters: 14, Multiplier: 3.0},
+		{MaxMonsters: 99, Multiplier: 4.0},
 	}
 }
 
@@ -152,3 +189,61 @@ func levelToLabel(level int) string {
 		return fmt.Sprintf("%dth Level", level)
 	}
 }
+
+// ThresholdRow represents the XP thresholds of a character level
+type ThresholdRow struct {
+	Level      int            `json:"level"`
+	Thresholds map[string]int `json:"thresholds"`
+	// XPPerDay is the adjusted XP a character can face in a day, only for 2014 rules
+	XPPerDay int `json:"xp_per_day,omitempty"`
+}
+
+// ThresholdTable represents the per-level XP thresholds of a ruleset
+type ThresholdTable struct {
+	Ruleset      string         `json:"ruleset"`
+	Difficulties []string       `json:"difficulties"`
+	Levels       []ThresholdRow `json:"levels"`
+}
+
+// GetThresholdTable returns the XP threshold of every difficulty for every supported level
+func (q *QueryHandler) GetThresholdTable(ruleset string) (*ThresholdTable, error) {
+	rulesetValue, err := encounter.NewRuleset(ruleset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+
+	var difficulties []encounter.Difficulty
+	switch rulesetValue {
+	case encounter.Ruleset2024:
+		difficulties = q.repository.GetAllDifficultiesFor2024()
+	case encounter.Ruleset2014:
+		difficulties = q.repository.GetAllDifficultiesFor2014()
+	}
+
+	table := &ThresholdTable{
+		Ruleset:      rulesetValue.String(),
+		Difficulties: make([]string, len(difficulties)),
+	}
+	for i, d := range difficulties {
+		table.Difficulties[i] = d.String()
+	}
+
+	for _, level := range q.repository.GetSupportedLevels() {
+		row := ThresholdRow{Level: level, Thresholds: make(map[string]int, len(difficulties))}
+		for _, d := range difficulties {
+			xp, err := q.GetXPThreshold(level, d.String(), rulesetValue.String())
+			if err != nil {
+				return nil, fmt.Errorf("level %d %s: %w", level, d, err)
+			}
+			row.Thresholds[d.String()] = xp
+		}
+		if rulesetValue == encounter.Ruleset2014 {
+			if row.XPPerDay, err = q.repository.GetXPPerDay(level); err != nil {
+				return nil, fmt.Errorf("level %d XP per day: %w", level, err)
+			}
+		}
+		table.Levels = append(table.Levels, row)
+	}
+
+	return table, nil
+}
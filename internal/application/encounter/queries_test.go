@@ -0,0 +1,69 @@
+package encounter
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
+)
+
+func TestQueryHandler_GetThresholdTable(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	queries := NewQueryHandler(logger, memory.NewEncounterRepository())
+
+	tests := []struct {
+		name           string
+		ruleset        string
+		difficulties   int
+		level5         map[string]int
+		level5XPPerDay int
+		expectError    bool
+	}{
+		{
+			name:         "2024",
+			ruleset:      "2024",
+			difficulties: 3,
+			level5:       map[string]int{"Low": 500, "Moderate": 750, "High": 1100},
+		},
+		{
+			name:           "2014 with XP per day",
+			ruleset:        "2014",
+			difficulties:   4,
+			level5:         map[string]int{"Facile": 250, "Media": 500, "Difficile": 750, "Letale": 1100},
+			level5XPPerDay: 3500,
+		},
+		{name: "invalid ruleset", ruleset: "3.5", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := queries.GetThresholdTable(tt.ruleset)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(table.Difficulties) != tt.difficulties {
+				t.Errorf("expected %d difficulties, got %v", tt.difficulties, table.Difficulties)
+			}
+			if len(table.Levels) != 20 {
+				t.Fatalf("expected 20 levels, got %d", len(table.Levels))
+			}
+			row := table.Levels[4]
+			for difficulty, xp := range tt.level5 {
+				if row.Thresholds[difficulty] != xp {
+					t.Errorf("expected level 5 %s = %d, got %d", difficulty, xp, row.Thresholds[difficulty])
+				}
+			}
+			if row.XPPerDay != tt.level5XPPerDay {
+				t.Errorf("expected level 5 XP per day %d, got %d", tt.level5XPPerDay, row.XPPerDay)
+			}
+		})
+	}
+}
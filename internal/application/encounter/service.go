@@ -1,12 +1,16 @@
 package encounter
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
 )
 
+// ErrInvalidRequest is wrapped by the errors caused by the values of a request rather than by the server
+var ErrInvalidRequest = errors.New("invalid request")
+
 // Service provides use cases for encounter management
 type Service struct {
 	logger     *slog.Logger
@@ -27,13 +31,16 @@ type CalculateXPRequest struct {
 	PartyMode       string
 	Difficulty      string
 	CharacterLevels []int
-	NumMonsters     int // Only used for 2014 ruleset
+	Monsters        []encounter.MonsterGroup // Only used for 2014 ruleset
+	// CountWeakMonsters disables the 2014 rule that ignores much weaker monsters in the count
+	CountWeakMonsters bool
 }
 
 // CalculateXPResponse represents the response from XP calculation
 type CalculateXPResponse struct {
 	encounter.XPCalculationResult
-	CalculatedDifficulty2014 string `json:"calculated_difficulty_2014,omitempty"`
+	CalculatedDifficulty2014 string                    `json:"calculated_difficulty_2014,omitempty"`
+	Adjusted2014             *encounter.AdjustedXP2014 `json:"adjusted_2014,omitempty"`
 }
 
 // CalculateXP calculates encounter XP based on the request parameters
@@ -42,31 +49,28 @@ func (s *Service) CalculateXP(req CalculateXPRequest) (*CalculateXPResponse, err
 		"ruleset", req.Ruleset,
 		"difficulty", req.Difficulty,
 		"character_levels", req.CharacterLevels,
-		"num_monsters", req.NumMonsters,
+		"num_monster_groups", len(req.Monsters),
 	)
 
 	// Validate and create value objects
 	ruleset, err := encounter.NewRuleset(req.Ruleset)
 	if err != nil {
-		return nil, fmt.Errorf("invalid ruleset: %w", err)
+		return nil, fmt.Errorf("%w: invalid ruleset: %w", ErrInvalidRequest, err)
 	}
 
 	difficulty, err := encounter.NewDifficulty(req.Difficulty, ruleset)
 	if err != nil {
-		return nil, fmt.Errorf("invalid difficulty: %w", err)
+		return nil, fmt.Errorf("%w: invalid difficulty: %w", ErrInvalidRequest, err)
 	}
 
 	// Create party
 	party, err := encounter.NewParty(req.CharacterLevels)
 	if err != nil {
-		return nil, fmt.Errorf("invalid party: %w", err)
+		return nil, fmt.Errorf("%w: invalid party: %w", ErrInvalidRequest, err)
 	}
 
 	// Create encounter
 	enc := encounter.NewEncounter("temp-id", party, ruleset, difficulty)
-	if ruleset == encounter.Ruleset2014 {
-		enc.NumMonsters = req.NumMonsters
-	}
 
 	// Calculate XP
 	if err := enc.CalculateXP(s.repository); err != nil {
@@ -79,14 +83,14 @@ func (s *Service) CalculateXP(req CalculateXPRequest) (*CalculateXPResponse, err
 		XPCalculationResult: result,
 	}
 
-	// For 2014 ruleset, calculate difficulty based on XP
-	if ruleset == encounter.Ruleset2014 {
-		calculatedDifficulty, err := s.calculateDifficulty2014(party, enc.TotalXP)
+	// For 2014 ruleset, evaluate the chosen monsters against the party thresholds
+	if ruleset == encounter.Ruleset2014 && len(req.Monsters) > 0 {
+		adjusted, err := encounter.CalculateAdjustedXP2014(party, req.Monsters, req.CountWeakMonsters, s.repository)
 		if err != nil {
-			s.logger.Warn("Failed to calculate 2014 difficulty", "error", err)
-		} else {
-			response.CalculatedDifficulty2014 = calculatedDifficulty.String()
+			return nil, fmt.Errorf("failed to calculate adjusted XP: %w", err)
 		}
+		response.Adjusted2014 = &adjusted
+		response.CalculatedDifficulty2014 = adjusted.Difficulty.String()
 	}
 
 	s.logger.Info("XP calculation completed",
@@ -98,39 +102,11 @@ func (s *Service) CalculateXP(req CalculateXPRequest) (*CalculateXPResponse, err
 	return response, nil
 }
 
-// calculateDifficulty2014 determines the actual encounter difficulty for 2014 rules
-func (s *Service) calculateDifficulty2014(party encounter.Party, totalXP int) (encounter.Difficulty, error) {
-	// Calculate thresholds for each difficulty level
-	difficulties := s.repository.GetAllDifficultiesFor2014()
-
-	var bestMatch encounter.Difficulty
-	minDifference := int(^uint(0) >> 1) // Max int
-
-	for _, diff := range difficulties {
-		totalThreshold := 0
-		for _, char := range party.Characters {
-			threshold, err := s.repository.GetThresholdFor2014(char.Level, diff)
-			if err != nil {
-				continue
-			}
-			totalThreshold += threshold
-		}
-
-		difference := abs(totalXP - totalThreshold)
-		if difference < minDifference {
-			minDifference = difference
-			bestMatch = diff
-		}
-	}
-
-	return bestMatch, nil
-}
-
 // GetAvailableDifficulties returns available difficulties for the given ruleset
 func (s *Service) GetAvailableDifficulties(ruleset string) ([]string, error) {
 	rulesetValue, err := encounter.NewRuleset(ruleset)
 	if err != nil {
-		return nil, fmt.Errorf("invalid ruleset: %w", err)
+		return nil, fmt.Errorf("%w: invalid ruleset: %w", ErrInvalidRequest, err)
 	}
 
 	var difficulties []encounter.Difficulty
@@ -185,11 +161,3 @@ func (s *Service) ValidatePartyComposition(partyMode string, levels []int, sameL
 
 	return nil
 }
-
-// Helper function
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
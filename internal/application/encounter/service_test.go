@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
 )
 
@@ -121,48 +122,132 @@ func TestService_CalculateXP2014(t *testing.T) {
 	repo := memory.NewEncounterRepository()
 	service := NewService(logger, repo)
 
+	ogre := monster.Monster{ID: "ogre", Name: "Ogre", CR: "2", XP: 450}
+	goblin := monster.Monster{ID: "goblin", Name: "Goblin", CR: "1/4", XP: 50}
+
 	tests := []struct {
-		name        string
-		request     CalculateXPRequest
-		expectedXP  int
-		expectError bool
+		name               string
+		request            CalculateXPRequest
+		expectedXP         int
+		expectedAdjustedXP int
+		expectedDifficulty string
+		expectedShift      int
+		expectedExcluded   int
+		expectError        bool
 	}{
 		{
-			name: "2014 single character media difficulty 1 monster",
+			name: "2014 single character media difficulty",
 			request: CalculateXPRequest{
 				Ruleset:         "2014",
 				PartyMode:       "same",
 				Difficulty:      "Media",
 				CharacterLevels: []int{5},
-				NumMonsters:     1,
 			},
-			expectedXP:  500, // 500 * 1.0 multiplier
+			expectedXP:  500,
 			expectError: false,
 		},
 		{
-			name: "2014 party of 4 level 5 characters media difficulty 2 monsters",
+			name: "2014 party of 4 level 5 characters media difficulty",
 			request: CalculateXPRequest{
 				Ruleset:         "2014",
 				PartyMode:       "same",
 				Difficulty:      "Media",
 				CharacterLevels: []int{5, 5, 5, 5},
-				NumMonsters:     2,
 			},
-			expectedXP:  3000, // (4 * 500) * 1.5 multiplier
+			expectedXP:  2000, // 4 * 500, no multiplier on the threshold
 			expectError: false,
 		},
 		{
-			name: "2014 mixed level party difficile difficulty 5 monsters",
+			name: "2014 mixed level party difficile difficulty",
 			request: CalculateXPRequest{
 				Ruleset:         "2014",
 				PartyMode:       "different",
 				Difficulty:      "Difficile",
 				CharacterLevels: []int{3, 5, 7},
-				NumMonsters:     5,
 			},
-			expectedXP:  5187, // (225 + 750 + 1100) * 2.5 multiplier = 2075 * 2.5 = 5187.5 -> 5187
+			expectedXP:  2075, // 225 + 750 + 1100
 			expectError: false,
 		},
+		{
+			name: "2014 two ogres against four level 3 characters",
+			request: CalculateXPRequest{
+				Ruleset:         "2014",
+				PartyMode:       "same",
+				Difficulty:      "Media",
+				CharacterLevels: []int{3, 3, 3, 3},
+				Monsters:        []encounter.MonsterGroup{{Monster: ogre, Quantity: 2}},
+			},
+			expectedXP:         600,  // 4 * 150
+			expectedAdjustedXP: 1350, // 900 * 1.5, Difficile threshold 900, Letale 1600
+			expectedDifficulty: "Difficile",
+			expectError:        false,
+		},
+		{
+			name: "2014 ogre and four goblins ignores the weak goblins in the count",
+			request: CalculateXPRequest{
+				Ruleset:         "2014",
+				PartyMode:       "same",
+				Difficulty:      "Media",
+				CharacterLevels: []int{5, 5, 5, 5},
+				Monsters: []encounter.MonsterGroup{
+					{Monster: ogre, Quantity: 1},
+					{Monster: goblin, Quantity: 4},
+				},
+			},
+			expectedXP:         2000,
+			expectedAdjustedXP: 650, // 650 * 1, only the ogre is counted
+			expectedDifficulty: "",
+			expectedExcluded:   1,
+			expectError:        false,
+		},
+		{
+			name: "2014 ogre and four goblins with weak monsters counted",
+			request: CalculateXPRequest{
+				Ruleset:         "2014",
+				PartyMode:       "same",
+				Difficulty:      "Media",
+				CharacterLevels: []int{5, 5, 5, 5},
+				Monsters: []encounter.MonsterGroup{
+					{Monster: ogre, Quantity: 1},
+					{Monster: goblin, Quantity: 4},
+				},
+				CountWeakMonsters: true,
+			},
+			expectedXP:         2000,
+			expectedAdjustedXP: 1300, // 650 * 2
+			expectedDifficulty: "Facile",
+			expectError:        false,
+		},
+		{
+			name: "2014 two ogres against two level 3 characters",
+			request: CalculateXPRequest{
+				Ruleset:         "2014",
+				PartyMode:       "same",
+				Difficulty:      "Media",
+				CharacterLevels: []int{3, 3},
+				Monsters:        []encounter.MonsterGroup{{Monster: ogre, Quantity: 2}},
+			},
+			expectedXP:         300,
+			expectedAdjustedXP: 1800, // 900 * 2, multiplier 1.5 shifted up for a small party
+			expectedDifficulty: "Letale",
+			expectedShift:      1,
+			expectError:        false,
+		},
+		{
+			name: "2014 two ogres against seven level 3 characters",
+			request: CalculateXPRequest{
+				Ruleset:         "2014",
+				PartyMode:       "same",
+				Difficulty:      "Media",
+				CharacterLevels: []int{3, 3, 3, 3, 3, 3, 3},
+				Monsters:        []encounter.MonsterGroup{{Monster: ogre, Quantity: 2}},
+			},
+			expectedXP:         1050,
+			expectedAdjustedXP: 900, // 900 * 1, multiplier 1.5 shifted down for a large party
+			expectedDifficulty: "Facile",
+			expectedShift:      -1,
+			expectError:        false,
+		},
 		{
 			name: "invalid difficulty for 2014",
 			request: CalculateXPRequest{
@@ -170,7 +255,6 @@ func TestService_CalculateXP2014(t *testing.T) {
 				PartyMode:       "same",
 				Difficulty:      "Low",
 				CharacterLevels: []int{5},
-				NumMonsters:     1,
 			},
 			expectError: true,
 		},
@@ -200,9 +284,27 @@ func TestService_CalculateXP2014(t *testing.T) {
 				t.Errorf("expected ruleset %s, got %s", encounter.Ruleset2014, result.Ruleset)
 			}
 
-			// For 2014, we should have a calculated difficulty
-			if result.CalculatedDifficulty2014 == "" {
-				t.Errorf("expected calculated difficulty for 2014 ruleset, got empty string")
+			if len(tt.request.Monsters) == 0 {
+				if result.Adjusted2014 != nil {
+					t.Errorf("expected no adjusted XP without monsters")
+				}
+				return
+			}
+
+			if result.Adjusted2014 == nil {
+				t.Fatalf("expected adjusted XP for 2014 ruleset with monsters")
+			}
+			if result.Adjusted2014.AdjustedXP != tt.expectedAdjustedXP {
+				t.Errorf("expected adjusted XP %d, got %d", tt.expectedAdjustedXP, result.Adjusted2014.AdjustedXP)
+			}
+			if result.CalculatedDifficulty2014 != tt.expectedDifficulty {
+				t.Errorf("expected calculated difficulty %s, got %s", tt.expectedDifficulty, result.CalculatedDifficulty2014)
+			}
+			if result.Adjusted2014.MultiplierStep.Shift != tt.expectedShift {
+				t.Errorf("expected multiplier shift %d, got %d", tt.expectedShift, result.Adjusted2014.MultiplierStep.Shift)
+			}
+			if len(result.Adjusted2014.ExcludedMonsters) != tt.expectedExcluded {
+				t.Errorf("expected %d excluded monster groups, got %d", tt.expectedExcluded, len(result.Adjusted2014.ExcludedMonsters))
 			}
 		})
 	}
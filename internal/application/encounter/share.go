@@ -0,0 +1,137 @@
+package encounter
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// ShareService provides use cases for sharing an encounter as a link
+type ShareService struct {
+	logger             *slog.Logger
+	service            *Service
+	compositionService *CompositionService
+	compositions       encounter.CompositionRepository
+	key                []byte
+}
+
+// NewShareService creates a new share application service that signs share codes with key
+func NewShareService(logger *slog.Logger, service *Service, compositionService *CompositionService, compositions encounter.CompositionRepository, key []byte) *ShareService {
+	return &ShareService{
+		logger:             logger,
+		service:            service,
+		compositionService: compositionService,
+		compositions:       compositions,
+		key:                key,
+	}
+}
+
+// SharedEncounterResponse represents an encounter rebuilt from a share code.
+// The composition of a link that was only opened has no ID: it is stored once the user edits it.
+type SharedEncounterResponse struct {
+	Code        string               `json:"code"`
+	Result      *CalculateXPResponse `json:"result"`
+	Composition *CompositionResponse `json:"composition"`
+}
+
+// Share returns the share code of a composition
+func (s *ShareService) Share(compositionID string) (string, error) {
+	composition, err := s.compositions.FindByID(compositionID)
+	if err != nil {
+		return "", err
+	}
+	return encounter.ShareComposition(composition).Encode(s.key)
+}
+
+// Open rebuilds the XP calculation and the monster selection of a share code without storing anything,
+// so crawlers and link previews that fetch the link leave no composition behind
+func (s *ShareService) Open(code string) (*SharedEncounterResponse, error) {
+	result, composition, err := s.rebuild(code, "")
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.compositionService.toResponse(composition)
+	if err != nil {
+		return nil, err
+	}
+	return &SharedEncounterResponse{Code: code, Result: result, Composition: response}, nil
+}
+
+// Edit stores the encounter of a share code as a new composition the user can change
+func (s *ShareService) Edit(code string) (*SharedEncounterResponse, error) {
+	id, err := newCompositionID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate composition ID: %w", err)
+	}
+
+	result, composition, err := s.rebuild(code, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.compositions.Save(composition); err != nil {
+		return nil, fmt.Errorf("failed to save composition: %w", err)
+	}
+
+	s.logger.Debug("Shared encounter stored for editing", "composition_id", id, "monster_groups", len(composition.Groups))
+
+	response, err := s.compositionService.toResponse(composition)
+	if err != nil {
+		return nil, err
+	}
+	return &SharedEncounterResponse{Code: code, Result: result, Composition: response}, nil
+}
+
+// rebuild decodes a share code into its XP calculation and a composition with the given ID
+func (s *ShareService) rebuild(code, id string) (*CalculateXPResponse, *encounter.Composition, error) {
+	shared, err := encounter.DecodeShareCode(code, s.key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	partyMode := encounter.PartyModeDifferent
+	if len(slices.Compact(slices.Clone(shared.Levels))) == 1 {
+		partyMode = encounter.PartyModeSame
+	}
+
+	result, err := s.service.CalculateXP(CalculateXPRequest{
+		Ruleset:         shared.Ruleset.String(),
+		PartyMode:       partyMode.String(),
+		Difficulty:      shared.Difficulty.String(),
+		CharacterLevels: shared.Levels,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	composition, err := newComposition(id, CreateCompositionRequest{
+		Ruleset:         shared.Ruleset.String(),
+		Difficulty:      shared.Difficulty.String(),
+		CharacterLevels: shared.Levels,
+		Budget:          result.TotalXP,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, sm := range shared.Monsters {
+		m, ok := s.compositionService.monsters.FindByID(sm.MonsterID)
+		if !ok {
+			return nil, nil, fmt.Errorf("failed to restore shared monsters: %w: %s", monster.ErrNotFound, sm.MonsterID)
+		}
+		if err := composition.AddMonster(m, sm.Quantity); err != nil {
+			return nil, nil, fmt.Errorf("failed to restore shared monsters: %w", invalidRequest(err))
+		}
+		if sm.InLair {
+			if err := composition.SetInLair(sm.MonsterID, true); err != nil {
+				return nil, nil, fmt.Errorf("failed to restore shared monsters: %w", invalidRequest(err))
+			}
+		}
+	}
+	composition.CountWeakMonsters = shared.CountWeakMonsters
+
+	return result, composition, nil
+}
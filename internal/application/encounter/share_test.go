@@ -0,0 +1,161 @@
+package encounter
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
+)
+
+var testShareKey = []byte("test share key")
+
+// countingCompositions counts the compositions stored through it
+type countingCompositions struct {
+	encounter.CompositionRepository
+	saves int
+}
+
+func (c *countingCompositions) Save(composition *encounter.Composition) error {
+	c.saves++
+	return c.CompositionRepository.Save(composition)
+}
+
+func newTestShareService() (*ShareService, *CompositionService, *countingCompositions) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	repo := memory.NewEncounterRepository()
+	compositions := &countingCompositions{CompositionRepository: memory.NewCompositionRepository()}
+	compositionService := NewCompositionService(logger, repo, compositions, memory.NewMonsterRepository())
+	return NewShareService(logger, NewService(logger, repo), compositionService, compositions, testShareKey), compositionService, compositions
+}
+
+func TestShareService_ShareAndOpen(t *testing.T) {
+	service, compositionService, compositions := newTestShareService()
+
+	original, err := compositionService.Create(CreateCompositionRequest{
+		Ruleset:         "2014",
+		Difficulty:      "Difficile",
+		CharacterLevels: []int{3, 3, 4},
+		Budget:          1300,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := compositionService.AddMonster(original.ID, "ogre", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if original, err = compositionService.SetCountWeakMonsters(original.ID, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	code, err := service.Share(original.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	saves := compositions.saves
+	opened, err := service.Open(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if compositions.saves != saves || opened.Composition.ID != "" {
+		t.Errorf("expected opening a link to store nothing, got %d saves and composition %q", compositions.saves-saves, opened.Composition.ID)
+	}
+	if opened.Composition.Budget != opened.Result.TotalXP {
+		t.Errorf("expected the composition budget to be the recalculated %d XP, got %d", opened.Result.TotalXP, opened.Composition.Budget)
+	}
+	if opened.Composition.XPUsed != original.XPUsed || opened.Composition.AdjustedXP != original.AdjustedXP {
+		t.Errorf("expected %d/%d XP, got %d/%d", original.XPUsed, original.AdjustedXP, opened.Composition.XPUsed, opened.Composition.AdjustedXP)
+	}
+	if !opened.Composition.CountWeakMonsters {
+		t.Error("expected the weak monster setting to be restored")
+	}
+	if len(opened.Composition.Monsters) != 1 || opened.Composition.Monsters[0].ID != "ogre" {
+		t.Errorf("expected one ogre, got %+v", opened.Composition.Monsters)
+	}
+
+	edited, err := service.Edit(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if edited.Composition.ID == "" || edited.Composition.ID == original.ID {
+		t.Errorf("expected editing the link to store a new composition, got %q", edited.Composition.ID)
+	}
+	stored, err := compositionService.Get(edited.Composition.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored.AdjustedXP != original.AdjustedXP || !stored.CountWeakMonsters || len(stored.Monsters) != 1 {
+		t.Errorf("expected the stored composition to match the shared one, got %+v", stored)
+	}
+}
+
+func TestShareService_KeepsInLair(t *testing.T) {
+	service, compositionService, _ := newTestShareService()
+
+	original, err := compositionService.Create(CreateCompositionRequest{
+		Ruleset:         "2024",
+		Difficulty:      "High",
+		CharacterLevels: []int{8, 8, 8, 8},
+		Budget:          20000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, id := range []string{"aboleth", "ogre"} {
+		if _, err := compositionService.AddMonster(original.ID, id, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if original, err = compositionService.SetInLair(original.ID, "aboleth", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	code, err := service.Share(original.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opened, err := service.Open(code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opened.Composition.XPUsed != original.XPUsed || opened.Composition.AdjustedXP != original.AdjustedXP {
+		t.Errorf("expected %d/%d XP, got %d/%d", original.XPUsed, original.AdjustedXP, opened.Composition.XPUsed, opened.Composition.AdjustedXP)
+	}
+	for _, m := range opened.Composition.Monsters {
+		if m.InLair != (m.ID == "aboleth") {
+			t.Errorf("expected only the aboleth in its lair, got %+v", m)
+		}
+	}
+}
+
+func TestShareService_Errors(t *testing.T) {
+	service, _, _ := newTestShareService()
+
+	unknownMonster, err := encounter.SharedEncounter{
+		Ruleset:    encounter.Ruleset2024,
+		Difficulty: encounter.DifficultyLow,
+		Levels:     []int{2},
+		Monsters:   []encounter.SharedMonster{{MonsterID: "not-a-monster", Quantity: 1}},
+	}.Encode(testShareKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.Share("missing"); !errors.Is(err, encounter.ErrCompositionNotFound) {
+		t.Errorf("expected ErrCompositionNotFound, got %v", err)
+	}
+	if _, err := service.Open("Aw"); !errors.Is(err, encounter.ErrInvalidShareCode) {
+		t.Errorf("expected ErrInvalidShareCode, got %v", err)
+	}
+	if _, err := service.Open(unknownMonster); !errors.Is(err, monster.ErrNotFound) {
+		t.Errorf("expected monster.ErrNotFound, got %v", err)
+	}
+	if _, err := service.Edit(unknownMonster); !errors.Is(err, monster.ErrNotFound) {
+		t.Errorf("expected monster.ErrNotFound, got %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package monster
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
 )
 
@@ -14,6 +17,11 @@ func NewService(repo monster.Repository) *Service {
 	return &Service{repo: repo}
 }
 
+// GetMonster returns the monster with the given ID.
+func (s *Service) GetMonster(id string) (monster.Monster, bool) {
+	return s.repo.FindByID(id)
+}
+
 // SearchMonsters returns monsters matching the query with XP up to maxXP.
 func (s *Service) SearchMonsters(query string, maxXP int) []monster.Monster {
 	return s.repo.Search(query, maxXP)
@@ -24,6 +32,22 @@ func (s *Service) SearchMonstersWithFilters(filters monster.SearchFilters) []mon
 	return s.repo.SearchWithFilters(filters)
 }
 
+// SearchMonstersRanked returns monsters matching the given filters, ranked by relevance
+// to the text query and with a snippet of the entry that matched.
+func (s *Service) SearchMonstersRanked(filters monster.SearchFilters) []monster.SearchResult {
+	return s.repo.SearchRanked(filters)
+}
+
+// SuggestMonsters returns up to limit monster names close to query, for autocompletion.
+func (s *Service) SuggestMonsters(query string, limit int) []monster.Suggestion {
+	return s.repo.Suggest(query, limit)
+}
+
+// ActionCoverage reports how much of the actions of every monster the action parser understands.
+func (s *Service) ActionCoverage() monster.ActionCoverage {
+	return monster.NewActionCoverage(s.repo.FindByMaxXP(math.MaxInt))
+}
+
 // AvailableTypes returns all distinct monster types.
 func (s *Service) AvailableTypes() []string {
 	return s.repo.AvailableTypes()
@@ -38,3 +62,39 @@ func (s *Service) AvailableSizes() []string {
 func (s *Service) AvailableCRs() []string {
 	return s.repo.AvailableCRs()
 }
+
+// CalculateChallenge rates a homebrew monster from its defensive and offensive statistics.
+func (s *Service) CalculateChallenge(stats monster.HomebrewStats) (monster.Challenge, error) {
+	return monster.CalculateChallenge(stats)
+}
+
+// ScaledMonster is a monster rescaled to another CR together with its original statblock.
+type ScaledMonster struct {
+	Original monster.Monster
+	Scaled   monster.Monster
+	Changes  []monster.FieldChange
+}
+
+// ScaleMonster rescales the monster with the given ID to targetCR.
+func (s *Service) ScaleMonster(id, targetCR string) (ScaledMonster, error) {
+	original, ok := s.repo.FindByID(id)
+	if !ok {
+		return ScaledMonster{}, fmt.Errorf("%w: %s", monster.ErrNotFound, id)
+	}
+
+	scaled, err := monster.Scale(original, targetCR)
+	if err != nil {
+		return ScaledMonster{}, err
+	}
+
+	return ScaledMonster{
+		Original: original,
+		Scaled:   scaled,
+		Changes:  monster.Diff(original, scaled),
+	}, nil
+}
+
+// ChallengeRatings returns every challenge rating a monster can be scaled to.
+func (s *Service) ChallengeRatings() []string {
+	return monster.ChallengeRatings()
+}
@@ -1,6 +1,7 @@
 package monster
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -11,6 +12,15 @@ type mockRepo struct {
 	monsters []domain.Monster
 }
 
+func (r *mockRepo) FindByID(id string) (domain.Monster, bool) {
+	for _, m := range r.monsters {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return domain.Monster{}, false
+}
+
 func (r *mockRepo) FindByMaxXP(maxXP int) []domain.Monster {
 	var result []domain.Monster
 	for _, m := range r.monsters {
@@ -51,6 +61,24 @@ func (r *mockRepo) SearchWithFilters(filters domain.SearchFilters) []domain.Mons
 	return result
 }
 
+func (r *mockRepo) SearchRanked(filters domain.SearchFilters) []domain.SearchResult {
+	var result []domain.SearchResult
+	for _, m := range r.SearchWithFilters(filters) {
+		result = append(result, domain.SearchResult{Monster: m})
+	}
+	return result
+}
+
+func (r *mockRepo) Suggest(query string, limit int) []domain.Suggestion {
+	var result []domain.Suggestion
+	for _, m := range r.monsters {
+		if len(result) < limit && strings.HasPrefix(strings.ToLower(m.Name), strings.ToLower(query)) {
+			result = append(result, domain.Suggestion{ID: m.ID, Name: m.Name})
+		}
+	}
+	return result
+}
+
 func (r *mockRepo) AvailableTypes() []string {
 	return []string{"Bestia", "Drago", "Umanoide"}
 }
@@ -125,3 +153,41 @@ func TestAvailableCRs(t *testing.T) {
 		t.Errorf("expected 5 CRs, got %d", len(crs))
 	}
 }
+
+func TestCalculateChallenge(t *testing.T) {
+	svc := newTestService()
+	attack := 6
+
+	challenge, err := svc.CalculateChallenge(domain.HomebrewStats{HP: 135, AC: 15, AttackBonus: &attack, DamagePerRound: 35})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if challenge.CR != "5" || challenge.XP != 1800 {
+		t.Errorf("expected CR 5 with 1800 XP, got CR %s with %d XP", challenge.CR, challenge.XP)
+	}
+}
+
+func TestScaleMonster(t *testing.T) {
+	svc := newTestService()
+
+	result, err := svc.ScaleMonster("goblin", "2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Original.ID != "goblin" || result.Scaled.ID != "goblin-gs-2" {
+		t.Errorf("expected goblin scaled to goblin-gs-2, got %s and %s", result.Original.ID, result.Scaled.ID)
+	}
+	if result.Scaled.CR != "2" || result.Scaled.XP != 450 {
+		t.Errorf("expected CR 2 with 450 XP, got CR %s with %d XP", result.Scaled.CR, result.Scaled.XP)
+	}
+	if len(result.Changes) == 0 {
+		t.Error("expected the changed fields to be listed")
+	}
+
+	if _, err := svc.ScaleMonster("missing", "2"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.ScaleMonster("goblin", "99"); !errors.Is(err, domain.ErrInvalidCR) {
+		t.Errorf("expected ErrInvalidCR, got %v", err)
+	}
+}
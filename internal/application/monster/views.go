@@ -0,0 +1,257 @@
+package monster
+
+import (
+	"math"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// MonsterSummary is the JSON summary of a monster returned by searches.
+type MonsterSummary struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Size string `json:"size"`
+	CR   string `json:"cr"`
+	XP   int    `json:"xp"`
+	AC   string `json:"ac"`
+	HP   string `json:"hp"`
+	// DamagePerRound is estimated against monster.DefaultTarget, or the target of the search.
+	DamagePerRound float64 `json:"damage_per_round"`
+}
+
+// Abilities is the JSON form of ability scores or modifiers.
+type Abilities struct {
+	Strength     int `json:"strength"`
+	Dexterity    int `json:"dexterity"`
+	Constitution int `json:"constitution"`
+	Intelligence int `json:"intelligence"`
+	Wisdom       int `json:"wisdom"`
+	Charisma     int `json:"charisma"`
+}
+
+// SavingThrows is the JSON form of saving throw modifiers.
+type SavingThrows struct {
+	Strength     string `json:"strength"`
+	Dexterity    string `json:"dexterity"`
+	Constitution string `json:"constitution"`
+	Intelligence string `json:"intelligence"`
+	Wisdom       string `json:"wisdom"`
+	Charisma     string `json:"charisma"`
+}
+
+// Entry is the JSON form of a trait, action, reaction or legendary action.
+// Actions also carry what was parsed from their text.
+type Entry struct {
+	Name              string      `json:"name"`
+	Description       string      `json:"description"`
+	Note              string      `json:"note,omitempty"`
+	Attack            *AttackView `json:"attack,omitempty"`
+	Save              *SaveView   `json:"save,omitempty"`
+	Recharge          int         `json:"recharge,omitempty"`
+	UsesPerDay        int         `json:"uses_per_day,omitempty"`
+	RechargeAfterRest bool        `json:"recharge_after_rest,omitempty"`
+}
+
+// DamageView is the JSON form of a damage roll. Dice is empty for fixed damage.
+type DamageView struct {
+	Average int    `json:"average"`
+	Dice    string `json:"dice,omitempty"`
+	Type    string `json:"type"`
+}
+
+// AttackView is the JSON form of the attack roll of an action. Distances are in metres.
+type AttackView struct {
+	Kind      string       `json:"kind"`
+	ToHit     int          `json:"to_hit"`
+	Reach     float64      `json:"reach,omitempty"`
+	Range     float64      `json:"range,omitempty"`
+	LongRange float64      `json:"long_range,omitempty"`
+	Damage    []DamageView `json:"damage"`
+}
+
+// SaveView is the JSON form of the saving throw of an action.
+type SaveView struct {
+	Ability       string       `json:"ability"`
+	DC            int          `json:"dc"`
+	Damage        []DamageView `json:"damage"`
+	HalfOnSuccess bool         `json:"half_on_success"`
+	Failure       string       `json:"failure,omitempty"`
+	Success       string       `json:"success,omitempty"`
+	Either        string       `json:"either,omitempty"`
+}
+
+// OffenseView is the JSON form of the damage per round estimated for a monster.
+type OffenseView struct {
+	TargetAC        int           `json:"target_ac"`
+	TargetSaveBonus int           `json:"target_save_bonus"`
+	DamagePerRound  float64       `json:"damage_per_round"`
+	Routine         []RoutineView `json:"routine"`
+	LimitedAction   string        `json:"limited_action,omitempty"`
+	BonusAction     string        `json:"bonus_action,omitempty"`
+	AttackBonus     int           `json:"attack_bonus"`
+	SaveDC          int           `json:"save_dc"`
+}
+
+// RoutineView is an action of the routine of an OffenseView.
+type RoutineView struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// MonsterDetail is the JSON form of a full statblock.
+type MonsterDetail struct {
+	MonsterSummary
+	Group               string       `json:"group"`
+	Subtype             string       `json:"subtype"`
+	Alignment           string       `json:"alignment"`
+	Initiative          string       `json:"initiative"`
+	Speed               string       `json:"speed"`
+	AbilityScores       Abilities    `json:"ability_scores"`
+	AbilityMods         Abilities    `json:"ability_mods"`
+	SavingThrows        SavingThrows `json:"saving_throws"`
+	Skills              string       `json:"skills"`
+	Senses              string       `json:"senses"`
+	Languages           string       `json:"languages"`
+	Resistances         string       `json:"resistances"`
+	DamageImmunities    string       `json:"damage_immunities"`
+	ConditionImmunities string       `json:"condition_immunities"`
+	Equipment           string       `json:"equipment"`
+	CRDetail            string       `json:"cr_detail"`
+	LairXP              int          `json:"lair_xp,omitempty"`
+	ProficiencyBonus    int          `json:"proficiency_bonus"`
+	Traits              []Entry      `json:"traits"`
+	Actions             []Entry      `json:"actions"`
+	BonusActions        []Entry      `json:"bonus_actions"`
+	Reactions           []Entry      `json:"reactions"`
+	LegendaryActions    []Entry      `json:"legendary_actions"`
+	Offense             OffenseView  `json:"offense"`
+	Source              string       `json:"source,omitempty"`
+}
+
+// NewMonsterSummary returns the JSON summary of a monster.
+func NewMonsterSummary(m monster.Monster) MonsterSummary {
+	return newMonsterSummary(m, m.Offense(monster.DefaultTarget))
+}
+
+// NewSearchSummary returns the JSON summary of a search result, with the damage per round
+// estimated against the target of the search.
+func NewSearchSummary(found monster.SearchResult) MonsterSummary {
+	return newMonsterSummary(found.Monster, found.Offense)
+}
+
+func newMonsterSummary(m monster.Monster, offense monster.OffensiveProfile) MonsterSummary {
+	return MonsterSummary{
+		ID: m.ID, Name: m.Name, Type: m.Type, Size: m.Size, CR: m.CR, XP: m.XP, AC: m.AC, HP: m.HP,
+		DamagePerRound: RoundDamage(offense.DamagePerRound),
+	}
+}
+
+// NewOffenseView returns the JSON form of an offensive profile.
+func NewOffenseView(p monster.OffensiveProfile) OffenseView {
+	v := OffenseView{
+		TargetAC:        p.Target.AC,
+		TargetSaveBonus: p.Target.SaveBonus,
+		DamagePerRound:  RoundDamage(p.DamagePerRound),
+		Routine:         make([]RoutineView, len(p.Routine)),
+		LimitedAction:   p.Limited,
+		BonusAction:     p.BonusAction,
+		AttackBonus:     p.AttackBonus,
+		SaveDC:          p.SaveDC,
+	}
+	for i, a := range p.Routine {
+		v.Routine[i] = RoutineView(a)
+	}
+	return v
+}
+
+// roundDamage rounds an estimated damage to one decimal.
+func RoundDamage(damage float64) float64 {
+	return math.Round(damage*10) / 10
+}
+
+// NewMonsterDetail returns the JSON form of the full statblock of a monster.
+func NewMonsterDetail(m monster.Monster) MonsterDetail {
+	offense := m.Offense(monster.DefaultTarget)
+	return MonsterDetail{
+		MonsterSummary:      newMonsterSummary(m, offense),
+		Group:               m.Group,
+		Subtype:             m.Subtype,
+		Alignment:           m.Alignment,
+		Initiative:          m.Initiative,
+		Speed:               m.Speed,
+		AbilityScores:       Abilities(m.AbilityScores),
+		AbilityMods:         Abilities(m.AbilityMods),
+		SavingThrows:        SavingThrows(m.SavingThrows),
+		Skills:              m.Skills,
+		Senses:              m.Senses,
+		Languages:           m.Languages,
+		Resistances:         m.Resistances,
+		DamageImmunities:    m.DamageImmunities,
+		ConditionImmunities: m.ConditionImmunities,
+		Equipment:           m.Equipment,
+		CRDetail:            m.CRDetail,
+		LairXP:              m.LairXP,
+		ProficiencyBonus:    m.ProficiencyBonus,
+		Traits:              newEntries(m.Traits),
+		Actions:             newActionEntries(m.Actions, m.ParsedActions.Actions),
+		BonusActions:        newActionEntries(m.BonusActions, m.ParsedActions.BonusActions),
+		Reactions:           newActionEntries(m.Reactions, m.ParsedActions.Reactions),
+		LegendaryActions:    newActionEntries(m.LegendaryActions, m.ParsedActions.LegendaryActions),
+		Offense:             NewOffenseView(offense),
+		Source:              m.Source,
+	}
+}
+
+func newEntries(entries []monster.NamedDescription) []Entry {
+	result := make([]Entry, len(entries))
+	for i, e := range entries {
+		result[i] = Entry{Name: e.Name, Description: e.Description}
+	}
+	return result
+}
+
+// newActionEntries pairs the entries of an action list with their parsed form, which is
+// missing when the statblock was not parsed.
+func newActionEntries(entries []monster.NamedDescription, parsed []monster.Action) []Entry {
+	result := newEntries(entries)
+	if len(parsed) != len(entries) {
+		return result
+	}
+	for i, a := range parsed {
+		result[i].Note = a.Note
+		result[i].Recharge = a.Usage.Recharge
+		result[i].UsesPerDay = a.Usage.PerDay
+		result[i].RechargeAfterRest = a.Usage.AfterRest
+		if a.Attack != nil {
+			result[i].Attack = &AttackView{
+				Kind:      string(a.Attack.Kind),
+				ToHit:     a.Attack.ToHit,
+				Reach:     a.Attack.Reach,
+				Range:     a.Attack.Range,
+				LongRange: a.Attack.LongRange,
+				Damage:    newDamageViews(a.Attack.Damage),
+			}
+		}
+		if a.Save != nil {
+			result[i].Save = &SaveView{
+				Ability:       string(a.Save.Ability),
+				DC:            a.Save.DC,
+				Damage:        newDamageViews(a.Save.Damage),
+				HalfOnSuccess: a.Save.HalfOnSuccess,
+				Failure:       a.Save.Failure,
+				Success:       a.Save.Success,
+				Either:        a.Save.Either,
+			}
+		}
+	}
+	return result
+}
+
+func newDamageViews(damage []monster.Damage) []DamageView {
+	result := make([]DamageView, len(damage))
+	for i, d := range damage {
+		result[i] = DamageView{Average: d.Average, Dice: d.Dice.String(), Type: string(d.Type)}
+	}
+	return result
+}
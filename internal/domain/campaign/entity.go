@@ -0,0 +1,177 @@
+package campaign
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+)
+
+var (
+	// ErrPartyNotFound is returned when a saved party does not exist
+	ErrPartyNotFound = errors.New("party not found")
+
+	// ErrEncounterNotFound is returned when a saved encounter does not exist
+	ErrEncounterNotFound = errors.New("saved encounter not found")
+
+	// ErrCampaignNotFound is returned when a campaign does not exist
+	ErrCampaignNotFound = errors.New("campaign not found")
+
+	// ErrInvalidName is returned when a party, encounter or campaign has no name
+	ErrInvalidName = errors.New("name cannot be empty")
+)
+
+// Member is a named character of a saved party
+type Member struct {
+	Name  string
+	Level int
+}
+
+// Party is a group of characters saved by the DM
+type Party struct {
+	ID      string
+	Name    string
+	Members []Member
+}
+
+// NewParty creates a saved party, validating its name and character levels
+func NewParty(id, name string, members []Member) (*Party, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidName
+	}
+
+	party := &Party{ID: id, Name: name, Members: members}
+	if _, err := encounter.NewParty(party.Levels()); err != nil {
+		return nil, err
+	}
+	return party, nil
+}
+
+// Levels returns the level of every member in order
+func (p Party) Levels() []int {
+	levels := make([]int, len(p.Members))
+	for i, m := range p.Members {
+		levels[i] = m.Level
+	}
+	return levels
+}
+
+// EncounterMonster is a monster, how many of it a saved encounter contains and whether it is in its lair
+type EncounterMonster struct {
+	MonsterID string
+	Quantity  int
+	InLair    bool
+}
+
+// SavedEncounter is an encounter composition saved by the DM.
+// Levels is the party the encounter was built for, empty for encounters saved before it was kept.
+type SavedEncounter struct {
+	ID         string
+	Name       string
+	Ruleset    encounter.Ruleset
+	Difficulty encounter.Difficulty
+	Levels     []int
+	Monsters   []EncounterMonster
+
+	// CountWeakMonsters keeps the 2014 weak monster setting of the composition
+	CountWeakMonsters bool
+}
+
+// NewSavedEncounter creates a saved encounter, validating the ruleset, difficulty, party and quantities
+func NewSavedEncounter(id, name string, ruleset encounter.Ruleset, difficulty encounter.Difficulty, levels []int, monsters []EncounterMonster) (*SavedEncounter, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidName
+	}
+	if !ruleset.IsValid() {
+		return nil, fmt.Errorf("invalid ruleset: %s", ruleset)
+	}
+	if _, err := encounter.NewDifficulty(difficulty.String(), ruleset); err != nil {
+		return nil, err
+	}
+	if _, err := encounter.NewParty(levels); err != nil {
+		return nil, err
+	}
+	for _, m := range monsters {
+		if m.Quantity < 1 || m.Quantity > encounter.MaxGroupQuantity {
+			return nil, fmt.Errorf("quantity of %s must be between 1 and %d, got %d", m.MonsterID, encounter.MaxGroupQuantity, m.Quantity)
+		}
+	}
+
+	return &SavedEncounter{
+		ID:         id,
+		Name:       name,
+		Ruleset:    ruleset,
+		Difficulty: difficulty,
+		Levels:     levels,
+		Monsters:   monsters,
+	}, nil
+}
+
+// SaveComposition snapshots the party, monsters, ruleset, difficulty and weak monster setting of a composition
+func SaveComposition(id, name string, c *encounter.Composition) (*SavedEncounter, error) {
+	monsters := make([]EncounterMonster, len(c.Groups))
+	for i, g := range c.Groups {
+		monsters[i] = EncounterMonster{MonsterID: g.Monster.ID, Quantity: g.Quantity, InLair: g.InLair}
+	}
+	saved, err := NewSavedEncounter(id, name, c.Ruleset, c.Difficulty, c.Party.Levels(), monsters)
+	if err != nil {
+		return nil, err
+	}
+	saved.CountWeakMonsters = c.CountWeakMonsters
+	return saved, nil
+}
+
+// Campaign groups saved parties and encounters
+type Campaign struct {
+	ID           string
+	Name         string
+	PartyIDs     []string
+	EncounterIDs []string
+}
+
+// NewCampaign creates an empty campaign
+func NewCampaign(id, name string) (*Campaign, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidName
+	}
+	return &Campaign{ID: id, Name: name}, nil
+}
+
+// AddParty adds a party to the campaign; adding it twice has no effect
+func (c *Campaign) AddParty(partyID string) {
+	if !slices.Contains(c.PartyIDs, partyID) {
+		c.PartyIDs = append(c.PartyIDs, partyID)
+	}
+}
+
+// AddEncounter adds a saved encounter to the campaign; adding it twice has no effect
+func (c *Campaign) AddEncounter(encounterID string) {
+	if !slices.Contains(c.EncounterIDs, encounterID) {
+		c.EncounterIDs = append(c.EncounterIDs, encounterID)
+	}
+}
+
+// RemoveParty removes a party from the campaign and reports whether it was there
+func (c *Campaign) RemoveParty(partyID string) bool {
+	i := slices.Index(c.PartyIDs, partyID)
+	if i < 0 {
+		return false
+	}
+	c.PartyIDs = slices.Delete(c.PartyIDs, i, i+1)
+	return true
+}
+
+// RemoveEncounter removes a saved encounter from the campaign and reports whether it was there
+func (c *Campaign) RemoveEncounter(encounterID string) bool {
+	i := slices.Index(c.EncounterIDs, encounterID)
+	if i < 0 {
+		return false
+	}
+	c.EncounterIDs = slices.Delete(c.EncounterIDs, i, i+1)
+	return true
+}
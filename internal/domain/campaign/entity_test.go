@@ -0,0 +1,133 @@
+package campaign
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+func TestNewParty(t *testing.T) {
+	tests := []struct {
+		name        string
+		partyName   string
+		members     []Member
+		expectError bool
+	}{
+		{name: "valid party", partyName: "La Compagnia", members: []Member{{"Aria", 5}, {"Borin", 6}}},
+		{name: "blank name", partyName: "  ", members: []Member{{"Aria", 5}}, expectError: true},
+		{name: "no members", partyName: "Vuoto", expectError: true},
+		{name: "level out of range", partyName: "Troppo forti", members: []Member{{"Aria", 21}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			party, err := NewParty("p1", tt.partyName, tt.members)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(party.Levels(), []int{5, 6}) {
+				t.Errorf("expected levels [5 6], got %v", party.Levels())
+			}
+		})
+	}
+}
+
+func TestNewSavedEncounter(t *testing.T) {
+	tests := []struct {
+		name        string
+		ruleset     encounter.Ruleset
+		difficulty  encounter.Difficulty
+		levels      []int
+		monsters    []EncounterMonster
+		expectError bool
+	}{
+		{name: "valid 2024 encounter", ruleset: encounter.Ruleset2024, difficulty: encounter.DifficultyHigh, levels: []int{5, 5}, monsters: []EncounterMonster{{MonsterID: "ogre", Quantity: 2}}},
+		{name: "empty encounter", ruleset: encounter.Ruleset2014, difficulty: encounter.DifficultyMedium, levels: []int{1}},
+		{name: "difficulty from the other ruleset", ruleset: encounter.Ruleset2014, difficulty: encounter.DifficultyHigh, levels: []int{1}, expectError: true},
+		{name: "invalid ruleset", ruleset: "3000", difficulty: encounter.DifficultyHigh, levels: []int{1}, expectError: true},
+		{name: "no party", ruleset: encounter.Ruleset2024, difficulty: encounter.DifficultyLow, expectError: true},
+		{name: "invalid level", ruleset: encounter.Ruleset2024, difficulty: encounter.DifficultyLow, levels: []int{21}, expectError: true},
+		{name: "zero quantity", ruleset: encounter.Ruleset2024, difficulty: encounter.DifficultyLow, levels: []int{1}, monsters: []EncounterMonster{{MonsterID: "ogre", Quantity: 0}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSavedEncounter("e1", "Imboscata", tt.ruleset, tt.difficulty, tt.levels, tt.monsters)
+			if tt.expectError != (err != nil) {
+				t.Errorf("expected error %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestSaveComposition(t *testing.T) {
+	party, _ := encounter.NewParty([]int{5, 5})
+	composition := encounter.NewComposition("c1", party, encounter.Ruleset2024, encounter.DifficultyModerate, 1500)
+	composition.CountWeakMonsters = true
+	if err := composition.AddMonster(monster.Monster{ID: "ogre", XP: 450}, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := composition.AddMonster(monster.Monster{ID: "aboleth", XP: 5900, LairXP: 7200}, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := composition.SetInLair("aboleth", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	saved, err := SaveComposition("e1", "Ogre al ponte", composition)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &SavedEncounter{
+		ID:         "e1",
+		Name:       "Ogre al ponte",
+		Ruleset:    encounter.Ruleset2024,
+		Difficulty: encounter.DifficultyModerate,
+		Levels:     []int{5, 5},
+		Monsters:   []EncounterMonster{{MonsterID: "ogre", Quantity: 2}, {MonsterID: "aboleth", Quantity: 1, InLair: true}},
+
+		CountWeakMonsters: true,
+	}
+	if !reflect.DeepEqual(saved, expected) {
+		t.Errorf("expected %+v, got %+v", expected, saved)
+	}
+}
+
+func TestCampaignMembership(t *testing.T) {
+	campaign, err := NewCampaign("c1", "La Maledizione")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	campaign.AddParty("p1")
+	campaign.AddParty("p1")
+	campaign.AddEncounter("e1")
+	campaign.AddEncounter("e2")
+
+	if !reflect.DeepEqual(campaign.PartyIDs, []string{"p1"}) {
+		t.Errorf("expected party added once, got %v", campaign.PartyIDs)
+	}
+	if !campaign.RemoveEncounter("e1") || campaign.RemoveEncounter("e1") {
+		t.Error("expected e1 to be removed exactly once")
+	}
+	if !reflect.DeepEqual(campaign.EncounterIDs, []string{"e2"}) {
+		t.Errorf("expected [e2], got %v", campaign.EncounterIDs)
+	}
+	if !campaign.RemoveParty("p1") || len(campaign.PartyIDs) != 0 {
+		t.Errorf("expected p1 to be removed, got %v", campaign.PartyIDs)
+	}
+
+	if _, err := NewCampaign("c2", ""); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("expected ErrInvalidName, got %v", err)
+	}
+}
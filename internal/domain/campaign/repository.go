@@ -0,0 +1,46 @@
+package campaign
+
+// PartyRepository defines the interface for storing saved parties
+type PartyRepository interface {
+	// Save stores the party, replacing any previous version with the same ID
+	Save(party *Party) error
+
+	// FindByID returns the party with the given ID or ErrPartyNotFound
+	FindByID(id string) (*Party, error)
+
+	// FindAll returns every saved party ordered by name
+	FindAll() ([]Party, error)
+
+	// Delete removes the party with the given ID or returns ErrPartyNotFound
+	Delete(id string) error
+}
+
+// EncounterRepository defines the interface for storing saved encounters
+type EncounterRepository interface {
+	// Save stores the encounter, replacing any previous version with the same ID
+	Save(encounter *SavedEncounter) error
+
+	// FindByID returns the encounter with the given ID or ErrEncounterNotFound
+	FindByID(id string) (*SavedEncounter, error)
+
+	// FindAll returns every saved encounter ordered by name
+	FindAll() ([]SavedEncounter, error)
+
+	// Delete removes the encounter with the given ID or returns ErrEncounterNotFound
+	Delete(id string) error
+}
+
+// CampaignRepository defines the interface for storing campaigns
+type CampaignRepository interface {
+	// Save stores the campaign, replacing any previous version with the same ID
+	Save(campaign *Campaign) error
+
+	// FindByID returns the campaign with the given ID or ErrCampaignNotFound
+	FindByID(id string) (*Campaign, error)
+
+	// FindAll returns every campaign ordered by name
+	FindAll() ([]Campaign, error)
+
+	// Delete removes the campaign with the given ID or returns ErrCampaignNotFound
+	Delete(id string) error
+}
@@ -0,0 +1,283 @@
+package combat
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+var (
+	// ErrCombatNotFound is returned when a combat does not exist
+	ErrCombatNotFound = errors.New("combat not found")
+
+	// ErrCombatantNotFound is returned when a combatant is not part of a combat
+	ErrCombatantNotFound = errors.New("combatant not found")
+
+	// ErrNoCombatants is returned when a combat would start without anyone to fight
+	ErrNoCombatants = errors.New("combat needs at least one combatant")
+)
+
+// Kind tells party characters apart from monsters
+type Kind string
+
+const (
+	KindCharacter Kind = "character"
+	KindMonster   Kind = "monster"
+)
+
+// Combatant is a party character or a single monster taking part in a combat.
+// Characters start without hit points and initiative, which the DM enters as the players roll.
+type Combatant struct {
+	ID        string
+	Name      string
+	Kind      Kind
+	Level     int    // characters only
+	MonsterID string // monsters only
+
+	// StatblockHP holds the hit dice of a monster, rolled by RollHitPoints
+	StatblockHP monster.HitPoints
+
+	Initiative         int
+	InitiativeModifier int
+	HasInitiative      bool
+
+	HP     int
+	MaxHP  int
+	TempHP int
+}
+
+// Defeated reports whether the combatant is a monster brought to 0 hit points.
+// Characters at 0 hit points keep their turn to make death saving throws.
+func (c Combatant) Defeated() bool {
+	return c.Kind == KindMonster && c.MaxHP > 0 && c.HP == 0
+}
+
+// Combat is a fight in progress: the combatants in initiative order, the round and whose turn it is
+type Combat struct {
+	ID            string
+	CompositionID string
+	Combatants    []Combatant
+	Round         int
+	Turn          int
+}
+
+// NewCombat starts a combat between the party and one combatant per monster of the groups.
+// Monsters use the initiative score of their statblock and their average hit points.
+func NewCombat(id, compositionID string, party encounter.Party, groups []encounter.MonsterGroup) (*Combat, error) {
+	var combatants []Combatant
+	for i, char := range party.Characters {
+		n := strconv.Itoa(i + 1)
+		combatants = append(combatants, Combatant{
+			ID:    "pg-" + n,
+			Name:  "Personaggio " + n,
+			Kind:  KindCharacter,
+			Level: char.Level,
+		})
+	}
+	for _, g := range groups {
+		for i := 1; i <= g.Quantity; i++ {
+			name := g.Monster.Name
+			if g.Quantity > 1 {
+				name += " " + strconv.Itoa(i)
+			}
+			combatants = append(combatants, Combatant{
+				ID:                 g.Monster.ID + "-" + strconv.Itoa(i),
+				Name:               name,
+				Kind:               KindMonster,
+				MonsterID:          g.Monster.ID,
+				Initiative:         g.Monster.InitiativeBonus.Score,
+				InitiativeModifier: g.Monster.InitiativeBonus.Modifier,
+				HasInitiative:      true,
+				HP:                 g.Monster.HitPoints.Average,
+				MaxHP:              g.Monster.HitPoints.Average,
+				StatblockHP:        g.Monster.HitPoints,
+			})
+		}
+	}
+	if len(combatants) == 0 {
+		return nil, ErrNoCombatants
+	}
+
+	c := &Combat{ID: id, CompositionID: compositionID, Combatants: combatants, Round: 1}
+	c.sortByInitiative()
+	return c, nil
+}
+
+// Current returns the combatant whose turn it is
+func (c *Combat) Current() Combatant {
+	return c.Combatants[c.Turn]
+}
+
+// Next passes the turn to the following combatant, skipping defeated monsters,
+// and starts a new round after the last one
+func (c *Combat) Next() {
+	for range c.Combatants {
+		c.Turn++
+		if c.Turn == len(c.Combatants) {
+			c.Turn = 0
+			c.Round++
+		}
+		if !c.Combatants[c.Turn].Defeated() {
+			return
+		}
+	}
+}
+
+// Previous gives the turn back to the preceding combatant, skipping defeated monsters.
+// It stops at the first turn of the first round, and stays on the current turn when no
+// living combatant acted before it.
+func (c *Combat) Previous() {
+	round, turn := c.Round, c.Turn
+	for range c.Combatants {
+		if c.Round == 1 && c.Turn == 0 {
+			break
+		}
+		c.Turn--
+		if c.Turn < 0 {
+			c.Turn = len(c.Combatants) - 1
+			c.Round--
+		}
+		if !c.Combatants[c.Turn].Defeated() {
+			return
+		}
+	}
+	c.Round, c.Turn = round, turn
+}
+
+// SetInitiative enters the initiative rolled for a combatant and moves it to its place in the order
+func (c *Combat) SetInitiative(combatantID string, initiative int) error {
+	i, err := c.find(combatantID)
+	if err != nil {
+		return err
+	}
+	c.Combatants[i].Initiative = initiative
+	c.Combatants[i].HasInitiative = true
+	c.sortByInitiative()
+	return nil
+}
+
+// RollInitiative rolls d20 plus the initiative modifier for every monster, instead of
+// the fixed score of the statblock. Characters keep the initiative entered for them.
+func (c *Combat) RollInitiative(rng *rand.Rand) {
+	for i := range c.Combatants {
+		if c.Combatants[i].Kind == KindMonster {
+			c.Combatants[i].Initiative = rng.IntN(20) + 1 + c.Combatants[i].InitiativeModifier
+		}
+	}
+	c.sortByInitiative()
+}
+
+// RollHitPoints rolls the hit dice of every monster instead of using the average, so that
+// monsters of the same kind differ from one another. Damage already taken is kept.
+func (c *Combat) RollHitPoints(rng *rand.Rand) {
+	for i := range c.Combatants {
+		cb := &c.Combatants[i]
+		if cb.Kind != KindMonster {
+			continue
+		}
+		damage := cb.MaxHP - cb.HP
+		cb.MaxHP = cb.StatblockHP.Roll(rng)
+		cb.HP = max(cb.MaxHP-damage, 0)
+	}
+}
+
+// Damage takes amount hit points from a combatant, temporary hit points first.
+// Hit points do not drop below 0.
+func (c *Combat) Damage(combatantID string, amount int) error {
+	if amount < 0 {
+		return errors.New("damage cannot be negative")
+	}
+	i, err := c.find(combatantID)
+	if err != nil {
+		return err
+	}
+
+	cb := &c.Combatants[i]
+	absorbed := min(cb.TempHP, amount)
+	cb.TempHP -= absorbed
+	cb.HP = max(cb.HP-(amount-absorbed), 0)
+	return nil
+}
+
+// Heal restores amount hit points to a combatant, up to its maximum
+func (c *Combat) Heal(combatantID string, amount int) error {
+	if amount < 0 {
+		return errors.New("healing cannot be negative")
+	}
+	i, err := c.find(combatantID)
+	if err != nil {
+		return err
+	}
+
+	cb := &c.Combatants[i]
+	cb.HP = min(cb.HP+amount, cb.MaxHP)
+	return nil
+}
+
+// SetTempHP gives a combatant temporary hit points. They do not stack: the new amount replaces the old one.
+func (c *Combat) SetTempHP(combatantID string, amount int) error {
+	if amount < 0 {
+		return errors.New("temporary hit points cannot be negative")
+	}
+	i, err := c.find(combatantID)
+	if err != nil {
+		return err
+	}
+	c.Combatants[i].TempHP = amount
+	return nil
+}
+
+// SetHitPoints enters the current and maximum hit points of a combatant
+func (c *Combat) SetHitPoints(combatantID string, hp, maxHP int) error {
+	if maxHP < 1 {
+		return errors.New("maximum hit points must be at least 1")
+	}
+	if hp < 0 || hp > maxHP {
+		return fmt.Errorf("hit points must be between 0 and %d", maxHP)
+	}
+	i, err := c.find(combatantID)
+	if err != nil {
+		return err
+	}
+	c.Combatants[i].HP = hp
+	c.Combatants[i].MaxHP = maxHP
+	return nil
+}
+
+func (c *Combat) find(combatantID string) (int, error) {
+	for i := range c.Combatants {
+		if c.Combatants[i].ID == combatantID {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrCombatantNotFound, combatantID)
+}
+
+// sortByInitiative orders the combatants from the highest initiative, breaking ties by the
+// higher modifier; combatants without initiative come last. Once the first turn has passed,
+// the turn stays with the same combatant; before that, it goes to whoever is now first.
+func (c *Combat) sortByInitiative() {
+	started := c.Round > 1 || c.Turn > 0
+	current := c.Combatants[c.Turn].ID
+	slices.SortStableFunc(c.Combatants, func(a, b Combatant) int {
+		if a.HasInitiative != b.HasInitiative {
+			if a.HasInitiative {
+				return -1
+			}
+			return 1
+		}
+		if a.Initiative != b.Initiative {
+			return b.Initiative - a.Initiative
+		}
+		return b.InitiativeModifier - a.InitiativeModifier
+	})
+	c.Turn = 0
+	if started {
+		c.Turn, _ = c.find(current)
+	}
+}
@@ -0,0 +1,256 @@
+package combat
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+var (
+	goblin = monster.Monster{
+		ID: "goblin", Name: "Goblin",
+		InitiativeBonus: monster.Initiative{Modifier: 2, Score: 12},
+		HitPoints:       monster.HitPoints{Average: 7},
+	}
+	ogre = monster.Monster{
+		ID: "ogre", Name: "Ogre",
+		InitiativeBonus: monster.Initiative{Modifier: -1, Score: 9},
+		HitPoints:       monster.HitPoints{Average: 68, Dice: monster.Dice{Count: 8, Sides: 10, Bonus: 24}},
+	}
+)
+
+func newTestCombat(t *testing.T) *Combat {
+	t.Helper()
+	party, err := encounter.NewParty([]int{3, 4})
+	if err != nil {
+		t.Fatalf("unexpected error creating party: %v", err)
+	}
+	c, err := NewCombat("combat-id", "composition-id", party, []encounter.MonsterGroup{
+		{Monster: ogre, Quantity: 1},
+		{Monster: goblin, Quantity: 2},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return c
+}
+
+func order(c *Combat) []string {
+	ids := make([]string, len(c.Combatants))
+	for i, cb := range c.Combatants {
+		ids[i] = cb.ID
+	}
+	return ids
+}
+
+func TestNewCombat(t *testing.T) {
+	c := newTestCombat(t)
+
+	// Monsters use their initiative score, characters wait for theirs at the end
+	expected := []string{"goblin-1", "goblin-2", "ogre-1", "pg-1", "pg-2"}
+	if !slices.Equal(order(c), expected) {
+		t.Errorf("expected order %v, got %v", expected, order(c))
+	}
+	if c.Round != 1 || c.Current().ID != "goblin-1" {
+		t.Errorf("expected round 1 to start with goblin-1, got round %d and %s", c.Round, c.Current().ID)
+	}
+
+	g := c.Combatants[0]
+	if g.Name != "Goblin 1" || g.HP != 7 || g.MaxHP != 7 || g.MonsterID != "goblin" {
+		t.Errorf("unexpected goblin %+v", g)
+	}
+	if ogre := c.Combatants[2]; ogre.Name != "Ogre" {
+		t.Errorf("expected a single monster to keep its name, got %q", ogre.Name)
+	}
+	if pc := c.Combatants[3]; pc.Kind != KindCharacter || pc.Level != 3 || pc.HasInitiative {
+		t.Errorf("unexpected character %+v", pc)
+	}
+
+	if _, err := NewCombat("id", "", encounter.Party{}, nil); !errors.Is(err, ErrNoCombatants) {
+		t.Errorf("expected ErrNoCombatants, got %v", err)
+	}
+}
+
+func TestCombatSetInitiative(t *testing.T) {
+	c := newTestCombat(t)
+
+	if err := c.SetInitiative("pg-2", 15); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.SetInitiative("pg-1", 9); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Ties go to the higher modifier: the ogre has -1, the character 0
+	expected := []string{"pg-2", "goblin-1", "goblin-2", "pg-1", "ogre-1"}
+	if !slices.Equal(order(c), expected) {
+		t.Errorf("expected order %v, got %v", expected, order(c))
+	}
+	if c.Current().ID != "pg-2" {
+		t.Errorf("expected the first in order to act before the fight starts, got %s", c.Current().ID)
+	}
+
+	// Once the fight has started, the turn stays with whoever has it
+	c.Next()
+	if err := c.SetInitiative("pg-1", 20); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Current().ID != "goblin-1" {
+		t.Errorf("expected the turn to stay with goblin-1, got %s", c.Current().ID)
+	}
+
+	if err := c.SetInitiative("nobody", 10); !errors.Is(err, ErrCombatantNotFound) {
+		t.Errorf("expected ErrCombatantNotFound, got %v", err)
+	}
+}
+
+func TestCombatRollInitiative(t *testing.T) {
+	c := newTestCombat(t)
+	if err := c.SetInitiative("pg-1", 30); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.RollInitiative(rand.New(rand.NewPCG(1, 2)))
+	for _, cb := range c.Combatants {
+		switch cb.Kind {
+		case KindCharacter:
+			if cb.ID == "pg-1" && cb.Initiative != 30 {
+				t.Errorf("expected the entered initiative to be kept, got %d", cb.Initiative)
+			}
+		case KindMonster:
+			if roll := cb.Initiative - cb.InitiativeModifier; roll < 1 || roll > 20 {
+				t.Errorf("%s: expected d20 + modifier, got %d", cb.ID, cb.Initiative)
+			}
+		}
+	}
+	if c.Combatants[0].ID != "pg-1" {
+		t.Errorf("expected the order to be sorted again, got %v", order(c))
+	}
+}
+
+func TestCombatRollHitPoints(t *testing.T) {
+	c := newTestCombat(t)
+	if err := c.Damage("ogre-1", 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.RollHitPoints(rand.New(rand.NewPCG(3, 4)))
+	for _, cb := range c.Combatants {
+		switch cb.ID {
+		case "ogre-1":
+			if cb.MaxHP < 32 || cb.MaxHP > 104 || cb.HP != cb.MaxHP-10 {
+				t.Errorf("expected rolled 8d10 + 24 keeping 10 damage, got %d/%d", cb.HP, cb.MaxHP)
+			}
+		case "goblin-1", "goblin-2":
+			if cb.MaxHP != 7 || cb.HP != 7 {
+				t.Errorf("expected fixed hit points to be kept, got %d/%d", cb.HP, cb.MaxHP)
+			}
+		default:
+			if cb.MaxHP != 0 {
+				t.Errorf("expected characters to be left alone, got %+v", cb)
+			}
+		}
+	}
+}
+
+func TestCombatTurns(t *testing.T) {
+	c := newTestCombat(t)
+
+	c.Previous()
+	if c.Round != 1 || c.Turn != 0 {
+		t.Errorf("expected Previous to stop at the first turn, got round %d turn %d", c.Round, c.Turn)
+	}
+
+	// goblin-2 is defeated and loses its turns
+	if err := c.Damage("goblin-2", 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var turns []string
+	for range 5 {
+		c.Next()
+		turns = append(turns, c.Current().ID)
+	}
+	expected := []string{"ogre-1", "pg-1", "pg-2", "goblin-1", "ogre-1"}
+	if !slices.Equal(turns, expected) {
+		t.Errorf("expected turns %v, got %v", expected, turns)
+	}
+	if c.Round != 2 {
+		t.Errorf("expected round 2, got %d", c.Round)
+	}
+
+	c.Previous()
+	c.Previous()
+	if c.Round != 1 || c.Current().ID != "pg-2" {
+		t.Errorf("expected to go back to pg-2 in round 1, got %s in round %d", c.Current().ID, c.Round)
+	}
+}
+
+func TestCombatPrevious_DefeatedFirst(t *testing.T) {
+	c := newTestCombat(t)
+	if first := c.Current().ID; first != "goblin-1" {
+		t.Fatalf("expected goblin-1 to act first, got %s", first)
+	}
+	c.Next()
+
+	// The only earlier turn belongs to a defeated monster, which never acts again
+	if err := c.Damage("goblin-1", 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Previous()
+	if c.Round != 1 || c.Current().ID != "goblin-2" {
+		t.Errorf("expected to stay on goblin-2 in round 1, got %s in round %d", c.Current().ID, c.Round)
+	}
+}
+
+func TestCombatHitPoints(t *testing.T) {
+	c := newTestCombat(t)
+
+	if err := c.SetTempHP("ogre-1", 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Damage("ogre-1", 12); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ogre := c.Combatants[2]
+	if ogre.TempHP != 0 || ogre.HP != 61 {
+		t.Errorf("expected temporary hit points to absorb damage first, got %d HP and %d temp", ogre.HP, ogre.TempHP)
+	}
+
+	if err := c.Heal("ogre-1", 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Combatants[2].HP != 68 {
+		t.Errorf("expected healing to stop at the maximum, got %d", c.Combatants[2].HP)
+	}
+
+	if err := c.Damage("ogre-1", 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.Combatants[2].Defeated() || c.Combatants[2].HP != 0 {
+		t.Errorf("expected the ogre to be defeated at 0 HP, got %+v", c.Combatants[2])
+	}
+
+	if err := c.SetHitPoints("pg-1", 20, 24); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Damage("pg-1", 30); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pc := c.Combatants[3]; pc.HP != 0 || pc.MaxHP != 24 || pc.Defeated() {
+		t.Errorf("expected a character at 0 HP to keep fighting, got %+v", pc)
+	}
+
+	if err := c.SetHitPoints("pg-1", 30, 24); err == nil {
+		t.Error("expected error for hit points above the maximum")
+	}
+	if err := c.Damage("pg-1", -1); err == nil {
+		t.Error("expected error for negative damage")
+	}
+	if err := c.SetTempHP("pg-1", -1); err == nil {
+		t.Error("expected error for negative temporary hit points")
+	}
+}
@@ -0,0 +1,14 @@
+package combat
+
+// Repository defines the interface for storing combats in progress
+type Repository interface {
+	// Save stores the combat, replacing any previous version with the same ID
+	Save(combat *Combat) error
+
+	// FindByID returns the combat with the given ID or ErrCombatNotFound
+	FindByID(id string) (*Combat, error)
+
+	// Update applies change to the combat with the given ID and saves it unless change fails,
+	// serializing concurrent updates of the same combat; it returns the updated combat
+	Update(id string, change func(combat *Combat) error) (*Combat, error)
+}
@@ -0,0 +1,402 @@
+// Package dice parses and rolls dice expressions such as "20d10 + 40", "4d6kh3" or "d20adv + 5".
+package dice
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// Limits keeping an expression cheap to roll and its results readable.
+const (
+	MaxTerms = 20
+	MaxDice  = 100
+	MaxSides = 1000
+	MaxValue = 1_000_000
+)
+
+// ErrInvalidExpression is returned when a dice expression cannot be parsed.
+var ErrInvalidExpression = errors.New("invalid dice expression")
+
+// Term is a group of dice or a constant, added to or subtracted from the expression.
+// Sides is zero for constants. Keep is the number of dice counted, the highest ones unless
+// KeepLowest is set; it equals Count when no dice are dropped.
+type Term struct {
+	Negative   bool
+	Count      int
+	Sides      int
+	Keep       int
+	KeepLowest bool
+	Value      int
+}
+
+// Expression is a sum of dice groups and constants.
+type Expression struct {
+	Terms []Term
+}
+
+// New returns the expression of count dice with the given sides plus a bonus, as in the statblocks.
+func New(count, sides, bonus int) Expression {
+	var e Expression
+	if count > 0 {
+		e.Terms = append(e.Terms, Term{Count: count, Sides: sides, Keep: count})
+	}
+	if bonus != 0 {
+		e.Terms = append(e.Terms, Term{Negative: bonus < 0, Value: abs(bonus)})
+	}
+	return e
+}
+
+// Parse parses a dice expression. Dice groups are written NdS, with N defaulting to 1 and
+// "d%" standing for d100, and may end with khK or klK to keep the K highest or lowest dice.
+// "adv" and "dis" turn a d20 into 2d20kh1 and 2d20kl1. Terms are joined by + or a minus sign
+// (-, − or –); spaces and case are ignored.
+func Parse(s string) (Expression, error) {
+	p := parser{input: s, s: normalize(s)}
+	if p.s == "" {
+		return Expression{}, p.errorf("empty expression")
+	}
+
+	var e Expression
+	negative := p.sign(false)
+	for {
+		t, err := p.term()
+		if err != nil {
+			return Expression{}, err
+		}
+		t.Negative = negative
+		e.Terms = append(e.Terms, t)
+		if len(e.Terms) > MaxTerms {
+			return Expression{}, p.errorf("more than %d terms", MaxTerms)
+		}
+
+		if p.done() {
+			return e, nil
+		}
+		if p.s[0] != '+' && p.s[0] != '-' {
+			return Expression{}, p.errorf("unexpected %q", p.s[:1])
+		}
+		negative = p.sign(true)
+	}
+}
+
+// String formats the expression in canonical form, e.g. "2d20kh1 + 5" or "20d10 − 40".
+func (e Expression) String() string {
+	var b strings.Builder
+	for i, t := range e.Terms {
+		switch {
+		case i > 0 && t.Negative:
+			b.WriteString(" − ")
+		case i > 0:
+			b.WriteString(" + ")
+		case t.Negative:
+			b.WriteString("−")
+		}
+		b.WriteString(t.String())
+	}
+	return b.String()
+}
+
+// String formats the term without its sign, e.g. "4d6kh3" or "5".
+func (t Term) String() string {
+	if t.Sides == 0 {
+		return strconv.Itoa(t.Value)
+	}
+	s := fmt.Sprintf("%dd%d", t.Count, t.Sides)
+	switch {
+	case t.Keep == t.Count:
+		return s
+	case t.KeepLowest:
+		return s + "kl" + strconv.Itoa(t.Keep)
+	default:
+		return s + "kh" + strconv.Itoa(t.Keep)
+	}
+}
+
+// Min returns the lowest total the expression can roll.
+func (e Expression) Min() int {
+	total := 0
+	for _, t := range e.Terms {
+		if t.Negative {
+			total -= t.high()
+		} else {
+			total += t.low()
+		}
+	}
+	return total
+}
+
+// Max returns the highest total the expression can roll.
+func (e Expression) Max() int {
+	total := 0
+	for _, t := range e.Terms {
+		if t.Negative {
+			total -= t.low()
+		} else {
+			total += t.high()
+		}
+	}
+	return total
+}
+
+// Average returns the exact expected total. Unlike the statblocks it is not rounded down.
+func (e Expression) Average() float64 {
+	total := 0.0
+	for _, t := range e.Terms {
+		if t.Negative {
+			total -= t.average()
+		} else {
+			total += t.average()
+		}
+	}
+	return total
+}
+
+// Roll is the outcome of rolling an expression.
+type Roll struct {
+	Total int
+	Terms []TermRoll
+}
+
+// TermRoll is the outcome of one term: the dice in the order they were rolled, which of them
+// were kept, and the signed value the term added to the total.
+type TermRoll struct {
+	Term  Term
+	Dice  []int
+	Kept  []bool
+	Value int
+}
+
+// Roll rolls every die of the expression with rng.
+func (e Expression) Roll(rng *rand.Rand) Roll {
+	r := Roll{Terms: make([]TermRoll, len(e.Terms))}
+	for i, t := range e.Terms {
+		tr := TermRoll{Term: t, Value: t.Value}
+		if t.Sides > 0 {
+			tr.Dice = make([]int, t.Count)
+			for j := range tr.Dice {
+				tr.Dice[j] = rng.IntN(t.Sides) + 1
+			}
+			tr.Kept = keep(tr.Dice, t.Keep, t.KeepLowest)
+			tr.Value = 0
+			for j, d := range tr.Dice {
+				if tr.Kept[j] {
+					tr.Value += d
+				}
+			}
+		}
+		if t.Negative {
+			tr.Value = -tr.Value
+		}
+		r.Total += tr.Value
+		r.Terms[i] = tr
+	}
+	return r
+}
+
+// keep marks the n highest dice, or the n lowest, preferring the earlier die on ties.
+func keep(dice []int, n int, lowest bool) []bool {
+	kept := make([]bool, len(dice))
+	for range n {
+		best := -1
+		for j, d := range dice {
+			if kept[j] {
+				continue
+			}
+			if best < 0 || (lowest && d < dice[best]) || (!lowest && d > dice[best]) {
+				best = j
+			}
+		}
+		kept[best] = true
+	}
+	return kept
+}
+
+func (t Term) low() int {
+	if t.Sides == 0 {
+		return t.Value
+	}
+	return t.Keep
+}
+
+func (t Term) high() int {
+	if t.Sides == 0 {
+		return t.Value
+	}
+	return t.Keep * t.Sides
+}
+
+// average returns the expected value of the term. Kept dice are summed as order statistics:
+// the j-th highest of n dice is at least x when at least j of them roll x or more, so each
+// face needs a single pass over the binomial tails.
+func (t Term) average() float64 {
+	if t.Sides == 0 {
+		return float64(t.Value)
+	}
+	if t.Keep == t.Count {
+		return float64(t.Count) * float64(t.Sides+1) / 2
+	}
+
+	total := 0.0
+	tails := make([]float64, t.Count+1)
+	for x := 1; x <= t.Sides; x++ {
+		if t.KeepLowest {
+			// The j-th lowest is at least x when fewer than j dice roll below x
+			binomialTails(tails, float64(x-1)/float64(t.Sides))
+			for j := 1; j <= t.Keep; j++ {
+				total += 1 - tails[j]
+			}
+		} else {
+			binomialTails(tails, float64(t.Sides-x+1)/float64(t.Sides))
+			for j := 1; j <= t.Keep; j++ {
+				total += tails[j]
+			}
+		}
+	}
+	return total
+}
+
+// binomialTails sets tails[k] to the probability that at least k of len(tails)-1
+// independent events of probability p happen.
+func binomialTails(tails []float64, p float64) {
+	n := len(tails) - 1
+	tails[0] = 1
+	for i := 1; i <= n; i++ {
+		tails[i] = tails[i-1] * p
+	}
+
+	// Walk down from n, where tails[i] still holds p^i, adding C(n, i)·p^i·(1-p)^(n-i)
+	coefficient, q, sum := 1.0, 1.0, 0.0
+	for i := n; i >= 0; i-- {
+		sum += coefficient * tails[i] * q
+		tails[i] = sum
+		coefficient = coefficient * float64(i) / float64(n-i+1)
+		q *= 1 - p
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// normalize lowercases s, drops spaces and turns the typographic minus signs into "-".
+func normalize(s string) string {
+	s = strings.NewReplacer("−", "-", "–", "-").Replace(strings.ToLower(s))
+	return strings.Join(strings.Fields(s), "")
+}
+
+// parser consumes a normalized expression from the left.
+type parser struct {
+	input string
+	s     string
+}
+
+func (p *parser) done() bool {
+	return p.s == ""
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w %q: %s", ErrInvalidExpression, p.input, fmt.Sprintf(format, args...))
+}
+
+// sign consumes a leading + or -, reporting whether it was a minus. Without required
+// a missing sign counts as +.
+func (p *parser) sign(required bool) bool {
+	if p.done() {
+		return false
+	}
+	switch p.s[0] {
+	case '-':
+		p.s = p.s[1:]
+		return true
+	case '+':
+		if required || len(p.s) > 1 {
+			p.s = p.s[1:]
+		}
+	}
+	return false
+}
+
+// number consumes a run of digits, returning false when there is none.
+func (p *parser) number() (int, bool, error) {
+	i := 0
+	for i < len(p.s) && p.s[i] >= '0' && p.s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, false, nil
+	}
+	n, err := strconv.Atoi(p.s[:i])
+	if err != nil || n > MaxValue {
+		return 0, false, p.errorf("%s is too large", p.s[:i])
+	}
+	p.s = p.s[i:]
+	return n, true, nil
+}
+
+// term consumes a constant or a dice group with its keep suffix.
+func (p *parser) term() (Term, error) {
+	count, hasCount, err := p.number()
+	if err != nil {
+		return Term{}, err
+	}
+	if !strings.HasPrefix(p.s, "d") {
+		if !hasCount {
+			if p.done() {
+				return Term{}, p.errorf("missing term at the end")
+			}
+			return Term{}, p.errorf("unexpected %q", p.s[:1])
+		}
+		return Term{Value: count}, nil
+	}
+	p.s = p.s[1:]
+
+	if !hasCount {
+		count = 1
+	}
+	var sides int
+	if strings.HasPrefix(p.s, "%") {
+		p.s = p.s[1:]
+		sides = 100
+	} else {
+		var ok bool
+		if sides, ok, err = p.number(); err != nil {
+			return Term{}, err
+		} else if !ok {
+			return Term{}, p.errorf("missing number of sides")
+		}
+	}
+	if count < 1 || count > MaxDice {
+		return Term{}, p.errorf("the number of dice must be between 1 and %d", MaxDice)
+	}
+	if sides < 1 || sides > MaxSides {
+		return Term{}, p.errorf("dice must have between 1 and %d sides", MaxSides)
+	}
+
+	t := Term{Count: count, Sides: sides, Keep: count}
+	switch {
+	case strings.HasPrefix(p.s, "adv"), strings.HasPrefix(p.s, "dis"):
+		if count != 1 {
+			return Term{}, p.errorf("advantage and disadvantage apply to a single die")
+		}
+		t.Count, t.Keep, t.KeepLowest = 2, 1, p.s[0] == 'd'
+		p.s = p.s[3:]
+	case strings.HasPrefix(p.s, "kh"), strings.HasPrefix(p.s, "kl"):
+		t.KeepLowest = p.s[1] == 'l'
+		p.s = p.s[2:]
+		keep, ok, err := p.number()
+		if err != nil {
+			return Term{}, err
+		}
+		if !ok || keep < 1 || keep > count {
+			return Term{}, p.errorf("the dice kept must be between 1 and %d", count)
+		}
+		t.Keep = keep
+	}
+	return t, nil
+}
@@ -0,0 +1,162 @@
+package dice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		min, max int
+		average  float64
+	}{
+		{"20d10 + 40", "20d10 + 40", 60, 240, 150},
+		{"2d8 − 1", "2d8 − 1", 1, 15, 8},
+		{"d20", "1d20", 1, 20, 10.5},
+		{"D%", "1d100", 1, 100, 50.5},
+		{"4d6kh3", "4d6kh3", 3, 18, 12.244598765432098},
+		{"d20adv + 5", "2d20kh1 + 5", 6, 25, 18.825},
+		{"d20dis", "2d20kl1", 1, 20, 7.175},
+		{"2d20kl1", "2d20kl1", 1, 20, 7.175},
+		{"-1d4 + 3", "−1d4 + 3", -1, 2, 0.5},
+		{"1d6 + 1d8 – 2", "1d6 + 1d8 − 2", 0, 12, 6},
+		{"+7", "7", 7, 7, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			e, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if e.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, e.String())
+			}
+			if e.Min() != tt.min || e.Max() != tt.max {
+				t.Errorf("expected range %d-%d, got %d-%d", tt.min, tt.max, e.Min(), e.Max())
+			}
+			if math.Abs(e.Average()-tt.average) > 1e-9 {
+				t.Errorf("expected average %v, got %v", tt.average, e.Average())
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"", "   ", "d", "2d", "2x6", "1d6 +", "1d6 ++ 2", "0d6", "101d6", "1d0", "1d1001",
+		"4d6kh5", "4d6kh0", "4d6kh", "2d20adv", "1d6 1d8", "9999999",
+	} {
+		if _, err := Parse(input); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("%q: expected ErrInvalidExpression, got %v", input, err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	if s := New(20, 10, 40).String(); s != "20d10 + 40" {
+		t.Errorf("expected 20d10 + 40, got %q", s)
+	}
+	if s := New(2, 8, -1).String(); s != "2d8 − 1" {
+		t.Errorf("expected 2d8 − 1, got %q", s)
+	}
+}
+
+func TestExpressionRoll(t *testing.T) {
+	e, err := Parse("4d6kh3 - 1d4 + 2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rng := rand.New(rand.NewPCG(7, 0))
+	for range 1000 {
+		r := e.Roll(rng)
+		if r.Total < e.Min() || r.Total > e.Max() {
+			t.Fatalf("total %d out of range %d-%d", r.Total, e.Min(), e.Max())
+		}
+
+		keep := r.Terms[0]
+		lowestKept, dropped, sum := 7, 0, 0
+		for i, d := range keep.Dice {
+			if keep.Kept[i] {
+				lowestKept = min(lowestKept, d)
+				sum += d
+			} else {
+				dropped = d
+			}
+		}
+		if len(keep.Dice) != 4 || dropped > lowestKept || sum != keep.Value {
+			t.Fatalf("expected the lowest of 4 dice to be dropped, got %+v", keep)
+		}
+		if r.Terms[1].Value > -1 || r.Terms[2].Value != 2 {
+			t.Fatalf("unexpected terms %+v", r.Terms[1:])
+		}
+		if r.Total != r.Terms[0].Value+r.Terms[1].Value+r.Terms[2].Value {
+			t.Fatalf("expected the total to sum the terms, got %+v", r)
+		}
+	}
+
+	// The same seed rolls the same dice
+	first := e.Roll(rand.New(rand.NewPCG(42, 0)))
+	second := e.Roll(rand.New(rand.NewPCG(42, 0)))
+	if first.Total != second.Total {
+		t.Errorf("expected seeded rolls to repeat, got %d and %d", first.Total, second.Total)
+	}
+}
+
+func TestExpressionAverage_MatchesRolls(t *testing.T) {
+	for _, input := range []string{"4d6kh3", "d20adv", "3d8kl2 + 1"} {
+		e, err := Parse(input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rng := rand.New(rand.NewPCG(1, 2))
+		const n = 200_000
+		total := 0
+		for range n {
+			total += e.Roll(rng).Total
+		}
+		if mean := float64(total) / n; math.Abs(mean-e.Average()) > 0.05 {
+			t.Errorf("%s: expected a mean close to %.3f, got %.3f", input, e.Average(), mean)
+		}
+	}
+}
+
+// worstCase is the costliest expression to average that Parse accepts: every term keeps
+// half of the most dice with the most sides.
+var worstCase = strings.TrimSuffix(strings.Repeat(fmt.Sprintf("%dd%dkh%d + ", MaxDice, MaxSides, MaxDice/2), MaxTerms), " + ")
+
+func TestExpressionAverage_WorstCase(t *testing.T) {
+	e, err := Parse(worstCase)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	average := e.Average()
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("expected the average within a few milliseconds, took %v", elapsed)
+	}
+	// The highest half of n uniform dice averages about (sides+1)·(3n+2)/(4n+4) each
+	perDie := float64(MaxSides+1) * float64(3*MaxDice+2) / float64(4*MaxDice+4)
+	if kept := average / float64(MaxTerms*MaxDice/2); math.Abs(kept-perDie) > 1 {
+		t.Errorf("expected about %.1f per kept die, got %.1f", perDie, kept)
+	}
+}
+
+func BenchmarkExpressionAverage_WorstCase(b *testing.B) {
+	e, err := Parse(worstCase)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	for b.Loop() {
+		e.Average()
+	}
+}
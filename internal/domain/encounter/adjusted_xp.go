@@ -0,0 +1,149 @@
+package encounter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Party sizes that shift the 2014 encounter multiplier by one step
+const (
+	SmallPartySize = 3 // parties with fewer characters use the next higher multiplier
+	LargePartySize = 6 // parties with at least this many characters use the next lower multiplier
+)
+
+// MultiplierShiftForPartySize returns how many steps the 2014 encounter multiplier
+// moves for the given party size
+func MultiplierShiftForPartySize(partySize int) int {
+	switch {
+	case partySize < SmallPartySize:
+		return 1
+	case partySize >= LargePartySize:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// WeakMonsterCRRatio is the fraction of the average CR of the other monsters below which
+// a monster is considered too weak to count for the 2014 encounter multiplier
+const WeakMonsterCRRatio = 0.25
+
+// ExcludedMonster represents a monster group left out of the 2014 monster count
+type ExcludedMonster struct {
+	MonsterID       string  `json:"monster_id"`
+	Name            string  `json:"name"`
+	CR              string  `json:"cr"`
+	Quantity        int     `json:"quantity"`
+	OthersAverageCR float64 `json:"others_average_cr"`
+	Reason          string  `json:"reason"`
+}
+
+// DifficultyThreshold represents the party XP threshold for a difficulty
+type DifficultyThreshold struct {
+	Difficulty Difficulty
+	XP         int
+}
+
+// AdjustedXP2014 represents the 2014 DMG evaluation of a group of monsters against a party
+type AdjustedXP2014 struct {
+	RawXP int
+	// MonsterCount is the number of monsters counted for the multiplier
+	MonsterCount int
+	// ExcludedMonsters lists the groups left out of the count by the weak monster rule
+	ExcludedMonsters []ExcludedMonster
+	// MultiplierStep is the multiplier used, including the party size shift
+	MultiplierStep MultiplierStep
+	Multiplier     float64
+	AdjustedXP     int
+	Thresholds     []DifficultyThreshold
+	// Difficulty is the highest band reached by the adjusted XP, empty when below Facile
+	Difficulty Difficulty
+}
+
+// CalculateAdjustedXP2014 sums the XP of the monster groups, applies the encounter
+// multiplier for the number of monsters and the party size, and finds the
+// difficulty band using the sum of the per-character thresholds of the party.
+// Unless countWeak is set, monsters much weaker than the others are left out of
+// the count used for the multiplier, while their XP still adds to the total.
+func CalculateAdjustedXP2014(party Party, groups []MonsterGroup, countWeak bool, repo Repository) (AdjustedXP2014, error) {
+	if party.Size() == 0 {
+		return AdjustedXP2014{}, errors.New("party must have at least one character")
+	}
+
+	result := AdjustedXP2014{}
+	for _, g := range groups {
+		if g.Quantity < 1 {
+			return AdjustedXP2014{}, fmt.Errorf("invalid quantity %d for monster %s", g.Quantity, g.Monster.ID)
+		}
+		result.RawXP += g.XP()
+		result.MonsterCount += g.Quantity
+	}
+
+	if !countWeak {
+		result.ExcludedMonsters = WeakMonsters(groups)
+		for _, e := range result.ExcludedMonsters {
+			result.MonsterCount -= e.Quantity
+		}
+	}
+
+	if result.MonsterCount > 0 {
+		step, err := repo.GetMultiplierFor2014(result.MonsterCount, party.Size())
+		if err != nil {
+			return AdjustedXP2014{}, fmt.Errorf("failed to get multiplier for %d monsters: %w", result.MonsterCount, err)
+		}
+		result.MultiplierStep = step
+		result.Multiplier = step.Multiplier
+		result.AdjustedXP = int(float64(result.RawXP) * step.Multiplier)
+	}
+
+	for _, diff := range repo.GetAllDifficultiesFor2014() {
+		threshold := 0
+		for _, char := range party.Characters {
+			t, err := repo.GetThresholdFor2014(char.Level, diff)
+			if err != nil {
+				return AdjustedXP2014{}, fmt.Errorf("failed to get threshold for level %d: %w", char.Level, err)
+			}
+			threshold += t
+		}
+		result.Thresholds = append(result.Thresholds, DifficultyThreshold{Difficulty: diff, XP: threshold})
+
+		if result.MonsterCount > 0 && result.AdjustedXP >= threshold {
+			result.Difficulty = diff
+		}
+	}
+
+	return result, nil
+}
+
+// WeakMonsters returns the groups whose CR is below WeakMonsterCRRatio times the
+// average CR of all the other monsters, as the 2014 DMG suggests ignoring them
+// when counting monsters. The strongest group is never excluded.
+func WeakMonsters(groups []MonsterGroup) []ExcludedMonster {
+	totalCR, totalCount := 0.0, 0
+	for _, g := range groups {
+		totalCR += g.Monster.CRValue() * float64(g.Quantity)
+		totalCount += g.Quantity
+	}
+
+	var excluded []ExcludedMonster
+	for _, g := range groups {
+		othersCount := totalCount - g.Quantity
+		if othersCount == 0 {
+			continue
+		}
+		cr := g.Monster.CRValue()
+		othersAverage := (totalCR - cr*float64(g.Quantity)) / float64(othersCount)
+		if cr < othersAverage*WeakMonsterCRRatio {
+			excluded = append(excluded, ExcludedMonster{
+				MonsterID:       g.Monster.ID,
+				Name:            g.Monster.Name,
+				CR:              g.Monster.CR,
+				Quantity:        g.Quantity,
+				OthersAverageCR: othersAverage,
+				Reason: fmt.Sprintf("CR %s is below %.0f%% of the average CR %.3g of the other monsters",
+					g.Monster.CR, WeakMonsterCRRatio*100, othersAverage),
+			})
+		}
+	}
+	return excluded
+}
@@ -0,0 +1,199 @@
+package encounter
+
+import (
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+func TestCalculateAdjustedXP2014(t *testing.T) {
+	tests := []struct {
+		name               string
+		levels             []int
+		groups             []MonsterGroup
+		countWeak          bool
+		expectedRawXP      int
+		expectedCount      int
+		expectedMultiplier float64
+		expectedAdjustedXP int
+		expectedDifficulty Difficulty
+		expectError        bool
+	}{
+		{
+			name:               "no monsters",
+			levels:             []int{5, 5, 5},
+			expectedDifficulty: "",
+		},
+		{
+			name:               "single monster uses multiplier 1",
+			levels:             []int{5, 5, 5},
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 1}},
+			expectedRawXP:      450,
+			expectedCount:      1,
+			expectedMultiplier: 1.0,
+			expectedAdjustedXP: 450,
+			expectedDifficulty: "", // Facile threshold is 750
+		},
+		{
+			name:               "multiplier uses the real monster count across groups",
+			levels:             []int{5, 5, 5},
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 1}, {Monster: goblin, Quantity: 2}},
+			countWeak:          true,
+			expectedRawXP:      550,
+			expectedCount:      3,
+			expectedMultiplier: 2.0,
+			expectedAdjustedXP: 1100,
+			expectedDifficulty: DifficultyEasy,
+		},
+		{
+			name:               "weak monsters are left out of the count but keep their XP",
+			levels:             []int{5, 5, 5},
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 1}, {Monster: goblin, Quantity: 2}},
+			expectedRawXP:      550,
+			expectedCount:      1,
+			expectedMultiplier: 1.0,
+			expectedAdjustedXP: 550,
+			expectedDifficulty: "",
+		},
+		{
+			name:               "difficile band",
+			levels:             []int{5, 5, 5},
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 3}},
+			expectedRawXP:      1350,
+			expectedCount:      3,
+			expectedMultiplier: 2.0,
+			expectedAdjustedXP: 2700,
+			expectedDifficulty: DifficultyHard,
+		},
+		{
+			name:               "small party shifts the multiplier up",
+			levels:             []int{5, 5},
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 1}},
+			expectedRawXP:      450,
+			expectedCount:      1,
+			expectedMultiplier: 1.5,
+			expectedAdjustedXP: 675,
+			expectedDifficulty: DifficultyEasy,
+		},
+		{
+			name:               "large party shifts the multiplier down to 0.5",
+			levels:             []int{5, 5, 5, 5, 5, 5},
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 1}},
+			expectedRawXP:      450,
+			expectedCount:      1,
+			expectedMultiplier: 0.5,
+			expectedAdjustedXP: 225,
+			expectedDifficulty: "",
+		},
+		{
+			name:        "invalid quantity",
+			levels:      []int{5},
+			groups:      []MonsterGroup{{Monster: ogre, Quantity: 0}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			party, err := NewParty(tt.levels)
+			if err != nil {
+				t.Fatalf("unexpected error creating party: %v", err)
+			}
+
+			result, err := CalculateAdjustedXP2014(party, tt.groups, tt.countWeak, stubRepository{})
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.RawXP != tt.expectedRawXP {
+				t.Errorf("expected raw XP %d, got %d", tt.expectedRawXP, result.RawXP)
+			}
+			if result.MonsterCount != tt.expectedCount {
+				t.Errorf("expected monster count %d, got %d", tt.expectedCount, result.MonsterCount)
+			}
+			if result.Multiplier != tt.expectedMultiplier {
+				t.Errorf("expected multiplier %.1f, got %.1f", tt.expectedMultiplier, result.Multiplier)
+			}
+			if result.AdjustedXP != tt.expectedAdjustedXP {
+				t.Errorf("expected adjusted XP %d, got %d", tt.expectedAdjustedXP, result.AdjustedXP)
+			}
+			if result.Difficulty != tt.expectedDifficulty {
+				t.Errorf("expected difficulty %q, got %q", tt.expectedDifficulty, result.Difficulty)
+			}
+			if len(result.Thresholds) != 4 {
+				t.Errorf("expected 4 thresholds, got %d", len(result.Thresholds))
+			}
+		})
+	}
+}
+
+func TestWeakMonsters(t *testing.T) {
+	rat := monster.Monster{ID: "rat", Name: "Rat", CR: "0", XP: 10}
+	hobgoblin := monster.Monster{ID: "hobgoblin", Name: "Hobgoblin", CR: "1/2", XP: 100}
+	dragon := monster.Monster{ID: "dragon", Name: "Dragon", CR: "10", XP: 5900}
+
+	tests := []struct {
+		name             string
+		groups           []MonsterGroup
+		expectedExcluded []string
+	}{
+		{
+			name:   "no monsters",
+			groups: nil,
+		},
+		{
+			name:   "a single group is never weak",
+			groups: []MonsterGroup{{Monster: goblin, Quantity: 6}},
+		},
+		{
+			name:             "goblins with an ogre",
+			groups:           []MonsterGroup{{Monster: ogre, Quantity: 1}, {Monster: goblin, Quantity: 4}},
+			expectedExcluded: []string{"goblin"},
+		},
+		{
+			name:   "similar CRs are all counted",
+			groups: []MonsterGroup{{Monster: goblin, Quantity: 3}, {Monster: hobgoblin, Quantity: 2}},
+		},
+		{
+			name:             "only the much weaker groups are excluded",
+			groups:           []MonsterGroup{{Monster: dragon, Quantity: 1}, {Monster: ogre, Quantity: 2}, {Monster: rat, Quantity: 5}},
+			expectedExcluded: []string{"rat"},
+		},
+		{
+			name:   "many weak monsters lower the average of the others",
+			groups: []MonsterGroup{{Monster: goblin, Quantity: 1}, {Monster: hobgoblin, Quantity: 1}, {Monster: rat, Quantity: 1}},
+			// rat CR 0 is below a quarter of the 0.375 average of the others
+			expectedExcluded: []string{"rat"},
+		},
+		{
+			name:   "monsters with the same CR are never excluded",
+			groups: []MonsterGroup{{Monster: rat, Quantity: 2}, {Monster: monster.Monster{ID: "bat", CR: "0"}, Quantity: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			excluded := WeakMonsters(tt.groups)
+
+			if len(excluded) != len(tt.expectedExcluded) {
+				t.Fatalf("expected %d excluded groups, got %d: %+v", len(tt.expectedExcluded), len(excluded), excluded)
+			}
+			for i, e := range excluded {
+				if e.MonsterID != tt.expectedExcluded[i] {
+					t.Errorf("expected %s to be excluded, got %s", tt.expectedExcluded[i], e.MonsterID)
+				}
+				if e.Reason == "" {
+					t.Errorf("expected a reason for excluding %s", e.MonsterID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package encounter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrArchetypeNotFound is returned when an encounter archetype does not exist
+var ErrArchetypeNotFound = errors.New("archetype not found")
+
+// ArchetypeRole represents a group of identical monsters in an encounter archetype
+type ArchetypeRole struct {
+	Name string
+	// Share is the fraction of the XP budget spent on the role
+	Share    float64
+	MinCount int
+	MaxCount int
+}
+
+// Archetype represents a named encounter template, expressed as XP shares and count ranges
+type Archetype struct {
+	ID    string
+	Name  string
+	Roles []ArchetypeRole
+}
+
+// Validate checks that the roles have valid count ranges and that their shares cover the whole budget
+func (a Archetype) Validate() error {
+	if a.ID == "" {
+		return errors.New("archetype ID cannot be empty")
+	}
+	if len(a.Roles) == 0 {
+		return fmt.Errorf("archetype %s must have at least one role", a.ID)
+	}
+
+	total := 0.0
+	for _, r := range a.Roles {
+		if r.Share <= 0 {
+			return fmt.Errorf("archetype %s role %s must have a positive share", a.ID, r.Name)
+		}
+		if r.MinCount < 1 || r.MaxCount < r.MinCount || r.MaxCount > MaxGroupQuantity {
+			return fmt.Errorf("archetype %s role %s has invalid count range %d-%d", a.ID, r.Name, r.MinCount, r.MaxCount)
+		}
+		total += r.Share
+	}
+	if math.Abs(total-1) > 1e-9 {
+		return fmt.Errorf("archetype %s shares must add up to 1, got %g", a.ID, total)
+	}
+
+	return nil
+}
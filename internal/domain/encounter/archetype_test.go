@@ -0,0 +1,64 @@
+package encounter
+
+import "testing"
+
+func TestArchetypeValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		archetype   Archetype
+		expectError bool
+	}{
+		{
+			name: "boss and minions",
+			archetype: Archetype{ID: "boss-minions", Roles: []ArchetypeRole{
+				{Name: "boss", Share: 0.6, MinCount: 1, MaxCount: 1},
+				{Name: "minions", Share: 0.4, MinCount: 2, MaxCount: 6},
+			}},
+		},
+		{
+			name:        "missing ID",
+			archetype:   Archetype{Roles: []ArchetypeRole{{Name: "boss", Share: 1, MinCount: 1, MaxCount: 1}}},
+			expectError: true,
+		},
+		{
+			name:        "no roles",
+			archetype:   Archetype{ID: "empty"},
+			expectError: true,
+		},
+		{
+			name: "shares do not cover the budget",
+			archetype: Archetype{ID: "short", Roles: []ArchetypeRole{
+				{Name: "boss", Share: 0.6, MinCount: 1, MaxCount: 1},
+				{Name: "minions", Share: 0.3, MinCount: 2, MaxCount: 6},
+			}},
+			expectError: true,
+		},
+		{
+			name:        "non positive share",
+			archetype:   Archetype{ID: "zero", Roles: []ArchetypeRole{{Name: "boss", Share: 0, MinCount: 1, MaxCount: 1}}},
+			expectError: true,
+		},
+		{
+			name:        "inverted count range",
+			archetype:   Archetype{ID: "range", Roles: []ArchetypeRole{{Name: "horde", Share: 1, MinCount: 12, MaxCount: 6}}},
+			expectError: true,
+		},
+		{
+			name:        "zero count",
+			archetype:   Archetype{ID: "range", Roles: []ArchetypeRole{{Name: "horde", Share: 1, MinCount: 0, MaxCount: 6}}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.archetype.Validate()
+			if tt.expectError && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
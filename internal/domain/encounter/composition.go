@@ -0,0 +1,218 @@
+package encounter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// MaxGroupQuantity is the largest number of identical monsters allowed in a single group
+const MaxGroupQuantity = 100
+
+var (
+	// ErrCompositionNotFound is returned when a composition does not exist
+	ErrCompositionNotFound = errors.New("composition not found")
+
+	// ErrMonsterNotInComposition is returned when a monster is not part of a composition
+	ErrMonsterNotInComposition = errors.New("monster not in composition")
+
+	// ErrNoLairVariant is returned when a monster without lair XP is placed in its lair
+	ErrNoLairVariant = errors.New("monster has no lair variant")
+)
+
+// MonsterGroup represents a number of identical monsters in an encounter
+type MonsterGroup struct {
+	Monster  monster.Monster
+	Quantity int
+
+	// InLair marks monsters fought in their lair, which are worth their lair XP
+	InLair bool
+}
+
+// XP returns the total XP of the group
+func (g MonsterGroup) XP() int {
+	return g.Monster.EncounterXP(g.InLair) * g.Quantity
+}
+
+// Composition represents the monsters a DM has chosen for an encounter
+type Composition struct {
+	ID         string
+	Party      Party
+	Ruleset    Ruleset
+	Difficulty Difficulty
+	Budget     int
+	Groups     []MonsterGroup
+
+	// CountWeakMonsters turns off the 2014 rule that leaves much weaker monsters
+	// out of the count used for the encounter multiplier
+	CountWeakMonsters bool
+}
+
+// CompositionEvaluation represents the budget usage and difficulty of a composition
+type CompositionEvaluation struct {
+	XPUsed       int
+	XPRemaining  int
+	MonsterCount int
+	Difficulty   Difficulty
+
+	// Multiplier and AdjustedXP are only set for the 2014 ruleset, where the
+	// budget is compared against the multiplied XP instead of the raw sum
+	Multiplier       float64
+	MultiplierStep   MultiplierStep
+	AdjustedXP       int
+	CountedMonsters  int
+	ExcludedMonsters []ExcludedMonster
+}
+
+// NewComposition creates a new empty composition for the given party and budget
+func NewComposition(id string, party Party, ruleset Ruleset, difficulty Difficulty, budget int) *Composition {
+	return &Composition{
+		ID:         id,
+		Party:      party,
+		Ruleset:    ruleset,
+		Difficulty: difficulty,
+		Budget:     budget,
+	}
+}
+
+// AddMonster adds quantity monsters to the composition, merging with an existing group
+func (c *Composition) AddMonster(m monster.Monster, quantity int) error {
+	if quantity < 1 {
+		return errors.New("quantity must be at least 1")
+	}
+
+	for i := range c.Groups {
+		if c.Groups[i].Monster.ID == m.ID {
+			return c.SetQuantity(m.ID, c.Groups[i].Quantity+quantity)
+		}
+	}
+
+	if quantity > MaxGroupQuantity {
+		return fmt.Errorf("quantity cannot exceed %d", MaxGroupQuantity)
+	}
+
+	c.Groups = append(c.Groups, MonsterGroup{Monster: m, Quantity: quantity})
+	return nil
+}
+
+// RemoveMonster removes the group of the given monster from the composition
+func (c *Composition) RemoveMonster(monsterID string) error {
+	for i := range c.Groups {
+		if c.Groups[i].Monster.ID == monsterID {
+			c.Groups = append(c.Groups[:i], c.Groups[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrMonsterNotInComposition, monsterID)
+}
+
+// SetQuantity changes the quantity of a monster group; a quantity of zero removes the group
+func (c *Composition) SetQuantity(monsterID string, quantity int) error {
+	if quantity < 0 {
+		return errors.New("quantity cannot be negative")
+	}
+	if quantity > MaxGroupQuantity {
+		return fmt.Errorf("quantity cannot exceed %d", MaxGroupQuantity)
+	}
+	if quantity == 0 {
+		return c.RemoveMonster(monsterID)
+	}
+
+	for i := range c.Groups {
+		if c.Groups[i].Monster.ID == monsterID {
+			c.Groups[i].Quantity = quantity
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrMonsterNotInComposition, monsterID)
+}
+
+// SetInLair places a monster group in its lair (true) or outside it (false)
+func (c *Composition) SetInLair(monsterID string, inLair bool) error {
+	for i := range c.Groups {
+		if c.Groups[i].Monster.ID == monsterID {
+			if inLair && !c.Groups[i].Monster.HasLair() {
+				return fmt.Errorf("%w: %s", ErrNoLairVariant, monsterID)
+			}
+			c.Groups[i].InLair = inLair
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrMonsterNotInComposition, monsterID)
+}
+
+// XPUsed returns the sum of the XP of all monsters in the composition
+func (c *Composition) XPUsed() int {
+	total := 0
+	for _, g := range c.Groups {
+		total += g.XP()
+	}
+	return total
+}
+
+// MonsterCount returns the total number of monsters in the composition
+func (c *Composition) MonsterCount() int {
+	count := 0
+	for _, g := range c.Groups {
+		count += g.Quantity
+	}
+	return count
+}
+
+// Evaluate computes XP used, XP remaining and the resulting difficulty of the composition
+func (c *Composition) Evaluate(repo Repository) (CompositionEvaluation, error) {
+	evaluation := CompositionEvaluation{
+		XPUsed:       c.XPUsed(),
+		MonsterCount: c.MonsterCount(),
+	}
+	evaluation.XPRemaining = c.Budget - evaluation.XPUsed
+
+	switch c.Ruleset {
+	case Ruleset2024:
+		if evaluation.MonsterCount == 0 {
+			return evaluation, nil
+		}
+		difficulty, err := c.difficulty2024(repo, evaluation.XPUsed)
+		if err != nil {
+			return CompositionEvaluation{}, err
+		}
+		evaluation.Difficulty = difficulty
+	case Ruleset2014:
+		adjusted, err := CalculateAdjustedXP2014(c.Party, c.Groups, c.CountWeakMonsters, repo)
+		if err != nil {
+			return CompositionEvaluation{}, err
+		}
+		evaluation.Multiplier = adjusted.Multiplier
+		evaluation.MultiplierStep = adjusted.MultiplierStep
+		evaluation.AdjustedXP = adjusted.AdjustedXP
+		evaluation.CountedMonsters = adjusted.MonsterCount
+		evaluation.ExcludedMonsters = adjusted.ExcludedMonsters
+		evaluation.XPRemaining = c.Budget - adjusted.AdjustedXP
+		evaluation.Difficulty = adjusted.Difficulty
+	default:
+		return CompositionEvaluation{}, fmt.Errorf("unsupported ruleset: %s", c.Ruleset)
+	}
+
+	return evaluation, nil
+}
+
+// difficulty2024 returns the lowest difficulty whose party budget covers the XP used.
+// Compositions above the High budget are reported as High.
+func (c *Composition) difficulty2024(repo Repository, xpUsed int) (Difficulty, error) {
+	difficulties := repo.GetAllDifficultiesFor2024()
+	for _, diff := range difficulties {
+		budget := 0
+		for _, char := range c.Party.Characters {
+			xp, err := repo.GetXPFor2024(char.Level, diff)
+			if err != nil {
+				return "", fmt.Errorf("failed to get XP for level %d: %w", char.Level, err)
+			}
+			budget += xp
+		}
+		if xpUsed <= budget {
+			return diff, nil
+		}
+	}
+	return difficulties[len(difficulties)-1], nil
+}
@@ -0,0 +1,284 @@
+package encounter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// stubRepository is a minimal Repository backed by a few table rows
+type stubRepository struct{}
+
+func (stubRepository) GetXPFor2024(level int, difficulty Difficulty) (int, error) {
+	xp := map[Difficulty]int{DifficultyLow: 500, DifficultyModerate: 750, DifficultyHigh: 1100}
+	return xp[difficulty], nil
+}
+
+func (stubRepository) GetThresholdFor2014(level int, difficulty Difficulty) (int, error) {
+	thresholds := map[Difficulty]int{DifficultyEasy: 250, DifficultyMedium: 500, DifficultyHard: 750, DifficultyDeadly: 1100}
+	return thresholds[difficulty], nil
+}
+
+func (stubRepository) GetXPPerDay(level int) (int, error) {
+	return 3500, nil
+}
+
+func (stubRepository) GetMultiplierFor2014(numMonsters, partySize int) (MultiplierStep, error) {
+	steps := []float64{0.5, 1.0, 1.5, 2.0, 2.5}
+	index := 3
+	switch {
+	case numMonsters <= 1:
+		index = 1
+	case numMonsters == 2:
+		index = 2
+	}
+	shift := MultiplierShiftForPartySize(partySize)
+	return MultiplierStep{BaseMultiplier: steps[index], Shift: shift, Multiplier: steps[index+shift]}, nil
+}
+
+func (stubRepository) GetAllDifficultiesFor2024() []Difficulty {
+	return []Difficulty{DifficultyLow, DifficultyModerate, DifficultyHigh}
+}
+
+func (stubRepository) GetAllDifficultiesFor2014() []Difficulty {
+	return []Difficulty{DifficultyEasy, DifficultyMedium, DifficultyHard, DifficultyDeadly}
+}
+
+func (stubRepository) GetSupportedLevels() []int {
+	return []int{5}
+}
+
+func (stubRepository) GetArchetype(id string) (Archetype, error) {
+	return Archetype{}, ErrArchetypeNotFound
+}
+
+func (stubRepository) GetAllArchetypes() []Archetype {
+	return nil
+}
+
+var (
+	goblin = monster.Monster{ID: "goblin", Name: "Goblin", CR: "1/4", XP: 50}
+	ogre   = monster.Monster{ID: "ogre", Name: "Ogre", CR: "2", XP: 450}
+)
+
+func newTestComposition(t *testing.T, ruleset Ruleset, budget int) *Composition {
+	t.Helper()
+	party, err := NewParty([]int{5, 5})
+	if err != nil {
+		t.Fatalf("unexpected error creating party: %v", err)
+	}
+	return NewComposition("test-id", party, ruleset, DifficultyModerate, budget)
+}
+
+func TestCompositionAddMonster(t *testing.T) {
+	c := newTestComposition(t, Ruleset2024, 1500)
+
+	if err := c.AddMonster(goblin, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.AddMonster(ogre, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.AddMonster(goblin, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(c.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(c.Groups))
+	}
+	if c.Groups[0].Quantity != 3 {
+		t.Errorf("expected 3 goblins, got %d", c.Groups[0].Quantity)
+	}
+	if c.MonsterCount() != 4 {
+		t.Errorf("expected 4 monsters, got %d", c.MonsterCount())
+	}
+	if c.XPUsed() != 600 {
+		t.Errorf("expected 600 XP used, got %d", c.XPUsed())
+	}
+
+	if err := c.AddMonster(goblin, 0); err == nil {
+		t.Error("expected error adding zero monsters")
+	}
+	if err := c.AddMonster(goblin, MaxGroupQuantity); err == nil {
+		t.Error("expected error exceeding max group quantity")
+	}
+}
+
+func TestCompositionSetQuantityAndRemove(t *testing.T) {
+	c := newTestComposition(t, Ruleset2024, 1500)
+	if err := c.AddMonster(goblin, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.AddMonster(ogre, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := c.SetQuantity("goblin", 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.XPUsed() != 650 {
+		t.Errorf("expected 650 XP used, got %d", c.XPUsed())
+	}
+
+	if err := c.SetQuantity("goblin", -1); err == nil {
+		t.Error("expected error for negative quantity")
+	}
+
+	if err := c.SetQuantity("goblin", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Groups) != 1 {
+		t.Errorf("expected quantity 0 to remove the group, got %d groups", len(c.Groups))
+	}
+
+	if err := c.RemoveMonster("ogre"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.RemoveMonster("ogre"); !errors.Is(err, ErrMonsterNotInComposition) {
+		t.Errorf("expected ErrMonsterNotInComposition, got %v", err)
+	}
+	if err := c.SetQuantity("dragon", 1); !errors.Is(err, ErrMonsterNotInComposition) {
+		t.Errorf("expected ErrMonsterNotInComposition, got %v", err)
+	}
+}
+
+func TestCompositionSetInLair(t *testing.T) {
+	aboleth := monster.Monster{ID: "aboleth", Name: "Aboleth", CR: "10", XP: 5900, LairXP: 7200}
+	c := newTestComposition(t, Ruleset2014, 10000)
+	if err := c.AddMonster(aboleth, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.AddMonster(goblin, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := c.SetInLair("aboleth", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.XPUsed() != 7300 {
+		t.Errorf("expected 7300 XP used with the aboleth in its lair, got %d", c.XPUsed())
+	}
+
+	if err := c.SetInLair("goblin", true); !errors.Is(err, ErrNoLairVariant) {
+		t.Errorf("expected ErrNoLairVariant, got %v", err)
+	}
+	if err := c.SetInLair("ogre", true); !errors.Is(err, ErrMonsterNotInComposition) {
+		t.Errorf("expected ErrMonsterNotInComposition, got %v", err)
+	}
+
+	if err := c.SetInLair("aboleth", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.XPUsed() != 6000 {
+		t.Errorf("expected 6000 XP used outside the lair, got %d", c.XPUsed())
+	}
+}
+
+func TestCompositionEvaluate(t *testing.T) {
+	tests := []struct {
+		name               string
+		ruleset            Ruleset
+		budget             int
+		groups             []MonsterGroup
+		countWeak          bool
+		expectedUsed       int
+		expectedRemaining  int
+		expectedDifficulty Difficulty
+	}{
+		{
+			name:               "empty composition has no difficulty",
+			ruleset:            Ruleset2024,
+			budget:             1500,
+			expectedRemaining:  1500,
+			expectedDifficulty: "",
+		},
+		{
+			name:               "2024 within low budget",
+			ruleset:            Ruleset2024,
+			budget:             1500,
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 2}},
+			expectedUsed:       900,
+			expectedRemaining:  600,
+			expectedDifficulty: DifficultyLow,
+		},
+		{
+			name:               "2024 within moderate budget",
+			ruleset:            Ruleset2024,
+			budget:             1500,
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 3}},
+			expectedUsed:       1350,
+			expectedRemaining:  150,
+			expectedDifficulty: DifficultyModerate,
+		},
+		{
+			name:               "2024 over high budget",
+			ruleset:            Ruleset2024,
+			budget:             1500,
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 5}},
+			expectedUsed:       2250,
+			expectedRemaining:  -750,
+			expectedDifficulty: DifficultyHigh,
+		},
+		{
+			name:               "2014 below easy threshold",
+			ruleset:            Ruleset2014,
+			budget:             1000,
+			groups:             []MonsterGroup{{Monster: goblin, Quantity: 1}},
+			expectedUsed:       50,
+			expectedRemaining:  925, // 50 * 1.5, small party shift
+			expectedDifficulty: "",
+		},
+		{
+			name:               "2014 multiplier applied to monster count",
+			ruleset:            Ruleset2014,
+			budget:             1000,
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 2}},
+			expectedUsed:       900,
+			expectedRemaining:  -800, // budget is compared against 900 * 2 (1.5 shifted for a small party)
+			expectedDifficulty: DifficultyHard,
+		},
+		{
+			name:               "2014 weak goblins are not counted",
+			ruleset:            Ruleset2014,
+			budget:             1000,
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 1}, {Monster: goblin, Quantity: 4}},
+			expectedUsed:       650,
+			expectedRemaining:  25, // 650 * 1.5, only the ogre is counted
+			expectedDifficulty: DifficultyEasy,
+		},
+		{
+			name:               "2014 weak goblins counted when the rule is off",
+			ruleset:            Ruleset2014,
+			budget:             1000,
+			groups:             []MonsterGroup{{Monster: ogre, Quantity: 1}, {Monster: goblin, Quantity: 4}},
+			countWeak:          true,
+			expectedUsed:       650,
+			expectedRemaining:  -625, // 650 * 2.5
+			expectedDifficulty: DifficultyHard,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestComposition(t, tt.ruleset, tt.budget)
+			c.Groups = tt.groups
+			c.CountWeakMonsters = tt.countWeak
+
+			evaluation, err := c.Evaluate(stubRepository{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if evaluation.XPUsed != tt.expectedUsed {
+				t.Errorf("expected XP used %d, got %d", tt.expectedUsed, evaluation.XPUsed)
+			}
+			if evaluation.XPRemaining != tt.expectedRemaining {
+				t.Errorf("expected XP remaining %d, got %d", tt.expectedRemaining, evaluation.XPRemaining)
+			}
+			if evaluation.Difficulty != tt.expectedDifficulty {
+				t.Errorf("expected difficulty %q, got %q", tt.expectedDifficulty, evaluation.Difficulty)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package encounter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxDayEncounters is the largest number of encounters in an adventuring day plan
+const MaxDayEncounters = 12
+
+// PlannedEncounter represents one encounter of an adventuring day and its party XP budget
+type PlannedEncounter struct {
+	Difficulty Difficulty
+	XP         int
+}
+
+// DayPlan represents an adventuring day split into encounters of chosen difficulties.
+// The day budget is the sum of the adjusted XP per day of each character; the 2024
+// rules have no such table, so the 2014 values are used as the expected budget.
+type DayPlan struct {
+	Party      Party
+	Ruleset    Ruleset
+	DayBudget  int
+	Encounters []PlannedEncounter
+}
+
+// NewDayPlan creates a day plan with one encounter for each of the given difficulties
+func NewDayPlan(party Party, ruleset Ruleset, difficulties []Difficulty, repo Repository) (*DayPlan, error) {
+	if party.Size() == 0 {
+		return nil, errors.New("party must have at least one character")
+	}
+	if len(difficulties) > MaxDayEncounters {
+		return nil, fmt.Errorf("a day cannot have more than %d encounters", MaxDayEncounters)
+	}
+
+	plan := &DayPlan{Party: party, Ruleset: ruleset}
+	for _, char := range party.Characters {
+		xp, err := repo.GetXPPerDay(char.Level)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get XP per day for level %d: %w", char.Level, err)
+		}
+		plan.DayBudget += xp
+	}
+
+	for _, difficulty := range difficulties {
+		xp, err := PartyBudget(party, ruleset, difficulty, repo)
+		if err != nil {
+			return nil, err
+		}
+		plan.Encounters = append(plan.Encounters, PlannedEncounter{Difficulty: difficulty, XP: xp})
+	}
+
+	return plan, nil
+}
+
+// PartyBudget returns the XP budget of the party for a single encounter of the given difficulty:
+// the 2024 XP budget or the sum of the 2014 thresholds
+func PartyBudget(party Party, ruleset Ruleset, difficulty Difficulty, repo Repository) (int, error) {
+	total := 0
+	for _, char := range party.Characters {
+		var xp int
+		var err error
+		switch ruleset {
+		case Ruleset2024:
+			xp, err = repo.GetXPFor2024(char.Level, difficulty)
+		case Ruleset2014:
+			xp, err = repo.GetThresholdFor2014(char.Level, difficulty)
+		default:
+			return 0, fmt.Errorf("unsupported ruleset: %s", ruleset)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get XP for level %d: %w", char.Level, err)
+		}
+		total += xp
+	}
+	return total, nil
+}
+
+// XPPlanned returns the sum of the XP budgets of the planned encounters
+func (p *DayPlan) XPPlanned() int {
+	total := 0
+	for _, e := range p.Encounters {
+		total += e.XP
+	}
+	return total
+}
+
+// XPRemaining returns the part of the day budget not yet assigned to an encounter
+func (p *DayPlan) XPRemaining() int {
+	return p.DayBudget - p.XPPlanned()
+}
+
+// BudgetUsed returns the fraction of the day budget used by the planned encounters
+func (p *DayPlan) BudgetUsed() float64 {
+	if p.DayBudget == 0 {
+		return 0
+	}
+	return float64(p.XPPlanned()) / float64(p.DayBudget)
+}
+
+// EvenShare returns the XP each encounter would get if the day budget were split evenly
+func (p *DayPlan) EvenShare() int {
+	if len(p.Encounters) == 0 {
+		return p.DayBudget
+	}
+	return p.DayBudget / len(p.Encounters)
+}
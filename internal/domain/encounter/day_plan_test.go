@@ -0,0 +1,95 @@
+package encounter
+
+import "testing"
+
+func TestNewDayPlan(t *testing.T) {
+	tests := []struct {
+		name              string
+		ruleset           Ruleset
+		difficulties      []Difficulty
+		expectedBudget    int
+		expectedPlanned   int
+		expectedRemaining int
+		expectedEvenShare int
+		expectError       bool
+	}{
+		{
+			name:              "no encounters yet",
+			ruleset:           Ruleset2014,
+			expectedBudget:    7000,
+			expectedRemaining: 7000,
+			expectedEvenShare: 7000,
+		},
+		{
+			name:              "2014 encounters use the party thresholds",
+			ruleset:           Ruleset2014,
+			difficulties:      []Difficulty{DifficultyMedium, DifficultyHard},
+			expectedBudget:    7000,
+			expectedPlanned:   2500, // 2 * 500 + 2 * 750
+			expectedRemaining: 4500,
+			expectedEvenShare: 3500,
+		},
+		{
+			name:              "2024 encounters use the party budgets",
+			ruleset:           Ruleset2024,
+			difficulties:      []Difficulty{DifficultyModerate, DifficultyHigh, DifficultyLow},
+			expectedBudget:    7000,
+			expectedPlanned:   4700, // 2 * 750 + 2 * 1100 + 2 * 500
+			expectedRemaining: 2300,
+			expectedEvenShare: 2333,
+		},
+		{
+			name:              "over the day budget",
+			ruleset:           Ruleset2014,
+			difficulties:      []Difficulty{DifficultyDeadly, DifficultyDeadly, DifficultyDeadly, DifficultyDeadly},
+			expectedBudget:    7000,
+			expectedPlanned:   8800,
+			expectedRemaining: -1800,
+			expectedEvenShare: 1750,
+		},
+		{
+			name:         "too many encounters",
+			ruleset:      Ruleset2014,
+			difficulties: make([]Difficulty, MaxDayEncounters+1),
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			party, err := NewParty([]int{5, 5})
+			if err != nil {
+				t.Fatalf("unexpected error creating party: %v", err)
+			}
+
+			plan, err := NewDayPlan(party, tt.ruleset, tt.difficulties, stubRepository{})
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if plan.DayBudget != tt.expectedBudget {
+				t.Errorf("expected day budget %d, got %d", tt.expectedBudget, plan.DayBudget)
+			}
+			if plan.XPPlanned() != tt.expectedPlanned {
+				t.Errorf("expected %d XP planned, got %d", tt.expectedPlanned, plan.XPPlanned())
+			}
+			if plan.XPRemaining() != tt.expectedRemaining {
+				t.Errorf("expected %d XP remaining, got %d", tt.expectedRemaining, plan.XPRemaining())
+			}
+			if plan.EvenShare() != tt.expectedEvenShare {
+				t.Errorf("expected even share %d, got %d", tt.expectedEvenShare, plan.EvenShare())
+			}
+			if len(plan.Encounters) != len(tt.difficulties) {
+				t.Errorf("expected %d encounters, got %d", len(tt.difficulties), len(plan.Encounters))
+			}
+		})
+	}
+}
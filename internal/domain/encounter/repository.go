@@ -8,8 +8,12 @@ type Repository interface {
 	// GetThresholdFor2014 returns the XP threshold for a given level and difficulty in 2014 rules
 	GetThresholdFor2014(level int, difficulty Difficulty) (int, error)
 
-	// GetMultiplierFor2014 returns the encounter multiplier based on number of monsters
-	GetMultiplierFor2014(numMonsters int) (float64, error)
+	// GetXPPerDay returns the adjusted XP per day a character of the given level can face (2014 rules)
+	GetXPPerDay(level int) (int, error)
+
+	// GetMultiplierFor2014 returns the encounter multiplier based on number of monsters,
+	// shifted one step up or down according to the party size
+	GetMultiplierFor2014(numMonsters, partySize int) (MultiplierStep, error)
 
 	// GetAllDifficultiesFor2024 returns all available difficulties for 2024 ruleset
 	GetAllDifficultiesFor2024() []Difficulty
@@ -19,6 +23,12 @@ type Repository interface {
 
 	// GetSupportedLevels returns all supported character levels
 	GetSupportedLevels() []int
+
+	// GetArchetype returns the encounter archetype with the given ID or ErrArchetypeNotFound
+	GetArchetype(id string) (Archetype, error)
+
+	// GetAllArchetypes returns all available encounter archetypes
+	GetAllArchetypes() []Archetype
 }
 
 // MultiplierRange represents a range for encounter multipliers
@@ -26,3 +36,26 @@ type MultiplierRange struct {
 	MaxMonsters int
 	Multiplier  float64
 }
+
+// MultiplierStep represents the encounter multiplier chosen for a number of monsters and a party size
+type MultiplierStep struct {
+	// BaseMultiplier is the multiplier for the number of monsters alone
+	BaseMultiplier float64
+	// Shift is +1 for small parties, -1 for large parties and 0 otherwise
+	Shift int
+	// Multiplier is the multiplier after the party size shift
+	Multiplier float64
+}
+
+// CompositionRepository defines the interface for storing encounter compositions
+type CompositionRepository interface {
+	// Save stores the composition, replacing any previous version with the same ID
+	Save(composition *Composition) error
+
+	// FindByID returns the composition with the given ID or ErrCompositionNotFound
+	FindByID(id string) (*Composition, error)
+
+	// Update applies change to the composition with the given ID and saves it unless change fails,
+	// serializing concurrent updates of the same composition; it returns the updated composition
+	Update(id string, change func(composition *Composition) error) (*Composition, error)
+}
@@ -0,0 +1,279 @@
+package encounter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ShareCodeVersion is the version of the share code format produced by Encode.
+// Version 3 replaced the CRC-32 checksum of versions 1 and 2 with a keyed signature,
+// so older codes are no longer accepted.
+const ShareCodeVersion = 3
+
+// shareSignatureSize is the length of the truncated HMAC-SHA256 that signs a share code
+const shareSignatureSize = 16
+
+// maxShareCodeLength bounds the codes accepted by DecodeShareCode
+const maxShareCodeLength = 2048
+
+var (
+	// ErrInvalidShareCode is returned when a share code is malformed, corrupted or tampered with
+	ErrInvalidShareCode = errors.New("invalid share code")
+
+	// ErrMissingShareKey is returned when a share code is encoded or decoded without a signing key
+	ErrMissingShareKey = errors.New("share code key cannot be empty")
+
+	// ErrUnsupportedShareVersion is returned when a share code was produced by another format version
+	ErrUnsupportedShareVersion = errors.New("unsupported share code version")
+)
+
+// shareRulesets and shareDifficulties give every ruleset and difficulty its index in a share code;
+// new values must only ever be appended so existing codes keep decoding
+var (
+	shareRulesets     = []Ruleset{Ruleset2024, Ruleset2014}
+	shareDifficulties = map[Ruleset][]Difficulty{
+		Ruleset2024: {DifficultyLow, DifficultyModerate, DifficultyHigh},
+		Ruleset2014: {DifficultyEasy, DifficultyMedium, DifficultyHard, DifficultyDeadly},
+	}
+)
+
+// shareFlagCountWeakMonsters marks compositions that count much weaker monsters for the 2014 multiplier
+const shareFlagCountWeakMonsters = 1 << 0
+
+// shareMonsterFlagInLair marks monsters fought in their lair
+const shareMonsterFlagInLair = 1 << 0
+
+// SharedMonster is a monster, how many of it a shared encounter contains and whether it is in its lair
+type SharedMonster struct {
+	MonsterID string
+	Quantity  int
+	InLair    bool
+}
+
+// SharedEncounter is the state of an encounter carried by a share code
+type SharedEncounter struct {
+	Ruleset           Ruleset
+	Difficulty        Difficulty
+	Levels            []int
+	Monsters          []SharedMonster
+	CountWeakMonsters bool
+}
+
+// ShareComposition captures the party, difficulty and monsters of a composition
+func ShareComposition(c *Composition) SharedEncounter {
+	monsters := make([]SharedMonster, len(c.Groups))
+	for i, g := range c.Groups {
+		monsters[i] = SharedMonster{MonsterID: g.Monster.ID, Quantity: g.Quantity, InLair: g.InLair}
+	}
+	return SharedEncounter{
+		Ruleset:           c.Ruleset,
+		Difficulty:        c.Difficulty,
+		Levels:            c.Party.Levels(),
+		Monsters:          monsters,
+		CountWeakMonsters: c.CountWeakMonsters,
+	}
+}
+
+// Encode returns the compact, URL-safe share code of the encounter.
+// The code is the base64url encoding of a version byte, the binary state
+// and an HMAC-SHA256 of both keyed by key and truncated to 16 bytes,
+// so only whoever holds the key can produce a code that decodes.
+func (s SharedEncounter) Encode(key []byte) (string, error) {
+	if len(key) == 0 {
+		return "", ErrMissingShareKey
+	}
+	if err := s.validate(); err != nil {
+		return "", err
+	}
+
+	var flags byte
+	if s.CountWeakMonsters {
+		flags |= shareFlagCountWeakMonsters
+	}
+
+	buf := []byte{
+		ShareCodeVersion,
+		byte(slices.Index(shareRulesets, s.Ruleset)),
+		byte(slices.Index(shareDifficulties[s.Ruleset], s.Difficulty)),
+		flags,
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(s.Levels)))
+	for _, level := range s.Levels {
+		buf = append(buf, byte(level))
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(s.Monsters)))
+	for _, m := range s.Monsters {
+		buf = binary.AppendUvarint(buf, uint64(len(m.MonsterID)))
+		buf = append(buf, m.MonsterID...)
+		buf = binary.AppendUvarint(buf, uint64(m.Quantity))
+		var monsterFlags byte
+		if m.InLair {
+			monsterFlags |= shareMonsterFlagInLair
+		}
+		buf = append(buf, monsterFlags)
+	}
+	buf = append(buf, shareSignature(key, buf)...)
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// DecodeShareCode parses a share code signed with key, rejecting codes that are malformed,
+// come from another format version or whose signature does not match
+func DecodeShareCode(code string, key []byte) (SharedEncounter, error) {
+	if len(key) == 0 {
+		return SharedEncounter{}, ErrMissingShareKey
+	}
+	if code == "" || len(code) > maxShareCodeLength {
+		return SharedEncounter{}, fmt.Errorf("%w: bad length", ErrInvalidShareCode)
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		return SharedEncounter{}, fmt.Errorf("%w: not base64url", ErrInvalidShareCode)
+	}
+	if len(buf) == 0 {
+		return SharedEncounter{}, fmt.Errorf("%w: too short", ErrInvalidShareCode)
+	}
+
+	// Older versions have a different envelope, so the version is checked before the signature
+	if buf[0] != ShareCodeVersion {
+		return SharedEncounter{}, fmt.Errorf("%w: got %d, want %d", ErrUnsupportedShareVersion, buf[0], ShareCodeVersion)
+	}
+	if len(buf) < 1+shareSignatureSize {
+		return SharedEncounter{}, fmt.Errorf("%w: too short", ErrInvalidShareCode)
+	}
+
+	payload, signature := buf[:len(buf)-shareSignatureSize], buf[len(buf)-shareSignatureSize:]
+	if !hmac.Equal(signature, shareSignature(key, payload)) {
+		return SharedEncounter{}, fmt.Errorf("%w: signature mismatch", ErrInvalidShareCode)
+	}
+
+	s, err := decodeSharePayload(&shareReader{buf: payload[1:]})
+	if err != nil {
+		return SharedEncounter{}, fmt.Errorf("%w: %v", ErrInvalidShareCode, err)
+	}
+	return s, nil
+}
+
+// shareSignature returns the truncated HMAC-SHA256 of a share code payload
+func shareSignature(key, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)[:shareSignatureSize]
+}
+
+func decodeSharePayload(r *shareReader) (SharedEncounter, error) {
+	var s SharedEncounter
+
+	rulesetIndex, difficultyIndex, flags := r.byte(), r.byte(), r.byte()
+	if int(rulesetIndex) >= len(shareRulesets) {
+		return s, fmt.Errorf("unknown ruleset %d", rulesetIndex)
+	}
+	s.Ruleset = shareRulesets[rulesetIndex]
+	if int(difficultyIndex) >= len(shareDifficulties[s.Ruleset]) {
+		return s, fmt.Errorf("unknown difficulty %d", difficultyIndex)
+	}
+	s.Difficulty = shareDifficulties[s.Ruleset][difficultyIndex]
+	if flags&^shareFlagCountWeakMonsters != 0 {
+		return s, fmt.Errorf("unknown flags %#x", flags)
+	}
+	s.CountWeakMonsters = flags&shareFlagCountWeakMonsters != 0
+
+	levelCount := r.uvarint(maxShareCodeLength)
+	s.Levels = make([]int, 0, levelCount)
+	for range levelCount {
+		s.Levels = append(s.Levels, int(r.byte()))
+	}
+
+	monsterCount := r.uvarint(maxShareCodeLength)
+	s.Monsters = make([]SharedMonster, 0, monsterCount)
+	for range monsterCount {
+		id := r.bytes(r.uvarint(maxShareCodeLength))
+		quantity := r.uvarint(MaxGroupQuantity)
+		monsterFlags := r.byte()
+		if monsterFlags&^shareMonsterFlagInLair != 0 {
+			return s, fmt.Errorf("unknown monster flags %#x", monsterFlags)
+		}
+		s.Monsters = append(s.Monsters, SharedMonster{MonsterID: string(id), Quantity: int(quantity), InLair: monsterFlags&shareMonsterFlagInLair != 0})
+	}
+
+	if r.err != nil {
+		return s, r.err
+	}
+	if len(r.buf) > 0 {
+		return s, errors.New("trailing data")
+	}
+	return s, s.validate()
+}
+
+// validate checks the party levels and monster quantities of a shared encounter
+func (s SharedEncounter) validate() error {
+	if _, ok := shareDifficulties[s.Ruleset]; !ok {
+		return fmt.Errorf("invalid ruleset: %s", s.Ruleset)
+	}
+	if _, err := NewDifficulty(s.Difficulty.String(), s.Ruleset); err != nil {
+		return err
+	}
+	if _, err := NewParty(s.Levels); err != nil {
+		return err
+	}
+	for _, m := range s.Monsters {
+		if m.MonsterID == "" {
+			return errors.New("monster ID cannot be empty")
+		}
+		if m.Quantity < 1 || m.Quantity > MaxGroupQuantity {
+			return fmt.Errorf("quantity of %s must be between 1 and %d, got %d", m.MonsterID, MaxGroupQuantity, m.Quantity)
+		}
+	}
+	return nil
+}
+
+// shareReader reads a share code payload, remembering the first error
+type shareReader struct {
+	buf []byte
+	err error
+}
+
+func (r *shareReader) byte() byte {
+	b := r.bytes(1)
+	if len(b) == 0 {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *shareReader) bytes(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > uint64(len(r.buf)) {
+		r.err = errors.New("unexpected end of data")
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+// uvarint reads a varint no larger than limit
+func (r *shareReader) uvarint(limit uint64) uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.err = errors.New("malformed number")
+		return 0
+	}
+	if v > limit {
+		r.err = fmt.Errorf("number %d out of range", v)
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
@@ -0,0 +1,172 @@
+package encounter
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testShareKey = []byte("test share key")
+
+func TestSharedEncounter_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		shared SharedEncounter
+	}{
+		{
+			name: "2024 party without monsters",
+			shared: SharedEncounter{
+				Ruleset:    Ruleset2024,
+				Difficulty: DifficultyModerate,
+				Levels:     []int{5, 5, 5, 5},
+				Monsters:   []SharedMonster{},
+			},
+		},
+		{
+			name: "2014 mixed party with monsters and weak monsters counted",
+			shared: SharedEncounter{
+				Ruleset:           Ruleset2014,
+				Difficulty:        DifficultyDeadly,
+				Levels:            []int{1, 20, 7},
+				Monsters:          []SharedMonster{{MonsterID: "ogre", Quantity: 2}, {MonsterID: "goblin", Quantity: 100}},
+				CountWeakMonsters: true,
+			},
+		},
+		{
+			name: "monster in its lair",
+			shared: SharedEncounter{
+				Ruleset:    Ruleset2024,
+				Difficulty: DifficultyHigh,
+				Levels:     []int{12, 12, 12},
+				Monsters:   []SharedMonster{{MonsterID: "drago-rosso-adulto", Quantity: 1, InLair: true}, {MonsterID: "kobold", Quantity: 6}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := tt.shared.Encode(testShareKey)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.ContainsAny(code, "+/=") {
+				t.Errorf("code %q is not URL-safe", code)
+			}
+
+			decoded, err := DecodeShareCode(code, testShareKey)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.shared) {
+				t.Errorf("expected %+v, got %+v", tt.shared, decoded)
+			}
+		})
+	}
+}
+
+func TestSharedEncounter_EncodeInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		shared SharedEncounter
+	}{
+		{name: "no party", shared: SharedEncounter{Ruleset: Ruleset2024, Difficulty: DifficultyLow}},
+		{name: "difficulty of the other ruleset", shared: SharedEncounter{Ruleset: Ruleset2024, Difficulty: DifficultyHard, Levels: []int{3}}},
+		{name: "zero quantity", shared: SharedEncounter{Ruleset: Ruleset2014, Difficulty: DifficultyHard, Levels: []int{3}, Monsters: []SharedMonster{{MonsterID: "ogre"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.shared.Encode(testShareKey); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestDecodeShareCode_Rejects(t *testing.T) {
+	valid, err := SharedEncounter{
+		Ruleset:    Ruleset2014,
+		Difficulty: DifficultyHard,
+		Levels:     []int{4, 4, 4},
+		Monsters:   []SharedMonster{{MonsterID: "ogre", Quantity: 1}},
+	}.Encode(testShareKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ := base64.RawURLEncoding.DecodeString(valid)
+	payload := raw[:len(raw)-shareSignatureSize]
+
+	// signed re-signs an edited payload with the server key, so only the content checks can reject it
+	signed := func(payload []byte) string {
+		return base64.RawURLEncoding.EncodeToString(append(append([]byte{}, payload...), shareSignature(testShareKey, payload)...))
+	}
+
+	corrupted := []byte(valid)
+	corrupted[6] ^= 1
+
+	// An attacker without the key can only edit the payload and recompute an unkeyed checksum
+	moreOgres := append([]byte{}, payload...)
+	moreOgres[len(moreOgres)-2] = 100
+	withCRC := base64.RawURLEncoding.EncodeToString(binary.BigEndian.AppendUint32(moreOgres, crc32.ChecksumIEEE(moreOgres)))
+	otherKey := base64.RawURLEncoding.EncodeToString(append(append([]byte{}, moreOgres...), shareSignature([]byte("another key"), moreOgres)...))
+
+	oldVersion := append([]byte{ShareCodeVersion - 1}, payload[1:]...)
+	newVersion := append([]byte{ShareCodeVersion + 1}, payload[1:]...)
+
+	badMonsterFlags := append([]byte{}, payload...)
+	badMonsterFlags[len(badMonsterFlags)-1] = 1 << 7
+
+	badDifficulty := append([]byte{}, payload...)
+	badDifficulty[2] = 9
+
+	badLevel := append([]byte{}, payload...)
+	badLevel[5] = 21
+
+	tests := []struct {
+		name     string
+		code     string
+		expected error
+	}{
+		{name: "empty", code: "", expected: ErrInvalidShareCode},
+		{name: "not base64url", code: "abc+/", expected: ErrInvalidShareCode},
+		{name: "too short", code: "Aw", expected: ErrInvalidShareCode},
+		{name: "corrupted", code: string(corrupted), expected: ErrInvalidShareCode},
+		{name: "truncated", code: valid[:len(valid)-2], expected: ErrInvalidShareCode},
+		{name: "edited with recomputed CRC", code: withCRC, expected: ErrInvalidShareCode},
+		{name: "signed with another key", code: otherKey, expected: ErrInvalidShareCode},
+		{name: "old version", code: base64.RawURLEncoding.EncodeToString(binary.BigEndian.AppendUint32(oldVersion, crc32.ChecksumIEEE(oldVersion))), expected: ErrUnsupportedShareVersion},
+		{name: "newer version", code: signed(newVersion), expected: ErrUnsupportedShareVersion},
+		{name: "unknown monster flags", code: signed(badMonsterFlags), expected: ErrInvalidShareCode},
+		{name: "unknown difficulty", code: signed(badDifficulty), expected: ErrInvalidShareCode},
+		{name: "invalid level", code: signed(badLevel), expected: ErrInvalidShareCode},
+		{name: "trailing data", code: signed(append(append([]byte{}, payload...), 0)), expected: ErrInvalidShareCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeShareCode(tt.code, testShareKey)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestShareCode_MissingKey(t *testing.T) {
+	shared := SharedEncounter{Ruleset: Ruleset2024, Difficulty: DifficultyLow, Levels: []int{1}}
+	if _, err := shared.Encode(nil); !errors.Is(err, ErrMissingShareKey) {
+		t.Errorf("expected ErrMissingShareKey, got %v", err)
+	}
+
+	code, err := shared.Encode(testShareKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := DecodeShareCode(code, nil); !errors.Is(err, ErrMissingShareKey) {
+		t.Errorf("expected ErrMissingShareKey, got %v", err)
+	}
+}
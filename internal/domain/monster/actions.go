@@ -0,0 +1,374 @@
+package monster
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Ability identifies an ability by its English key, e.g. "constitution".
+type Ability string
+
+// Abilities.
+const (
+	AbilityStrength     Ability = "strength"
+	AbilityDexterity    Ability = "dexterity"
+	AbilityConstitution Ability = "constitution"
+	AbilityIntelligence Ability = "intelligence"
+	AbilityWisdom       Ability = "wisdom"
+	AbilityCharisma     Ability = "charisma"
+)
+
+// abilityNames maps the Italian name of each ability, as written in the statblocks, to its key.
+var abilityNames = map[string]Ability{
+	"Forza":        AbilityStrength,
+	"Destrezza":    AbilityDexterity,
+	"Costituzione": AbilityConstitution,
+	"Intelligenza": AbilityIntelligence,
+	"Saggezza":     AbilityWisdom,
+	"Carisma":      AbilityCharisma,
+}
+
+// AttackKind tells melee attacks from ranged ones.
+type AttackKind string
+
+// Attack kinds.
+const (
+	AttackMelee         AttackKind = "melee"
+	AttackRanged        AttackKind = "ranged"
+	AttackMeleeOrRanged AttackKind = "melee_or_ranged"
+)
+
+var (
+	// attackRollPattern matches the attack roll of an action, e.g. "Tiro per colpire in mischia: +6".
+	attackRollPattern = regexp.MustCompile(`Tiro per colpire ?(in mischia o a distanza|in mischia|a distanza)\s*:\s*(` + signClass + `)\s*(\d+)`)
+	// distancePattern matches a reach or range, e.g. "portata 1,5 m" or "gittata 9/36 m".
+	distancePattern = regexp.MustCompile(`(portata|gittata)\s+(\d+(?:,\d+)?)(?:\s*/\s*(\d+(?:,\d+)?))?\s*m\b`)
+	// savePattern matches the saving throw of an action, e.g. "Tiro salvezza su Costituzione: CD 14".
+	savePattern = regexp.MustCompile(`Tiro salvezza su (Forza|Destrezza|Costituzione|Intelligenza|Saggezza|Carisma)\s*:\s*CD\s*(\d+)`)
+	// outcomePattern matches the labels introducing the outcomes of an attack or a saving throw.
+	outcomePattern = regexp.MustCompile(`(Colpito o mancato|Colpito|Fallimento o successo|Primo ?fallimento|Secondo ?fallimento|Fallimenti successivi|Fallimento|Successo|Attivazione|Esito)\s*:`)
+	// damageRollPattern matches a damage roll at the start of an outcome, e.g. "13 (2d8 + 4) danni contundenti"
+	// or "1 danno perforante".
+	damageRollPattern = regexp.MustCompile(`^(\d+)(?:\s*\((\d+)d(\d+)(?:\s*(` + signClass + `)\s*(\d+))?\))?\s+dann[oi]\s+(?:da\s+|di\s+)?(\pL+)`)
+	// morePattern matches the "più" joining extra damage to a roll.
+	morePattern = regexp.MustCompile(`^\s*più\s+`)
+	// halfDamagePattern matches a successful save that halves the damage.
+	halfDamagePattern = regexp.MustCompile(`(?i)danni dimezzati|metà dei danni`)
+	// rechargePattern matches a recharge such as "Ricarica 5–6" or "ricarica 6".
+	rechargePattern = regexp.MustCompile(`(?i)^ricarica (\d)(?:\s*[–-]\s*6)?$`)
+	// restPattern matches an action recharging after a rest.
+	restPattern = regexp.MustCompile(`(?i)^ricarica dopo un riposo`)
+	// perDayPattern matches uses per day such as "3/giorno" or "2 al giorno".
+	perDayPattern = regexp.MustCompile(`(?i)^(\d+)\s*(?:/|al)\s*giorno$`)
+	// attackMention and saveMention find the actions ParseAction should understand.
+	attackMention = regexp.MustCompile(`Tiro per colpire ?(?:in mischia|a distanza)`)
+	saveMention   = regexp.MustCompile(`Tiro salvezza su \pL+\s*:`)
+	usageMention  = regexp.MustCompile(`(?i)\((?:[^)]*\b)?(?:ricarica|giorno)\b[^)]*\)`)
+)
+
+// Damage is a damage roll of an action. Dice is zero for fixed damage such as "1 danno perforante".
+type Damage struct {
+	Average int
+	Dice    Dice
+	Type    DamageType
+}
+
+// Attack is the attack roll of an action. Distances are in metres: Reach for melee attacks,
+// Range and LongRange for ranged ones. Damage lists the rolls dealt on a hit, extra damage
+// included; alternatives such as the damage of a two-handed grip are left out.
+type Attack struct {
+	Kind      AttackKind
+	ToHit     int
+	Reach     float64
+	Range     float64
+	LongRange float64
+	Damage    []Damage
+}
+
+// Save is the saving throw of an action. Damage lists the rolls dealt on a failure; the
+// texts keep what happens on a failure, on a success and in either case.
+type Save struct {
+	Ability       Ability
+	DC            int
+	Damage        []Damage
+	HalfOnSuccess bool
+	Failure       string
+	Success       string
+	Either        string
+}
+
+// Usage holds the limits on how often an action can be used. Recharge is the lowest d6 roll
+// recharging it, e.g. 5 for "Ricarica 5–6", and zero when it does not recharge that way.
+type Usage struct {
+	Recharge  int
+	PerDay    int
+	AfterRest bool
+}
+
+// Limited reports whether the action cannot be used at will.
+func (u Usage) Limited() bool {
+	return u.Recharge > 0 || u.PerDay > 0 || u.AfterRest
+}
+
+// Action is the structured content of an action, bonus action, reaction or legendary action.
+// Name drops the notes in parentheses: usage limits go to Usage and the rest, such as
+// "solo in forma di lupo", to Note. Attack and Save are nil when the text has none.
+type Action struct {
+	Name   string
+	Note   string
+	Attack *Attack
+	Save   *Save
+	Usage  Usage
+}
+
+// ActionSet holds the parsed actions of a monster, in the order of the statblock lists,
+// and its Multiattack, nil when it has none.
+type ActionSet struct {
+	Actions          []Action
+	BonusActions     []Action
+	Reactions        []Action
+	LegendaryActions []Action
+	Multiattack      *Multiattack
+}
+
+// ParseActions parses every entry of an action list.
+func ParseActions(entries []NamedDescription) []Action {
+	if len(entries) == 0 {
+		return nil
+	}
+	result := make([]Action, len(entries))
+	for i, e := range entries {
+		result[i] = ParseAction(e)
+	}
+	return result
+}
+
+// ParseAction extracts the attack, saving throw and usage limits of an action written like
+// the Italian statblocks, e.g. "*Tiro per colpire in mischia:* +6, portata 1,5 m. *Colpito:*
+// 13 (2d8 + 4) danni contundenti.". Text it does not recognize is left out.
+func ParseAction(e NamedDescription) Action {
+	a := Action{Name: strings.TrimSpace(e.Name)}
+	if note := notePattern.FindStringSubmatch(a.Name); note != nil {
+		a.Name = note[1]
+		var notes []string
+		for _, part := range strings.Split(note[2], ";") {
+			part = strings.TrimSpace(part)
+			if !a.Usage.parse(part) {
+				notes = append(notes, part)
+			}
+		}
+		a.Note = strings.Join(notes, "; ")
+	}
+
+	text := plainText(e.Description)
+	a.Attack = parseAttack(text)
+	a.Save = parseSave(text)
+	return a
+}
+
+// parse reads a usage note into u, reporting whether it was one.
+func (u *Usage) parse(note string) bool {
+	if parts := rechargePattern.FindStringSubmatch(note); parts != nil {
+		u.Recharge, _ = strconv.Atoi(parts[1])
+		return true
+	}
+	if restPattern.MatchString(note) {
+		u.AfterRest = true
+		return true
+	}
+	if parts := perDayPattern.FindStringSubmatch(note); parts != nil {
+		u.PerDay, _ = strconv.Atoi(parts[1])
+		return true
+	}
+	return false
+}
+
+func parseAttack(text string) *Attack {
+	loc := attackRollPattern.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return nil
+	}
+
+	a := &Attack{ToHit: diceBonus(text[loc[4]:loc[5]], text[loc[6]:loc[7]])}
+	switch text[loc[2]:loc[3]] {
+	case "in mischia":
+		a.Kind = AttackMelee
+	case "a distanza":
+		a.Kind = AttackRanged
+	default:
+		a.Kind = AttackMeleeOrRanged
+	}
+
+	header, outcomes := text[loc[1]:], ""
+	if next := outcomePattern.FindStringIndex(header); next != nil {
+		header, outcomes = header[:next[0]], header[next[0]:]
+	}
+	for _, d := range distancePattern.FindAllStringSubmatch(header, -1) {
+		// A distance with a long range is a range even when the statblock calls it portata
+		if d[1] == "gittata" || d[3] != "" {
+			a.Range, a.LongRange = parseMetres(d[2]), parseMetres(d[3])
+		} else {
+			a.Reach = parseMetres(d[2])
+		}
+	}
+
+	a.Damage = parseDamage(outcome(outcomes, "Colpito"))
+	return a
+}
+
+func parseSave(text string) *Save {
+	loc := savePattern.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return nil
+	}
+
+	s := &Save{Ability: abilityNames[text[loc[2]:loc[3]]]}
+	s.DC, _ = strconv.Atoi(text[loc[4]:loc[5]])
+
+	outcomes := text[loc[1]:]
+	s.Failure = outcome(outcomes, "Fallimento")
+	if s.Failure == "" {
+		s.Failure = outcome(outcomes, "Primo fallimento")
+	}
+	s.Success = outcome(outcomes, "Successo")
+	s.Either = outcome(outcomes, "Fallimento o successo")
+	s.Damage = parseDamage(s.Failure)
+	s.HalfOnSuccess = halfDamagePattern.MatchString(s.Success)
+	return s
+}
+
+// outcome returns the text following the first outcome label of text, up to the next label.
+// Labels written without their space, such as "Primofallimento", match too.
+func outcome(text, label string) string {
+	for _, loc := range outcomePattern.FindAllStringSubmatchIndex(text, -1) {
+		found := text[loc[2]:loc[3]]
+		if found != label && strings.Replace(label, " ", "", 1) != found {
+			continue
+		}
+		rest := text[loc[1]:]
+		if next := outcomePattern.FindStringIndex(rest); next != nil {
+			rest = rest[:next[0]]
+		}
+		return strings.TrimSpace(rest)
+	}
+	return ""
+}
+
+// parseDamage reads the damage rolls an outcome starts with, joined by "più".
+func parseDamage(text string) []Damage {
+	var result []Damage
+	for {
+		parts := damageRollPattern.FindStringSubmatch(text)
+		if parts == nil {
+			return result
+		}
+		t, ok := damageTypeOf(parts[6])
+		if !ok {
+			return result
+		}
+
+		d := Damage{Type: t}
+		d.Average, _ = strconv.Atoi(parts[1])
+		if parts[2] != "" {
+			d.Dice.Count, _ = strconv.Atoi(parts[2])
+			d.Dice.Sides, _ = strconv.Atoi(parts[3])
+			d.Dice.Bonus = diceBonus(parts[4], parts[5])
+		}
+		result = append(result, d)
+
+		more := morePattern.FindStringIndex(text[len(parts[0]):])
+		if more == nil {
+			return result
+		}
+		text = text[len(parts[0])+more[1]:]
+	}
+}
+
+// damageTypeOf reads a damage type in the singular or plural, e.g. "perforanti" or "fuoco".
+func damageTypeOf(word string) (DamageType, bool) {
+	word = strings.ToLower(word)
+	for _, d := range damageTypeNames {
+		stem := d.name[:len(d.name)-1]
+		if strings.HasPrefix(word, stem) && len(word) == len(d.name) {
+			return d.damage, true
+		}
+	}
+	return "", false
+}
+
+// plainText drops the emphasis and soft hyphens of a description and collapses its spaces,
+// joining labels split by the markup such as "*Suc**cesso:*".
+func plainText(s string) string {
+	s = strings.NewReplacer("*", "", "­", "").Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// ActionCoverage reports how many actions of a set of monsters mention an attack roll, a
+// saving throw or a usage limit, and how many of those ParseAction understood.
+type ActionCoverage struct {
+	Monsters      int            `json:"monsters"`
+	Actions       int            `json:"actions"`
+	Attacks       int            `json:"attacks"`
+	ParsedAttacks int            `json:"parsed_attacks"`
+	Saves         int            `json:"saves"`
+	ParsedSaves   int            `json:"parsed_saves"`
+	Usages        int            `json:"usages"`
+	ParsedUsages  int            `json:"parsed_usages"`
+	Missed        []MissedAction `json:"missed"`
+}
+
+// MissedAction is an action whose attack, saving throw or usage limit was not understood.
+type MissedAction struct {
+	MonsterID string `json:"monster_id"`
+	Action    string `json:"action"`
+	Missing   string `json:"missing"`
+}
+
+// NewActionCoverage parses the actions, bonus actions, reactions and legendary actions of
+// the monsters and counts what was understood.
+func NewActionCoverage(monsters []Monster) ActionCoverage {
+	c := ActionCoverage{Monsters: len(monsters), Missed: []MissedAction{}}
+	for _, m := range monsters {
+		for _, list := range [][]NamedDescription{m.Actions, m.BonusActions, m.Reactions, m.LegendaryActions} {
+			for _, e := range list {
+				c.add(m.ID, e)
+			}
+		}
+	}
+	return c
+}
+
+func (c *ActionCoverage) add(monsterID string, e NamedDescription) {
+	c.Actions++
+	a := ParseAction(e)
+	text := plainText(e.Description)
+	miss := func(what string) {
+		c.Missed = append(c.Missed, MissedAction{MonsterID: monsterID, Action: e.Name, Missing: what})
+	}
+
+	if attackMention.MatchString(text) {
+		c.Attacks++
+		if a.Attack != nil {
+			c.ParsedAttacks++
+		} else {
+			miss("attack")
+		}
+	}
+	if saveMention.MatchString(text) {
+		c.Saves++
+		if a.Save != nil {
+			c.ParsedSaves++
+		} else {
+			miss("save")
+		}
+	}
+	if usageMention.MatchString(e.Name) {
+		c.Usages++
+		if a.Usage.Limited() {
+			c.ParsedUsages++
+		} else {
+			miss("usage")
+		}
+	}
+}
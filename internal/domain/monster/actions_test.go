@@ -0,0 +1,164 @@
+package monster
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAction_Attack(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    NamedDescription
+		expected Attack
+	}{
+		{
+			name:  "melee or ranged",
+			entry: NamedDescription{Name: "Giavellotto", Description: "*Tiro per colpire in mischia o a distanza:* +6,  portata 1,5 m o gittata 9/36 m. *Colpito:* 11 (2d6 + 4)  danni perforanti."},
+			expected: Attack{
+				Kind: AttackMeleeOrRanged, ToHit: 6, Reach: 1.5, Range: 9, LongRange: 36,
+				Damage: []Damage{{Average: 11, Dice: Dice{Count: 2, Sides: 6, Bonus: 4}, Type: DamagePiercing}},
+			},
+		},
+		{
+			name:  "extra damage",
+			entry: NamedDescription{Name: "Squarcio", Description: "*Tiro per colpire in mischia:*+14, portata 3 m Colpito:13 (1d10 + 8) danni taglienti più 5 (2d4) danni da fuoco."},
+			expected: Attack{
+				Kind: AttackMelee, ToHit: 14, Reach: 3,
+				Damage: []Damage{
+					{Average: 13, Dice: Dice{Count: 1, Sides: 10, Bonus: 8}, Type: DamageSlashing},
+					{Average: 5, Dice: Dice{Count: 2, Sides: 4}, Type: DamageFire},
+				},
+			},
+		},
+		{
+			name:  "alternative damage is left out",
+			entry: NamedDescription{Name: "Morso", Description: "*Tiro per colpire in mischia:* +7, portata 1,5 m. *Colpito:* 11 (2d6 + 4) danni perforanti, o 18 (4d6 + 4) danni perforanti se la chimera dispone di vantaggio."},
+			expected: Attack{
+				Kind: AttackMelee, ToHit: 7, Reach: 1.5,
+				Damage: []Damage{{Average: 11, Dice: Dice{Count: 2, Sides: 6, Bonus: 4}, Type: DamagePiercing}},
+			},
+		},
+		{
+			name:  "ranged written as portata, fixed damage",
+			entry: NamedDescription{Name: "Arco", Description: "*Tiro per colpire a distanza:* −1, portata 24/96 m. *Colpito:* 1 danno perforante."},
+			expected: Attack{
+				Kind: AttackRanged, ToHit: -1, Range: 24, LongRange: 96,
+				Damage: []Damage{{Average: 1, Type: DamagePiercing}},
+			},
+		},
+		{
+			name:     "no damage",
+			entry:    NamedDescription{Name: "Tentacolo", Description: "*Tiro per colpire in mischia:* +6, portata 3 m. *Colpito:* il bersaglio è afferrato (CD 14 per sfuggire)."},
+			expected: Attack{Kind: AttackMelee, ToHit: 6, Reach: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := ParseAction(tt.entry)
+			if a.Attack == nil {
+				t.Fatal("expected an attack")
+			}
+			if !reflect.DeepEqual(*a.Attack, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, *a.Attack)
+			}
+			if a.Save != nil {
+				t.Errorf("expected no saving throw, got %+v", *a.Save)
+			}
+		})
+	}
+}
+
+func TestParseAction_Save(t *testing.T) {
+	a := ParseAction(NamedDescription{
+		Name:        "Consuma ricordi",
+		Description: "*Tiro salvezza su Intelligenza:* CD 16,  una creatura entro 9 metri. *Fallimento:* 10 (3d6) danni psichici. *Suc**cesso:* danni dimezzati. *Fallimento o successo:*l'aboleth  acquisisce i ricordi del bersaglio.",
+	})
+	expected := Save{
+		Ability:       AbilityIntelligence,
+		DC:            16,
+		Damage:        []Damage{{Average: 10, Dice: Dice{Count: 3, Sides: 6}, Type: DamagePsychic}},
+		HalfOnSuccess: true,
+		Failure:       "10 (3d6) danni psichici.",
+		Success:       "danni dimezzati.",
+		Either:        "l'aboleth acquisisce i ricordi del bersaglio.",
+	}
+	if a.Save == nil || !reflect.DeepEqual(*a.Save, expected) {
+		t.Errorf("expected %+v, got %+v", expected, a.Save)
+	}
+	if a.Attack != nil {
+		t.Errorf("expected no attack, got %+v", *a.Attack)
+	}
+
+	// Split labels, an effect without damage and staged failures
+	a = ParseAction(NamedDescription{
+		Name:        "Sguardo pietrificante",
+		Description: "*Tiro salvezza su Costitu**zione:*CD 12. *Primo**fallimento:* il bersaglio è trattenuto. *Secondo fallimento:* il bersaglio è pietrificato.",
+	})
+	if a.Save == nil || a.Save.Ability != AbilityConstitution || a.Save.DC != 12 || a.Save.Failure != "il bersaglio è trattenuto." || len(a.Save.Damage) != 0 || a.Save.HalfOnSuccess {
+		t.Errorf("unexpected save %+v", a.Save)
+	}
+}
+
+func TestParseAction_Usage(t *testing.T) {
+	tests := []struct {
+		name         string
+		expectedName string
+		expectedNote string
+		expected     Usage
+	}{
+		{"Soffio di fuoco (Ricarica 5–6)", "Soffio di fuoco", "", Usage{Recharge: 5}},
+		{"Soffio velenoso (ricarica 6)", "Soffio velenoso", "", Usage{Recharge: 6}},
+		{"Domina mente (2/giorno)", "Domina mente", "", Usage{PerDay: 2}},
+		{"Teletrasporto (1/Giorno)", "Teletrasporto", "", Usage{PerDay: 1}},
+		{"Ruggito (2 al giorno)", "Ruggito", "", Usage{PerDay: 2}},
+		{"Avvolgere (ricarica dopo un riposo breve o lungo)", "Avvolgere", "", Usage{AfterRest: true}},
+		{"Risucchio (1/giorno; richiede Borsa dell'anima)", "Risucchio", "richiede Borsa dell'anima", Usage{PerDay: 1}},
+		{"Morso (solo in forma di lupo o ibrida)", "Morso", "solo in forma di lupo o ibrida", Usage{}},
+		{"Artiglio", "Artiglio", "", Usage{}},
+	}
+
+	for _, tt := range tests {
+		a := ParseAction(NamedDescription{Name: tt.name})
+		if a.Name != tt.expectedName || a.Note != tt.expectedNote || a.Usage != tt.expected {
+			t.Errorf("%q: expected %q, %q, %+v, got %q, %q, %+v", tt.name, tt.expectedName, tt.expectedNote, tt.expected, a.Name, a.Note, a.Usage)
+		}
+		if a.Usage.Limited() != (tt.expected != Usage{}) {
+			t.Errorf("%q: unexpected Limited() %v", tt.name, a.Usage.Limited())
+		}
+	}
+}
+
+func TestNewActionCoverage(t *testing.T) {
+	monsters := []Monster{
+		{
+			ID: "known",
+			Actions: []NamedDescription{
+				{Name: "Randello", Description: "*Tiro per colpire in mischia:* +6, portata 1,5 m. *Colpito:* 13 (2d8 + 4) danni contundenti."},
+				{Name: "Soffio (Ricarica 5–6)", Description: "*Tiro salvezza su Destrezza:* CD 13. *Fallimento:* 21 (6d6) danni da fuoco."},
+			},
+			LegendaryActions: []NamedDescription{{Name: "Sferzata", Description: "Il mostro effettua un attacco Randello."}},
+		},
+		{
+			ID: "odd",
+			BonusActions: []NamedDescription{
+				{Name: "Morso (ricarica quando sanguinante)", Description: "*Tiro per colpire in mischia:* sei, portata 1,5 m."},
+			},
+		},
+	}
+
+	c := NewActionCoverage(monsters)
+	if c.Monsters != 2 || c.Actions != 4 {
+		t.Errorf("expected 2 monsters and 4 actions, got %d and %d", c.Monsters, c.Actions)
+	}
+	if c.Attacks != 2 || c.ParsedAttacks != 1 || c.Saves != 1 || c.ParsedSaves != 1 || c.Usages != 2 || c.ParsedUsages != 1 {
+		t.Errorf("unexpected counts %+v", c)
+	}
+	expected := []MissedAction{
+		{MonsterID: "odd", Action: "Morso (ricarica quando sanguinante)", Missing: "attack"},
+		{MonsterID: "odd", Action: "Morso (ricarica quando sanguinante)", Missing: "usage"},
+	}
+	if !reflect.DeepEqual(c.Missed, expected) {
+		t.Errorf("expected missed %+v, got %+v", expected, c.Missed)
+	}
+}
@@ -0,0 +1,189 @@
+package monster
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidStats is returned when homebrew statistics cannot be rated.
+var ErrInvalidStats = errors.New("invalid monster statistics")
+
+// challengeRow is one row of the DMG "Monster Statistics by Challenge Rating" table.
+type challengeRow struct {
+	CR               string
+	XP               int
+	ProficiencyBonus int
+	AC               int
+	MaxHP            int
+	AttackBonus      int
+	MaxDPR           int
+	SaveDC           int
+}
+
+// challengeTable lists every challenge rating in ascending order.
+// A row covers HP and damage per round from the previous row's maximum + 1.
+var challengeTable = []challengeRow{
+	{"0", 10, 2, 13, 6, 3, 1, 13},
+	{"1/8", 25, 2, 13, 35, 3, 3, 13},
+	{"1/4", 50, 2, 13, 49, 3, 5, 13},
+	{"1/2", 100, 2, 13, 70, 3, 8, 13},
+	{"1", 200, 2, 13, 85, 3, 14, 13},
+	{"2", 450, 2, 13, 100, 3, 20, 13},
+	{"3", 700, 2, 13, 115, 4, 26, 13},
+	{"4", 1100, 2, 14, 130, 5, 32, 14},
+	{"5", 1800, 3, 15, 145, 6, 38, 15},
+	{"6", 2300, 3, 15, 160, 6, 44, 15},
+	{"7", 2900, 3, 15, 175, 6, 50, 15},
+	{"8", 3900, 3, 16, 190, 7, 56, 16},
+	{"9", 5000, 4, 16, 205, 7, 62, 16},
+	{"10", 5900, 4, 17, 220, 7, 68, 16},
+	{"11", 7200, 4, 17, 235, 8, 74, 17},
+	{"12", 8400, 4, 17, 250, 8, 80, 17},
+	{"13", 10000, 5, 18, 265, 8, 86, 18},
+	{"14", 11500, 5, 18, 280, 8, 92, 18},
+	{"15", 13000, 5, 18, 295, 8, 98, 18},
+	{"16", 15000, 5, 18, 310, 9, 104, 18},
+	{"17", 18000, 6, 19, 325, 10, 110, 19},
+	{"18", 20000, 6, 19, 340, 10, 116, 19},
+	{"19", 22000, 6, 19, 355, 10, 122, 19},
+	{"20", 25000, 6, 19, 400, 10, 140, 19},
+	{"21", 33000, 7, 19, 445, 11, 158, 20},
+	{"22", 41000, 7, 19, 490, 11, 176, 20},
+	{"23", 50000, 7, 19, 535, 11, 194, 20},
+	{"24", 62000, 7, 19, 580, 12, 212, 21},
+	{"25", 75000, 8, 19, 625, 12, 230, 21},
+	{"26", 90000, 8, 19, 670, 12, 248, 21},
+	{"27", 105000, 8, 19, 715, 13, 266, 22},
+	{"28", 120000, 8, 19, 760, 13, 284, 22},
+	{"29", 135000, 9, 19, 805, 13, 302, 22},
+	{"30", 155000, 9, 19, 850, 14, 320, 23},
+}
+
+// HomebrewStats holds the combat statistics used to rate a custom monster.
+// Either AttackBonus or SaveDC must be set; when both are set the one giving
+// the higher offensive challenge rating is used.
+type HomebrewStats struct {
+	HP             int
+	AC             int
+	AttackBonus    *int
+	SaveDC         *int
+	DamagePerRound int
+}
+
+// Challenge is the rating of a homebrew monster.
+// CR and XP have the same format as the fields of Monster.
+type Challenge struct {
+	DefensiveCR      string `json:"defensive_cr"`
+	OffensiveCR      string `json:"offensive_cr"`
+	CR               string `json:"cr"`
+	XP               int    `json:"xp"`
+	ProficiencyBonus int    `json:"proficiency_bonus"`
+}
+
+// CalculateChallenge rates a homebrew monster following the DMG "Creating a Monster" procedure.
+// Defensive CR comes from HP and is adjusted by one step for every 2 points AC differs from
+// the expected AC; offensive CR comes from damage per round and is adjusted the same way by
+// attack bonus or save DC. The final CR is the average of the two.
+func CalculateChallenge(stats HomebrewStats) (Challenge, error) {
+	if err := stats.validate(); err != nil {
+		return Challenge{}, err
+	}
+
+	defensive := hpRow(stats.HP)
+	defensive = clampRow(defensive + stepAdjustment(stats.AC, challengeTable[defensive].AC))
+
+	dprIndex := dprRow(stats.DamagePerRound)
+	offensive := -1
+	if stats.AttackBonus != nil {
+		offensive = clampRow(dprIndex + stepAdjustment(*stats.AttackBonus, challengeTable[dprIndex].AttackBonus))
+	}
+	if stats.SaveDC != nil {
+		offensive = max(offensive, clampRow(dprIndex+stepAdjustment(*stats.SaveDC, challengeTable[dprIndex].SaveDC)))
+	}
+
+	average := (ParseCR(challengeTable[defensive].CR) + ParseCR(challengeTable[offensive].CR)) / 2
+	final := challengeTable[nearestRow(average)]
+
+	return Challenge{
+		DefensiveCR:      challengeTable[defensive].CR,
+		OffensiveCR:      challengeTable[offensive].CR,
+		CR:               final.CR,
+		XP:               final.XP,
+		ProficiencyBonus: final.ProficiencyBonus,
+	}, nil
+}
+
+// XPForCR returns the experience points of a challenge rating.
+func XPForCR(cr string) (int, bool) {
+	for _, row := range challengeTable {
+		if row.CR == cr {
+			return row.XP, true
+		}
+	}
+	return 0, false
+}
+
+// ProficiencyBonusForCR returns the proficiency bonus of a challenge rating.
+func ProficiencyBonusForCR(cr string) (int, bool) {
+	for _, row := range challengeTable {
+		if row.CR == cr {
+			return row.ProficiencyBonus, true
+		}
+	}
+	return 0, false
+}
+
+func (s HomebrewStats) validate() error {
+	switch {
+	case s.HP < 1:
+		return fmt.Errorf("%w: HP must be at least 1, got %d", ErrInvalidStats, s.HP)
+	case s.AC < 1:
+		return fmt.Errorf("%w: AC must be at least 1, got %d", ErrInvalidStats, s.AC)
+	case s.DamagePerRound < 0:
+		return fmt.Errorf("%w: damage per round cannot be negative, got %d", ErrInvalidStats, s.DamagePerRound)
+	case s.AttackBonus == nil && s.SaveDC == nil:
+		return fmt.Errorf("%w: attack bonus or save DC is required", ErrInvalidStats)
+	}
+	return nil
+}
+
+// hpRow returns the index of the row whose HP range contains hp.
+func hpRow(hp int) int {
+	for i, row := range challengeTable {
+		if hp <= row.MaxHP {
+			return i
+		}
+	}
+	return len(challengeTable) - 1
+}
+
+// dprRow returns the index of the row whose damage range contains dpr.
+func dprRow(dpr int) int {
+	for i, row := range challengeTable {
+		if dpr <= row.MaxDPR {
+			return i
+		}
+	}
+	return len(challengeTable) - 1
+}
+
+// stepAdjustment returns one CR step for every full 2 points actual differs from expected.
+func stepAdjustment(actual, expected int) int {
+	return (actual - expected) / 2
+}
+
+func clampRow(i int) int {
+	return min(max(i, 0), len(challengeTable)-1)
+}
+
+// nearestRow returns the index of the CR closest to cr, rounding ties up.
+func nearestRow(cr float64) int {
+	best := 0
+	for i, row := range challengeTable {
+		if math.Abs(ParseCR(row.CR)-cr) <= math.Abs(ParseCR(challengeTable[best].CR)-cr) {
+			best = i
+		}
+	}
+	return best
+}
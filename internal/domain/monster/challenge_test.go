@@ -0,0 +1,111 @@
+package monster
+
+import (
+	"errors"
+	"testing"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestCalculateChallenge(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    HomebrewStats
+		expected Challenge
+	}{
+		{
+			name:     "stats matching the CR 5 row",
+			stats:    HomebrewStats{HP: 135, AC: 15, AttackBonus: intPtr(6), DamagePerRound: 35},
+			expected: Challenge{DefensiveCR: "5", OffensiveCR: "5", CR: "5", XP: 1800, ProficiencyBonus: 3},
+		},
+		{
+			name:     "high AC raises defensive CR by one step every 2 points",
+			stats:    HomebrewStats{HP: 40, AC: 17, AttackBonus: intPtr(3), DamagePerRound: 4},
+			expected: Challenge{DefensiveCR: "1", OffensiveCR: "1/4", CR: "1/2", XP: 100, ProficiencyBonus: 2},
+		},
+		{
+			name:     "low AC lowers defensive CR and ties round up",
+			stats:    HomebrewStats{HP: 100, AC: 11, AttackBonus: intPtr(3), DamagePerRound: 15},
+			expected: Challenge{DefensiveCR: "1", OffensiveCR: "2", CR: "2", XP: 450, ProficiencyBonus: 2},
+		},
+		{
+			name:     "save DC adjusts offensive CR",
+			stats:    HomebrewStats{HP: 200, AC: 16, SaveDC: intPtr(20), DamagePerRound: 60},
+			expected: Challenge{DefensiveCR: "9", OffensiveCR: "11", CR: "10", XP: 5900, ProficiencyBonus: 4},
+		},
+		{
+			name:     "the stronger of attack bonus and save DC is used",
+			stats:    HomebrewStats{HP: 200, AC: 16, AttackBonus: intPtr(3), SaveDC: intPtr(20), DamagePerRound: 60},
+			expected: Challenge{DefensiveCR: "9", OffensiveCR: "11", CR: "10", XP: 5900, ProficiencyBonus: 4},
+		},
+		{
+			name:     "stats beyond the table are clamped to CR 30",
+			stats:    HomebrewStats{HP: 1000, AC: 25, AttackBonus: intPtr(20), DamagePerRound: 400},
+			expected: Challenge{DefensiveCR: "30", OffensiveCR: "30", CR: "30", XP: 155000, ProficiencyBonus: 9},
+		},
+		{
+			name:     "weak stats are clamped to CR 0",
+			stats:    HomebrewStats{HP: 3, AC: 8, AttackBonus: intPtr(0), DamagePerRound: 0},
+			expected: Challenge{DefensiveCR: "0", OffensiveCR: "0", CR: "0", XP: 10, ProficiencyBonus: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CalculateChallenge(tt.stats)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCalculateChallengeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats HomebrewStats
+	}{
+		{name: "zero HP", stats: HomebrewStats{HP: 0, AC: 12, AttackBonus: intPtr(3), DamagePerRound: 5}},
+		{name: "zero AC", stats: HomebrewStats{HP: 10, AC: 0, AttackBonus: intPtr(3), DamagePerRound: 5}},
+		{name: "negative damage", stats: HomebrewStats{HP: 10, AC: 12, AttackBonus: intPtr(3), DamagePerRound: -1}},
+		{name: "no attack bonus or save DC", stats: HomebrewStats{HP: 10, AC: 12, DamagePerRound: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CalculateChallenge(tt.stats)
+			if !errors.Is(err, ErrInvalidStats) {
+				t.Errorf("expected ErrInvalidStats, got %v", err)
+			}
+		})
+	}
+}
+
+func TestXPForCR(t *testing.T) {
+	tests := []struct {
+		cr       string
+		expected int
+		ok       bool
+	}{
+		{"0", 10, true},
+		{"1/8", 25, true},
+		{"1/2", 100, true},
+		{"17", 18000, true},
+		{"30", 155000, true},
+		{"31", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cr, func(t *testing.T) {
+			xp, ok := XPForCR(tt.cr)
+			if xp != tt.expected || ok != tt.ok {
+				t.Errorf("XPForCR(%q) = %d, %v, expected %d, %v", tt.cr, xp, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package monster
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DamageType identifies a damage type by its English key, e.g. "fire".
+type DamageType string
+
+// Damage types.
+const (
+	DamageAcid        DamageType = "acid"
+	DamageBludgeoning DamageType = "bludgeoning"
+	DamageCold        DamageType = "cold"
+	DamageFire        DamageType = "fire"
+	DamageForce       DamageType = "force"
+	DamageLightning   DamageType = "lightning"
+	DamageNecrotic    DamageType = "necrotic"
+	DamagePiercing    DamageType = "piercing"
+	DamagePoison      DamageType = "poison"
+	DamagePsychic     DamageType = "psychic"
+	DamageRadiant     DamageType = "radiant"
+	DamageSlashing    DamageType = "slashing"
+	DamageThunder     DamageType = "thunder"
+
+	// DamageChosen stands for a damage type picked when the monster is created,
+	// such as the one of a draconic origin. The qualifier keeps the statblock text.
+	DamageChosen DamageType = "chosen"
+)
+
+// Condition identifies a condition by its English key, e.g. "charmed".
+type Condition string
+
+// Conditions.
+const (
+	ConditionBlinded       Condition = "blinded"
+	ConditionCharmed       Condition = "charmed"
+	ConditionDeafened      Condition = "deafened"
+	ConditionExhaustion    Condition = "exhaustion"
+	ConditionFrightened    Condition = "frightened"
+	ConditionGrappled      Condition = "grappled"
+	ConditionIncapacitated Condition = "incapacitated"
+	ConditionInvisible     Condition = "invisible"
+	ConditionParalyzed     Condition = "paralyzed"
+	ConditionPetrified     Condition = "petrified"
+	ConditionPoisoned      Condition = "poisoned"
+	ConditionProne         Condition = "prone"
+	ConditionRestrained    Condition = "restrained"
+	ConditionStunned       Condition = "stunned"
+	ConditionUnconscious   Condition = "unconscious"
+)
+
+// damageTypeName pairs a damage type with its Italian name.
+type damageTypeName struct {
+	name   string
+	damage DamageType
+}
+
+// damageTypeNames lists the Italian name of each damage type, in alphabetical order of the name.
+var damageTypeNames = []damageTypeName{
+	{"acido", DamageAcid},
+	{"contundente", DamageBludgeoning},
+	{"forza", DamageForce},
+	{"freddo", DamageCold},
+	{"fulmine", DamageLightning},
+	{"fuoco", DamageFire},
+	{"necrotico", DamageNecrotic},
+	{"perforante", DamagePiercing},
+	{"psichico", DamagePsychic},
+	{"radioso", DamageRadiant},
+	{"tagliente", DamageSlashing},
+	{"tuono", DamageThunder},
+	{"veleno", DamagePoison},
+}
+
+// conditionName pairs a condition with its Italian name.
+type conditionName struct {
+	name      string
+	condition Condition
+}
+
+// conditionNames lists the Italian name of each condition, in alphabetical order of the name.
+var conditionNames = []conditionName{
+	{"accecato", ConditionBlinded},
+	{"affascinato", ConditionCharmed},
+	{"afferrato", ConditionGrappled},
+	{"assordato", ConditionDeafened},
+	{"avvelenato", ConditionPoisoned},
+	{"incapacitato", ConditionIncapacitated},
+	{"indebolimento", ConditionExhaustion},
+	{"invisibile", ConditionInvisible},
+	{"paralizzato", ConditionParalyzed},
+	{"pietrificato", ConditionPetrified},
+	{"privo di sensi", ConditionUnconscious},
+	{"prono", ConditionProne},
+	{"spaventato", ConditionFrightened},
+	{"stordito", ConditionStunned},
+	{"trattenuto", ConditionRestrained},
+}
+
+var (
+	// chosenDamagePattern matches resistances to a damage type picked elsewhere in the statblock.
+	chosenDamagePattern = regexp.MustCompile(`(?i)^il tipo di danno scelto\b`)
+	// defenseSeparator splits "contundente, perforante e tagliente" into its terms.
+	defenseSeparator = regexp.MustCompile(`,\s*| e `)
+)
+
+// DamageTypes returns every damage type, in alphabetical order of the Italian name.
+func DamageTypes() []DamageType {
+	result := make([]DamageType, len(damageTypeNames))
+	for i, d := range damageTypeNames {
+		result[i] = d.damage
+	}
+	return result
+}
+
+// Conditions returns every condition, in alphabetical order of the Italian name.
+func Conditions() []Condition {
+	result := make([]Condition, len(conditionNames))
+	for i, c := range conditionNames {
+		result[i] = c.condition
+	}
+	return result
+}
+
+// Label returns the Italian name of the damage type.
+func (d DamageType) Label() string {
+	for _, n := range damageTypeNames {
+		if n.damage == d {
+			return n.name
+		}
+	}
+	return string(d)
+}
+
+// Label returns the Italian name of the condition.
+func (c Condition) Label() string {
+	for _, n := range conditionNames {
+		if n.condition == c {
+			return n.name
+		}
+	}
+	return string(c)
+}
+
+// DamageDefense is a damage type a monster resists or is immune to. Qualifier restricts it,
+// e.g. "da attacchi non magici", and is empty when the defense always applies.
+type DamageDefense struct {
+	Type      DamageType
+	Qualifier string
+}
+
+// ConditionImmunity is a condition a monster cannot suffer. Qualifier restricts it,
+// e.g. "tranne che dal suo padrone vampiro".
+type ConditionImmunity struct {
+	Condition Condition
+	Qualifier string
+}
+
+// Defenses holds the typed damage resistances, damage immunities and condition immunities of a monster.
+type Defenses struct {
+	Resistances         []DamageDefense
+	DamageImmunities    []DamageDefense
+	ConditionImmunities []ConditionImmunity
+}
+
+// Resists reports whether the monster resists damage of type t, even only under a qualifier.
+func (d Defenses) Resists(t DamageType) bool {
+	return slices.ContainsFunc(d.Resistances, func(r DamageDefense) bool { return r.Type == t })
+}
+
+// ImmuneTo reports whether the monster is immune to damage of type t, even only under a qualifier.
+func (d Defenses) ImmuneTo(t DamageType) bool {
+	return slices.ContainsFunc(d.DamageImmunities, func(r DamageDefense) bool { return r.Type == t })
+}
+
+// ImmuneToCondition reports whether the monster is immune to condition c, even only under a qualifier.
+func (d Defenses) ImmuneToCondition(c Condition) bool {
+	return slices.ContainsFunc(d.ConditionImmunities, func(i ConditionImmunity) bool { return i.Condition == c })
+}
+
+// ParseDamageDefenses parses resistances or immunities such as "freddo, fuoco" or
+// "contundente, perforante e tagliente da attacchi non magici". Groups separated by
+// semicolons carry their own qualifier.
+func ParseDamageDefenses(s string) ([]DamageDefense, error) {
+	if chosenDamagePattern.MatchString(strings.TrimSpace(s)) {
+		return []DamageDefense{{Type: DamageChosen, Qualifier: strings.TrimSpace(s)}}, nil
+	}
+
+	names := make([]string, len(damageTypeNames))
+	for i, d := range damageTypeNames {
+		names[i] = d.name
+	}
+	terms, err := splitDefenses(s, names)
+	if err != nil {
+		return nil, fmt.Errorf("%w: damage types %q", err, s)
+	}
+
+	var result []DamageDefense
+	for _, t := range terms {
+		i := slices.Index(names, t.name)
+		result = append(result, DamageDefense{Type: damageTypeNames[i].damage, Qualifier: t.qualifier})
+	}
+	return result, nil
+}
+
+// ParseConditionImmunities parses condition immunities such as
+// "affascinato (tranne che dal suo padrone vampiro), spaventato".
+func ParseConditionImmunities(s string) ([]ConditionImmunity, error) {
+	names := make([]string, len(conditionNames))
+	for i, c := range conditionNames {
+		names[i] = c.name
+	}
+	terms, err := splitDefenses(s, names)
+	if err != nil {
+		return nil, fmt.Errorf("%w: conditions %q", err, s)
+	}
+
+	var result []ConditionImmunity
+	for _, t := range terms {
+		i := slices.Index(names, t.name)
+		result = append(result, ConditionImmunity{Condition: conditionNames[i].condition, Qualifier: t.qualifier})
+	}
+	return result, nil
+}
+
+// ParseDamageTypes reads a comma separated list of damage types given by key or by Italian name,
+// such as "fire, freddo".
+func ParseDamageTypes(list string) ([]DamageType, error) {
+	var result []DamageType
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		i := slices.IndexFunc(damageTypeNames, func(d damageTypeName) bool {
+			return d.name == entry || string(d.damage) == entry
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("%w: damage type %q", ErrUnparsable, entry)
+		}
+		result = append(result, damageTypeNames[i].damage)
+	}
+	return result, nil
+}
+
+// ParseConditions reads a comma separated list of conditions given by key or by Italian name,
+// such as "charmed, spaventato".
+func ParseConditions(list string) ([]Condition, error) {
+	var result []Condition
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		i := slices.IndexFunc(conditionNames, func(c conditionName) bool {
+			return c.name == entry || string(c.condition) == entry
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("%w: condition %q", ErrUnparsable, entry)
+		}
+		result = append(result, conditionNames[i].condition)
+	}
+	return result, nil
+}
+
+// defenseTerm is one known name of a defense list and the qualifier restricting it.
+type defenseTerm struct {
+	name      string
+	qualifier string
+}
+
+// splitDefenses splits a defense list into known names. A note in parentheses qualifies its
+// own term; text following the last name of a group, such as "da attacchi non magici",
+// qualifies every term of the group.
+func splitDefenses(s string, names []string) ([]defenseTerm, error) {
+	var result []defenseTerm
+	for _, group := range strings.Split(s, ";") {
+		group = strings.TrimSpace(group)
+		// A piece ends at a separator followed by a name; other separators belong to a qualifier
+		var pieces []string
+		start := 0
+		for _, sep := range append(defenseSeparator.FindAllStringIndex(group, -1), []int{len(group), len(group)}) {
+			if sep[0] == len(group) || knownPrefix(group[sep[1]:], names) != "" {
+				pieces = append(pieces, group[start:sep[0]])
+				start = sep[1]
+			}
+		}
+
+		var terms []defenseTerm
+		groupQualifier := ""
+		for _, piece := range pieces {
+			if piece == "" {
+				continue
+			}
+			lower := strings.ToLower(piece)
+			name := knownPrefix(lower, names)
+			if name == "" {
+				return nil, ErrUnparsable
+			}
+			term := defenseTerm{name: name}
+			if loc := defenseSeparator.FindStringIndex(piece[len(name):]); loc != nil && loc[0] == 0 {
+				// An unknown term following a name
+				return nil, ErrUnparsable
+			}
+			rest := strings.TrimSpace(piece[len(name):])
+			if note := notePattern.FindStringSubmatch(rest); note != nil && note[1] == "" {
+				term.qualifier = note[2]
+			} else if rest != "" {
+				groupQualifier = rest
+			}
+			terms = append(terms, term)
+		}
+		for i := range terms {
+			if terms[i].qualifier == "" {
+				terms[i].qualifier = groupQualifier
+			}
+		}
+		result = append(result, terms...)
+	}
+	return result, nil
+}
+
+// knownPrefix returns the name that s starts with as a whole word, or "".
+func knownPrefix(s string, names []string) string {
+	s = strings.ToLower(s)
+	for _, name := range names {
+		if strings.HasPrefix(s, name) && (len(s) == len(name) || !unicode.IsLetter(rune(s[len(name)]))) {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package monster
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseDamageDefenses(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []DamageDefense
+		wantErr  bool
+	}{
+		{input: "", expected: nil},
+		{input: "fuoco", expected: []DamageDefense{{Type: DamageFire}}},
+		{input: "freddo, fulmine, fuoco", expected: []DamageDefense{{Type: DamageCold}, {Type: DamageLightning}, {Type: DamageFire}}},
+		{
+			input: "contundente, perforante e tagliente da attacchi non magici",
+			expected: []DamageDefense{
+				{Type: DamageBludgeoning, Qualifier: "da attacchi non magici"},
+				{Type: DamagePiercing, Qualifier: "da attacchi non magici"},
+				{Type: DamageSlashing, Qualifier: "da attacchi non magici"},
+			},
+		},
+		{
+			input: "fuoco; contundente e perforante da attacchi non magici e non argentati",
+			expected: []DamageDefense{
+				{Type: DamageFire},
+				{Type: DamageBludgeoning, Qualifier: "da attacchi non magici e non argentati"},
+				{Type: DamagePiercing, Qualifier: "da attacchi non magici e non argentati"},
+			},
+		},
+		{input: "Veleno (solo in forma gassosa)", expected: []DamageDefense{{Type: DamagePoison, Qualifier: "solo in forma gassosa"}}},
+		{
+			input:    "il tipo di danno scelto per il tratto Origine draconica descritto sotto",
+			expected: []DamageDefense{{Type: DamageChosen, Qualifier: "il tipo di danno scelto per il tratto Origine draconica descritto sotto"}},
+		},
+		{input: "fuocoso", wantErr: true},
+		{input: "fuoco, luce", wantErr: true},
+		{input: "fuoco e luce", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDamageDefenses(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnparsable) {
+					t.Errorf("expected ErrUnparsable, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseConditionImmunities(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []ConditionImmunity
+		wantErr  bool
+	}{
+		{input: "", expected: nil},
+		{
+			input:    "avvelenato, privo di sensi, prono",
+			expected: []ConditionImmunity{{Condition: ConditionPoisoned}, {Condition: ConditionUnconscious}, {Condition: ConditionProne}},
+		},
+		{
+			input:    "affascinato (tranne che dal suo padrone vampiro), spaventato",
+			expected: []ConditionImmunity{{Condition: ConditionCharmed, Qualifier: "tranne che dal suo padrone vampiro"}, {Condition: ConditionFrightened}},
+		},
+		{input: "affascinato (con vuoto mentale)", expected: []ConditionImmunity{{Condition: ConditionCharmed, Qualifier: "con vuoto mentale"}}},
+		{input: "annoiato", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseConditionImmunities(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnparsable) {
+					t.Errorf("expected ErrUnparsable, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseDamageTypesAndConditions(t *testing.T) {
+	damage, err := ParseDamageTypes("fire, Freddo,,psichico")
+	if err != nil || !slices.Equal(damage, []DamageType{DamageFire, DamageCold, DamagePsychic}) {
+		t.Errorf("got %v, %v", damage, err)
+	}
+	if _, err := ParseDamageTypes("fire, chosen"); !errors.Is(err, ErrUnparsable) {
+		t.Errorf("expected ErrUnparsable for chosen, got %v", err)
+	}
+
+	conditions, err := ParseConditions("charmed, privo di sensi")
+	if err != nil || !slices.Equal(conditions, []Condition{ConditionCharmed, ConditionUnconscious}) {
+		t.Errorf("got %v, %v", conditions, err)
+	}
+	if _, err := ParseConditions("sleepy"); !errors.Is(err, ErrUnparsable) {
+		t.Errorf("expected ErrUnparsable, got %v", err)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	if len(DamageTypes()) != 13 || len(Conditions()) != 15 {
+		t.Fatalf("expected 13 damage types and 15 conditions, got %d and %d", len(DamageTypes()), len(Conditions()))
+	}
+	if DamageFire.Label() != "fuoco" || ConditionUnconscious.Label() != "privo di sensi" {
+		t.Errorf("got %q and %q", DamageFire.Label(), ConditionUnconscious.Label())
+	}
+}
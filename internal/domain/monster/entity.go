@@ -1,5 +1,13 @@
 package monster
 
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrNotFound is returned when a monster does not exist.
+var ErrNotFound = errors.New("monster not found")
+
 // AbilityScores holds the six core ability values.
 type AbilityScores struct {
 	Strength     int
@@ -59,6 +67,60 @@ type Monster struct {
 	BonusActions        []NamedDescription
 	Reactions           []NamedDescription
 	LegendaryActions    []NamedDescription
+
+	// Typed values of AC, HP, Initiative, Speed, Senses, the defenses and CRDetail, filled by ParseStats.
+	// LairXP is zero for monsters without a lair variant.
+	ArmorClass       ArmorClass
+	HitPoints        HitPoints
+	InitiativeBonus  Initiative
+	Movement         Movement
+	SpecialSenses    Senses
+	Defenses         Defenses
+	LairXP           int
+	ProficiencyBonus int
+
+	// ParsedActions holds the attacks, saving throws and usage limits of the action lists, filled by ParseStats.
+	ParsedActions ActionSet
+
+	// Source names the imported dataset the monster comes from, empty for the embedded one.
+	Source string
+}
+
+// ParseCR converts a challenge rating string (e.g. "1/4", "5") to a numeric value.
+// Unknown values are treated as 0.
+func ParseCR(cr string) float64 {
+	switch cr {
+	case "1/8":
+		return 0.125
+	case "1/4":
+		return 0.25
+	case "1/2":
+		return 0.5
+	}
+	v, err := strconv.ParseFloat(cr, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// CRValue returns the numeric challenge rating of the monster.
+func (m Monster) CRValue() float64 {
+	return ParseCR(m.CR)
+}
+
+// HasLair reports whether the monster is worth more XP when fought in its lair.
+func (m Monster) HasLair() bool {
+	return m.LairXP > 0
+}
+
+// EncounterXP returns the XP the monster is worth in an encounter, using the lair XP
+// when it is fought in its lair and has a lair variant.
+func (m Monster) EncounterXP(inLair bool) int {
+	if inLair && m.HasLair() {
+		return m.LairXP
+	}
+	return m.XP
 }
 
 // SearchFilters holds all possible filter criteria for monster search.
@@ -69,13 +131,40 @@ type SearchFilters struct {
 	Size  string
 	CRMin string
 	CRMax string
+
+	// Movement and sense criteria; zero values do not filter.
+	CanFly               bool
+	MinSwim              float64 // metres
+	HasBlindsight        bool
+	MaxPassivePerception int
+
+	// Defense criteria: monsters must have every With entry and none of the Without ones.
+	// Defenses limited by a qualifier, such as "da attacchi non magici", count as present.
+	WithResistances            []DamageType
+	WithoutResistances         []DamageType
+	WithImmunities             []DamageType
+	WithoutImmunities          []DamageType
+	WithConditionImmunities    []Condition
+	WithoutConditionImmunities []Condition
+
+	// Offense criteria: the damage per round is estimated against Target, DefaultTarget
+	// when it is zero, and bounds at zero do not filter.
+	Target            Target
+	MinDamagePerRound float64
+	MaxDamagePerRound float64
+
+	// Sort orders the results; empty keeps the relevance or XP order.
+	Sort SortOrder
 }
 
 // Repository defines the interface for accessing monster data.
 type Repository interface {
+	FindByID(id string) (Monster, bool)
 	FindByMaxXP(maxXP int) []Monster
 	Search(query string, maxXP int) []Monster
 	SearchWithFilters(filters SearchFilters) []Monster
+	SearchRanked(filters SearchFilters) []SearchResult
+	Suggest(query string, limit int) []Suggestion
 	AvailableTypes() []string
 	AvailableSizes() []string
 	AvailableCRs() []string
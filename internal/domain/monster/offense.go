@@ -0,0 +1,423 @@
+package monster
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Target is the creature the damage of a monster is estimated against.
+type Target struct {
+	AC        int
+	SaveBonus int
+}
+
+// DefaultTarget is a typical low-level character: AC 15 and +3 to saving throws.
+var DefaultTarget = Target{AC: 15, SaveBonus: 3}
+
+// combatRounds is the length of the fight over which usage limits are spread, the three
+// rounds the Dungeon Master's Guide assumes when rating the offense of a monster.
+const combatRounds = 3
+
+// critChance is the chance of rolling a natural 20, which hits whatever the AC and doubles the dice.
+const critChance = 0.05
+
+// RoutineStep is part of a Multiattack: Count uses of the best of Options, which name
+// actions of the monster. Without Options any attack will do. Attack tells the steps
+// made of attacks, which replacements may swap, from the actions used.
+type RoutineStep struct {
+	Count   int
+	Options []string
+	Attack  bool
+}
+
+// Replacement lets a Multiattack swap Count of its attacks, or all of them when Count
+// is zero, for a use of the best of Options.
+type Replacement struct {
+	Count   int
+	Options []string
+}
+
+// Multiattack is the structured content of the Multiattack action: the routines the
+// monster chooses from each turn and the replacements allowed in them.
+type Multiattack struct {
+	Routines     [][]RoutineStep
+	Replacements []Replacement
+}
+
+// RoutineAttack is an action of the routine chosen by Offense, used Count times.
+type RoutineAttack struct {
+	Name  string
+	Count int
+}
+
+// OffensiveProfile estimates the damage a monster deals each round to a single target.
+// DamagePerRound adds the best turn, Multiattack or single action, to the best bonus action.
+// Actions with usage limits are spread over a three-round fight: Limited names the one used
+// instead of the routine whenever it is available. Reactions and legendary actions are left out.
+type OffensiveProfile struct {
+	Target         Target
+	DamagePerRound float64
+	Routine        []RoutineAttack
+	Limited        string
+	BonusAction    string
+	AttackBonus    int
+	SaveDC         int
+}
+
+var (
+	// countedStepPattern and usedStepPattern match the start of a Multiattack step,
+	// e.g. "due attacchi" or "usa".
+	countedStepPattern = regexp.MustCompile(`\b(un|una|uno|due|tre|quattro|cinque|sei)\s+attacch?[io]\b`)
+	usedStepPattern    = regexp.MustCompile(`\b(?:usa|utilizza|usare|utilizzare)\b`)
+	// alternativePattern matches the "o" introducing another routine.
+	alternativePattern = regexp.MustCompile(`,\s*o\s+(?:effettua\s+)?|\s+oppure\s+`)
+	// replacementPattern matches a replacement, e.g. "può sostituire un attacco con un utilizzo di".
+	replacementPattern = regexp.MustCompile(`può sostituire (un|due|qualsiasi|l')\s*attacch?[io]\b`)
+	// twicePattern matches an action used twice by a step.
+	twicePattern = regexp.MustCompile(`\bdue volte\b`)
+)
+
+var countWords = map[string]int{"un": 1, "una": 1, "uno": 1, "l'": 1, "due": 2, "tre": 3, "quattro": 4, "cinque": 5, "sei": 6}
+
+// IsMultiattack reports whether the action is the Multiattack of the monster.
+func (a Action) IsMultiattack() bool {
+	return strings.HasPrefix(a.Name, "Multiattacco")
+}
+
+// ParseMultiattack resolves the Multiattack of actions against the names of the other actions,
+// returning nil when there is none. The routine is the first sentence with a step; further
+// routines follow an "o", and sentences starting with "Può sostituire" list the replacements.
+// Steps that name no known action, such as "due attacchi", stand for any attack.
+func ParseMultiattack(entries []NamedDescription, actions []Action) *Multiattack {
+	var names []string
+	var text string
+	for i, a := range actions {
+		if a.IsMultiattack() {
+			text = plainText(strings.TrimPrefix(entries[i].Name, "Multiattacco") + ". " + entries[i].Description)
+		} else {
+			names = append(names, a.Name)
+		}
+	}
+	if text == "" {
+		return nil
+	}
+	// Longer names first, so that "Schianto con tentacolo" is not read as "Schianto"
+	slices.SortStableFunc(names, func(a, b string) int { return len(b) - len(a) })
+
+	var ma Multiattack
+	for _, sentence := range strings.Split(strings.ToLower(text), ". ") {
+		if loc := replacementPattern.FindStringSubmatchIndex(sentence); loc != nil {
+			word := sentence[loc[2]:loc[3]]
+			// Only what follows "con" replaces, as in "l'attacco Artiglio con un utilizzo di Soffio"
+			rest := sentence[loc[1]:]
+			if i := strings.Index(rest, " con "); i >= 0 {
+				rest = rest[i:]
+			}
+			options := matchNames(rest, names)
+			if len(options) > 0 {
+				ma.Replacements = append(ma.Replacements, Replacement{Count: countWords[word], Options: options})
+			}
+			continue
+		}
+		if ma.Routines != nil {
+			continue
+		}
+		for i, alternative := range splitAlternatives(sentence) {
+			// Routines allowed only under a condition, such as "se ha usato Fretta", are left out
+			if i > 0 && strings.Contains(alternative, " se ") {
+				continue
+			}
+			if routine := parseRoutine(alternative, names); len(routine) > 0 {
+				ma.Routines = append(ma.Routines, routine)
+			}
+		}
+	}
+	if len(ma.Routines) == 0 {
+		return nil
+	}
+	return &ma
+}
+
+// splitAlternatives splits a Multiattack sentence where an "o" starts another routine.
+func splitAlternatives(sentence string) []string {
+	var parts []string
+	start := 0
+	for _, loc := range alternativePattern.FindAllStringIndex(sentence, -1) {
+		rest := sentence[loc[1]:]
+		if startsStep(rest) {
+			parts = append(parts, sentence[start:loc[0]])
+			start = loc[1]
+		}
+	}
+	return append(parts, sentence[start:])
+}
+
+func startsStep(s string) bool {
+	loc := countedStepPattern.FindStringIndex(s)
+	if loc == nil || loc[0] != 0 {
+		loc = usedStepPattern.FindStringIndex(s)
+	}
+	return loc != nil && loc[0] == 0
+}
+
+// parseRoutine reads the steps of one routine: each step starts at a count of attacks or a
+// verb of use and takes the action names up to the next one.
+func parseRoutine(s string, names []string) []RoutineStep {
+	type mark struct {
+		start, end, count int
+		attack            bool
+	}
+	var marks []mark
+	for _, loc := range countedStepPattern.FindAllStringSubmatchIndex(s, -1) {
+		marks = append(marks, mark{loc[0], loc[1], countWords[s[loc[2]:loc[3]]], true})
+	}
+	for _, loc := range usedStepPattern.FindAllStringIndex(s, -1) {
+		marks = append(marks, mark{loc[0], loc[1], 1, false})
+	}
+	slices.SortFunc(marks, func(a, b mark) int { return a.start - b.start })
+
+	var steps []RoutineStep
+	for i, m := range marks {
+		end := len(s)
+		if i+1 < len(marks) {
+			end = marks[i+1].start
+		}
+		part := s[m.end:end]
+		step := RoutineStep{Count: m.count, Options: matchNames(part, names), Attack: m.attack}
+		if !m.attack {
+			if len(step.Options) == 0 {
+				continue
+			}
+			if twicePattern.MatchString(part) {
+				step.Count = 2
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// matchNames returns the action names found in s, longest first, each at most once.
+func matchNames(s string, names []string) []string {
+	var found []string
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		if i := strings.Index(s, lower); i >= 0 && !slices.Contains(found, name) {
+			found = append(found, name)
+			// Blank the match so that shorter names inside it are not found again
+			s = s[:i] + strings.Repeat(" ", len(lower)) + s[i+len(lower):]
+		}
+	}
+	return found
+}
+
+// Offense estimates the damage per round of the monster against target from its parsed actions.
+func (m Monster) Offense(target Target) OffensiveProfile {
+	p := OffensiveProfile{Target: target}
+	set := m.ParsedActions
+
+	values := make(map[string]float64)
+	bestAttack, bestAttackName := 0.0, ""
+	for _, a := range set.Actions {
+		v := a.expectedDamage(target)
+		if a.Usage.Limited() {
+			values[a.Name] = v * usageShare(a.Usage)
+		} else {
+			values[a.Name] = v
+		}
+		if a.Attack != nil && !a.Usage.Limited() && v >= bestAttack {
+			bestAttack, bestAttackName = v, a.Name
+		}
+	}
+	for _, list := range [][]Action{set.Actions, set.BonusActions, set.Reactions, set.LegendaryActions} {
+		for _, a := range list {
+			if a.Attack != nil {
+				p.AttackBonus = max(p.AttackBonus, a.Attack.ToHit)
+			}
+			if a.Save != nil {
+				p.SaveDC = max(p.SaveDC, a.Save.DC)
+			}
+		}
+	}
+
+	// The best turn at will: a Multiattack routine or a single action
+	turn := 0.0
+	if set.Multiattack != nil {
+		turn, p.Routine = set.Multiattack.best(values, bestAttack, bestAttackName)
+	}
+	for _, a := range set.Actions {
+		if a.Usage.Limited() || a.IsMultiattack() && set.Multiattack != nil {
+			continue
+		}
+		if v := values[a.Name]; v > turn {
+			turn, p.Routine = v, []RoutineAttack{{Name: a.Name, Count: 1}}
+		}
+	}
+
+	// The limited action worth the most when used instead of that turn
+	gain := 0.0
+	for _, a := range set.Actions {
+		if !a.Usage.Limited() {
+			continue
+		}
+		if g := usageShare(a.Usage) * (a.expectedDamage(target) - turn); g > gain {
+			gain, p.Limited = g, a.Name
+		}
+	}
+
+	bonus := 0.0
+	for _, a := range set.BonusActions {
+		v := a.expectedDamage(target)
+		if a.Usage.Limited() {
+			v *= usageShare(a.Usage)
+		}
+		if v > bonus {
+			bonus, p.BonusAction = v, a.Name
+		}
+	}
+
+	p.DamagePerRound = turn + gain + bonus
+	return p
+}
+
+// best returns the value of the routine dealing the most damage, with its actions. Steps without
+// options use the best attack; replacements swap the weakest attacks when they deal more.
+func (ma Multiattack) best(values map[string]float64, bestAttack float64, bestAttackName string) (float64, []RoutineAttack) {
+	type use struct {
+		name   string
+		value  float64
+		attack bool
+	}
+	bestTotal := -1.0
+	var bestRoutine []RoutineAttack
+	for _, routine := range ma.Routines {
+		var uses []use
+		for _, step := range routine {
+			name, value := bestAttackName, bestAttack
+			if len(step.Options) > 0 {
+				name, value = bestOf(step.Options, values)
+			}
+			if name == "" {
+				continue
+			}
+			for range step.Count {
+				uses = append(uses, use{name, value, step.Attack})
+			}
+		}
+
+		for _, r := range ma.Replacements {
+			name, value := bestOf(r.Options, values)
+			swaps := r.Count
+			if swaps == 0 {
+				swaps = len(uses)
+			}
+			// Weakest attacks first, keeping the order of the routine
+			order := make([]int, len(uses))
+			for i := range order {
+				order[i] = i
+			}
+			slices.SortStableFunc(order, func(a, b int) int {
+				return cmp.Compare(uses[a].value, uses[b].value)
+			})
+			for _, i := range order {
+				if swaps == 0 || uses[i].value >= value {
+					break
+				}
+				if uses[i].attack {
+					uses[i] = use{name, value, false}
+					swaps--
+				}
+			}
+		}
+
+		total := 0.0
+		var attacks []RoutineAttack
+		for _, u := range uses {
+			total += u.value
+			if i := slices.IndexFunc(attacks, func(a RoutineAttack) bool { return a.Name == u.name }); i >= 0 {
+				attacks[i].Count++
+			} else {
+				attacks = append(attacks, RoutineAttack{Name: u.name, Count: 1})
+			}
+		}
+		if total > bestTotal {
+			bestTotal, bestRoutine = total, attacks
+		}
+	}
+	return max(bestTotal, 0), bestRoutine
+}
+
+// bestOf returns the option worth the most.
+func bestOf(options []string, values map[string]float64) (string, float64) {
+	name, value := "", -1.0
+	for _, o := range options {
+		if v := values[o]; v > value {
+			name, value = o, v
+		}
+	}
+	return name, max(value, 0)
+}
+
+// expectedDamage returns the average damage of one use of the action against target. Attacks
+// hit on a roll of AC minus the bonus or more, always on a 20 that doubles the dice and never
+// on a 1; a saving throw that follows an attack is only made on a hit.
+func (a Action) expectedDamage(target Target) float64 {
+	switch {
+	case a.Attack != nil:
+		hit := min(max(float64(21+a.Attack.ToHit-target.AC)/20, critChance), 1-critChance)
+		total := hit*totalAverage(a.Attack.Damage) + critChance*diceAverage(a.Attack.Damage)
+		if a.Save != nil {
+			total += hit * a.Save.expectedDamage(target)
+		}
+		return total
+	case a.Save != nil:
+		return a.Save.expectedDamage(target)
+	}
+	return 0
+}
+
+// expectedDamage returns the average damage of the saving throw: saves succeed on a roll of
+// DC minus the bonus or more, with no automatic success or failure.
+func (s Save) expectedDamage(target Target) float64 {
+	success := min(max(float64(21+target.SaveBonus-s.DC)/20, 0), 1)
+	damage := totalAverage(s.Damage)
+	total := (1 - success) * damage
+	if s.HalfOnSuccess {
+		total += success * damage / 2
+	}
+	return total
+}
+
+// usageShare returns the share of the rounds of a fight in which an action with limited uses
+// is available, when it is used as soon as it is: recharges roll each round after the first.
+func usageShare(u Usage) float64 {
+	switch {
+	case u.Recharge > 0:
+		recharge := float64(7-u.Recharge) / 6
+		return (1 + (combatRounds-1)*recharge) / combatRounds
+	case u.PerDay > 0:
+		return float64(min(u.PerDay, combatRounds)) / combatRounds
+	case u.AfterRest:
+		return 1.0 / combatRounds
+	}
+	return 1
+}
+
+func totalAverage(damage []Damage) float64 {
+	total := 0
+	for _, d := range damage {
+		total += d.Average
+	}
+	return float64(total)
+}
+
+// diceAverage returns the average of the dice alone, the extra damage of a critical hit.
+func diceAverage(damage []Damage) float64 {
+	total := 0.0
+	for _, d := range damage {
+		total += float64(d.Dice.Count*(d.Dice.Sides+1)) / 2
+	}
+	return total
+}
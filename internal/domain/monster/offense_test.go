@@ -0,0 +1,142 @@
+package monster
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseMultiattack(t *testing.T) {
+	tests := []struct {
+		name        string
+		multiattack string
+		others      []string
+		expected    *Multiattack
+	}{
+		{
+			name:        "attacks and a use",
+			multiattack: "L'aboleth effettua due attacchi Tentacolo  e usa Consuma ricordi o Domina mente, se disponibili.",
+			others:      []string{"Tentacolo", "Consuma ricordi", "Domina mente (2/giorno)"},
+			expected: &Multiattack{Routines: [][]RoutineStep{{
+				{Count: 2, Options: []string{"Tentacolo"}, Attack: true},
+				{Count: 1, Options: []string{"Consuma ricordi", "Domina mente"}},
+			}}},
+		},
+		{
+			name:        "any combination and a replacement",
+			multiattack: "Il lupo mannaro effettua due  attacchi,  usando Graffio o Arco lungo in qualsiasi combinazione.  Può sostituire un attacco con un attacco Morso.",
+			others:      []string{"Graffio", "Morso (solo in forma di lupo o ibrida)", "Arco lungo (solo in forma umanoide o ibrida)"},
+			expected: &Multiattack{
+				Routines:     [][]RoutineStep{{{Count: 2, Options: []string{"Arco lungo", "Graffio"}, Attack: true}}},
+				Replacements: []Replacement{{Count: 1, Options: []string{"Morso"}}},
+			},
+		},
+		{
+			name:        "alternative routines, the conditional one left out",
+			multiattack: "La medusa effettua due attacchi Artiglio  e un attacco Chioma di serpenti, o tre attacchi Raggio  velenoso, o effettua quattro attacchi Artiglio se è furiosa.",
+			others:      []string{"Artiglio", "Chioma di serpenti", "Raggio velenoso"},
+			expected: &Multiattack{Routines: [][]RoutineStep{
+				{{Count: 2, Options: []string{"Artiglio"}, Attack: true}, {Count: 1, Options: []string{"Chioma di serpenti"}, Attack: true}},
+				{{Count: 3, Options: []string{"Raggio velenoso"}, Attack: true}},
+			}},
+		},
+		{
+			name:        "a use repeated, the named attack replaced",
+			multiattack: "Il planetar effettua tre attacchi Spada  radiosa oppure usa Conflagrazione divina due volte. Può sostituire l'attacco Spada radiosa con un utilizzo di Schianto.",
+			others:      []string{"Spada radiosa", "Conflagrazione divina", "Schianto con tentacolo", "Schianto"},
+			expected: &Multiattack{
+				Routines: [][]RoutineStep{
+					{{Count: 3, Options: []string{"Spada radiosa"}, Attack: true}},
+					{{Count: 2, Options: []string{"Conflagrazione divina"}}},
+				},
+				Replacements: []Replacement{{Count: 1, Options: []string{"Schianto"}}},
+			},
+		},
+		{
+			name:        "any attack",
+			multiattack: "Il triceratopo effettua due attacchi.",
+			others:      []string{"Trafiggere"},
+			expected:    &Multiattack{Routines: [][]RoutineStep{{{Count: 2, Attack: true}}}},
+		},
+		{
+			name:        "no step",
+			multiattack: "L'idra effettua un numero di attacchi  Morso pari al numero di teste che possiede.",
+			others:      []string{"Morso"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := []NamedDescription{{Name: "Multiattacco", Description: tt.multiattack}}
+			for _, name := range tt.others {
+				entries = append(entries, NamedDescription{Name: name})
+			}
+			got := ParseMultiattack(entries, ParseActions(entries))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+
+	if ParseMultiattack([]NamedDescription{{Name: "Morso"}}, []Action{{Name: "Morso"}}) != nil {
+		t.Error("expected no Multiattack")
+	}
+}
+
+func TestMonster_Offense(t *testing.T) {
+	ogre := testOgre()
+	ogre.ParseStats()
+
+	// The club: hits on 9 or more, 13 damage plus the 9 of the dice on a 20
+	p := ogre.Offense(DefaultTarget)
+	if math.Abs(p.DamagePerRound-(0.6*13+0.05*9)) > 1e-9 {
+		t.Errorf("expected 8.25 damage per round, got %v", p.DamagePerRound)
+	}
+	if !reflect.DeepEqual(p.Routine, []RoutineAttack{{Name: "Randello pesante", Count: 1}}) || p.AttackBonus != 6 || p.Target != DefaultTarget {
+		t.Errorf("unexpected profile %+v", p)
+	}
+
+	// Hits only on a 20 against a high AC, always but on a 1 against a low one
+	if p := ogre.Offense(Target{AC: 30}); math.Abs(p.DamagePerRound-0.05*(13+9)) > 1e-9 {
+		t.Errorf("expected only critical hits, got %v", p.DamagePerRound)
+	}
+	if p := ogre.Offense(Target{AC: 5}); math.Abs(p.DamagePerRound-(0.95*13+0.05*9)) > 1e-9 {
+		t.Errorf("expected hits on 2 or more, got %v", p.DamagePerRound)
+	}
+
+	dragon := Monster{
+		Actions: []NamedDescription{
+			{Name: "Multiattacco", Description: "Il drago effettua due attacchi Squarcio. Può sostituire un attacco con un utilizzo di Ruggito."},
+			{Name: "Squarcio", Description: "*Tiro per colpire in mischia:* +7, portata 1,5 m. *Colpito:* 10 (2d6 + 3) danni taglienti."},
+			{Name: "Ruggito", Description: "*Tiro salvezza su Saggezza:* CD 14, una creatura. *Fallimento:* 20 (8d4) danni da tuono."},
+			{Name: "Soffio di fuoco (ricarica 5–6)", Description: "*Tiro salvezza su Destrezza:* CD 14, ogni creatura in un cono. *Fallimento:* 42 (12d6) danni da fuoco. *Successo:* danni dimezzati."},
+		},
+		BonusActions: []NamedDescription{
+			{Name: "Coda", Description: "*Tiro per colpire in mischia:* +7, portata 3 m. *Colpito:* 6 (1d6 + 3) danni contundenti."},
+		},
+	}
+	dragon.ParseStats()
+	p = dragon.Offense(DefaultTarget)
+
+	slash := 0.65*10 + 0.05*7
+	roar := 0.5 * 20
+	turn := slash + roar
+	// Available in the first round and then when 5 or 6 is rolled, a third of the time
+	breath := 0.5*42 + 0.5*21
+	limited := (1 + 2.0/3) / 3 * (breath - turn)
+	tail := 0.65*6 + 0.05*3.5
+	if math.Abs(p.DamagePerRound-(turn+limited+tail)) > 1e-9 {
+		t.Errorf("expected %v damage per round, got %v", turn+limited+tail, p.DamagePerRound)
+	}
+	expected := []RoutineAttack{{Name: "Ruggito", Count: 1}, {Name: "Squarcio", Count: 1}}
+	if !reflect.DeepEqual(p.Routine, expected) || p.Limited != "Soffio di fuoco" || p.BonusAction != "Coda" || p.AttackBonus != 7 || p.SaveDC != 14 {
+		t.Errorf("unexpected profile %+v", p)
+	}
+
+	// Only the at-will turn is used when the limited action deals less
+	weak := dragon
+	weak.ParsedActions.Actions[3].Save.Damage = []Damage{{Average: 1, Type: DamageFire}}
+	if p := weak.Offense(DefaultTarget); p.Limited != "" || math.Abs(p.DamagePerRound-(turn+tail)) > 1e-9 {
+		t.Errorf("expected the breath to be left out, got %+v", p)
+	}
+}
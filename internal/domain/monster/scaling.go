@@ -0,0 +1,255 @@
+package monster
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCR is returned when a challenge rating is not in the DMG table.
+var ErrInvalidCR = errors.New("invalid challenge rating")
+
+var (
+	// hpPattern matches hit points such as "68 (8d10 + 24)" or "7 (2d6)".
+	hpPattern = regexp.MustCompile(`^(\d+) \((\d+)d(\d+)(?: ?(` + signClass + `) ?(\d+))?\)`)
+	// damagePattern matches a damage roll such as "13 (2d8 + 4)" inside a description.
+	damagePattern = regexp.MustCompile(`(\d+) \((\d+)d(\d+)(?: ?(` + signClass + `) ?(\d+))?\)`)
+	// attackPattern matches the bonus of an attack roll, e.g. "*Tiro per colpire in mischia:* +6".
+	attackPattern = regexp.MustCompile(`(Tiro per colpire[^:]*:\*\s*)(` + signClass + `)(\d+)`)
+	// saveDCPattern matches a saving throw DC, e.g. "CD 13".
+	saveDCPattern = regexp.MustCompile(`CD (\d+)`)
+)
+
+// FieldChange describes one statblock field changed by scaling.
+// Entry names the trait or action for list fields and is empty otherwise.
+type FieldChange struct {
+	Field  string
+	Entry  string
+	Before string
+	After  string
+}
+
+// ChallengeRatings returns every challenge rating of the DMG table in ascending order.
+func ChallengeRatings() []string {
+	crs := make([]string, len(challengeTable))
+	for i, row := range challengeTable {
+		crs[i] = row.CR
+	}
+	return crs
+}
+
+// Scale derives a copy of m rescaled to targetCR.
+// HP and damage dice are scaled by the ratio between the typical values of the two CRs,
+// while AC, attack bonuses and save DCs move by the difference between the expected values;
+// the AC is read from the parsed ArmorClass, so m must have gone through ParseStats.
+// Flavor fields are kept as they are; the copy gets a distinct ID and the new CR and XP.
+func Scale(m Monster, targetCR string) (Monster, error) {
+	target, ok := challengeIndex(targetCR)
+	if !ok {
+		return Monster{}, fmt.Errorf("%w: %s", ErrInvalidCR, targetCR)
+	}
+	source, ok := challengeIndex(m.CR)
+	if !ok {
+		return Monster{}, fmt.Errorf("%w: %s", ErrInvalidCR, m.CR)
+	}
+
+	from, to := challengeTable[source], challengeTable[target]
+	hpRatio := typicalHP(target) / typicalHP(source)
+	damageRatio := typicalDPR(target) / typicalDPR(source)
+
+	scaled := m
+	scaled.ID = m.ID + "-gs-" + strings.ReplaceAll(to.CR, "/", "-")
+	scaled.CR = to.CR
+	scaled.XP = to.XP
+	scaled.CRDetail = crDetail(target, strings.Contains(m.CRDetail, "nella tana"))
+	scaled.HP = scaleHP(m.HP, hpRatio)
+	if m.ArmorClass.Value > 0 {
+		ac := m.ArmorClass
+		ac.Value = max(ac.Value+to.AC-from.AC, 1)
+		scaled.AC = ac.String()
+	}
+
+	scaleText := func(text string) string {
+		text = attackPattern.ReplaceAllStringFunc(text, func(s string) string {
+			parts := attackPattern.FindStringSubmatch(s)
+			bonus, _ := strconv.Atoi(parts[3])
+			if parts[2] != "+" {
+				bonus = -bonus
+			}
+			return parts[1] + signed(bonus+to.AttackBonus-from.AttackBonus)
+		})
+		text = saveDCPattern.ReplaceAllStringFunc(text, func(s string) string {
+			dc, _ := strconv.Atoi(saveDCPattern.FindStringSubmatch(s)[1])
+			return "CD " + strconv.Itoa(dc+to.SaveDC-from.SaveDC)
+		})
+		return damagePattern.ReplaceAllStringFunc(text, func(s string) string {
+			return scaleDamage(s, damageRatio)
+		})
+	}
+	scaled.Traits = scaleEntries(m.Traits, scaleText)
+	scaled.Actions = scaleEntries(m.Actions, scaleText)
+	scaled.BonusActions = scaleEntries(m.BonusActions, scaleText)
+	scaled.Reactions = scaleEntries(m.Reactions, scaleText)
+	scaled.LegendaryActions = scaleEntries(m.LegendaryActions, scaleText)
+	scaled.ParseStats()
+
+	return scaled, nil
+}
+
+// Diff lists the statblock fields that differ between original and scaled.
+func Diff(original, scaled Monster) []FieldChange {
+	var changes []FieldChange
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"CR", original.CR, scaled.CR},
+		{"XP", strconv.Itoa(original.XP), strconv.Itoa(scaled.XP)},
+		{"CRDetail", original.CRDetail, scaled.CRDetail},
+		{"AC", original.AC, scaled.AC},
+		{"HP", original.HP, scaled.HP},
+	}
+	for _, f := range fields {
+		if f.before != f.after {
+			changes = append(changes, FieldChange{Field: f.name, Before: f.before, After: f.after})
+		}
+	}
+
+	lists := []struct {
+		name          string
+		before, after []NamedDescription
+	}{
+		{"Traits", original.Traits, scaled.Traits},
+		{"Actions", original.Actions, scaled.Actions},
+		{"BonusActions", original.BonusActions, scaled.BonusActions},
+		{"Reactions", original.Reactions, scaled.Reactions},
+		{"LegendaryActions", original.LegendaryActions, scaled.LegendaryActions},
+	}
+	for _, l := range lists {
+		for i := range min(len(l.before), len(l.after)) {
+			if l.before[i].Description != l.after[i].Description {
+				changes = append(changes, FieldChange{
+					Field:  l.name,
+					Entry:  l.before[i].Name,
+					Before: l.before[i].Description,
+					After:  l.after[i].Description,
+				})
+			}
+		}
+	}
+	return changes
+}
+
+func challengeIndex(cr string) (int, bool) {
+	for i, row := range challengeTable {
+		if row.CR == cr {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// typicalHP returns the middle of the HP range of a table row.
+func typicalHP(i int) float64 {
+	low := 1
+	if i > 0 {
+		low = challengeTable[i-1].MaxHP + 1
+	}
+	return float64(low+challengeTable[i].MaxHP) / 2
+}
+
+// typicalDPR returns the middle of the damage per round range of a table row.
+func typicalDPR(i int) float64 {
+	low := 0
+	if i > 0 {
+		low = challengeTable[i-1].MaxDPR + 1
+	}
+	return float64(low+challengeTable[i].MaxDPR) / 2
+}
+
+// crDetail formats a challenge rating like the statblocks, e.g. "5 (PE 1.800; BC +3)".
+// Monsters with a lair also get the XP of the next challenge rating.
+func crDetail(i int, lair bool) string {
+	row := challengeTable[i]
+	xp := "PE " + formatThousands(row.XP)
+	if lair && i+1 < len(challengeTable) {
+		xp += ", o " + formatThousands(challengeTable[i+1].XP) + " nella tana"
+	}
+	return fmt.Sprintf("%s (%s; BC +%d)", row.CR, xp, row.ProficiencyBonus)
+}
+
+// formatThousands formats n with the Italian thousands separator.
+func formatThousands(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "." + s[i:]
+	}
+	return s
+}
+
+// scaleHP rescales the hit dice of hp, keeping the die size and the bonus per die.
+// Values without hit dice are returned unchanged.
+func scaleHP(hp string, ratio float64) string {
+	parts := hpPattern.FindStringSubmatch(hp)
+	if parts == nil {
+		return hp
+	}
+	average, _ := strconv.Atoi(parts[1])
+	count, _ := strconv.Atoi(parts[2])
+	die, _ := strconv.Atoi(parts[3])
+	bonus := diceBonus(parts[4], parts[5])
+
+	perDie := float64(bonus) / float64(count)
+	dieAverage := float64(die+1) / 2
+	newCount := max(int(math.Round(float64(average)*ratio/(dieAverage+perDie))), 1)
+	newBonus := int(math.Round(perDie * float64(newCount)))
+	return diceExpression(newCount, die, newBonus) + hp[len(parts[0]):]
+}
+
+// scaleDamage rescales the dice of a damage roll, keeping the die size and the flat bonus.
+func scaleDamage(damage string, ratio float64) string {
+	parts := damagePattern.FindStringSubmatch(damage)
+	average, _ := strconv.Atoi(parts[1])
+	die, _ := strconv.Atoi(parts[3])
+	bonus := diceBonus(parts[4], parts[5])
+
+	dieAverage := float64(die+1) / 2
+	newCount := max(int(math.Round((float64(average)*ratio-float64(bonus))/dieAverage)), 1)
+	return diceExpression(newCount, die, bonus)
+}
+
+func diceBonus(sign, value string) int {
+	bonus, _ := strconv.Atoi(value)
+	if sign == "−" || sign == "–" || sign == "-" {
+		return -bonus
+	}
+	return bonus
+}
+
+// diceExpression formats a roll with its average, e.g. "13 (2d8 + 4)".
+func diceExpression(count, die, bonus int) string {
+	dice := Dice{Count: count, Sides: die, Bonus: bonus}
+	return fmt.Sprintf("%d (%s)", max(dice.Average(), 1), dice)
+}
+
+// signed formats a modifier with its sign, using the typographic minus like the statblocks.
+func signed(n int) string {
+	if n < 0 {
+		return "−" + strconv.Itoa(-n)
+	}
+	return "+" + strconv.Itoa(n)
+}
+
+func scaleEntries(entries []NamedDescription, scale func(string) string) []NamedDescription {
+	if entries == nil {
+		return nil
+	}
+	scaled := make([]NamedDescription, len(entries))
+	for i, e := range entries {
+		scaled[i] = NamedDescription{Name: e.Name, Description: scale(e.Description)}
+	}
+	return scaled
+}
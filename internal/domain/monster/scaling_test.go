@@ -0,0 +1,173 @@
+package monster
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testOgre() Monster {
+	m := Monster{
+		ID:        "ogre",
+		Name:      "Ogre",
+		Type:      "Gigante",
+		Size:      "Grande",
+		Alignment: "caotico malvagio",
+		CR:        "2",
+		XP:        450,
+		AC:        "11",
+		HP:        "68 (8d10 + 24)",
+		CRDetail:  "2 (PE 450; BC +2)",
+		Languages: "Comune, Gigante",
+		Actions: []NamedDescription{
+			{Name: "Giavellotto", Description: "*Tiro per colpire in mischia o a distanza:* +6,  portata 1,5 m o gittata 9/36 m. *Colpito:* 11 (2d6 + 4)  danni perforanti."},
+			{Name: "Randello pesante", Description: "*Tiro per colpire in mischia:* +6, portata 1,5 m. *Colpito:* 13 (2d8 + 4) danni contundenti."},
+		},
+	}
+	m.ParseStats()
+	return m
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		name     string
+		monster  Monster
+		targetCR string
+		expected func(Monster) Monster
+	}{
+		{
+			name:     "ogre scaled up to CR 5",
+			monster:  testOgre(),
+			targetCR: "5",
+			expected: func(m Monster) Monster {
+				m.ID = "ogre-gs-5"
+				m.CR = "5"
+				m.XP = 1800
+				m.CRDetail = "5 (PE 1.800; BC +3)"
+				m.AC = "13"
+				m.HP = "102 (12d10 + 36)"
+				m.Actions = []NamedDescription{
+					{Name: "Giavellotto", Description: "*Tiro per colpire in mischia o a distanza:* +9,  portata 1,5 m o gittata 9/36 m. *Colpito:* 21 (5d6 + 4)  danni perforanti."},
+					{Name: "Randello pesante", Description: "*Tiro per colpire in mischia:* +9, portata 1,5 m. *Colpito:* 26 (5d8 + 4) danni contundenti."},
+				}
+				return m
+			},
+		},
+		{
+			name:     "same CR keeps the statblock",
+			monster:  testOgre(),
+			targetCR: "2",
+			expected: func(m Monster) Monster {
+				m.ID = "ogre-gs-2"
+				return m
+			},
+		},
+		{
+			name: "save DC and lair XP scaled down to a fractional CR",
+			monster: Monster{
+				ID:       "mage",
+				CR:       "6",
+				XP:       2300,
+				AC:       "15",
+				HP:       "81 (18d8)",
+				CRDetail: "6 (PE 2.300, o 2.900 nella tana; BC +3)",
+				Traits:   []NamedDescription{{Name: "Aura", Description: "Tiro salvezza su Costituzione CD 15 o 10 (3d6) danni da fuoco."}},
+			},
+			targetCR: "1/2",
+			expected: func(m Monster) Monster {
+				m.ID = "mage-gs-1-2"
+				m.CR = "1/2"
+				m.XP = 100
+				m.CRDetail = "1/2 (PE 100, o 200 nella tana; BC +2)"
+				m.AC = "13"
+				m.HP = "31 (7d8)"
+				m.Traits = []NamedDescription{{Name: "Aura", Description: "Tiro salvezza su Costituzione CD 13 o 3 (1d6) danni da fuoco."}}
+				return m
+			},
+		},
+		{
+			name: "armor note and en dash bonuses kept",
+			monster: Monster{
+				ID:       "zombie",
+				CR:       "2",
+				XP:       450,
+				AC:       "11 (armatura naturale)",
+				HP:       "40 (10d8 – 5)",
+				CRDetail: "2 (PE 450; BC +2)",
+				Actions:  []NamedDescription{{Name: "Schianto", Description: "*Tiro per colpire in mischia:* –1, portata 1,5 m. *Colpito:* 10 (3d8 – 3) danni contundenti."}},
+			},
+			targetCR: "5",
+			expected: func(m Monster) Monster {
+				m.ID = "zombie-gs-5"
+				m.CR = "5"
+				m.XP = 1800
+				m.CRDetail = "5 (PE 1.800; BC +3)"
+				m.AC = "13 (armatura naturale)"
+				m.HP = "59 (15d8 − 8)"
+				m.Actions = []NamedDescription{{Name: "Schianto", Description: "*Tiro per colpire in mischia:* +2, portata 1,5 m. *Colpito:* 19 (5d8 − 3) danni contundenti."}}
+				return m
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.monster.ParseStats()
+			scaled, err := Scale(tt.monster, tt.targetCR)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Typed values must follow the scaled strings
+			expected := tt.expected(tt.monster)
+			expected.ParseStats()
+			if !reflect.DeepEqual(scaled, expected) {
+				t.Errorf("expected %+v, got %+v", expected, scaled)
+			}
+		})
+	}
+}
+
+func TestScaleInvalidCR(t *testing.T) {
+	tests := []struct {
+		name     string
+		monster  Monster
+		targetCR string
+	}{
+		{name: "unknown target CR", monster: testOgre(), targetCR: "31"},
+		{name: "unknown source CR", monster: Monster{ID: "x", CR: "?"}, targetCR: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Scale(tt.monster, tt.targetCR); !errors.Is(err, ErrInvalidCR) {
+				t.Errorf("expected ErrInvalidCR, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	original := testOgre()
+	scaled, err := Scale(original, "5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changes := Diff(original, scaled)
+
+	var fields []string
+	for _, c := range changes {
+		fields = append(fields, c.Field+"/"+c.Entry)
+	}
+	expected := []string{"CR/", "XP/", "CRDetail/", "AC/", "HP/", "Actions/Giavellotto", "Actions/Randello pesante"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected changed fields %v, got %v", expected, fields)
+	}
+	if changes[4].Before != "68 (8d10 + 24)" || changes[4].After != "102 (12d10 + 36)" {
+		t.Errorf("unexpected HP change %+v", changes[4])
+	}
+
+	if changes := Diff(original, original); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
@@ -0,0 +1,58 @@
+package monster
+
+import "fmt"
+
+// SearchResult is a monster matching a search, with its relevance to the text query
+// and the passage of its statblock that matched best.
+type SearchResult struct {
+	Monster Monster
+	Score   float64
+	Snippet Snippet
+	// Offense is estimated against the target of the search filters.
+	Offense OffensiveProfile
+}
+
+// SortOrder is an order of search results.
+type SortOrder string
+
+// Sort orders: by estimated damage per round, highest or lowest first.
+const (
+	SortDamageDesc SortOrder = "dpr_desc"
+	SortDamageAsc  SortOrder = "dpr_asc"
+)
+
+// ParseSortOrder returns the sort order named s, empty for the default order.
+func ParseSortOrder(s string) (SortOrder, error) {
+	switch o := SortOrder(s); o {
+	case "", SortDamageDesc, SortDamageAsc:
+		return o, nil
+	}
+	return "", fmt.Errorf("%w: sort order %q", ErrUnparsable, s)
+}
+
+// Snippet is an excerpt of a statblock passage, split into parts so that
+// the words matching the query can be highlighted.
+type Snippet struct {
+	// Field names the passage, e.g. "Azioni: Tentacolo".
+	Field string
+	Parts []SnippetPart
+}
+
+// SnippetPart is a run of snippet text, highlighted when it matches the query.
+type SnippetPart struct {
+	Text      string
+	Highlight bool
+}
+
+// IsZero reports whether the snippet is empty, as it is for searches without a text query.
+func (s Snippet) IsZero() bool {
+	return len(s.Parts) == 0
+}
+
+// Suggestion is a monster name offered while the user types. Distance counts the typos
+// between the query and the name; zero means the name starts with what was typed.
+type Suggestion struct {
+	ID       string
+	Name     string
+	Distance int
+}
@@ -0,0 +1,143 @@
+package monster
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// speedPattern matches a speed such as "9 m", "nuoto 4,5 m" or "scalata o volo 6 metri".
+	speedPattern = regexp.MustCompile(`^(?:(\pL+(?: o \pL+)*) )?(\d+(?:,\d+)?) ?(?:m|metri)$`)
+	// sensePattern matches a sense such as "vista cieca 18 m".
+	sensePattern = regexp.MustCompile(`^(\pL+(?: \pL+)*) (\d+(?:,\d+)?) ?(?:m|metri)$`)
+	// passivePerceptionPattern matches "Percezione passiva 14", in any case.
+	passivePerceptionPattern = regexp.MustCompile(`(?i)^percezione passiva (\d+)$`)
+	// senseSeparator splits senses on semicolons and on commas followed by a space,
+	// leaving the decimal comma of "4,5 m" alone.
+	senseSeparator = regexp.MustCompile(`;\s*|,\s+`)
+	// notePattern matches a trailing note such as "(fluttuare)" or "(solo in forma di lupo)".
+	notePattern = regexp.MustCompile(`^(.*?)\s*\(([^)]*)\)$`)
+)
+
+// Movement holds the speeds of a monster, in metres. Zero means the monster lacks that mode.
+type Movement struct {
+	Walk   float64
+	Fly    float64
+	Swim   float64
+	Climb  float64
+	Burrow float64
+	Hover  bool
+}
+
+// Senses holds the special senses of a monster, in metres, and its passive Perception.
+// Notes keeps the senses that carry a qualification, such as
+// "scurovisione 36 m (non ostacolata dall'oscurità magica)", separated by "; ".
+type Senses struct {
+	Darkvision        float64
+	Blindsight        float64
+	Tremorsense       float64
+	Truesight         float64
+	PassivePerception int
+	Notes             string
+}
+
+// ParseMovement parses a speed such as "12 m, volo 24 m (fluttuare)".
+// A mode listed twice, as shapechangers do with "9 m, 12 m (solo in forma di lupo)",
+// keeps its fastest speed; "scalata o volo 6 m" grants both modes.
+func ParseMovement(s string) (Movement, error) {
+	var moves Movement
+	if strings.TrimSpace(s) == "" {
+		return moves, fmt.Errorf("%w: speed %q", ErrUnparsable, s)
+	}
+
+	for _, part := range strings.Split(s, ", ") {
+		text := strings.TrimSpace(part)
+		if note := notePattern.FindStringSubmatch(text); note != nil {
+			text = note[1]
+			if note[2] == "fluttuare" {
+				moves.Hover = true
+			}
+		}
+
+		match := speedPattern.FindStringSubmatch(text)
+		if match == nil {
+			return Movement{}, fmt.Errorf("%w: speed %q", ErrUnparsable, s)
+		}
+		distance := parseMetres(match[2])
+
+		modes := []string{""}
+		if match[1] != "" {
+			modes = strings.Split(match[1], " o ")
+		}
+		for _, mode := range modes {
+			var speed *float64
+			switch mode {
+			case "":
+				speed = &moves.Walk
+			case "volo":
+				speed = &moves.Fly
+			case "nuoto":
+				speed = &moves.Swim
+			case "scalata":
+				speed = &moves.Climb
+			case "scavo":
+				speed = &moves.Burrow
+			default:
+				return Movement{}, fmt.Errorf("%w: speed %q", ErrUnparsable, s)
+			}
+			*speed = max(*speed, distance)
+		}
+	}
+	return moves, nil
+}
+
+// ParseSenses parses senses such as "Percezione passiva 20; scurovisione 36 m".
+func ParseSenses(s string) (Senses, error) {
+	var senses Senses
+	var notes []string
+	found := false
+	for _, part := range senseSeparator.Split(strings.TrimSpace(s), -1) {
+		if match := passivePerceptionPattern.FindStringSubmatch(part); match != nil {
+			senses.PassivePerception, _ = strconv.Atoi(match[1])
+			found = true
+			continue
+		}
+
+		text := part
+		if note := notePattern.FindStringSubmatch(part); note != nil {
+			text = note[1]
+			notes = append(notes, part)
+		}
+		match := sensePattern.FindStringSubmatch(text)
+		if match == nil {
+			return Senses{}, fmt.Errorf("%w: senses %q", ErrUnparsable, s)
+		}
+		distance := parseMetres(match[2])
+
+		switch strings.ToLower(match[1]) {
+		case "scurovisione":
+			senses.Darkvision = distance
+		case "vista cieca":
+			senses.Blindsight = distance
+		case "percezione tellurica":
+			senses.Tremorsense = distance
+		case "vista pura":
+			senses.Truesight = distance
+		default:
+			return Senses{}, fmt.Errorf("%w: senses %q", ErrUnparsable, s)
+		}
+	}
+	if !found {
+		return Senses{}, fmt.Errorf("%w: senses %q lack passive Perception", ErrUnparsable, s)
+	}
+	senses.Notes = strings.Join(notes, "; ")
+	return senses, nil
+}
+
+// parseMetres reads a distance with a decimal comma, such as "4,5".
+func parseMetres(s string) float64 {
+	v, _ := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	return v
+}
@@ -0,0 +1,233 @@
+package monster
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/dice"
+)
+
+// ErrUnparsable is returned when a statblock value does not have the expected shape.
+var ErrUnparsable = errors.New("unparsable statblock value")
+
+// signClass matches the sign of a modifier: the plus, the typographic minus, the en dash
+// some statblocks use in its place and the ASCII hyphen.
+const signClass = `[+−–-]`
+
+var (
+	// hitPointsPattern matches "150 (20d10 + 40)", "7 (2d6)" or fixed hit points such as "1".
+	hitPointsPattern = regexp.MustCompile(`^(\d+)(?:\s*\((\d+)d(\d+)(?:\s*(` + signClass + `)\s*(\d+))?\))?$`)
+	// armorClassPattern matches "17" or "17 (armatura naturale)".
+	armorClassPattern = regexp.MustCompile(`^(\d+)(?:\s*\((.+)\))?$`)
+	// initiativePattern matches "+7 (17)" or "−2 (8)"; the score is optional.
+	initiativePattern = regexp.MustCompile(`^(` + signClass + `)\s*(\d+)(?:\s*\((\d+)\))?$`)
+	// challengePattern matches "10 (PE 5.900, o 7.200 nella tana; BC +4)"; the lair XP is optional.
+	challengePattern = regexp.MustCompile(`^\S+\s*\(PE\s+([\d.]+)(?:,\s*o\s+([\d.]+)\s*nella tana)?\s*;\s*BC\s*(` + signClass + `)\s*(\d+)\)$`)
+)
+
+// Dice is a dice expression such as 20d10 + 40.
+type Dice struct {
+	Count int
+	Sides int
+	Bonus int
+}
+
+// String formats the dice like the statblocks, e.g. "20d10 + 40" or "2d8 − 1".
+func (d Dice) String() string {
+	switch {
+	case d.Count == 0:
+		return ""
+	case d.Bonus > 0:
+		return fmt.Sprintf("%dd%d + %d", d.Count, d.Sides, d.Bonus)
+	case d.Bonus < 0:
+		return fmt.Sprintf("%dd%d − %d", d.Count, d.Sides, -d.Bonus)
+	default:
+		return fmt.Sprintf("%dd%d", d.Count, d.Sides)
+	}
+}
+
+// Average returns the average roll, rounded down like the statblocks.
+func (d Dice) Average() int {
+	return d.Count*(d.Sides+1)/2 + d.Bonus
+}
+
+// Expression returns the dice as an expression that can be rolled.
+func (d Dice) Expression() dice.Expression {
+	return dice.New(d.Count, d.Sides, d.Bonus)
+}
+
+// HitPoints holds the average hit points of a monster and the dice they come from.
+// Dice is zero for monsters with fixed hit points.
+type HitPoints struct {
+	Average int
+	Dice    Dice
+}
+
+// Roll rolls the hit dice, so that monsters of the same kind differ from one another.
+// Monsters with fixed hit points get their average. The result is at least 1.
+func (hp HitPoints) Roll(rng *rand.Rand) int {
+	if hp.Dice.Count == 0 {
+		return hp.Average
+	}
+	return max(hp.Dice.Expression().Roll(rng).Total, 1)
+}
+
+// ArmorClass holds the armor class and the note explaining it, e.g. "armatura naturale".
+type ArmorClass struct {
+	Value int
+	Note  string
+}
+
+// String formats the armor class like the statblocks, e.g. "17 (armatura naturale)".
+func (ac ArmorClass) String() string {
+	if ac.Note == "" {
+		return strconv.Itoa(ac.Value)
+	}
+	return fmt.Sprintf("%d (%s)", ac.Value, ac.Note)
+}
+
+// Initiative holds the initiative modifier and the score used instead of rolling.
+type Initiative struct {
+	Modifier int
+	Score    int
+}
+
+// ChallengeDetail holds the XP and proficiency bonus given by a challenge rating.
+// LairXP is the XP of the monster fought in its lair, zero when it has no lair variant.
+type ChallengeDetail struct {
+	XP               int
+	LairXP           int
+	ProficiencyBonus int
+}
+
+// FieldError reports a statblock field that could not be parsed.
+type FieldError struct {
+	MonsterID string
+	Field     string
+	Value     string
+	Err       error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s %q: %v", e.MonsterID, e.Field, e.Value, e.Err)
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ParseHitPoints parses hit points such as "150 (20d10 + 40)".
+func ParseHitPoints(s string) (HitPoints, error) {
+	parts := hitPointsPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if parts == nil {
+		return HitPoints{}, fmt.Errorf("%w: hit points %q", ErrUnparsable, s)
+	}
+
+	average, _ := strconv.Atoi(parts[1])
+	hp := HitPoints{Average: average}
+	if parts[2] != "" {
+		hp.Dice.Count, _ = strconv.Atoi(parts[2])
+		hp.Dice.Sides, _ = strconv.Atoi(parts[3])
+		hp.Dice.Bonus = diceBonus(parts[4], parts[5])
+	}
+	return hp, nil
+}
+
+// ParseArmorClass parses an armor class such as "17" or "17 (armatura naturale)".
+func ParseArmorClass(s string) (ArmorClass, error) {
+	parts := armorClassPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if parts == nil {
+		return ArmorClass{}, fmt.Errorf("%w: armor class %q", ErrUnparsable, s)
+	}
+
+	value, _ := strconv.Atoi(parts[1])
+	return ArmorClass{Value: value, Note: parts[2]}, nil
+}
+
+// ParseInitiative parses an initiative such as "+7 (17)".
+// Without a score the passive one, 10 plus the modifier, is assumed.
+func ParseInitiative(s string) (Initiative, error) {
+	parts := initiativePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if parts == nil {
+		return Initiative{}, fmt.Errorf("%w: initiative %q", ErrUnparsable, s)
+	}
+
+	modifier := diceBonus(parts[1], parts[2])
+	score := 10 + modifier
+	if parts[3] != "" {
+		score, _ = strconv.Atoi(parts[3])
+	}
+	return Initiative{Modifier: modifier, Score: score}, nil
+}
+
+// ParseChallengeDetail parses a challenge rating detail such as "10 (PE 5.900, o 7.200 nella tana; BC +4)".
+// XP use dots as thousands separators, so "5.900" is 5900.
+func ParseChallengeDetail(s string) (ChallengeDetail, error) {
+	parts := challengePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if parts == nil {
+		return ChallengeDetail{}, fmt.Errorf("%w: challenge %q", ErrUnparsable, s)
+	}
+
+	c := ChallengeDetail{ProficiencyBonus: diceBonus(parts[3], parts[4])}
+	c.XP, _ = strconv.Atoi(strings.ReplaceAll(parts[1], ".", ""))
+	if parts[2] != "" {
+		c.LairXP, _ = strconv.Atoi(strings.ReplaceAll(parts[2], ".", ""))
+	}
+	return c, nil
+}
+
+// ParseStats fills ArmorClass, HitPoints, InitiativeBonus, Movement, SpecialSenses, Defenses, LairXP
+// and ProficiencyBonus from the AC, HP, Initiative, Speed, Senses, Resistances, DamageImmunities,
+// ConditionImmunities and CRDetail strings, returning the fields that could not be parsed. Those are
+// left zero. XP is not touched: it is set with the CR and may not match a hand-written CRDetail.
+// ParsedActions is filled too; action text is free-form, so what it lacks is not reported here
+// but by NewActionCoverage.
+func (m *Monster) ParseStats() []FieldError {
+	var errs []FieldError
+	report := func(field, value string, err error) {
+		errs = append(errs, FieldError{MonsterID: m.ID, Field: field, Value: value, Err: err})
+	}
+
+	var err error
+	if m.ArmorClass, err = ParseArmorClass(m.AC); err != nil {
+		report("ac", m.AC, err)
+	}
+	if m.HitPoints, err = ParseHitPoints(m.HP); err != nil {
+		report("hp", m.HP, err)
+	}
+	if m.InitiativeBonus, err = ParseInitiative(m.Initiative); err != nil {
+		report("initiative", m.Initiative, err)
+	}
+	if m.Movement, err = ParseMovement(m.Speed); err != nil {
+		report("speed", m.Speed, err)
+	}
+	if m.SpecialSenses, err = ParseSenses(m.Senses); err != nil {
+		report("senses", m.Senses, err)
+	}
+	if m.Defenses.Resistances, err = ParseDamageDefenses(m.Resistances); err != nil {
+		report("resistances", m.Resistances, err)
+	}
+	if m.Defenses.DamageImmunities, err = ParseDamageDefenses(m.DamageImmunities); err != nil {
+		report("damage_immunities", m.DamageImmunities, err)
+	}
+	if m.Defenses.ConditionImmunities, err = ParseConditionImmunities(m.ConditionImmunities); err != nil {
+		report("condition_immunities", m.ConditionImmunities, err)
+	}
+	challenge, err := ParseChallengeDetail(m.CRDetail)
+	if err != nil {
+		report("cr_detail", m.CRDetail, err)
+	}
+	m.LairXP, m.ProficiencyBonus = challenge.LairXP, challenge.ProficiencyBonus
+	m.ParsedActions = ActionSet{
+		Actions:          ParseActions(m.Actions),
+		BonusActions:     ParseActions(m.BonusActions),
+		Reactions:        ParseActions(m.Reactions),
+		LegendaryActions: ParseActions(m.LegendaryActions),
+	}
+	m.ParsedActions.Multiattack = ParseMultiattack(m.Actions, m.ParsedActions.Actions)
+	return errs
+}
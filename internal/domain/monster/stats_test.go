@@ -0,0 +1,313 @@
+package monster
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestParseHitPoints(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected HitPoints
+		wantErr  bool
+	}{
+		{input: "150 (20d10 + 40)", expected: HitPoints{Average: 150, Dice: Dice{Count: 20, Sides: 10, Bonus: 40}}},
+		{input: "7 (2d6)", expected: HitPoints{Average: 7, Dice: Dice{Count: 2, Sides: 6}}},
+		{input: "9 (2d8 − 0)", expected: HitPoints{Average: 9, Dice: Dice{Count: 2, Sides: 8}}},
+		{input: "1 (1d4 − 1)", expected: HitPoints{Average: 1, Dice: Dice{Count: 1, Sides: 4, Bonus: -1}}},
+		{input: "5 (2d4-0)", expected: HitPoints{Average: 5, Dice: Dice{Count: 2, Sides: 4}}},
+		{input: "1", expected: HitPoints{Average: 1}},
+		{input: "", wantErr: true},
+		{input: "metà dei PF del suo evocatore", wantErr: true},
+		{input: "150 (20d10 + 40", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseHitPoints(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnparsable) {
+					t.Errorf("expected ErrUnparsable, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseArmorClass(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ArmorClass
+		wantErr  bool
+	}{
+		{input: "17", expected: ArmorClass{Value: 17}},
+		{input: "15 (armatura naturale)", expected: ArmorClass{Value: 15, Note: "armatura naturale"}},
+		{input: " 12 ", expected: ArmorClass{Value: 12}},
+		{input: "", wantErr: true},
+		{input: "diciassette", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseArmorClass(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnparsable) {
+					t.Errorf("expected ErrUnparsable, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseInitiative(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Initiative
+		wantErr  bool
+	}{
+		{input: "+7 (17)", expected: Initiative{Modifier: 7, Score: 17}},
+		{input: "−2 (8)", expected: Initiative{Modifier: -2, Score: 8}},
+		{input: "–1 (9)", expected: Initiative{Modifier: -1, Score: 9}},
+		{input: "+14 (24)", expected: Initiative{Modifier: 14, Score: 24}},
+		{input: "+3", expected: Initiative{Modifier: 3, Score: 13}},
+		{input: "", wantErr: true},
+		{input: "7 (17)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseInitiative(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnparsable) {
+					t.Errorf("expected ErrUnparsable, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseChallengeDetail(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ChallengeDetail
+		wantErr  bool
+	}{
+		{input: "10 (PE 5.900, o 7.200 nella tana; BC +4)", expected: ChallengeDetail{XP: 5900, LairXP: 7200, ProficiencyBonus: 4}},
+		{input: "0 (PE 0; BC +2)", expected: ChallengeDetail{ProficiencyBonus: 2}},
+		{input: "30 (PE 155.000; BC +9)", expected: ChallengeDetail{XP: 155000, ProficiencyBonus: 9}},
+		// Spacing slips found in the dataset
+		{input: "2 (PE 450;BC +2)", expected: ChallengeDetail{XP: 450, ProficiencyBonus: 2}},
+		{input: "14 (PE 11.500, o 13.000nella tana; BC +5)", expected: ChallengeDetail{XP: 11500, LairXP: 13000, ProficiencyBonus: 5}},
+		{input: "", wantErr: true},
+		{input: "10 (BC +4)", wantErr: true},
+		{input: "10 (PE 5.900)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseChallengeDetail(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnparsable) {
+					t.Errorf("expected ErrUnparsable, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMonster_EncounterXP(t *testing.T) {
+	dragon := Monster{XP: 5900, LairXP: 7200}
+	if dragon.EncounterXP(false) != 5900 || dragon.EncounterXP(true) != 7200 {
+		t.Errorf("expected 5900 outside and 7200 in the lair, got %d and %d", dragon.EncounterXP(false), dragon.EncounterXP(true))
+	}
+	ogre := Monster{XP: 450}
+	if ogre.EncounterXP(true) != 450 {
+		t.Errorf("expected monsters without a lair to keep their XP, got %d", ogre.EncounterXP(true))
+	}
+}
+
+func TestDice(t *testing.T) {
+	tests := []struct {
+		dice            Dice
+		expectedString  string
+		expectedAverage int
+	}{
+		{Dice{Count: 20, Sides: 10, Bonus: 40}, "20d10 + 40", 150},
+		{Dice{Count: 2, Sides: 6}, "2d6", 7},
+		{Dice{Count: 1, Sides: 4, Bonus: -1}, "1d4 − 1", 1},
+		{Dice{}, "", 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dice.String(); got != tt.expectedString {
+			t.Errorf("String() = %q, expected %q", got, tt.expectedString)
+		}
+		if got := tt.dice.Average(); got != tt.expectedAverage {
+			t.Errorf("%s: Average() = %d, expected %d", tt.expectedString, got, tt.expectedAverage)
+		}
+	}
+}
+
+func TestHitPoints_Roll(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 0))
+
+	ogre := HitPoints{Average: 68, Dice: Dice{Count: 8, Sides: 10, Bonus: 24}}
+	seen := map[int]bool{}
+	for range 100 {
+		hp := ogre.Roll(rng)
+		if hp < 32 || hp > 104 {
+			t.Fatalf("expected 8d10 + 24 between 32 and 104, got %d", hp)
+		}
+		seen[hp] = true
+	}
+	if len(seen) < 10 {
+		t.Errorf("expected rolled hit points to vary, got %v", seen)
+	}
+
+	if hp := (HitPoints{Average: 1}).Roll(rng); hp != 1 {
+		t.Errorf("expected fixed hit points to be kept, got %d", hp)
+	}
+	if hp := (HitPoints{Average: 1, Dice: Dice{Count: 1, Sides: 4, Bonus: -3}}).Roll(rand.New(rand.NewPCG(1, 0))); hp < 1 {
+		t.Errorf("expected at least 1 hit point, got %d", hp)
+	}
+}
+
+func TestMonster_ParseStats(t *testing.T) {
+	m := Monster{ID: "ogre", AC: "11", HP: "68 (8d10 + 24)", Initiative: "−1 (9)", Speed: "12 m", Senses: "Percezione passiva 8; scurovisione 18 m", CRDetail: "2 (PE 450; BC +2)"}
+	if errs := m.ParseStats(); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if m.ArmorClass.Value != 11 || m.HitPoints.Average != 68 || m.HitPoints.Dice.Count != 8 || m.InitiativeBonus.Modifier != -1 {
+		t.Errorf("got AC %+v, HP %+v, initiative %+v", m.ArmorClass, m.HitPoints, m.InitiativeBonus)
+	}
+	if m.Movement.Walk != 12 || m.SpecialSenses.Darkvision != 18 || m.SpecialSenses.PassivePerception != 8 {
+		t.Errorf("got movement %+v, senses %+v", m.Movement, m.SpecialSenses)
+	}
+	if m.ProficiencyBonus != 2 || m.HasLair() {
+		t.Errorf("got proficiency bonus %d, lair XP %d", m.ProficiencyBonus, m.LairXP)
+	}
+
+	broken := Monster{ID: "broken", AC: "", HP: "tanti", Initiative: "+2 (12)", Speed: "9 m", Senses: "Percezione passiva 10", CRDetail: "1 (PE 200; BC +2)"}
+	errs := broken.ParseStats()
+	if len(errs) != 2 || errs[0].Field != "ac" || errs[1].Field != "hp" || errs[1].Value != "tanti" {
+		t.Fatalf("expected ac and hp errors, got %v", errs)
+	}
+	if !errors.Is(errs[1], ErrUnparsable) {
+		t.Errorf("expected the error to wrap ErrUnparsable, got %v", errs[1])
+	}
+	if broken.HitPoints != (HitPoints{}) || broken.InitiativeBonus.Score != 12 {
+		t.Errorf("expected zero HP and parsed initiative, got %+v and %+v", broken.HitPoints, broken.InitiativeBonus)
+	}
+}
+
+func TestParseMovement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Movement
+		wantErr  bool
+	}{
+		{input: "9 m", expected: Movement{Walk: 9}},
+		{input: "3 m, nuoto 12 m", expected: Movement{Walk: 3, Swim: 12}},
+		{input: "1,5 m, volo 9 m (fluttuare)", expected: Movement{Walk: 1.5, Fly: 9, Hover: true}},
+		{input: "12 m, scavo 9 m, scalata 4,5 m", expected: Movement{Walk: 12, Burrow: 9, Climb: 4.5}},
+		{input: "9 metri, nuoto 9 metri", expected: Movement{Walk: 9, Swim: 9}},
+		{input: "9 m, 12 m (solo in forma di lupo)", expected: Movement{Walk: 12}},
+		{input: "9 m, scalata o volo 6 m (a scelta del GM)", expected: Movement{Walk: 9, Climb: 6, Fly: 6}},
+		{input: "0 m, volo 9 m (fluttuare)", expected: Movement{Fly: 9, Hover: true}},
+		{input: "", wantErr: true},
+		{input: "9 m, teletrasporto 9 m", wantErr: true},
+		{input: "veloce", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMovement(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnparsable) {
+					t.Errorf("expected ErrUnparsable, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseSenses(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Senses
+		wantErr  bool
+	}{
+		{input: "Percezione passiva 10", expected: Senses{PassivePerception: 10}},
+		{input: "percezione passiva 17", expected: Senses{PassivePerception: 17}},
+		{
+			input:    "Percezione passiva 11; percezione tellurica 18 m, scurovisione 18 m",
+			expected: Senses{PassivePerception: 11, Tremorsense: 18, Darkvision: 18},
+		},
+		{input: "Percezione passiva 12;scurovisione 18 m", expected: Senses{PassivePerception: 12, Darkvision: 18}},
+		{input: "Percezione passiva 16; vista cieca 3 m, vista pura 36 m", expected: Senses{PassivePerception: 16, Blindsight: 3, Truesight: 36}},
+		{
+			input: "Percezione passiva 13; scurovisione 36 m (non ostacolata dall'oscurità magica)",
+			expected: Senses{
+				PassivePerception: 13, Darkvision: 36,
+				Notes: "scurovisione 36 m (non ostacolata dall'oscurità magica)",
+			},
+		},
+		{input: "", wantErr: true},
+		{input: "scurovisione 18 m", wantErr: true},
+		{input: "Percezione passiva 10; sesto senso 9 m", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSenses(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnparsable) {
+					t.Errorf("expected ErrUnparsable, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -2,14 +2,25 @@ package config
 
 import (
 	"os"
+	"strings"
 	"time"
 )
 
+// Storage backends for parties, saved encounters and campaigns
+const (
+	StorageMemory = "memory"
+	StorageSQLite = "sqlite"
+)
+
 type Config struct {
 	Environment     string
 	Host            string
 	Port            string
 	LogLevel        string
+	StorageBackend  string
+	DatabasePath    string
+	MonsterImports  []string
+	ShareSecret     string
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	IdleTimeout     time.Duration
@@ -22,6 +33,10 @@ func NewConfig() *Config {
 		Host:            getEnv("HOST", ""),
 		Port:            getEnv("PORT", "8080"),
 		LogLevel:        getEnv("LOG_LEVEL", "info"),
+		StorageBackend:  getEnv("STORAGE_BACKEND", StorageMemory),
+		DatabasePath:    getEnv("DATABASE_PATH", "due-draghi.db"),
+		MonsterImports:  getEnvList("MONSTER_IMPORT_PATHS"),
+		ShareSecret:     getEnv("SHARE_SECRET", ""),
 		ReadTimeout:     15 * time.Second,
 		WriteTimeout:    15 * time.Second,
 		IdleTimeout:     60 * time.Second,
@@ -43,3 +58,14 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvList splits a comma-separated variable, skipping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
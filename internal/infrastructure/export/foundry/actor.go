@@ -0,0 +1,201 @@
+// Package foundry exports monsters as Foundry VTT actors of the dnd5e system.
+//
+// An actor is the JSON document accepted by "Import Data" on an NPC actor:
+// the statblock becomes system data (abilities, AC, HP, movement, senses,
+// traits) and every trait and action becomes a feat item. Values Foundry
+// knows as keys, such as damage types and conditions, are mapped from the
+// Italian vocabulary; anything else is kept as custom text.
+package foundry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// FlagScope is the flag namespace carrying the ID of the exported monster.
+const FlagScope = "due-draghi-combattimenti"
+
+// defaultImage is the portrait Foundry uses for actors without one.
+const defaultImage = "icons/svg/mystery-man.svg"
+
+// Actor is a Foundry dnd5e NPC actor.
+type Actor struct {
+	Name           string                    `json:"name"`
+	Type           string                    `json:"type"`
+	Img            string                    `json:"img"`
+	System         System                    `json:"system"`
+	Items          []Item                    `json:"items"`
+	PrototypeToken PrototypeToken            `json:"prototypeToken"`
+	Flags          map[string]map[string]any `json:"flags"`
+}
+
+// System holds the dnd5e data of an NPC.
+type System struct {
+	Abilities  map[string]Ability `json:"abilities"`
+	Attributes Attributes         `json:"attributes"`
+	Details    Details            `json:"details"`
+	Traits     Traits             `json:"traits"`
+	Skills     map[string]Skill   `json:"skills"`
+	Resources  Resources          `json:"resources"`
+}
+
+// Ability is an ability score; Proficient is 1 when the monster adds its proficiency bonus to the save.
+type Ability struct {
+	Value      int `json:"value"`
+	Proficient int `json:"proficient"`
+}
+
+// Attributes holds the combat values of an NPC.
+type Attributes struct {
+	AC       ArmorClass `json:"ac"`
+	HP       HitPoints  `json:"hp"`
+	Init     Initiative `json:"init"`
+	Movement Movement   `json:"movement"`
+	Senses   Senses     `json:"senses"`
+}
+
+// ArmorClass is a flat armor class.
+type ArmorClass struct {
+	Flat int    `json:"flat"`
+	Calc string `json:"calc"`
+}
+
+// HitPoints holds the average hit points and the dice formula they come from.
+type HitPoints struct {
+	Value   int    `json:"value"`
+	Max     int    `json:"max"`
+	Formula string `json:"formula"`
+}
+
+// Initiative holds the initiative bonus on top of the Dexterity modifier.
+type Initiative struct {
+	Bonus string `json:"bonus"`
+}
+
+// Movement holds the speeds of an NPC.
+type Movement struct {
+	Burrow float64 `json:"burrow"`
+	Climb  float64 `json:"climb"`
+	Fly    float64 `json:"fly"`
+	Swim   float64 `json:"swim"`
+	Walk   float64 `json:"walk"`
+	Units  string  `json:"units"`
+	Hover  bool    `json:"hover"`
+}
+
+// Senses holds the special senses of an NPC.
+type Senses struct {
+	Darkvision  float64 `json:"darkvision"`
+	Blindsight  float64 `json:"blindsight"`
+	Tremorsense float64 `json:"tremorsense"`
+	Truesight   float64 `json:"truesight"`
+	Units       string  `json:"units"`
+	Special     string  `json:"special"`
+}
+
+// Details holds the descriptive data of an NPC.
+type Details struct {
+	Biography Biography    `json:"biography"`
+	Alignment string       `json:"alignment"`
+	Type      CreatureType `json:"type"`
+	CR        float64      `json:"cr"`
+	XP        XP           `json:"xp"`
+}
+
+// Biography is the HTML biography of an NPC.
+type Biography struct {
+	Value string `json:"value"`
+}
+
+// CreatureType is a creature type key, with the size key of the members of a swarm.
+type CreatureType struct {
+	Value   string `json:"value"`
+	Subtype string `json:"subtype"`
+	Swarm   string `json:"swarm"`
+	Custom  string `json:"custom"`
+}
+
+// XP is the experience awarded for the NPC.
+type XP struct {
+	Value int `json:"value"`
+}
+
+// Traits holds size, damage and condition traits and languages.
+type Traits struct {
+	Size      string   `json:"size"`
+	DI        TraitSet `json:"di"`
+	DR        TraitSet `json:"dr"`
+	CI        TraitSet `json:"ci"`
+	Languages TraitSet `json:"languages"`
+}
+
+// TraitSet holds the keys Foundry knows and the rest as custom text.
+type TraitSet struct {
+	Value  []string `json:"value"`
+	Custom string   `json:"custom"`
+}
+
+// Skill is a skill proficiency: 1 for proficiency, 2 for expertise.
+type Skill struct {
+	Value   int    `json:"value"`
+	Ability string `json:"ability"`
+}
+
+// Resources holds legendary actions and resistances.
+type Resources struct {
+	Legact Uses `json:"legact"`
+	Legres Uses `json:"legres"`
+}
+
+// Uses is a resource with a current and maximum value.
+type Uses struct {
+	Value int `json:"value"`
+	Max   int `json:"max"`
+}
+
+// Item is a feat item carrying a trait or an action.
+type Item struct {
+	Name   string     `json:"name"`
+	Type   string     `json:"type"`
+	Img    string     `json:"img"`
+	System ItemSystem `json:"system"`
+}
+
+// ItemSystem holds the dnd5e data of a feat.
+type ItemSystem struct {
+	Description Biography  `json:"description"`
+	Activation  Activation `json:"activation"`
+	Type        FeatType   `json:"type"`
+}
+
+// Activation tells how a feat is used: action, bonus, reaction, legendary or empty for passive traits.
+type Activation struct {
+	Type string `json:"type"`
+	Cost int    `json:"cost"`
+}
+
+// FeatType is the category of a feat.
+type FeatType struct {
+	Value string `json:"value"`
+}
+
+// PrototypeToken is the token placed on the scene for the actor.
+type PrototypeToken struct {
+	Name        string  `json:"name"`
+	ActorLink   bool    `json:"actorLink"`
+	Disposition int     `json:"disposition"`
+	Width       float64 `json:"width"`
+	Height      float64 `json:"height"`
+}
+
+// Encode writes actor as indented JSON, leaving the HTML of descriptions unescaped.
+func Encode(w io.Writer, actor Actor) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(actor); err != nil {
+		return fmt.Errorf("failed to encode actor %s: %w", actor.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package foundry
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func findMonster(t *testing.T, id string) monster.Monster {
+	t.Helper()
+	m, ok := memory.NewMonsterRepository().FindByID(id)
+	if !ok {
+		t.Fatalf("monster %s not found", id)
+	}
+	return m
+}
+
+func TestNewActor_Golden(t *testing.T) {
+	for _, id := range []string{"aboleth", "sciame-di-pipistrelli", "goblin-guerriero", "drago-rosso-adulto"} {
+		t.Run(id, func(t *testing.T) {
+			var got bytes.Buffer
+			if err := Encode(&got, NewActor(findMonster(t, id))); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			golden := filepath.Join("testdata", id+".json")
+			if *update {
+				if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+					t.Fatalf("failed to write %s: %v", golden, err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read %s (run with -update to create it): %v", golden, err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("actor differs from %s (run with -update to accept it):\n%s", golden, got.String())
+			}
+		})
+	}
+}
+
+func TestMovement(t *testing.T) {
+	tests := map[string]Movement{
+		"9 m":                            {Walk: 9, Units: "m"},
+		"3 m, nuoto 12 m":                {Walk: 3, Swim: 12, Units: "m"},
+		"1,5 m, volo 9 m (fluttuare)":    {Walk: 1.5, Fly: 9, Units: "m", Hover: true},
+		"12 m, scavo 9 m, scalata 4,5 m": {Walk: 12, Burrow: 9, Climb: 4.5, Units: "m"},
+		"9 metri, nuoto 9 metri":         {Walk: 9, Swim: 9, Units: "m"},
+	}
+	for speed, expected := range tests {
+		parsed, err := monster.ParseMovement(speed)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := movement(parsed); got != expected {
+			t.Errorf("movement(%q) = %+v, expected %+v", speed, got, expected)
+		}
+	}
+}
+
+func TestSenses(t *testing.T) {
+	tests := map[string]Senses{
+		"Percezione passiva 10": {Units: "m"},
+		"Percezione passiva 11; percezione tellurica 18 m, scurovisione 18 m": {Tremorsense: 18, Darkvision: 18, Units: "m"},
+		"Percezione passiva 13; scurovisione 36 m (non ostacolata dall'oscurità magica)": {
+			Darkvision: 36, Units: "m", Special: "scurovisione 36 m (non ostacolata dall'oscurità magica)",
+		},
+	}
+	for text, expected := range tests {
+		parsed, err := monster.ParseSenses(text)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := senses(parsed); got != expected {
+			t.Errorf("senses(%q) = %+v, expected %+v", text, got, expected)
+		}
+	}
+}
+
+func TestSizeAndType(t *testing.T) {
+	tests := []struct {
+		name         string
+		monster      monster.Monster
+		expectedSize string
+		expectedType CreatureType
+	}{
+		{
+			name:         "single creature",
+			monster:      monster.Monster{Type: "Drago", Subtype: "cromatico", Size: "Enorme"},
+			expectedSize: "huge",
+			expectedType: CreatureType{Value: "dragon", Subtype: "cromatico"},
+		},
+		{
+			name:         "either size takes the first",
+			monster:      monster.Monster{Type: "Umanoide", Size: "Medio o Piccolo"},
+			expectedSize: "med",
+			expectedType: CreatureType{Value: "humanoid"},
+		},
+		{
+			name:         "swarm",
+			monster:      monster.Monster{Type: "Sciame", Size: "Mastodontica"},
+			expectedSize: "grg",
+			expectedType: CreatureType{Value: "custom", Custom: "Sciame"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, creatureType := sizeAndType(tt.monster)
+			if size != tt.expectedSize || creatureType != tt.expectedType {
+				t.Errorf("got %s %+v, expected %s %+v", size, creatureType, tt.expectedSize, tt.expectedType)
+			}
+		})
+	}
+}
+
+func TestNewActor_KnowsEveryDatasetValue(t *testing.T) {
+	for _, m := range memory.NewMonsterRepository().FindByMaxXP(1_000_000) {
+		actor := NewActor(m)
+		if actor.System.Details.Type.Value == "custom" && m.Type != "Sciame" {
+			t.Errorf("%s: unknown creature type %q (%s)", m.ID, m.Type, m.Size)
+		}
+		if actor.System.Attributes.HP.Formula == "" || actor.System.Attributes.AC.Flat == 0 {
+			t.Errorf("%s: unparsed HP %q or AC %q", m.ID, m.HP, m.AC)
+		}
+		if actor.System.Attributes.Movement == (Movement{Units: "m"}) {
+			t.Errorf("%s: unparsed speed %q", m.ID, m.Speed)
+		}
+		if m.Skills != "" && len(actor.System.Skills) == 0 {
+			t.Errorf("%s: unparsed skills %q", m.ID, m.Skills)
+		}
+	}
+}
+
+func TestWriteEncounter(t *testing.T) {
+	groups := []encounter.MonsterGroup{
+		{Monster: findMonster(t, "ogre"), Quantity: 1},
+		{Monster: findMonster(t, "goblin-guerriero"), Quantity: 3},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEncounter(&buf, groups); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+
+	var files, names []string
+	for _, f := range archive.File {
+		files = append(files, f.Name)
+
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", f.Name, err)
+		}
+
+		var actor Actor
+		if err := json.Unmarshal(data, &actor); err != nil {
+			t.Fatalf("%s is not an actor: %v", f.Name, err)
+		}
+		if actor.PrototypeToken.Name != actor.Name {
+			t.Errorf("%s: token %q does not match actor %q", f.Name, actor.PrototypeToken.Name, actor.Name)
+		}
+		names = append(names, actor.Name)
+	}
+
+	expectedFiles := []string{"ogre-1.json", "goblin-guerriero-1.json", "goblin-guerriero-2.json", "goblin-guerriero-3.json"}
+	if !slices.Equal(files, expectedFiles) {
+		t.Errorf("files = %v, expected %v", files, expectedFiles)
+	}
+	expectedNames := []string{"Ogre", "Goblin guerriero 1", "Goblin guerriero 2", "Goblin guerriero 3"}
+	if !slices.Equal(names, expectedNames) {
+		t.Errorf("actor names = %v, expected %v", names, expectedNames)
+	}
+}
@@ -0,0 +1,303 @@
+package foundry
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// legendaryActionUses is the number of legendary actions a monster can take each round.
+const legendaryActionUses = 3
+
+var (
+	legresPattern = regexp.MustCompile(`(?i)^resistenza leggendaria \((\d+)/giorno`)
+	strongPattern = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	emPattern     = regexp.MustCompile(`[*_](.+?)[*_]`)
+
+	// escapeHTML escapes the characters that would be read as markup, leaving apostrophes readable
+	escapeHTML = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+)
+
+// NewActor converts a monster into a Foundry dnd5e NPC actor.
+func NewActor(m monster.Monster) Actor {
+	size, creatureType := sizeAndType(m)
+	pb, _ := monster.ProficiencyBonusForCR(m.CR)
+
+	actor := Actor{
+		Name: m.Name,
+		Type: "npc",
+		Img:  defaultImage,
+		System: System{
+			Abilities: abilities(m),
+			Attributes: Attributes{
+				AC:       ArmorClass{Flat: m.ArmorClass.Value, Calc: "flat"},
+				HP:       hitPoints(m.HitPoints),
+				Init:     initiative(m),
+				Movement: movement(m.Movement),
+				Senses:   senses(m.SpecialSenses),
+			},
+			Details: Details{
+				Biography: biography(m),
+				Alignment: m.Alignment,
+				Type:      creatureType,
+				CR:        m.CRValue(),
+				XP:        XP{Value: m.XP},
+			},
+			Traits: Traits{
+				Size:      size,
+				DI:        damageTraits(m.Defenses.DamageImmunities),
+				DR:        damageTraits(m.Defenses.Resistances),
+				CI:        conditionTraits(m.Defenses.ConditionImmunities),
+				Languages: languages(m.Languages),
+			},
+			Skills:    skillSet(m, pb),
+			Resources: resources(m),
+		},
+		Items: items(m),
+		PrototypeToken: PrototypeToken{
+			Name:        m.Name,
+			Disposition: -1,
+			Width:       tokenSizes[size],
+			Height:      tokenSizes[size],
+		},
+		Flags: map[string]map[string]any{
+			FlagScope: {"monsterId": m.ID},
+		},
+	}
+	if m.Source != "" {
+		actor.Flags[FlagScope]["source"] = m.Source
+	}
+	return actor
+}
+
+// sizeAndType reads the size key and the creature type; types Foundry does not know,
+// such as the swarms of the dataset, are kept as custom text.
+func sizeAndType(m monster.Monster) (string, CreatureType) {
+	size := "med"
+	if words := strings.Fields(m.Size); len(words) > 0 {
+		if key := sizeKey(words[0]); key != "" {
+			size = key
+		}
+	}
+
+	creatureType := CreatureType{Subtype: m.Subtype}
+	if key, ok := creatureTypes[strings.ToLower(m.Type)]; ok {
+		creatureType.Value = key
+	} else {
+		creatureType.Value = "custom"
+		creatureType.Custom = m.Type
+	}
+	return size, creatureType
+}
+
+func abilities(m monster.Monster) map[string]Ability {
+	scores, mods, saves := m.AbilityScores, m.AbilityMods, m.SavingThrows
+	ability := func(score, mod int, save string) Ability {
+		a := Ability{Value: score}
+		if bonus, ok := parseModifier(save); ok && bonus != mod {
+			a.Proficient = 1
+		}
+		return a
+	}
+	return map[string]Ability{
+		"str": ability(scores.Strength, mods.Strength, saves.Strength),
+		"dex": ability(scores.Dexterity, mods.Dexterity, saves.Dexterity),
+		"con": ability(scores.Constitution, mods.Constitution, saves.Constitution),
+		"int": ability(scores.Intelligence, mods.Intelligence, saves.Intelligence),
+		"wis": ability(scores.Wisdom, mods.Wisdom, saves.Wisdom),
+		"cha": ability(scores.Charisma, mods.Charisma, saves.Charisma),
+	}
+}
+
+// hitPoints writes the dice with an ASCII minus, the only one Foundry rolls.
+func hitPoints(hp monster.HitPoints) HitPoints {
+	return HitPoints{
+		Value:   hp.Average,
+		Max:     hp.Average,
+		Formula: strings.ReplaceAll(hp.Dice.String(), "−", "-"),
+	}
+}
+
+// initiative returns the part of the initiative modifier that Dexterity does not explain.
+func initiative(m monster.Monster) Initiative {
+	bonus := m.InitiativeBonus.Modifier - m.AbilityMods.Dexterity
+	if bonus == 0 {
+		return Initiative{}
+	}
+	return Initiative{Bonus: strconv.Itoa(bonus)}
+}
+
+func movement(m monster.Movement) Movement {
+	return Movement{
+		Walk:   m.Walk,
+		Fly:    m.Fly,
+		Swim:   m.Swim,
+		Climb:  m.Climb,
+		Burrow: m.Burrow,
+		Units:  "m",
+		Hover:  m.Hover,
+	}
+}
+
+// senses leaves passive Perception to Foundry and the qualified senses in Special.
+func senses(s monster.Senses) Senses {
+	return Senses{
+		Darkvision:  s.Darkvision,
+		Blindsight:  s.Blindsight,
+		Tremorsense: s.Tremorsense,
+		Truesight:   s.Truesight,
+		Units:       "m",
+		Special:     s.Notes,
+	}
+}
+
+// damageTraits lists the damage types, whose keys Foundry shares, keeping qualified or chosen
+// ones as custom text.
+func damageTraits(defenses []monster.DamageDefense) TraitSet {
+	set := TraitSet{Value: []string{}}
+	var custom []string
+	for _, d := range defenses {
+		switch {
+		case d.Type == monster.DamageChosen:
+			custom = append(custom, d.Qualifier)
+		case d.Qualifier != "":
+			custom = append(custom, d.Type.Label()+" ("+d.Qualifier+")")
+		default:
+			set.Value = append(set.Value, string(d.Type))
+		}
+	}
+	set.Custom = strings.Join(custom, "; ")
+	return set
+}
+
+// conditionTraits lists the conditions, whose keys Foundry shares, keeping qualified ones as custom text.
+func conditionTraits(immunities []monster.ConditionImmunity) TraitSet {
+	set := TraitSet{Value: []string{}}
+	var custom []string
+	for _, i := range immunities {
+		if i.Qualifier != "" {
+			custom = append(custom, i.Condition.Label()+" ("+i.Qualifier+")")
+			continue
+		}
+		set.Value = append(set.Value, string(i.Condition))
+	}
+	set.Custom = strings.Join(custom, "; ")
+	return set
+}
+
+func languages(text string) TraitSet {
+	if strings.EqualFold(text, "nessuna") {
+		text = ""
+	}
+	return TraitSet{Value: []string{}, Custom: text}
+}
+
+// skillSet reads skills such as "Percezione +10, Storia +12", telling proficiency from expertise
+// by how much the bonus exceeds the ability modifier.
+func skillSet(m monster.Monster, pb int) map[string]Skill {
+	mods := map[string]int{
+		"str": m.AbilityMods.Strength,
+		"dex": m.AbilityMods.Dexterity,
+		"con": m.AbilityMods.Constitution,
+		"int": m.AbilityMods.Intelligence,
+		"wis": m.AbilityMods.Wisdom,
+		"cha": m.AbilityMods.Charisma,
+	}
+
+	result := map[string]Skill{}
+	for _, entry := range strings.Split(m.Skills, ",") {
+		fields := strings.Fields(entry)
+		if len(fields) < 2 {
+			continue
+		}
+		bonus, ok := parseModifier(fields[len(fields)-1])
+		skill, known := skills[strings.ToLower(strings.Join(fields[:len(fields)-1], " "))]
+		if !ok || !known {
+			continue
+		}
+
+		value := 1
+		if pb > 0 && bonus-mods[skill.ability] >= 2*pb {
+			value = 2
+		}
+		result[skill.key] = Skill{Value: value, Ability: skill.ability}
+	}
+	return result
+}
+
+func resources(m monster.Monster) Resources {
+	var r Resources
+	if len(m.LegendaryActions) > 0 {
+		r.Legact = Uses{Value: legendaryActionUses, Max: legendaryActionUses}
+	}
+	for _, t := range m.Traits {
+		if match := legresPattern.FindStringSubmatch(t.Name); match != nil {
+			uses, _ := strconv.Atoi(match[1])
+			r.Legres = Uses{Value: uses, Max: uses}
+		}
+	}
+	return r
+}
+
+func biography(m monster.Monster) Biography {
+	var b strings.Builder
+	if m.Equipment != "" {
+		b.WriteString("<p><strong>Equipaggiamento:</strong> " + escapeHTML(m.Equipment) + "</p>")
+	}
+	if m.CRDetail != "" {
+		b.WriteString("<p><strong>Grado di sfida:</strong> " + escapeHTML(m.CRDetail) + "</p>")
+	}
+	return Biography{Value: b.String()}
+}
+
+// items turns traits and actions into feats, in statblock order.
+func items(m monster.Monster) []Item {
+	groups := []struct {
+		entries    []monster.NamedDescription
+		activation string
+	}{
+		{m.Traits, ""},
+		{m.Actions, "action"},
+		{m.BonusActions, "bonus"},
+		{m.Reactions, "reaction"},
+		{m.LegendaryActions, "legendary"},
+	}
+
+	result := []Item{}
+	for _, g := range groups {
+		for _, entry := range g.entries {
+			item := Item{
+				Name: entry.Name,
+				Type: "feat",
+				Img:  "icons/svg/item-bag.svg",
+				System: ItemSystem{
+					Description: Biography{Value: description(entry.Description)},
+					Activation:  Activation{Type: g.activation},
+					Type:        FeatType{Value: "monster"},
+				},
+			}
+			if g.activation != "" {
+				item.System.Activation.Cost = 1
+			}
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// description turns the markdown emphasis of the dataset into HTML, collapsing repeated spaces.
+func description(text string) string {
+	text = escapeHTML(strings.Join(strings.Fields(text), " "))
+	text = strongPattern.ReplaceAllString(text, "<strong>$1</strong>")
+	text = emPattern.ReplaceAllString(text, "<em>$1</em>")
+	return "<p>" + text + "</p>"
+}
+
+// parseModifier reads a signed modifier such as "+5" or "−2".
+func parseModifier(s string) (int, bool) {
+	s = strings.TrimPrefix(strings.ReplaceAll(strings.TrimSpace(s), "−", "-"), "+")
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
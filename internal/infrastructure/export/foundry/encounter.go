@@ -0,0 +1,43 @@
+package foundry
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+)
+
+// WriteEncounter writes a zip archive with one actor per monster instance of groups.
+// Instances of a group are numbered, both in the actor name and in the file name
+// (ogre-1.json, ogre-2.json, ...), so that tokens can be told apart on the scene.
+func WriteEncounter(w io.Writer, groups []encounter.MonsterGroup) error {
+	archive := zip.NewWriter(w)
+
+	for _, g := range groups {
+		for i := 1; i <= g.Quantity; i++ {
+			actor := NewActor(g.Monster)
+			if g.Quantity > 1 {
+				actor.Name += " " + strconv.Itoa(i)
+				actor.PrototypeToken.Name = actor.Name
+			}
+
+			file, err := archive.CreateHeader(&zip.FileHeader{
+				Name:   fmt.Sprintf("%s-%d.json", g.Monster.ID, i),
+				Method: zip.Deflate,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to add %s to the archive: %w", actor.Name, err)
+			}
+			if err := Encode(file, actor); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to close the archive: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package foundry
+
+import "strings"
+
+// sizePrefixes maps the stem of Italian sizes, in any gender and number, to Foundry size keys.
+var sizePrefixes = []struct {
+	prefix string
+	key    string
+}{
+	{"minuscol", "tiny"},
+	{"piccol", "sm"},
+	{"medi", "med"},
+	{"grand", "lg"},
+	{"enorm", "huge"},
+	{"mastodontic", "grg"},
+}
+
+// tokenSizes is the side of the token, in grid squares, of each size key.
+var tokenSizes = map[string]float64{
+	"tiny": 0.5,
+	"sm":   1,
+	"med":  1,
+	"lg":   2,
+	"huge": 3,
+	"grg":  4,
+}
+
+// creatureTypes maps Italian creature types to Foundry type keys.
+var creatureTypes = map[string]string{
+	"aberrazione": "aberration",
+	"bestia":      "beast",
+	"celestiale":  "celestial",
+	"costrutto":   "construct",
+	"drago":       "dragon",
+	"elementale":  "elemental",
+	"folletto":    "fey",
+	"immondo":     "fiend",
+	"gigante":     "giant",
+	"umanoide":    "humanoid",
+	"mostruosità": "monstrosity",
+	"melma":       "ooze",
+	"vegetale":    "plant",
+	"non morto":   "undead",
+}
+
+// skills maps Italian skill names to Foundry skill keys and their ability.
+var skills = map[string]struct {
+	key     string
+	ability string
+}{
+	"acrobazia":          {"acr", "dex"},
+	"addestrare animali": {"ani", "wis"},
+	"arcano":             {"arc", "int"},
+	"atletica":           {"ath", "str"},
+	"inganno":            {"dec", "cha"},
+	"storia":             {"his", "int"},
+	"intuizione":         {"ins", "wis"},
+	"intimidire":         {"itm", "cha"},
+	"indagare":           {"inv", "int"},
+	"medicina":           {"med", "wis"},
+	"natura":             {"nat", "int"},
+	"percezione":         {"prc", "wis"},
+	"intrattenere":       {"prf", "cha"},
+	"persuasione":        {"per", "cha"},
+	"religione":          {"rel", "int"},
+	"rapidità di mano":   {"slt", "dex"},
+	"furtività":          {"ste", "dex"},
+	"sopravvivenza":      {"sur", "wis"},
+}
+
+// sizeKey returns the Foundry key of an Italian size word, or "" when it is not a size.
+func sizeKey(word string) string {
+	word = strings.ToLower(word)
+	for _, s := range sizePrefixes {
+		if strings.HasPrefix(word, s.prefix) {
+			return s.key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+)
+
+// CampaignRepository implements the campaign.CampaignRepository interface using in-memory data
+type CampaignRepository struct {
+	mu        sync.RWMutex
+	campaigns map[string]campaign.Campaign
+}
+
+// NewCampaignRepository creates a new in-memory campaign repository
+func NewCampaignRepository() *CampaignRepository {
+	return &CampaignRepository{campaigns: make(map[string]campaign.Campaign)}
+}
+
+// Save stores a copy of the campaign
+func (r *CampaignRepository) Save(c *campaign.Campaign) error {
+	if c.ID == "" {
+		return fmt.Errorf("campaign ID cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.campaigns[c.ID] = copyCampaign(*c)
+	return nil
+}
+
+// FindByID returns a copy of the stored campaign
+func (r *CampaignRepository) FindByID(id string) (*campaign.Campaign, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, exists := r.campaigns[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", campaign.ErrCampaignNotFound, id)
+	}
+
+	c = copyCampaign(c)
+	return &c, nil
+}
+
+// FindAll returns copies of all stored campaigns ordered by name
+func (r *CampaignRepository) FindAll() ([]campaign.Campaign, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	campaigns := make([]campaign.Campaign, 0, len(r.campaigns))
+	for _, c := range r.campaigns {
+		campaigns = append(campaigns, copyCampaign(c))
+	}
+	sort.Slice(campaigns, func(i, j int) bool {
+		return campaigns[i].Name < campaigns[j].Name
+	})
+	return campaigns, nil
+}
+
+// Delete removes the campaign with the given ID
+func (r *CampaignRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.campaigns[id]; !exists {
+		return fmt.Errorf("%w: %s", campaign.ErrCampaignNotFound, id)
+	}
+	delete(r.campaigns, id)
+	return nil
+}
+
+// copyCampaign returns a campaign that shares no slices with the original
+func copyCampaign(c campaign.Campaign) campaign.Campaign {
+	c.PartyIDs = append([]string(nil), c.PartyIDs...)
+	c.EncounterIDs = append([]string(nil), c.EncounterIDs...)
+	return c
+}
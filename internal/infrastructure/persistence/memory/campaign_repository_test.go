@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+)
+
+func TestPartyRepository(t *testing.T) {
+	repo := NewPartyRepository()
+
+	party := &campaign.Party{ID: "p1", Name: "La Compagnia", Members: []campaign.Member{{Name: "Aria", Level: 5}}}
+	if err := repo.Save(party); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(&campaign.Party{ID: "p2", Name: "Avventurieri"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The stored party must not change when the caller's copy does
+	party.Members[0].Level = 20
+	found, err := repo.FindByID("p1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.Members[0].Level != 5 {
+		t.Errorf("expected stored level 5, got %d", found.Members[0].Level)
+	}
+
+	all, _ := repo.FindAll()
+	if len(all) != 2 || all[0].ID != "p2" {
+		t.Errorf("expected parties ordered by name, got %+v", all)
+	}
+
+	if err := repo.Delete("p1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.FindByID("p1"); !errors.Is(err, campaign.ErrPartyNotFound) {
+		t.Errorf("expected ErrPartyNotFound, got %v", err)
+	}
+	if err := repo.Delete("p1"); !errors.Is(err, campaign.ErrPartyNotFound) {
+		t.Errorf("expected ErrPartyNotFound, got %v", err)
+	}
+}
+
+func TestSavedEncounterRepository(t *testing.T) {
+	repo := NewSavedEncounterRepository()
+
+	saved := &campaign.SavedEncounter{
+		ID:         "e1",
+		Name:       "Ogre al ponte",
+		Ruleset:    encounter.Ruleset2014,
+		Difficulty: encounter.DifficultyHard,
+		Levels:     []int{3, 3, 4},
+		Monsters:   []campaign.EncounterMonster{{MonsterID: "ogre", Quantity: 2}, {MonsterID: "aboleth", Quantity: 1, InLair: true}},
+
+		CountWeakMonsters: true,
+	}
+	if err := repo.Save(saved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, err := repo.FindByID("e1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(found, saved) {
+		t.Errorf("expected %+v, got %+v", saved, found)
+	}
+
+	if err := repo.Delete("e1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.FindByID("e1"); !errors.Is(err, campaign.ErrEncounterNotFound) {
+		t.Errorf("expected ErrEncounterNotFound, got %v", err)
+	}
+}
+
+func TestCampaignRepository(t *testing.T) {
+	repo := NewCampaignRepository()
+
+	c := &campaign.Campaign{ID: "c1", Name: "La Maledizione", PartyIDs: []string{"p1"}, EncounterIDs: []string{"e1", "e2"}}
+	if err := repo.Save(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, err := repo.FindByID("c1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(found, c) {
+		t.Errorf("expected %+v, got %+v", c, found)
+	}
+
+	if err := repo.Delete("c1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.FindByID("c1"); !errors.Is(err, campaign.ErrCampaignNotFound) {
+		t.Errorf("expected ErrCampaignNotFound, got %v", err)
+	}
+}
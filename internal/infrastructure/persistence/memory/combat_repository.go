@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/combat"
+)
+
+// combatTTL is how long an untouched combat is kept in memory
+const combatTTL = 24 * time.Hour
+
+// CombatRepository implements the combat.Repository interface using in-memory data
+type CombatRepository struct {
+	combats *ttlStore[combat.Combat]
+}
+
+// NewCombatRepository creates a new in-memory combat repository
+func NewCombatRepository() *CombatRepository {
+	return &CombatRepository{
+		combats: newTTLStore(combatTTL, copyCombat, combat.ErrCombatNotFound),
+	}
+}
+
+// Save stores a copy of the combat
+func (r *CombatRepository) Save(c *combat.Combat) error {
+	if c.ID == "" {
+		return fmt.Errorf("combat ID cannot be empty")
+	}
+	r.combats.put(c.ID, *c)
+	return nil
+}
+
+// FindByID returns a copy of the stored combat
+func (r *CombatRepository) FindByID(id string) (*combat.Combat, error) {
+	c, err := r.combats.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Update applies change to the stored combat and saves the result, one update at a time
+func (r *CombatRepository) Update(id string, change func(c *combat.Combat) error) (*combat.Combat, error) {
+	c, err := r.combats.update(id, change)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// copyCombat returns a combat that shares no slices with the original
+func copyCombat(c combat.Combat) combat.Combat {
+	c.Combatants = append([]combat.Combatant(nil), c.Combatants...)
+	return c
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+)
+
+// compositionTTL is how long an untouched composition is kept in memory
+const compositionTTL = 24 * time.Hour
+
+// CompositionRepository implements the encounter.CompositionRepository interface using in-memory data
+type CompositionRepository struct {
+	compositions *ttlStore[encounter.Composition]
+}
+
+// NewCompositionRepository creates a new in-memory composition repository
+func NewCompositionRepository() *CompositionRepository {
+	return &CompositionRepository{
+		compositions: newTTLStore(compositionTTL, copyComposition, encounter.ErrCompositionNotFound),
+	}
+}
+
+// Save stores a copy of the composition
+func (r *CompositionRepository) Save(composition *encounter.Composition) error {
+	if composition.ID == "" {
+		return fmt.Errorf("composition ID cannot be empty")
+	}
+	r.compositions.put(composition.ID, *composition)
+	return nil
+}
+
+// FindByID returns a copy of the stored composition
+func (r *CompositionRepository) FindByID(id string) (*encounter.Composition, error) {
+	composition, err := r.compositions.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return &composition, nil
+}
+
+// Update applies change to the stored composition and saves the result, one update at a time
+func (r *CompositionRepository) Update(id string, change func(c *encounter.Composition) error) (*encounter.Composition, error) {
+	composition, err := r.compositions.update(id, change)
+	if err != nil {
+		return nil, err
+	}
+	return &composition, nil
+}
+
+// copyComposition returns a composition that shares no slices with the original
+func copyComposition(c encounter.Composition) encounter.Composition {
+	c.Groups = append([]encounter.MonsterGroup(nil), c.Groups...)
+	c.Party.Characters = append([]encounter.Character(nil), c.Party.Characters...)
+	return c
+}
@@ -10,7 +10,10 @@ import (
 type EncounterRepository struct {
 	xpData2024       map[int]map[string]int
 	xpThresholds2014 map[int]map[string]int
+	xpPerDay2014     map[int]int
 	multiplierRanges []encounter.MultiplierRange
+	multiplierSteps  []float64
+	archetypes       []encounter.Archetype
 }
 
 // NewEncounterRepository creates a new in-memory encounter repository
@@ -60,14 +63,40 @@ func NewEncounterRepository() *EncounterRepository {
 			19: {"Facile": 2400, "Media": 4900, "Difficile": 7300, "Letale": 10900},
 			20: {"Facile": 2800, "Media": 5700, "Difficile": 8500, "Letale": 12700},
 		},
+		// Adjusted XP per day for each character, from the 2014 DMG adventuring day table
+		xpPerDay2014: map[int]int{
+			1: 300, 2: 600, 3: 1200, 4: 1700, 5: 3500,
+			6: 4000, 7: 5000, 8: 6000, 9: 7500, 10: 9000,
+			11: 10500, 12: 11500, 13: 13500, 14: 15000, 15: 18000,
+			16: 20000, 17: 25000, 18: 27000, 19: 30000, 20: 40000,
+		},
+		// Monster count multipliers of the 2014 DMG: 1 monster ×1, 2 ×1.5, 3-6 ×2,
+		// 7-10 ×2.5, 11-14 ×3, 15 or more ×4
 		multiplierRanges: []encounter.MultiplierRange{
 			{MaxMonsters: 1, Multiplier: 1.0},
 			{MaxMonsters: 2, Multiplier: 1.5},
-			{MaxMonsters: 3, Multiplier: 2.0},
-			{MaxMonsters: 7, Multiplier: 2.5},
-			{MaxMonsters: 11, Multiplier: 3.0},
-			{MaxMonsters: 15, Multiplier: 4.0},
-			{MaxMonsters: 99, Multiplier: 5.0},
+			{MaxMonsters: 6, Multiplier: 2.0},
+			{MaxMonsters: 10, Multiplier: 2.5},
+			{MaxMonsters: 14, Multiplier: 3.0},
+			{MaxMonsters: 99, Multiplier: 4.0},
+		},
+		// The DMG adds a 0.5 step below and a 5 step above the monster count table,
+		// reachable only through the party size shift
+		multiplierSteps: []float64{0.5, 1.0, 1.5, 2.0, 2.5, 3.0, 4.0, 5.0},
+		archetypes: []encounter.Archetype{
+			{ID: "solo-boss", Name: "Boss solitario", Roles: []encounter.ArchetypeRole{
+				{Name: "boss", Share: 1.0, MinCount: 1, MaxCount: 1},
+			}},
+			{ID: "boss-minions", Name: "Boss + minioni", Roles: []encounter.ArchetypeRole{
+				{Name: "boss", Share: 0.6, MinCount: 1, MaxCount: 1},
+				{Name: "minioni", Share: 0.4, MinCount: 2, MaxCount: 6},
+			}},
+			{ID: "horde", Name: "Orda", Roles: []encounter.ArchetypeRole{
+				{Name: "orda", Share: 1.0, MinCount: 6, MaxCount: 12},
+			}},
+			{ID: "elite-pair", Name: "Coppia d'élite", Roles: []encounter.ArchetypeRole{
+				{Name: "élite", Share: 1.0, MinCount: 2, MaxCount: 2},
+			}},
 		},
 	}
 }
@@ -102,20 +131,42 @@ func (r *EncounterRepository) GetThresholdFor2014(level int, difficulty encounte
 	return threshold, nil
 }
 
-// GetMultiplierFor2014 returns the encounter multiplier based on number of monsters
-func (r *EncounterRepository) GetMultiplierFor2014(numMonsters int) (float64, error) {
+// GetXPPerDay returns the adjusted XP per day for a character of the given level (2014 rules)
+func (r *EncounterRepository) GetXPPerDay(level int) (int, error) {
+	xp, exists := r.xpPerDay2014[level]
+	if !exists {
+		return 0, fmt.Errorf("unsupported character level: %d", level)
+	}
+	return xp, nil
+}
+
+// GetMultiplierFor2014 returns the encounter multiplier based on number of monsters and party size
+func (r *EncounterRepository) GetMultiplierFor2014(numMonsters, partySize int) (encounter.MultiplierStep, error) {
 	if numMonsters < 1 {
-		return 0, fmt.Errorf("number of monsters must be at least 1")
+		return encounter.MultiplierStep{}, fmt.Errorf("number of monsters must be at least 1")
+	}
+	if partySize < 1 {
+		return encounter.MultiplierStep{}, fmt.Errorf("party size must be at least 1")
 	}
 
-	for _, multiplierRange := range r.multiplierRanges {
+	// Default to highest range for very large numbers
+	rangeIndex := len(r.multiplierRanges) - 1
+	for i, multiplierRange := range r.multiplierRanges {
 		if numMonsters <= multiplierRange.MaxMonsters {
-			return multiplierRange.Multiplier, nil
+			rangeIndex = i
+			break
 		}
 	}
 
-	// Default to highest multiplier for very large numbers
-	return r.multiplierRanges[len(r.multiplierRanges)-1].Multiplier, nil
+	// Steps start one below the first range
+	shift := encounter.MultiplierShiftForPartySize(partySize)
+	stepIndex := rangeIndex + 1 + shift
+
+	return encounter.MultiplierStep{
+		BaseMultiplier: r.multiplierRanges[rangeIndex].Multiplier,
+		Shift:          shift,
+		Multiplier:     r.multiplierSteps[stepIndex],
+	}, nil
 }
 
 // GetAllDifficultiesFor2024 returns all available difficulties for 2024 ruleset
@@ -155,3 +206,18 @@ func (r *EncounterRepository) GetSupportedLevels() []int {
 
 	return levels
 }
+
+// GetArchetype returns the encounter archetype with the given ID
+func (r *EncounterRepository) GetArchetype(id string) (encounter.Archetype, error) {
+	for _, a := range r.archetypes {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return encounter.Archetype{}, fmt.Errorf("%w: %s", encounter.ErrArchetypeNotFound, id)
+}
+
+// GetAllArchetypes returns all available encounter archetypes
+func (r *EncounterRepository) GetAllArchetypes() []encounter.Archetype {
+	return r.archetypes
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+)
+
+func TestGetMultiplierFor2014(t *testing.T) {
+	repo := NewEncounterRepository()
+
+	tests := []struct {
+		name               string
+		numMonsters        int
+		partySize          int
+		expectedBase       float64
+		expectedShift      int
+		expectedMultiplier float64
+	}{
+		{name: "1 monster, 4 characters", numMonsters: 1, partySize: 4, expectedBase: 1.0, expectedShift: 0, expectedMultiplier: 1.0},
+		{name: "2 monsters, 4 characters", numMonsters: 2, partySize: 4, expectedBase: 1.5, expectedShift: 0, expectedMultiplier: 1.5},
+		{name: "3 monsters, 4 characters", numMonsters: 3, partySize: 4, expectedBase: 2.0, expectedShift: 0, expectedMultiplier: 2.0},
+		{name: "6 monsters, 4 characters", numMonsters: 6, partySize: 4, expectedBase: 2.0, expectedShift: 0, expectedMultiplier: 2.0},
+		{name: "7 monsters, 4 characters", numMonsters: 7, partySize: 4, expectedBase: 2.5, expectedShift: 0, expectedMultiplier: 2.5},
+		{name: "10 monsters, 4 characters", numMonsters: 10, partySize: 4, expectedBase: 2.5, expectedShift: 0, expectedMultiplier: 2.5},
+		{name: "11 monsters, 4 characters", numMonsters: 11, partySize: 4, expectedBase: 3.0, expectedShift: 0, expectedMultiplier: 3.0},
+		{name: "14 monsters, 4 characters", numMonsters: 14, partySize: 4, expectedBase: 3.0, expectedShift: 0, expectedMultiplier: 3.0},
+		{name: "15 monsters, 4 characters", numMonsters: 15, partySize: 4, expectedBase: 4.0, expectedShift: 0, expectedMultiplier: 4.0},
+		{name: "200 monsters, 4 characters", numMonsters: 200, partySize: 4, expectedBase: 4.0, expectedShift: 0, expectedMultiplier: 4.0},
+		{name: "1 monster, 2 characters", numMonsters: 1, partySize: 2, expectedBase: 1.0, expectedShift: 1, expectedMultiplier: 1.5},
+		{name: "15 monsters, 1 character", numMonsters: 15, partySize: 1, expectedBase: 4.0, expectedShift: 1, expectedMultiplier: 5.0},
+		{name: "1 monster, 6 characters", numMonsters: 1, partySize: 6, expectedBase: 1.0, expectedShift: -1, expectedMultiplier: 0.5},
+		{name: "4 monsters, 7 characters", numMonsters: 4, partySize: 7, expectedBase: 2.0, expectedShift: -1, expectedMultiplier: 1.5},
+		{name: "3 monsters, 5 characters", numMonsters: 3, partySize: 5, expectedBase: 2.0, expectedShift: 0, expectedMultiplier: 2.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, err := repo.GetMultiplierFor2014(tt.numMonsters, tt.partySize)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if step.BaseMultiplier != tt.expectedBase {
+				t.Errorf("expected base multiplier %.1f, got %.1f", tt.expectedBase, step.BaseMultiplier)
+			}
+			if step.Shift != tt.expectedShift {
+				t.Errorf("expected shift %d, got %d", tt.expectedShift, step.Shift)
+			}
+			if step.Multiplier != tt.expectedMultiplier {
+				t.Errorf("expected multiplier %.1f, got %.1f", tt.expectedMultiplier, step.Multiplier)
+			}
+		})
+	}
+
+	if _, err := repo.GetMultiplierFor2014(0, 4); err == nil {
+		t.Error("expected error for zero monsters")
+	}
+	if _, err := repo.GetMultiplierFor2014(1, 0); err == nil {
+		t.Error("expected error for empty party")
+	}
+}
+
+func TestArchetypes(t *testing.T) {
+	repo := NewEncounterRepository()
+
+	archetypes := repo.GetAllArchetypes()
+	if len(archetypes) == 0 {
+		t.Fatal("expected some archetypes")
+	}
+
+	for _, a := range archetypes {
+		t.Run(a.ID, func(t *testing.T) {
+			if err := a.Validate(); err != nil {
+				t.Errorf("invalid archetype: %v", err)
+			}
+
+			found, err := repo.GetArchetype(a.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if found.Name != a.Name {
+				t.Errorf("expected archetype %s, got %s", a.Name, found.Name)
+			}
+		})
+	}
+
+	if _, err := repo.GetArchetype("missing"); !errors.Is(err, encounter.ErrArchetypeNotFound) {
+		t.Errorf("expected ErrArchetypeNotFound, got %v", err)
+	}
+}
@@ -4,9 +4,7 @@ import (
 	"embed"
 	"encoding/json"
 	"log"
-	"regexp"
 	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
@@ -15,8 +13,6 @@ import (
 //go:embed data/monsters.json
 var monstersFS embed.FS
 
-var xpRegex = regexp.MustCompile(`PE\s+([\d.]+)`)
-
 type jsonAbilityScores struct {
 	Strength     int `json:"strength"`
 	Dexterity    int `json:"dexterity"`
@@ -74,9 +70,16 @@ type jsonMonster struct {
 // MonsterRepository provides in-memory access to monster data.
 type MonsterRepository struct {
 	monsters       []monster.Monster
+	parseErrors    []monster.FieldError
+	byID           map[string]int
 	availableTypes []string
 	availableSizes []string
 	availableCRs   []string
+	text           *textIndex
+	names          *nameIndex
+	// offense holds the offensive profile of every monster against monster.DefaultTarget,
+	// so searches against it estimate no damage
+	offense []monster.OffensiveProfile
 }
 
 // NewMonsterRepository loads monsters from embedded JSON.
@@ -91,15 +94,19 @@ func NewMonsterRepository() *MonsterRepository {
 		log.Fatalf("failed to parse monsters.json: %v", err)
 	}
 
+	var parseErrors []monster.FieldError
 	monsters := make([]monster.Monster, len(raw))
 	for i, m := range raw {
+		// A cr_detail that cannot be parsed is reported by ParseStats
+		challenge, _ := monster.ParseChallengeDetail(m.CRDetail)
+
 		monsters[i] = monster.Monster{
 			ID:   m.ID,
 			Name: m.Name,
 			Type: m.Type,
 			Size: m.Size,
 			CR:   m.CR,
-			XP:   parseXP(m.CRDetail),
+			XP:   challenge.XP,
 			AC:   m.AC,
 			HP:   m.HP,
 
@@ -125,6 +132,7 @@ func NewMonsterRepository() *MonsterRepository {
 			Reactions:           convertNamedDescriptions(m.Reactions),
 			LegendaryActions:    convertNamedDescriptions(m.LegendaryActions),
 		}
+		parseErrors = append(parseErrors, monsters[i].ParseStats()...)
 	}
 
 	// Normalize sizes
@@ -136,11 +144,54 @@ func NewMonsterRepository() *MonsterRepository {
 		return monsters[i].XP < monsters[j].XP
 	})
 
-	repo := &MonsterRepository{monsters: monsters}
+	repo := &MonsterRepository{monsters: monsters, parseErrors: parseErrors}
+	repo.buildIndex()
 	repo.buildFacets()
 	return repo
 }
 
+// Merge adds monsters from another source. Monsters whose ID is already taken
+// are left out and their IDs returned, so the embedded data always wins.
+func (r *MonsterRepository) Merge(monsters []monster.Monster) []string {
+	var duplicates []string
+	for _, m := range monsters {
+		if _, ok := r.byID[m.ID]; ok {
+			duplicates = append(duplicates, m.ID)
+			continue
+		}
+		m.Size = normalizeSize(m.Size)
+		r.parseErrors = append(r.parseErrors, m.ParseStats()...)
+		r.byID[m.ID] = len(r.monsters)
+		r.monsters = append(r.monsters, m)
+	}
+
+	sort.SliceStable(r.monsters, func(i, j int) bool {
+		return r.monsters[i].XP < r.monsters[j].XP
+	})
+	r.buildIndex()
+	r.buildFacets()
+	return duplicates
+}
+
+// ParseErrors returns the statblock fields that could not be parsed while loading or merging monsters.
+// Their typed values are left zero.
+func (r *MonsterRepository) ParseErrors() []monster.FieldError {
+	return r.parseErrors
+}
+
+func (r *MonsterRepository) buildIndex() {
+	r.byID = make(map[string]int, len(r.monsters))
+	for i, m := range r.monsters {
+		r.byID[m.ID] = i
+	}
+	r.text = newTextIndex(r.monsters)
+	r.names = newNameIndex(r.monsters)
+	r.offense = make([]monster.OffensiveProfile, len(r.monsters))
+	for i, m := range r.monsters {
+		r.offense[i] = m.Offense(monster.DefaultTarget)
+	}
+}
+
 func convertNamedDescriptions(src []jsonNamedDescription) []monster.NamedDescription {
 	if len(src) == 0 {
 		return nil
@@ -152,20 +203,13 @@ func convertNamedDescriptions(src []jsonNamedDescription) []monster.NamedDescrip
 	return result
 }
 
-// parseXP extracts the XP value from a cr_detail string like "10 (PE 5.900; BC +4)".
-// Italian uses dots as thousands separators, so "5.900" → 5900.
-func parseXP(crDetail string) int {
-	matches := xpRegex.FindStringSubmatch(crDetail)
-	if len(matches) < 2 {
-		return 0
-	}
-	// Remove dots (Italian thousands separator)
-	numStr := strings.ReplaceAll(matches[1], ".", "")
-	xp, err := strconv.Atoi(numStr)
-	if err != nil {
-		return 0
+// FindByID returns the monster with the given ID.
+func (r *MonsterRepository) FindByID(id string) (monster.Monster, bool) {
+	i, ok := r.byID[id]
+	if !ok {
+		return monster.Monster{}, false
 	}
-	return xp
+	return r.monsters[i], true
 }
 
 func (r *MonsterRepository) FindByMaxXP(maxXP int) []monster.Monster {
@@ -215,19 +259,7 @@ func normalizeSize(s string) string {
 
 // crValue converts a CR string to a numeric value for comparison.
 func crValue(cr string) float64 {
-	switch cr {
-	case "1/8":
-		return 0.125
-	case "1/4":
-		return 0.25
-	case "1/2":
-		return 0.5
-	}
-	v, err := strconv.ParseFloat(cr, 64)
-	if err != nil {
-		return 0
-	}
-	return v
+	return monster.ParseCR(cr)
 }
 
 func (r *MonsterRepository) buildFacets() {
@@ -264,9 +296,42 @@ func sortedKeys(m map[string]bool) []string {
 	return keys
 }
 
+// SearchWithFilters returns the monsters found by SearchRanked, in the same order.
 func (r *MonsterRepository) SearchWithFilters(filters monster.SearchFilters) []monster.Monster {
-	query := strings.ToLower(filters.Query)
-	var result []monster.Monster
+	ranked := r.SearchRanked(filters)
+	result := make([]monster.Monster, len(ranked))
+	for i, found := range ranked {
+		result[i] = found.Monster
+	}
+	return result
+}
+
+// SearchRanked returns the monsters matching filters. A text query is looked up in the
+// full-text index of names, types and statblock entries: results are ranked by relevance
+// and carry a snippet of the entry that matched. Names containing the query match too,
+// as typed, and when nothing does, names within a few typos of it. Without a query the
+// monsters keep their XP order, unless filters sort them by the damage per round estimated
+// against their target. Against monster.DefaultTarget the profiles computed at load time
+// are used; another target is estimated only for the monsters that pass the other filters.
+func (r *MonsterRepository) SearchRanked(filters monster.SearchFilters) []monster.SearchResult {
+	query := normalize(strings.TrimSpace(filters.Query))
+	var scores map[int]float64
+	var hits map[int][]hit
+	if query != "" {
+		scores, hits = r.text.search(filters.Query)
+		if len(scores) == 0 && !r.anyNameContains(query) {
+			// Nothing matches as typed: look for misspelt names instead
+			scores = make(map[int]float64)
+			for _, m := range r.names.match(filters.Query) {
+				scores[m.doc] = nameWeight / float64(1+m.distance)
+			}
+		}
+	}
+
+	target := filters.Target
+	if target == (monster.Target{}) {
+		target = monster.DefaultTarget
+	}
 
 	crMin := float64(-1)
 	crMax := float64(1_000_000)
@@ -277,11 +342,17 @@ func (r *MonsterRepository) SearchWithFilters(filters monster.SearchFilters) []m
 		crMax = crValue(filters.CRMax)
 	}
 
-	for _, m := range r.monsters {
+	var result []monster.SearchResult
+	for doc, m := range r.monsters {
 		if filters.MaxXP > 0 && m.XP > filters.MaxXP {
 			continue
 		}
-		if query != "" && !strings.Contains(strings.ToLower(m.Name), query) {
+		score, found := scores[doc]
+		if query != "" && strings.Contains(normalize(m.Name), query) {
+			score += nameWeight
+			found = true
+		}
+		if query != "" && !found {
 			continue
 		}
 		if filters.Type != "" && m.Type != filters.Type {
@@ -294,11 +365,116 @@ func (r *MonsterRepository) SearchWithFilters(filters monster.SearchFilters) []m
 		if cr < crMin || cr > crMax {
 			continue
 		}
-		result = append(result, m)
+		if !matchesMovementAndSenses(m, filters) || !matchesDefenses(m, filters) {
+			continue
+		}
+		offense := r.offense[doc]
+		if target != monster.DefaultTarget {
+			offense = m.Offense(target)
+		}
+		if filters.MinDamagePerRound > 0 && offense.DamagePerRound < filters.MinDamagePerRound {
+			continue
+		}
+		if filters.MaxDamagePerRound > 0 && offense.DamagePerRound > filters.MaxDamagePerRound {
+			continue
+		}
+		result = append(result, monster.SearchResult{Monster: m, Score: score, Snippet: r.text.snippet(doc, hits[doc]), Offense: offense})
+	}
+
+	switch {
+	case filters.Sort == monster.SortDamageDesc:
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].Offense.DamagePerRound > result[j].Offense.DamagePerRound
+		})
+	case filters.Sort == monster.SortDamageAsc:
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].Offense.DamagePerRound < result[j].Offense.DamagePerRound
+		})
+	case query != "":
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].Score > result[j].Score
+		})
 	}
 	return result
 }
 
+// anyNameContains reports whether a monster name contains the normalized query.
+func (r *MonsterRepository) anyNameContains(query string) bool {
+	for _, m := range r.monsters {
+		if strings.Contains(normalize(m.Name), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggest returns up to limit monster names close to query, for autocompletion. Names starting
+// with the query come first, then those within a few typos of it, closest first.
+func (r *MonsterRepository) Suggest(query string, limit int) []monster.Suggestion {
+	matches := r.names.match(query)
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	result := make([]monster.Suggestion, len(matches))
+	for i, found := range matches {
+		m := r.monsters[found.doc]
+		result[i] = monster.Suggestion{ID: m.ID, Name: m.Name, Distance: found.distance}
+	}
+	return result
+}
+
+// matchesMovementAndSenses applies the speed and sense criteria of filters to m.
+func matchesMovementAndSenses(m monster.Monster, filters monster.SearchFilters) bool {
+	if filters.CanFly && m.Movement.Fly == 0 {
+		return false
+	}
+	if filters.MinSwim > 0 && m.Movement.Swim < filters.MinSwim {
+		return false
+	}
+	if filters.HasBlindsight && m.SpecialSenses.Blindsight == 0 {
+		return false
+	}
+	if filters.MaxPassivePerception > 0 && m.SpecialSenses.PassivePerception > filters.MaxPassivePerception {
+		return false
+	}
+	return true
+}
+
+// matchesDefenses applies the resistance and immunity criteria of filters to m.
+func matchesDefenses(m monster.Monster, filters monster.SearchFilters) bool {
+	for _, t := range filters.WithResistances {
+		if !m.Defenses.Resists(t) {
+			return false
+		}
+	}
+	for _, t := range filters.WithoutResistances {
+		if m.Defenses.Resists(t) {
+			return false
+		}
+	}
+	for _, t := range filters.WithImmunities {
+		if !m.Defenses.ImmuneTo(t) {
+			return false
+		}
+	}
+	for _, t := range filters.WithoutImmunities {
+		if m.Defenses.ImmuneTo(t) {
+			return false
+		}
+	}
+	for _, c := range filters.WithConditionImmunities {
+		if !m.Defenses.ImmuneToCondition(c) {
+			return false
+		}
+	}
+	for _, c := range filters.WithoutConditionImmunities {
+		if m.Defenses.ImmuneToCondition(c) {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *MonsterRepository) AvailableTypes() []string {
 	return r.availableTypes
 }
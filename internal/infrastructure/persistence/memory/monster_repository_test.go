@@ -1,6 +1,9 @@
 package memory
 
 import (
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
@@ -33,6 +36,39 @@ func TestNewMonsterRepository_ParsesXP(t *testing.T) {
 	}
 }
 
+func TestScale_AllMonsters(t *testing.T) {
+	repo := NewMonsterRepository()
+	for _, m := range repo.FindByMaxXP(1_000_000) {
+		scaled, err := monster.Scale(m, "10")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", m.ID, err)
+			continue
+		}
+		if m.CR != "10" && scaled.HP == m.HP {
+			t.Errorf("%s: expected HP %q to be rescaled", m.ID, m.HP)
+		}
+		if m.CR != "10" && len(monster.Diff(m, scaled)) == 0 {
+			t.Errorf("%s: expected changed fields", m.ID)
+		}
+	}
+}
+
+func TestFindByID(t *testing.T) {
+	repo := NewMonsterRepository()
+
+	m, ok := repo.FindByID("aboleth")
+	if !ok {
+		t.Fatal("expected to find aboleth")
+	}
+	if m.Name != "Aboleth" {
+		t.Errorf("expected name Aboleth, got %s", m.Name)
+	}
+
+	if _, ok := repo.FindByID("not-a-monster"); ok {
+		t.Error("expected unknown ID not to be found")
+	}
+}
+
 func TestFindByMaxXP_FiltersCorrectly(t *testing.T) {
 	repo := NewMonsterRepository()
 
@@ -290,6 +326,215 @@ func TestSearchWithFilters_Combined(t *testing.T) {
 	}
 }
 
+func TestSearchWithFilters_ByDamagePerRound(t *testing.T) {
+	repo := NewMonsterRepository()
+	filters := monster.SearchFilters{MaxXP: 1_000_000, CRMin: "3", CRMax: "3", Sort: monster.SortDamageDesc}
+	results := repo.SearchRanked(filters)
+	if len(results) < 2 {
+		t.Fatalf("expected CR 3 monsters, got %d", len(results))
+	}
+	// The mummy hits hardest among CR 3 monsters: two fists and its dreadful glare
+	if results[0].Monster.ID != "mummia" {
+		t.Errorf("expected the mummy first, got %s", results[0].Monster.ID)
+	}
+	for i, found := range results {
+		if found.Offense.Target != monster.DefaultTarget {
+			t.Errorf("%s: expected the default target, got %+v", found.Monster.ID, found.Offense.Target)
+		}
+		if i > 0 && found.Offense.DamagePerRound > results[i-1].Offense.DamagePerRound {
+			t.Errorf("%s: expected descending damage per round", found.Monster.ID)
+		}
+	}
+
+	filters.Sort = monster.SortDamageAsc
+	filters.MinDamagePerRound = 10
+	filters.MaxDamagePerRound = 15
+	results = repo.SearchRanked(filters)
+	if len(results) == 0 {
+		t.Fatal("expected CR 3 monsters dealing 10-15 damage per round")
+	}
+	for i, found := range results {
+		if dpr := found.Offense.DamagePerRound; dpr < 10 || dpr > 15 {
+			t.Errorf("%s: expected 10-15 damage per round, got %.1f", found.Monster.ID, dpr)
+		}
+		if i > 0 && found.Offense.DamagePerRound < results[i-1].Offense.DamagePerRound {
+			t.Errorf("%s: expected ascending damage per round", found.Monster.ID)
+		}
+	}
+
+	// A harder target takes less damage
+	damageTo := func(target monster.Target) float64 {
+		for _, found := range repo.SearchRanked(monster.SearchFilters{Query: "ogre", Target: target}) {
+			if found.Monster.ID == "ogre" {
+				return found.Offense.DamagePerRound
+			}
+		}
+		t.Fatal("expected the ogre to be found")
+		return 0
+	}
+	if easy, hard := damageTo(monster.Target{AC: 10}), damageTo(monster.Target{AC: 20, SaveBonus: 8}); easy <= hard {
+		t.Errorf("expected the ogre to deal more damage to AC 10 than to AC 20, got %.1f and %.1f", easy, hard)
+	}
+}
+
+func TestSearchRanked_CachesDefaultOffense(t *testing.T) {
+	repo := NewMonsterRepository()
+	repo.Merge([]monster.Monster{{
+		ID: "brigante-armato", Name: "Brigante armato", Type: "Umanoide", Size: "Media", CR: "1", XP: 200,
+		Actions: []monster.NamedDescription{{Name: "Ascia", Description: "*Tiro per colpire in mischia:* +5, portata 1,5 m. *Colpito:* 9 (1d12 + 3) danni taglienti."}},
+	}})
+
+	// The profiles computed at load and merge time match a fresh estimate
+	for doc, m := range repo.monsters {
+		if !reflect.DeepEqual(repo.offense[doc], m.Offense(monster.DefaultTarget)) {
+			t.Fatalf("%s: stale cached offense %+v", m.ID, repo.offense[doc])
+		}
+	}
+	results := repo.SearchRanked(monster.SearchFilters{Query: "brigante armato"})
+	if len(results) == 0 || results[0].Monster.ID != "brigante-armato" || results[0].Offense.DamagePerRound == 0 {
+		t.Errorf("expected the merged monster with its damage per round, got %+v", results)
+	}
+}
+
+func TestSearchWithFilters_ByMovementAndSenses(t *testing.T) {
+	repo := NewMonsterRepository()
+	tests := []struct {
+		name    string
+		filters monster.SearchFilters
+		check   func(monster.Monster) bool
+	}{
+		{"can fly", monster.SearchFilters{CanFly: true}, func(m monster.Monster) bool { return m.Movement.Fly > 0 }},
+		{"swim speed", monster.SearchFilters{MinSwim: 12}, func(m monster.Monster) bool { return m.Movement.Swim >= 12 }},
+		{"blindsight", monster.SearchFilters{HasBlindsight: true}, func(m monster.Monster) bool { return m.SpecialSenses.Blindsight > 0 }},
+		{"passive Perception", monster.SearchFilters{MaxPassivePerception: 10}, func(m monster.Monster) bool {
+			return m.SpecialSenses.PassivePerception <= 10
+		}},
+	}
+
+	all := repo.FindByMaxXP(1_000_000)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filters.MaxXP = 1_000_000
+			results := repo.SearchWithFilters(tt.filters)
+			expected := 0
+			for _, m := range all {
+				if tt.check(m) {
+					expected++
+				}
+			}
+			if len(results) == 0 || len(results) != expected {
+				t.Fatalf("expected %d monsters, got %d", expected, len(results))
+			}
+			for _, m := range results {
+				if !tt.check(m) {
+					t.Errorf("%s does not match: %+v %+v", m.ID, m.Movement, m.SpecialSenses)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchWithFilters_ByDefenses(t *testing.T) {
+	repo := NewMonsterRepository()
+	tests := []struct {
+		name    string
+		filters monster.SearchFilters
+		check   func(monster.Defenses) bool
+	}{
+		{"resists cold", monster.SearchFilters{WithResistances: []monster.DamageType{monster.DamageCold}}, func(d monster.Defenses) bool {
+			return d.Resists(monster.DamageCold)
+		}},
+		{"no fire immunity", monster.SearchFilters{WithoutImmunities: []monster.DamageType{monster.DamageFire}}, func(d monster.Defenses) bool {
+			return !d.ImmuneTo(monster.DamageFire)
+		}},
+		{"immune to fire and poison", monster.SearchFilters{WithImmunities: []monster.DamageType{monster.DamageFire, monster.DamagePoison}}, func(d monster.Defenses) bool {
+			return d.ImmuneTo(monster.DamageFire) && d.ImmuneTo(monster.DamagePoison)
+		}},
+		{"not immune to charmed", monster.SearchFilters{WithoutConditionImmunities: []monster.Condition{monster.ConditionCharmed}}, func(d monster.Defenses) bool {
+			return !d.ImmuneToCondition(monster.ConditionCharmed)
+		}},
+		{"immune to petrified, resists no acid", monster.SearchFilters{
+			WithConditionImmunities: []monster.Condition{monster.ConditionPetrified},
+			WithoutResistances:      []monster.DamageType{monster.DamageAcid},
+		}, func(d monster.Defenses) bool {
+			return d.ImmuneToCondition(monster.ConditionPetrified) && !d.Resists(monster.DamageAcid)
+		}},
+	}
+
+	all := repo.FindByMaxXP(1_000_000)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filters.MaxXP = 1_000_000
+			results := repo.SearchWithFilters(tt.filters)
+			expected := 0
+			for _, m := range all {
+				if tt.check(m.Defenses) {
+					expected++
+				}
+			}
+			if len(results) == 0 || len(results) != expected {
+				t.Fatalf("expected %d monsters, got %d", expected, len(results))
+			}
+			for _, m := range results {
+				if !tt.check(m.Defenses) {
+					t.Errorf("%s does not match: %+v", m.ID, m.Defenses)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMonsterRepository_ParsesDefenses(t *testing.T) {
+	// Qualifiers found in the embedded statblocks; anything else is a parsing mistake
+	knownQualifiers := map[string]bool{
+		"con vuoto mentale":                  true,
+		"tranne che dal suo padrone vampiro": true,
+		"il tipo di danno scelto per il tratto Origine draconica descritto sotto": true,
+	}
+
+	for _, m := range NewMonsterRepository().FindByMaxXP(1_000_000) {
+		fields := []struct {
+			name       string
+			raw        string
+			qualifiers []string
+		}{
+			{"resistances", m.Resistances, damageQualifiers(m.Defenses.Resistances)},
+			{"damage immunities", m.DamageImmunities, damageQualifiers(m.Defenses.DamageImmunities)},
+			{"condition immunities", m.ConditionImmunities, conditionQualifiers(m.Defenses.ConditionImmunities)},
+		}
+		for _, f := range fields {
+			entries := 0
+			if f.raw != "" {
+				entries = len(strings.Split(f.raw, ","))
+			}
+			if len(f.qualifiers) != entries {
+				t.Errorf("%s: %s %q parsed into %d entries, expected %d", m.ID, f.name, f.raw, len(f.qualifiers), entries)
+			}
+			for _, q := range f.qualifiers {
+				if q != "" && !knownQualifiers[q] {
+					t.Errorf("%s: unexpected %s qualifier %q", m.ID, f.name, q)
+				}
+			}
+		}
+	}
+}
+
+func damageQualifiers(defenses []monster.DamageDefense) []string {
+	var result []string
+	for _, d := range defenses {
+		result = append(result, d.Qualifier)
+	}
+	return result
+}
+
+func conditionQualifiers(immunities []monster.ConditionImmunity) []string {
+	var result []string
+	for _, i := range immunities {
+		result = append(result, i.Qualifier)
+	}
+	return result
+}
+
 func TestAvailableTypes(t *testing.T) {
 	repo := NewMonsterRepository()
 	types := repo.AvailableTypes()
@@ -330,6 +575,199 @@ func TestAvailableCRs(t *testing.T) {
 	}
 }
 
+func TestNewMonsterRepository_ParsesStats(t *testing.T) {
+	repo := NewMonsterRepository()
+	for _, err := range repo.ParseErrors() {
+		t.Errorf("unexpected parse error: %v", err)
+	}
+
+	aboleth, _ := repo.FindByID("aboleth")
+	expectedHP := monster.HitPoints{Average: 150, Dice: monster.Dice{Count: 20, Sides: 10, Bonus: 40}}
+	if aboleth.HitPoints != expectedHP {
+		t.Errorf("expected HP %+v, got %+v", expectedHP, aboleth.HitPoints)
+	}
+	if aboleth.ArmorClass.Value != 17 {
+		t.Errorf("expected AC 17, got %+v", aboleth.ArmorClass)
+	}
+	if aboleth.InitiativeBonus != (monster.Initiative{Modifier: 7, Score: 17}) {
+		t.Errorf("expected initiative +7 (17), got %+v", aboleth.InitiativeBonus)
+	}
+
+	for _, m := range repo.FindByMaxXP(1_000_000) {
+		if m.HitPoints.Average < 1 || m.ArmorClass.Value < 1 || m.InitiativeBonus.Score < 1 {
+			t.Errorf("%s: expected positive stats, got HP %+v, AC %+v, initiative %+v", m.ID, m.HitPoints, m.ArmorClass, m.InitiativeBonus)
+		}
+	}
+}
+
+func TestNewMonsterRepository_ParsesLairXP(t *testing.T) {
+	repo := NewMonsterRepository()
+	tests := []struct {
+		id               string
+		xp               int
+		lairXP           int
+		proficiencyBonus int
+	}{
+		{"aboleth", 5900, 7200, 4},
+		{"drago-bianco-adulto", 10_000, 11_500, 5},
+		{"drago-bianco-antico", 25_000, 33_000, 6},
+		{"drago-blu-adulto", 15_000, 18_000, 5},
+		{"drago-blu-antico", 50_000, 62_000, 7},
+		{"drago-dargento-adulto", 15_000, 18_000, 5},
+		{"drago-dargento-antico", 50_000, 62_000, 7},
+		{"drago-di-bronzo-adulto", 13_000, 15_000, 5},
+		{"drago-di-bronzo-antico", 41_000, 50_000, 7},
+		{"drago-di-rame-adulto", 11_500, 13_000, 5},
+		{"drago-di-rame-antico", 33_000, 41_000, 7},
+		{"drago-doro-adulto", 18_000, 20_000, 6},
+		{"drago-doro-antico", 62_000, 75_000, 7},
+		{"drago-dottone-adulto", 10_000, 11_500, 5},
+		{"drago-dottone-antico", 25_000, 33_000, 6},
+		{"drago-nero-adulto", 11_500, 13_000, 5},
+		{"drago-nero-antico", 33_000, 41_000, 7},
+		{"drago-rosso-adulto", 18_000, 20_000, 6},
+		{"drago-rosso-antico", 62_000, 75_000, 7},
+		{"drago-verde-adulto", 13_000, 15_000, 5},
+		{"drago-verde-antico", 41_000, 50_000, 7},
+		{"kraken", 50_000, 62_000, 7},
+		{"lich", 33_000, 41_000, 7},
+		{"signore-delle-mummie", 13_000, 15_000, 5},
+		{"sfinge-della-conoscenza", 7200, 8400, 4},
+		{"sfinge-del-valore", 18_000, 20_000, 6},
+		{"vampiro", 10_000, 11_500, 5},
+	}
+
+	for _, tt := range tests {
+		m, ok := repo.FindByID(tt.id)
+		if !ok {
+			t.Errorf("%s: not found", tt.id)
+			continue
+		}
+		if m.XP != tt.xp || m.LairXP != tt.lairXP || m.ProficiencyBonus != tt.proficiencyBonus {
+			t.Errorf("%s: got XP %d, lair XP %d, proficiency bonus %d; expected %d, %d, %d",
+				tt.id, m.XP, m.LairXP, m.ProficiencyBonus, tt.xp, tt.lairXP, tt.proficiencyBonus)
+		}
+	}
+
+	// Every lair variant of the dataset is listed above
+	lairs := 0
+	for _, m := range repo.FindByMaxXP(1_000_000) {
+		if m.HasLair() {
+			lairs++
+		}
+		if m.ProficiencyBonus < 2 {
+			t.Errorf("%s: expected a proficiency bonus, got %d", m.ID, m.ProficiencyBonus)
+		}
+	}
+	if lairs != len(tests) {
+		t.Errorf("expected %d monsters with a lair, got %d", len(tests), lairs)
+	}
+}
+
+func TestNewMonsterRepository_ParsesActions(t *testing.T) {
+	repo := NewMonsterRepository()
+
+	ogre, _ := repo.FindByID("ogre")
+	javelin := ogre.ParsedActions.Actions[0]
+	if javelin.Name != "Giavellotto" || javelin.Attack == nil {
+		t.Fatalf("expected the javelin attack, got %+v", javelin)
+	}
+	expected := monster.Attack{
+		Kind: monster.AttackMeleeOrRanged, ToHit: 6, Reach: 1.5, Range: 9, LongRange: 36,
+		Damage: []monster.Damage{{Average: 11, Dice: monster.Dice{Count: 2, Sides: 6, Bonus: 4}, Type: monster.DamagePiercing}},
+	}
+	if !reflect.DeepEqual(*javelin.Attack, expected) {
+		t.Errorf("expected %+v, got %+v", expected, *javelin.Attack)
+	}
+
+	dragon, _ := repo.FindByID("drago-rosso-adulto")
+	breath := dragon.ParsedActions.Actions[3]
+	if breath.Name != "Soffio di fuoco" || breath.Usage.Recharge != 5 || breath.Save == nil {
+		t.Fatalf("expected the fire breath, got %+v", breath)
+	}
+	if breath.Save.Ability != monster.AbilityDexterity || breath.Save.DC != 21 || !breath.Save.HalfOnSuccess ||
+		len(breath.Save.Damage) != 1 || breath.Save.Damage[0].Type != monster.DamageFire {
+		t.Errorf("unexpected fire breath save %+v", *breath.Save)
+	}
+
+	aboleth, _ := repo.FindByID("aboleth")
+	if dominate := aboleth.ParsedActions.Actions[3]; dominate.Usage.PerDay != 2 || dominate.Save == nil {
+		t.Errorf("expected a save usable twice a day, got %+v", dominate)
+	}
+
+	// Every attack, saving throw and usage limit of the dataset is understood
+	coverage := monster.NewActionCoverage(repo.FindByMaxXP(1_000_000))
+	if coverage.Attacks == 0 || coverage.Saves == 0 || coverage.Usages == 0 {
+		t.Fatalf("expected attacks, saves and usage limits, got %+v", coverage)
+	}
+	for _, missed := range coverage.Missed {
+		t.Errorf("%s: %q has an unparsed %s", missed.MonsterID, missed.Action, missed.Missing)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	repo := NewMonsterRepository()
+	duplicates := repo.Merge([]monster.Monster{
+		{ID: "young-red-dragon", Name: "Young Red Dragon", Type: "Drago", Size: "Grande", CR: "10", XP: 5900, AC: "18", HP: "178 (17d10 + 85)", Initiative: "+0 (10)", Speed: "12 m, scalata 12 m, volo 24 m", Senses: "Percezione passiva 18; scurovisione 36 m", CRDetail: "10 (PE 5.900, o 7.200 nella tana; BC +4)", Source: "5etools"},
+		{ID: "bog-crawler", Name: "Bog Crawler", Type: "Creatura palustre", Size: "Medio", CR: "31", XP: 200000, Source: "homebrew"},
+		{ID: "aboleth", Name: "Aboleth", Type: "Aberrazione", Size: "Grande", CR: "10", XP: 5900, Source: "srd"},
+	})
+
+	if len(duplicates) != 1 || duplicates[0] != "aboleth" {
+		t.Errorf("expected aboleth to be reported as duplicate, got %v", duplicates)
+	}
+
+	aboleth, _ := repo.FindByID("aboleth")
+	if aboleth.Source != "" {
+		t.Errorf("expected the embedded aboleth to be kept, got source %q", aboleth.Source)
+	}
+
+	dragon, ok := repo.FindByID("young-red-dragon")
+	if !ok || dragon.Source != "5etools" {
+		t.Fatalf("expected imported dragon, got %+v, %v", dragon, ok)
+	}
+	if dragon.HitPoints.Average != 178 || dragon.ArmorClass.Value != 18 {
+		t.Errorf("expected merged stats to be parsed, got HP %+v and AC %+v", dragon.HitPoints, dragon.ArmorClass)
+	}
+	if dragon.Movement.Fly != 24 || dragon.SpecialSenses.PassivePerception != 18 {
+		t.Errorf("expected merged speed and senses to be parsed, got %+v and %+v", dragon.Movement, dragon.SpecialSenses)
+	}
+	if dragon.LairXP != 7200 || dragon.ProficiencyBonus != 4 {
+		t.Errorf("expected merged cr_detail to be parsed, got lair XP %d and proficiency bonus %d", dragon.LairXP, dragon.ProficiencyBonus)
+	}
+	// The homebrew monster has no AC, HP, initiative, speed, senses or cr_detail
+	if errs := repo.ParseErrors(); len(errs) != 6 || errs[0].MonsterID != "bog-crawler" {
+		t.Errorf("expected 6 parse errors for bog-crawler, got %v", errs)
+	}
+	if len(repo.FindByMaxXP(1_000_000_000)) != 332 {
+		t.Errorf("expected 332 monsters after merge, got %d", len(repo.FindByMaxXP(1_000_000_000)))
+	}
+
+	// Facets include the new values, sizes normalized
+	if !slices.Contains(repo.AvailableTypes(), "Creatura palustre") {
+		t.Error("expected merged type in facets")
+	}
+	if slices.Contains(repo.AvailableSizes(), "Medio") {
+		t.Error("expected merged size to be normalized")
+	}
+	if crs := repo.AvailableCRs(); crs[len(crs)-1] != "31" {
+		t.Errorf("expected highest CR 31, got %s", crs[len(crs)-1])
+	}
+
+	// Still ordered by XP and indexed
+	all := repo.FindByMaxXP(1_000_000_000)
+	for i := 1; i < len(all); i++ {
+		if all[i].XP < all[i-1].XP {
+			t.Fatalf("monsters not sorted by XP at %d", i)
+		}
+	}
+	for _, m := range all {
+		if found, ok := repo.FindByID(m.ID); !ok || found.ID != m.ID {
+			t.Fatalf("index out of date for %s", m.ID)
+		}
+	}
+}
+
 func containsInsensitive(s, substr string) bool {
 	return len(s) >= len(substr) // placeholder, real check in impl
 }
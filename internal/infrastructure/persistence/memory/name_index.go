@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// nameEntry is a monster name split into normalized words.
+type nameEntry struct {
+	doc   int
+	key   string
+	words [][]rune
+}
+
+// nameIndex matches monster names against misspelt queries such as "abolet" or "tarasque".
+type nameIndex struct {
+	entries []nameEntry
+}
+
+// nameMatch is a monster whose name is within reach of a query, with the edits it takes.
+type nameMatch struct {
+	doc      int
+	distance int
+}
+
+// newNameIndex indexes the names of monsters; like the text index, it must be rebuilt
+// whenever their order changes.
+func newNameIndex(monsters []monster.Monster) *nameIndex {
+	idx := &nameIndex{entries: make([]nameEntry, len(monsters))}
+	for doc, m := range monsters {
+		words := nameWords(m.Name)
+		keys := make([]string, len(words))
+		for i, w := range words {
+			keys[i] = string(w)
+		}
+		idx.entries[doc] = nameEntry{doc: doc, key: strings.Join(keys, " "), words: words}
+	}
+	return idx
+}
+
+// nameWords splits a name into lowercase words without accents.
+func nameWords(s string) [][]rune {
+	var words [][]rune
+	for _, w := range strings.FieldsFunc(normalize(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words = append(words, []rune(w))
+	}
+	return words
+}
+
+// maxEdits is the number of typos tolerated in a word of n letters.
+func maxEdits(n int) int {
+	switch {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// match returns the monsters whose name holds every word of query, each within maxEdits typos.
+// The last word may be incomplete unless the query ends with a space. Matches come closest first;
+// among equals, names starting with the query and then shorter names come first.
+func (idx *nameIndex) match(query string) []nameMatch {
+	words := nameWords(query)
+	if len(words) == 0 {
+		return nil
+	}
+	typing := !strings.HasSuffix(query, " ")
+	keys := make([]string, len(words))
+	for i, w := range words {
+		keys[i] = string(w)
+	}
+	key := strings.Join(keys, " ")
+
+	var d editDistance
+	var result []nameMatch
+	for _, e := range idx.entries {
+		total := 0
+		for i, w := range words {
+			limit := maxEdits(len(w))
+			best := limit + 1
+			for _, nw := range e.words {
+				best = min(best, d.distance(w, nw, typing && i == len(words)-1, limit))
+			}
+			if best > limit {
+				total = -1
+				break
+			}
+			total += best
+		}
+		if total >= 0 {
+			result = append(result, nameMatch{doc: e.doc, distance: total})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := idx.entries[result[i].doc], idx.entries[result[j].doc]
+		if result[i].distance != result[j].distance {
+			return result[i].distance < result[j].distance
+		}
+		if ap, bp := strings.HasPrefix(a.key, key), strings.HasPrefix(b.key, key); ap != bp {
+			return ap
+		}
+		if len(a.key) != len(b.key) {
+			return len(a.key) < len(b.key)
+		}
+		return a.key < b.key
+	})
+	return result
+}
+
+// editDistance computes Damerau-Levenshtein distances, reusing its rows between calls
+// so that matching a keystroke against every name does not allocate.
+type editDistance struct {
+	rows [3][]int
+}
+
+// distance returns the edits, counting a swap of adjacent letters as one, that turn a into b,
+// or into a prefix of b when prefix is set. It gives up as soon as the distance exceeds limit
+// and returns limit+1.
+func (d *editDistance) distance(a, b []rune, prefix bool, limit int) int {
+	if len(a) > len(b)+limit || !prefix && len(b) > len(a)+limit {
+		return limit + 1
+	}
+	for i := range d.rows {
+		if cap(d.rows[i]) < len(b)+1 {
+			d.rows[i] = make([]int, len(b)+1)
+		}
+		d.rows[i] = d.rows[i][:len(b)+1]
+	}
+
+	before, prev, cur := d.rows[0], d.rows[1], d.rows[2]
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			v := min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				v = min(v, before[j-2]+1)
+			}
+			cur[j] = v
+			rowMin = min(rowMin, v)
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		before, prev, cur = prev, cur, before
+	}
+
+	result := prev[len(b)]
+	if prefix {
+		for _, v := range prev {
+			result = min(result, v)
+		}
+	}
+	return min(result, limit+1)
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		prefix   bool
+		limit    int
+		expected int
+	}{
+		{a: "abolet", b: "aboleth", limit: 2, expected: 1},
+		{a: "tarasque", b: "tarrasque", limit: 2, expected: 1},
+		// A swap of adjacent letters is a single typo
+		{a: "shceletro", b: "scheletro", limit: 2, expected: 1},
+		{a: "zmobi", b: "zombi", limit: 2, expected: 1},
+		// While typing, the query only has to be close to the start of the name
+		{a: "scheltr", b: "scheletro", prefix: true, limit: 2, expected: 1},
+		{a: "scheltr", b: "scheletro", limit: 1, expected: 2},
+		// Gives up past the limit
+		{a: "goblin", b: "tarrasque", limit: 2, expected: 3},
+	}
+
+	var d editDistance
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := d.distance([]rune(tt.a), []rune(tt.b), tt.prefix, tt.limit); got != tt.expected {
+				t.Errorf("distance = %d, expected %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	repo := NewMonsterRepository()
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "abolet", expected: "aboleth"},
+		{query: "tarasque", expected: "tarrasque"},
+		{query: "ZOMBI", expected: "zombi"},
+		{query: "drago roso adul", expected: "drago-rosso-adulto"},
+		{query: "zombi og", expected: "zombi-ogre"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			suggestions := repo.Suggest(tt.query, 5)
+			if len(suggestions) == 0 || suggestions[0].ID != tt.expected {
+				t.Errorf("expected %s first, got %+v", tt.expected, suggestions)
+			}
+		})
+	}
+}
+
+func TestSuggest_PrefixesFirstAndLimit(t *testing.T) {
+	repo := NewMonsterRepository()
+	suggestions := repo.Suggest("gobl", 3)
+	if len(suggestions) != 3 {
+		t.Fatalf("expected 3 suggestions, got %+v", suggestions)
+	}
+	// "Hobgoblin" holds the word too, but names starting with it come first
+	for _, s := range suggestions {
+		if s.Distance != 0 || s.Name[:6] != "Goblin" {
+			t.Errorf("expected exact goblin prefixes, got %+v", suggestions)
+		}
+	}
+
+	if got := repo.Suggest("  ", 5); len(got) != 0 {
+		t.Errorf("expected no suggestions for a blank query, got %+v", got)
+	}
+	if got := repo.Suggest("xyzzyq", 5); len(got) != 0 {
+		t.Errorf("expected no suggestions for gibberish, got %+v", got)
+	}
+}
+
+func TestSearchRanked_FallsBackToMisspeltNames(t *testing.T) {
+	repo := NewMonsterRepository()
+	ids := resultIDs(repo.SearchRanked(monster.SearchFilters{Query: "abolet"}))
+	if len(ids) == 0 || ids[0] != "aboleth" {
+		t.Errorf("expected the aboleth first, got %v", ids)
+	}
+}
+
+func BenchmarkSuggest(b *testing.B) {
+	repo := NewMonsterRepository()
+	queries := []string{"a", "ab", "abo", "abol", "abole", "abolet", "drago ross", "tarasque"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		repo.Suggest(queries[i%len(queries)], 8)
+	}
+}
+
+func BenchmarkSuggest_Misspelt(b *testing.B) {
+	repo := NewMonsterRepository()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		repo.Suggest("sheclétro di minotaruo", 8)
+	}
+}
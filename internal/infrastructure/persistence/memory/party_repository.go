@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+)
+
+// PartyRepository implements the campaign.PartyRepository interface using in-memory data
+type PartyRepository struct {
+	mu      sync.RWMutex
+	parties map[string]campaign.Party
+}
+
+// NewPartyRepository creates a new in-memory party repository
+func NewPartyRepository() *PartyRepository {
+	return &PartyRepository{parties: make(map[string]campaign.Party)}
+}
+
+// Save stores a copy of the party
+func (r *PartyRepository) Save(party *campaign.Party) error {
+	if party.ID == "" {
+		return fmt.Errorf("party ID cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.parties[party.ID] = copyParty(*party)
+	return nil
+}
+
+// FindByID returns a copy of the stored party
+func (r *PartyRepository) FindByID(id string) (*campaign.Party, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	party, exists := r.parties[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", campaign.ErrPartyNotFound, id)
+	}
+
+	party = copyParty(party)
+	return &party, nil
+}
+
+// FindAll returns copies of all stored parties ordered by name
+func (r *PartyRepository) FindAll() ([]campaign.Party, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	parties := make([]campaign.Party, 0, len(r.parties))
+	for _, party := range r.parties {
+		parties = append(parties, copyParty(party))
+	}
+	sort.Slice(parties, func(i, j int) bool {
+		return parties[i].Name < parties[j].Name
+	})
+	return parties, nil
+}
+
+// Delete removes the party with the given ID
+func (r *PartyRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.parties[id]; !exists {
+		return fmt.Errorf("%w: %s", campaign.ErrPartyNotFound, id)
+	}
+	delete(r.parties, id)
+	return nil
+}
+
+// copyParty returns a party that shares no slices with the original
+func copyParty(p campaign.Party) campaign.Party {
+	p.Members = append([]campaign.Member(nil), p.Members...)
+	return p
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+)
+
+// SavedEncounterRepository implements the campaign.EncounterRepository interface using in-memory data
+type SavedEncounterRepository struct {
+	mu         sync.RWMutex
+	encounters map[string]campaign.SavedEncounter
+}
+
+// NewSavedEncounterRepository creates a new in-memory saved encounter repository
+func NewSavedEncounterRepository() *SavedEncounterRepository {
+	return &SavedEncounterRepository{encounters: make(map[string]campaign.SavedEncounter)}
+}
+
+// Save stores a copy of the encounter
+func (r *SavedEncounterRepository) Save(encounter *campaign.SavedEncounter) error {
+	if encounter.ID == "" {
+		return fmt.Errorf("encounter ID cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.encounters[encounter.ID] = copySavedEncounter(*encounter)
+	return nil
+}
+
+// FindByID returns a copy of the stored encounter
+func (r *SavedEncounterRepository) FindByID(id string) (*campaign.SavedEncounter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	encounter, exists := r.encounters[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", campaign.ErrEncounterNotFound, id)
+	}
+
+	encounter = copySavedEncounter(encounter)
+	return &encounter, nil
+}
+
+// FindAll returns copies of all stored encounters ordered by name
+func (r *SavedEncounterRepository) FindAll() ([]campaign.SavedEncounter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	encounters := make([]campaign.SavedEncounter, 0, len(r.encounters))
+	for _, encounter := range r.encounters {
+		encounters = append(encounters, copySavedEncounter(encounter))
+	}
+	sort.Slice(encounters, func(i, j int) bool {
+		return encounters[i].Name < encounters[j].Name
+	})
+	return encounters, nil
+}
+
+// Delete removes the encounter with the given ID
+func (r *SavedEncounterRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.encounters[id]; !exists {
+		return fmt.Errorf("%w: %s", campaign.ErrEncounterNotFound, id)
+	}
+	delete(r.encounters, id)
+	return nil
+}
+
+// copySavedEncounter returns an encounter that shares no slices with the original
+func copySavedEncounter(e campaign.SavedEncounter) campaign.SavedEncounter {
+	e.Levels = append([]int(nil), e.Levels...)
+	e.Monsters = append([]campaign.EncounterMonster(nil), e.Monsters...)
+	return e
+}
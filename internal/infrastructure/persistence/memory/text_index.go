@@ -0,0 +1,396 @@
+package memory
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// Weights of the passages of a statblock: a word in the name counts as much as eight in an action.
+const (
+	nameWeight = 8.0
+	typeWeight = 4.0
+	textWeight = 1.0
+)
+
+// Snippet window, in words, around the first highlighted word.
+const (
+	snippetBefore = 8
+	snippetLength = 30
+)
+
+// minStemLength is the shortest stem left after removing a suffix.
+const minStemLength = 3
+
+// foldAccents maps accented Italian letters to their plain form.
+var foldAccents = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ä", "a",
+	"è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i",
+	"ò", "o", "ó", "o", "ô", "o", "ö", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u",
+)
+
+// stripEmphasis removes the markdown emphasis markers of the dataset.
+var stripEmphasis = strings.NewReplacer("*", "", "_", "")
+
+// stopwords are articles, prepositions and conjunctions left out of the index.
+// "non" is kept, since "non magici" must not match "magici".
+var stopwords = map[string]bool{
+	"il": true, "lo": true, "la": true, "i": true, "gli": true, "le": true, "l": true,
+	"un": true, "uno": true, "una": true,
+	"di": true, "a": true, "da": true, "in": true, "con": true, "su": true, "per": true, "tra": true, "fra": true,
+	"d": true, "del": true, "dello": true, "della": true, "dei": true, "degli": true, "delle": true, "dell": true,
+	"al": true, "allo": true, "alla": true, "ai": true, "agli": true, "alle": true, "all": true,
+	"dal": true, "dallo": true, "dalla": true, "dai": true, "dagli": true, "dalle": true, "dall": true,
+	"nel": true, "nello": true, "nella": true, "nei": true, "negli": true, "nelle": true, "nell": true,
+	"sul": true, "sullo": true, "sulla": true, "sui": true, "sugli": true, "sulle": true, "sull": true,
+	"e": true, "ed": true, "o": true, "od": true, "che": true, "si": true,
+}
+
+// suffixes are the inflections removed by stem, longest first so that "ato" wins over "o".
+var suffixes = []string{
+	"azioni", "azione",
+	"ando", "endo",
+	"are", "ere", "ire",
+	"ato", "ata", "ati", "ate",
+	"ito", "ita", "iti", "ite",
+	"uto", "uta", "uti", "ute",
+	"a", "e", "i", "o",
+}
+
+// token is an indexed word: its stem, the normalized word, its position among the words
+// of the passage and its byte span in the passage text.
+type token struct {
+	term       string
+	word       string
+	position   int
+	start, end int
+}
+
+// passage is a unit of statblock text, such as the name or one action. Phrases never span passages.
+type passage struct {
+	field  string
+	text   string
+	weight float64
+	tokens []token
+}
+
+// occurrence locates a term in the index.
+type occurrence struct {
+	doc, passage, position int
+}
+
+// textIndex is an inverted index over the names, types and statblock entries of monsters.
+// Documents are positions in the repository slice.
+type textIndex struct {
+	passages [][]passage
+	postings map[string][]occurrence
+	// words is the sorted vocabulary before stemming, used for prefix lookups, and stems maps it to the terms
+	words []string
+	stems map[string]string
+}
+
+// newTextIndex indexes monsters; it must be rebuilt whenever their order changes.
+func newTextIndex(monsters []monster.Monster) *textIndex {
+	idx := &textIndex{
+		passages: make([][]passage, len(monsters)),
+		postings: make(map[string][]occurrence),
+		stems:    make(map[string]string),
+	}
+	for doc, m := range monsters {
+		idx.passages[doc] = monsterPassages(m)
+		for p, ps := range idx.passages[doc] {
+			for _, t := range ps.tokens {
+				idx.postings[t.term] = append(idx.postings[t.term], occurrence{doc: doc, passage: p, position: t.position})
+				idx.stems[t.word] = t.term
+			}
+		}
+	}
+	for word := range idx.stems {
+		idx.words = append(idx.words, word)
+	}
+	sort.Strings(idx.words)
+	return idx
+}
+
+// monsterPassages splits a monster into weighted passages.
+func monsterPassages(m monster.Monster) []passage {
+	typeText := m.Type
+	if m.Subtype != "" {
+		typeText += " " + m.Subtype
+	}
+	passages := []passage{
+		{field: "Nome", text: m.Name, weight: nameWeight},
+		{field: "Tipo", text: typeText, weight: typeWeight},
+	}
+	sections := []struct {
+		label   string
+		entries []monster.NamedDescription
+	}{
+		{"Tratti", m.Traits},
+		{"Azioni", m.Actions},
+		{"Azioni Bonus", m.BonusActions},
+		{"Reazioni", m.Reactions},
+		{"Azioni Leggendarie", m.LegendaryActions},
+	}
+	for _, s := range sections {
+		for _, e := range s.entries {
+			text := plainText(e.Name + ". " + e.Description)
+			passages = append(passages, passage{field: s.label + ": " + e.Name, text: text, weight: textWeight})
+		}
+	}
+	for i := range passages {
+		passages[i].tokens = analyze(passages[i].text)
+	}
+	return passages
+}
+
+// plainText drops the markdown emphasis of the dataset and collapses repeated spaces.
+func plainText(s string) string {
+	return strings.Join(strings.Fields(stripEmphasis.Replace(s)), " ")
+}
+
+// analyze splits text into words, dropping stopwords and stemming the rest. Stopwords still
+// take a position, so that phrases match only the same wording.
+func analyze(text string) []token {
+	var tokens []token
+	position := 0
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := normalize(text[start:end])
+		if !stopwords[word] {
+			tokens = append(tokens, token{term: stem(word), word: word, position: position, start: start, end: end})
+		}
+		position++
+		start = -1
+	}
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+	return tokens
+}
+
+// normalize lowercases a word and removes its accents.
+func normalize(word string) string {
+	return foldAccents.Replace(strings.ToLower(word))
+}
+
+// stem removes the most common Italian inflections, so that "paralizzato", "paralizza"
+// and "paralizzare" share the stem "paralizz". The hard "c" and "g" of "magiche" are kept as "magic".
+func stem(word string) string {
+	for _, suffix := range suffixes {
+		rest, ok := strings.CutSuffix(word, suffix)
+		if !ok || utf8.RuneCountInString(rest) < minStemLength {
+			continue
+		}
+		if strings.HasSuffix(rest, "ch") || strings.HasSuffix(rest, "gh") {
+			rest = rest[:len(rest)-1]
+		}
+		return rest
+	}
+	return word
+}
+
+// clause is part of a query: a word or a quoted phrase, whose tokens keep their relative positions.
+// The last word of a query is matched as a prefix, so results follow the user while typing.
+type clause struct {
+	tokens []token
+	prefix bool
+}
+
+// parseQuery splits a query into clauses. Text between double quotes is a phrase.
+func parseQuery(query string) []clause {
+	var clauses []clause
+	for i, part := range strings.Split(query, `"`) {
+		tokens := analyze(part)
+		if i%2 == 1 {
+			if len(tokens) > 0 {
+				clauses = append(clauses, clause{tokens: tokens})
+			}
+			continue
+		}
+		for _, t := range tokens {
+			clauses = append(clauses, clause{tokens: []token{t}})
+		}
+	}
+
+	trimmed := strings.TrimRightFunc(query, unicode.IsSpace)
+	if n := len(clauses); n > 0 && len(clauses[n-1].tokens) == 1 && trimmed == query && !strings.HasSuffix(query, `"`) {
+		clauses[n-1].prefix = true
+	}
+	return clauses
+}
+
+// hit is a match of a clause in a passage; positions are those of every matched word.
+type hit struct {
+	passage   int
+	positions []int
+}
+
+// lookup returns the occurrences of the stem of t and, when prefix is set, of every word starting with t.
+func (idx *textIndex) lookup(t token, prefix bool) []occurrence {
+	result := idx.postings[t.term]
+	if !prefix {
+		return result
+	}
+	seen := map[string]bool{t.term: true}
+	result = slices.Clone(result)
+	for i := sort.SearchStrings(idx.words, t.word); i < len(idx.words) && strings.HasPrefix(idx.words[i], t.word); i++ {
+		if term := idx.stems[idx.words[i]]; !seen[term] {
+			seen[term] = true
+			result = append(result, idx.postings[term]...)
+		}
+	}
+	return result
+}
+
+// match returns the hits of c grouped by document.
+func (idx *textIndex) match(c clause) map[int][]hit {
+	first := c.tokens[0]
+	following := make([]map[occurrence]bool, len(c.tokens)-1)
+	for i, t := range c.tokens[1:] {
+		following[i] = make(map[occurrence]bool)
+		for _, o := range idx.lookup(t, false) {
+			following[i][o] = true
+		}
+	}
+
+	hits := make(map[int][]hit)
+	for _, o := range idx.lookup(first, c.prefix) {
+		positions := []int{o.position}
+		for i, t := range c.tokens[1:] {
+			next := occurrence{doc: o.doc, passage: o.passage, position: o.position + t.position - first.position}
+			if !following[i][next] {
+				positions = nil
+				break
+			}
+			positions = append(positions, next.position)
+		}
+		if positions != nil {
+			hits[o.doc] = append(hits[o.doc], hit{passage: o.passage, positions: positions})
+		}
+	}
+	return hits
+}
+
+// search scores the documents matching every clause of query. Each clause adds its inverse
+// document frequency times the square root of the weights of the passages it occurs in.
+// Hits are returned too, to build snippets.
+func (idx *textIndex) search(query string) (map[int]float64, map[int][]hit) {
+	clauses := parseQuery(query)
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+
+	scores := make(map[int]float64)
+	hits := make(map[int][]hit)
+	for i, c := range clauses {
+		matched := idx.match(c)
+		idf := math.Log(1 + float64(len(idx.passages))/float64(max(len(matched), 1)))
+		next := make(map[int]float64, len(matched))
+		for doc, docHits := range matched {
+			if _, ok := scores[doc]; i > 0 && !ok {
+				continue
+			}
+			weight := 0.0
+			for _, h := range docHits {
+				weight += idx.passages[doc][h.passage].weight
+			}
+			next[doc] = scores[doc] + idf*math.Sqrt(weight)
+			hits[doc] = append(hits[doc], docHits...)
+		}
+		scores = next
+	}
+	for doc := range hits {
+		if _, ok := scores[doc]; !ok {
+			delete(hits, doc)
+		}
+	}
+	return scores, hits
+}
+
+// snippet excerpts the statblock entry with the most hits, highlighting the matched words.
+// Matches only in the name or type give no snippet, as the list already shows them.
+func (idx *textIndex) snippet(doc int, hits []hit) monster.Snippet {
+	highlighted := make(map[int]map[int]bool)
+	for _, h := range hits {
+		if idx.passages[doc][h.passage].weight != textWeight {
+			continue
+		}
+		if highlighted[h.passage] == nil {
+			highlighted[h.passage] = make(map[int]bool)
+		}
+		for _, p := range h.positions {
+			highlighted[h.passage][p] = true
+		}
+	}
+
+	best := -1
+	for p, positions := range highlighted {
+		if best < 0 || len(positions) > len(highlighted[best]) || (len(positions) == len(highlighted[best]) && p < best) {
+			best = p
+		}
+	}
+	if best < 0 {
+		return monster.Snippet{}
+	}
+
+	ps := idx.passages[doc][best]
+	firstHit := len(ps.tokens)
+	for i, t := range ps.tokens {
+		if highlighted[best][t.position] {
+			firstHit = i
+			break
+		}
+	}
+	from := max(firstHit-snippetBefore, 0)
+	to := min(from+snippetLength, len(ps.tokens))
+
+	start, end := ps.tokens[from].start, ps.tokens[to-1].end
+	if from == 0 {
+		start = 0
+	}
+	if to == len(ps.tokens) {
+		end = len(ps.text)
+	}
+
+	var parts []monster.SnippetPart
+	if start > 0 {
+		parts = append(parts, monster.SnippetPart{Text: "…"})
+	}
+	cursor := start
+	for _, t := range ps.tokens[from:to] {
+		if !highlighted[best][t.position] {
+			continue
+		}
+		if t.start > cursor {
+			parts = append(parts, monster.SnippetPart{Text: ps.text[cursor:t.start]})
+		}
+		parts = append(parts, monster.SnippetPart{Text: ps.text[t.start:t.end], Highlight: true})
+		cursor = t.end
+	}
+	if end > cursor {
+		parts = append(parts, monster.SnippetPart{Text: ps.text[cursor:end]})
+	}
+	if end < len(ps.text) {
+		parts = append(parts, monster.SnippetPart{Text: "…"})
+	}
+	return monster.Snippet{Field: ps.field, Parts: parts}
+}
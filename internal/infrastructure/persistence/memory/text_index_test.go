@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		words    []string
+		expected string
+	}{
+		{[]string{"paralizza", "paralizzato", "paralizzata", "paralizzati", "paralizzare"}, "paralizz"},
+		{[]string{"incantesimo", "incantesimi"}, "incantesim"},
+		{[]string{"magica", "magiche", "magico", "magici"}, "magic"},
+		{[]string{"orco", "orchi"}, "orc"},
+		{[]string{"ira"}, "ira"},
+		{[]string{"mare"}, "mar"},
+	}
+
+	for _, tt := range tests {
+		for _, w := range tt.words {
+			if got := stem(w); got != tt.expected {
+				t.Errorf("stem(%q) = %q, expected %q", w, got, tt.expected)
+			}
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tokens := analyze("Il soffio dell'Ombra è VELENOSO")
+	var terms []string
+	var positions []int
+	for _, tok := range tokens {
+		terms = append(terms, tok.term)
+		positions = append(positions, tok.position)
+	}
+
+	// "Il" and the elided "dell'" are stopwords but keep their positions
+	if !slices.Equal(terms, []string{"soffi", "ombr", "velenos"}) || !slices.Equal(positions, []int{1, 3, 5}) {
+		t.Errorf("got terms %v at %v", terms, positions)
+	}
+	if tokens[2].word != "velenoso" || tokens[2].start != 24 {
+		t.Errorf("expected normalized word at its byte offset, got %+v", tokens[2])
+	}
+	if got := analyze("Mostruosità"); len(got) != 1 || got[0].word != "mostruosita" {
+		t.Errorf("expected accents to be folded, got %+v", got)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query        string
+		expectedLens []int
+		expectPrefix bool
+	}{
+		{query: "veleno morso", expectedLens: []int{1, 1}, expectPrefix: true},
+		{query: "veleno morso ", expectedLens: []int{1, 1}},
+		{query: `"soffio di fuoco" drago`, expectedLens: []int{2, 1}, expectPrefix: true},
+		{query: `drago "soffio di fuoco"`, expectedLens: []int{1, 2}},
+		{query: "il della", expectedLens: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			clauses := parseQuery(tt.query)
+			var lens []int
+			for _, c := range clauses {
+				lens = append(lens, len(c.tokens))
+			}
+			if !slices.Equal(lens, tt.expectedLens) {
+				t.Fatalf("clause lengths = %v, expected %v", lens, tt.expectedLens)
+			}
+			if len(clauses) > 0 && clauses[len(clauses)-1].prefix != tt.expectPrefix {
+				t.Errorf("prefix = %v, expected %v", clauses[len(clauses)-1].prefix, tt.expectPrefix)
+			}
+		})
+	}
+}
+
+func resultIDs(results []monster.SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Monster.ID
+	}
+	return ids
+}
+
+func TestSearchRanked_FullText(t *testing.T) {
+	repo := NewMonsterRepository()
+	tests := []struct {
+		query    string
+		includes []string
+		excludes []string
+	}{
+		// Inflections of the same verb
+		{query: "paralizza", includes: []string{"ghoul", "chuul", "lich"}},
+		{query: "paralizzato ", includes: []string{"ghoul", "chuul"}},
+		// Plural against singular, in any case
+		{query: "INCANTESIMI", includes: []string{"drago-di-bronzo-adulto", "megera-verde"}},
+		// A phrase needs its words in order, a bag of words does not
+		{query: `"soffio di fuoco"`, includes: []string{"mephit-del-magma", "drago-doro-cucciolo"}, excludes: []string{"golem-di-ferro"}},
+		{query: "soffio fuoco ", includes: []string{"drago-rosso-adulto", "golem-di-ferro"}},
+		// Accents are ignored
+		{query: "mostruosita", includes: []string{"chimera"}},
+		// Names still match while typing
+		{query: "gobl", includes: []string{"goblin-guerriero", "hobgoblin-guerriero"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			ids := resultIDs(repo.SearchRanked(monster.SearchFilters{Query: tt.query}))
+			for _, id := range tt.includes {
+				if !slices.Contains(ids, id) {
+					t.Errorf("expected %s among %v", id, ids)
+				}
+			}
+			for _, id := range tt.excludes {
+				if slices.Contains(ids, id) {
+					t.Errorf("did not expect %s", id)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchRanked_RanksNamesFirst(t *testing.T) {
+	repo := NewMonsterRepository()
+	results := repo.SearchRanked(monster.SearchFilters{Query: "vampiro "})
+	if len(results) == 0 || !slices.Contains(resultIDs(results), "progenie-vampirica") {
+		t.Fatalf("expected the vampire spawn among %v", resultIDs(results))
+	}
+	inName := true
+	for i, r := range results {
+		if strings.Contains(strings.ToLower(r.Monster.Name), "vampiro") {
+			if !inName {
+				t.Errorf("%s ranked below a monster mentioning vampires only in its text", r.Monster.ID)
+			}
+		} else {
+			inName = false
+		}
+		if i > 0 && r.Score > results[i-1].Score {
+			t.Errorf("results not sorted by score: %v", resultIDs(results))
+		}
+	}
+}
+
+func TestSearchRanked_Snippet(t *testing.T) {
+	repo := NewMonsterRepository()
+	results := repo.SearchRanked(monster.SearchFilters{Query: "tocco paralizzato "})
+	i := slices.IndexFunc(results, func(r monster.SearchResult) bool { return r.Monster.ID == "lich" })
+	if i < 0 {
+		t.Fatalf("expected the lich among %v", resultIDs(results))
+	}
+
+	snippet := results[i].Snippet
+	if snippet.Field != "Azioni: Tocco paralizzante" {
+		t.Errorf("field = %q", snippet.Field)
+	}
+	var highlighted []string
+	var text strings.Builder
+	for _, p := range snippet.Parts {
+		if p.Highlight {
+			highlighted = append(highlighted, p.Text)
+		}
+		text.WriteString(p.Text)
+	}
+	if !slices.Equal(highlighted, []string{"Tocco", "paralizzato"}) {
+		t.Errorf("highlighted = %v", highlighted)
+	}
+	if strings.Contains(text.String(), "*") || !strings.HasPrefix(text.String(), "Tocco paralizzante.") {
+		t.Errorf("unexpected snippet text %q", text.String())
+	}
+
+	// Matches in the name alone need no snippet
+	results = repo.SearchRanked(monster.SearchFilters{Query: "ogre "})
+	if len(results) == 0 || !results[0].Snippet.IsZero() {
+		t.Errorf("expected no snippet for a name match, got %+v", results)
+	}
+}
+
+func TestSearchRanked_NoQueryKeepsXPOrder(t *testing.T) {
+	repo := NewMonsterRepository()
+	results := repo.SearchRanked(monster.SearchFilters{Type: "Drago"})
+	for i := 1; i < len(results); i++ {
+		if results[i].Monster.XP < results[i-1].Monster.XP {
+			t.Fatalf("expected XP order, got %s before %s", results[i-1].Monster.ID, results[i].Monster.ID)
+		}
+		if !results[i].Snippet.IsZero() {
+			t.Errorf("expected no snippet without a query, got %+v", results[i].Snippet)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often a TTL store looks for expired values while it is written to
+const sweepInterval = time.Hour
+
+// ttlStore keeps copies of values by ID and forgets those left untouched for longer than the
+// TTL. Expired values are never returned, and they are dropped by a sweep that runs at most
+// once per sweepInterval, so writes do not scan the whole store.
+type ttlStore[T any] struct {
+	mu        sync.RWMutex
+	items     map[string]ttlItem[T]
+	ttl       time.Duration
+	clone     func(T) T
+	notFound  error
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type ttlItem[T any] struct {
+	value     T
+	updatedAt time.Time
+}
+
+// newTTLStore creates a store whose values are copied with clone on the way in and out,
+// and whose lookups of missing IDs wrap notFound
+func newTTLStore[T any](ttl time.Duration, clone func(T) T, notFound error) *ttlStore[T] {
+	return &ttlStore[T]{
+		items:    make(map[string]ttlItem[T]),
+		ttl:      ttl,
+		clone:    clone,
+		notFound: notFound,
+		now:      time.Now,
+	}
+}
+
+// put stores a copy of value under id
+func (s *ttlStore[T]) put(id string, value T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	s.items[id] = ttlItem[T]{value: s.clone(value), updatedAt: now}
+}
+
+// get returns a copy of the value stored under id
+func (s *ttlStore[T]) get(id string) (T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, ok := s.items[id]
+	if !ok || s.expired(item, s.now()) {
+		var zero T
+		return zero, fmt.Errorf("%w: %s", s.notFound, id)
+	}
+	return s.clone(item.value), nil
+}
+
+// update applies change to a copy of the value stored under id while holding the write
+// lock, so concurrent updates of the same value are serialized, and stores the result
+// unless change fails
+func (s *ttlStore[T]) update(id string, change func(*T) error) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var zero T
+	now := s.now()
+	item, ok := s.items[id]
+	if !ok || s.expired(item, now) {
+		return zero, fmt.Errorf("%w: %s", s.notFound, id)
+	}
+
+	value := s.clone(item.value)
+	if err := change(&value); err != nil {
+		return zero, err
+	}
+	s.sweep(now)
+	s.items[id] = ttlItem[T]{value: s.clone(value), updatedAt: now}
+	return value, nil
+}
+
+func (s *ttlStore[T]) expired(item ttlItem[T], now time.Time) bool {
+	return now.Sub(item.updatedAt) > s.ttl
+}
+
+// sweep drops the expired values if the last sweep is older than sweepInterval; the
+// caller holds the write lock
+func (s *ttlStore[T]) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for id, item := range s.items {
+		if s.expired(item, now) {
+			delete(s.items, id)
+		}
+	}
+}
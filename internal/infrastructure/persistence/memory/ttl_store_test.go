@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTestNotFound = errors.New("not found")
+
+func TestTTLStore(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newTTLStore(24*time.Hour, func(v []int) []int { return append([]int(nil), v...) }, errTestNotFound)
+	store.now = func() time.Time { return now }
+
+	value := []int{1, 2}
+	store.put("a", value)
+	value[0] = 9
+	if got, err := store.get("a"); err != nil || got[0] != 1 {
+		t.Fatalf("expected a copy of the stored value, got %v, %v", got, err)
+	}
+
+	// A failed change leaves the value untouched
+	if _, err := store.update("a", func(v *[]int) error { (*v)[0] = 7; return errors.New("refused") }); err == nil {
+		t.Error("expected the change error")
+	}
+	updated, err := store.update("a", func(v *[]int) error { *v = append(*v, 3); return nil })
+	if err != nil || len(updated) != 3 {
+		t.Fatalf("expected the updated value, got %v, %v", updated, err)
+	}
+	if got, _ := store.get("a"); got[0] != 1 || len(got) != 3 {
+		t.Errorf("expected [1 2 3], got %v", got)
+	}
+
+	// Untouched values expire, even before the next sweep drops them
+	store.put("b", []int{1})
+	now = now.Add(25 * time.Hour)
+	store.put("c", nil)
+	now = now.Add(30 * time.Minute)
+	store.put("d", nil)
+	if _, err := store.get("a"); !errors.Is(err, errTestNotFound) {
+		t.Errorf("expected an expired value to be missing, got %v", err)
+	}
+	if _, err := store.update("b", func(*[]int) error { return nil }); !errors.Is(err, errTestNotFound) {
+		t.Errorf("expected an expired value not to be updated, got %v", err)
+	}
+	if len(store.items) != 2 {
+		t.Errorf("expected the sweep to keep only the fresh values, got %d", len(store.items))
+	}
+}
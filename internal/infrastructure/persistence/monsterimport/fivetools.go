@@ -0,0 +1,376 @@
+package monsterimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// fiveToolsMonster is a bestiary entry in the 5etools schema.
+type fiveToolsMonster struct {
+	Name      string            `json:"name"`
+	Source    string            `json:"source"`
+	Copy      json.RawMessage   `json:"_copy"`
+	Size      []string          `json:"size"`
+	Type      json.RawMessage   `json:"type"`
+	Alignment []json.RawMessage `json:"alignment"`
+	AC        []json.RawMessage `json:"ac"`
+	HP        struct {
+		Average int    `json:"average"`
+		Formula string `json:"formula"`
+		Special string `json:"special"`
+	} `json:"hp"`
+	Speed map[string]json.RawMessage `json:"speed"`
+
+	Str int `json:"str"`
+	Dex int `json:"dex"`
+	Con int `json:"con"`
+	Int int `json:"int"`
+	Wis int `json:"wis"`
+	Cha int `json:"cha"`
+
+	Save            map[string]string          `json:"save"`
+	Skill           map[string]json.RawMessage `json:"skill"`
+	Senses          []string                   `json:"senses"`
+	Passive         json.RawMessage            `json:"passive"`
+	Resist          []json.RawMessage          `json:"resist"`
+	Immune          []json.RawMessage          `json:"immune"`
+	Vulnerable      []json.RawMessage          `json:"vulnerable"`
+	ConditionImmune []json.RawMessage          `json:"conditionImmune"`
+	Languages       []string                   `json:"languages"`
+	CR              json.RawMessage            `json:"cr"`
+
+	Trait     []fiveToolsEntry `json:"trait"`
+	Action    []fiveToolsEntry `json:"action"`
+	Bonus     []fiveToolsEntry `json:"bonus"`
+	Reaction  []fiveToolsEntry `json:"reaction"`
+	Legendary []fiveToolsEntry `json:"legendary"`
+}
+
+type fiveToolsEntry struct {
+	Name    string            `json:"name"`
+	Entries []json.RawMessage `json:"entries"`
+}
+
+func convert5etools(raw json.RawMessage) (monster.Monster, *mapper, error) {
+	var src fiveToolsMonster
+	if err := json.Unmarshal(raw, &src); err != nil {
+		return monster.Monster{}, nil, fmt.Errorf("invalid 5etools monster: %w", err)
+	}
+
+	m := newMapper(src.Name)
+	m.book = src.Source
+	if src.Name == "" {
+		m.skip("name", "", "missing name")
+		return monster.Monster{}, m, nil
+	}
+	if len(src.Copy) > 0 {
+		m.skip("_copy", "", "entries copied from another monster are not supported")
+		return monster.Monster{}, m, nil
+	}
+
+	mon := monster.Monster{
+		Name:      src.Name,
+		Alignment: m.fiveToolsAlignment(src.Alignment),
+		AC:        m.fiveToolsArmorClass(src.AC),
+		HP:        formatHP(src.HP.Average, src.HP.Formula),
+		Speed:     m.fiveToolsSpeed(src.Speed),
+		Languages: convertDistances(stripTags(strings.Join(src.Languages, ", "))),
+
+		Resistances:         m.translateList("resist", damageTypes, m.fiveToolsList("resist", src.Resist)),
+		DamageImmunities:    m.translateList("immune", damageTypes, m.fiveToolsList("immune", src.Immune)),
+		ConditionImmunities: m.translateList("conditionImmune", conditions, m.fiveToolsList("conditionImmune", src.ConditionImmune)),
+
+		Traits:           fiveToolsEntries(src.Trait),
+		Actions:          fiveToolsEntries(src.Action),
+		BonusActions:     fiveToolsEntries(src.Bonus),
+		Reactions:        fiveToolsEntries(src.Reaction),
+		LegendaryActions: fiveToolsEntries(src.Legendary),
+	}
+	if src.HP.Special != "" {
+		mon.HP = src.HP.Special
+		m.issue("hp", src.HP.Special, "hit points without average")
+	}
+
+	if len(src.Size) > 0 {
+		mon.Size = m.translate("size", sizes, sizeCodes[src.Size[0]])
+	} else {
+		m.issue("size", "", "missing size")
+	}
+	m.fiveToolsType(&mon, src.Type)
+
+	if vulnerabilities := m.fiveToolsList("vulnerable", src.Vulnerable); len(vulnerabilities) > 0 {
+		m.issue("vulnerable", strings.Join(vulnerabilities, ", "), "no matching field")
+	}
+
+	skillBonuses := make(map[string]string)
+	for _, name := range sortedKeys(src.Skill) {
+		var bonus string
+		if err := json.Unmarshal(src.Skill[name], &bonus); err != nil {
+			m.issue("skill", name, "unreadable skill")
+			continue
+		}
+		skillBonuses[name] = bonus
+	}
+	mon.Skills = m.skillList(skillBonuses)
+
+	var passive int
+	if len(src.Passive) > 0 && json.Unmarshal(src.Passive, &passive) != nil {
+		m.issue("passive", string(src.Passive), "unreadable passive Perception")
+	}
+	senseEntries := make([]string, len(src.Senses))
+	for i, s := range src.Senses {
+		senseEntries[i] = stripTags(s)
+	}
+	mon.Senses = m.senses(senseEntries, passive)
+
+	abilities(&mon, monster.AbilityScores{
+		Strength:     src.Str,
+		Dexterity:    src.Dex,
+		Constitution: src.Con,
+		Intelligence: src.Int,
+		Wisdom:       src.Wis,
+		Charisma:     src.Cha,
+	}, src.Save)
+
+	cr, lairCR, ok := fiveToolsChallengeRating(src.CR)
+	if !ok {
+		m.skip("cr", string(src.CR), "unreadable challenge rating")
+		return mon, m, nil
+	}
+	m.challenge(&mon, "cr", cr, lairCR, 0, 0)
+
+	return mon, m, nil
+}
+
+// fiveToolsType reads a type given as "dragon" or as {"type": ..., "tags": [...], "swarmSize": ...}.
+func (m *mapper) fiveToolsType(mon *monster.Monster, raw json.RawMessage) {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		mon.Type = m.translate("type", creatureTypes, name)
+		return
+	}
+
+	var detail struct {
+		Type      json.RawMessage   `json:"type"`
+		Tags      []json.RawMessage `json:"tags"`
+		SwarmSize string            `json:"swarmSize"`
+	}
+	if err := json.Unmarshal(raw, &detail); err != nil || json.Unmarshal(detail.Type, &name) != nil {
+		m.issue("type", string(raw), "unreadable type")
+		return
+	}
+
+	if detail.SwarmSize != "" {
+		mon.Type = creatureTypes["swarm"]
+	} else {
+		mon.Type = m.translate("type", creatureTypes, name)
+	}
+
+	var tags []string
+	for _, rawTag := range detail.Tags {
+		var tag string
+		if json.Unmarshal(rawTag, &tag) != nil {
+			var prefixed struct {
+				Tag    string `json:"tag"`
+				Prefix string `json:"prefix"`
+			}
+			if json.Unmarshal(rawTag, &prefixed) != nil {
+				m.issue("type", string(rawTag), "unreadable tag")
+				continue
+			}
+			tag = strings.TrimSpace(prefixed.Prefix + " " + prefixed.Tag)
+		}
+		tags = append(tags, tag)
+	}
+	mon.Subtype = strings.Join(tags, ", ")
+}
+
+// fiveToolsAlignment reads alignments given as letters (["L", "E"]).
+func (m *mapper) fiveToolsAlignment(raw []json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	words := make([]string, 0, len(raw))
+	for _, r := range raw {
+		var code string
+		if json.Unmarshal(r, &code) != nil {
+			m.issue("alignment", string(r), "unreadable alignment")
+			return ""
+		}
+		word, ok := alignmentCodes[code]
+		if !ok {
+			m.issue("alignment", code, "unknown alignment")
+			return ""
+		}
+		words = append(words, word)
+	}
+	return m.translate("alignment", alignments, strings.Join(words, " "))
+}
+
+// fiveToolsArmorClass reads the first AC, given as a number or as {"ac": n, "from": [...]}.
+func (m *mapper) fiveToolsArmorClass(raw []json.RawMessage) string {
+	if len(raw) == 0 {
+		m.issue("ac", "", "missing armor class")
+		return ""
+	}
+
+	var value int
+	if err := json.Unmarshal(raw[0], &value); err == nil {
+		return strconv.Itoa(value)
+	}
+
+	var detail struct {
+		AC int `json:"ac"`
+	}
+	if err := json.Unmarshal(raw[0], &detail); err == nil && detail.AC > 0 {
+		return strconv.Itoa(detail.AC)
+	}
+
+	m.issue("ac", string(raw[0]), "unreadable armor class")
+	return ""
+}
+
+// fiveToolsSpeed reads speeds given as numbers or as {"number": n, "condition": "..."}.
+func (m *mapper) fiveToolsSpeed(fields map[string]json.RawMessage) string {
+	var canHover bool
+	if flag, ok := fields["canHover"]; ok {
+		_ = json.Unmarshal(flag, &canHover)
+	}
+
+	modes := make(map[string]speed)
+	for _, mode := range sortedKeys(fields) {
+		if mode == "canHover" || mode == "choose" || mode == "alternate" {
+			continue
+		}
+
+		var feet int
+		if err := json.Unmarshal(fields[mode], &feet); err == nil {
+			modes[mode] = speed{feet: feet, hover: canHover && mode == "fly"}
+			continue
+		}
+
+		var detail struct {
+			Number    int    `json:"number"`
+			Condition string `json:"condition"`
+		}
+		if err := json.Unmarshal(fields[mode], &detail); err != nil {
+			m.issue("speed", string(fields[mode]), "unreadable speed")
+			continue
+		}
+		s := speed{feet: detail.Number, hover: canHover && mode == "fly"}
+		if condition := stripTags(detail.Condition); strings.Contains(condition, "hover") {
+			s.hover = true
+		} else if condition != "" {
+			s.note = condition
+		}
+		modes[mode] = s
+	}
+	return m.speeds(modes)
+}
+
+// fiveToolsList flattens resistances and immunities given as strings or as
+// nested {"resist": [...], "note": "..."} groups.
+func (m *mapper) fiveToolsList(field string, raw []json.RawMessage) []string {
+	var result []string
+	for _, r := range raw {
+		var term string
+		if json.Unmarshal(r, &term) == nil {
+			result = append(result, term)
+			continue
+		}
+
+		var group map[string]json.RawMessage
+		if json.Unmarshal(r, &group) != nil {
+			m.issue(field, string(r), "unreadable entry")
+			continue
+		}
+		if special, ok := group["special"]; ok {
+			m.issue(field, string(special), "special entry not translated")
+			continue
+		}
+		if rawNote, ok := group["note"]; ok {
+			var note string
+			_ = json.Unmarshal(rawNote, &note)
+			m.issue(field, stripTags(note), "note not translated")
+		}
+		if nested, ok := group[field]; ok {
+			var list []json.RawMessage
+			if json.Unmarshal(nested, &list) == nil {
+				result = append(result, m.fiveToolsList(field, list)...)
+				continue
+			}
+		}
+		m.issue(field, string(r), "unreadable entry")
+	}
+	return result
+}
+
+// fiveToolsChallengeRating reads a CR given as "1/4" or as {"cr": "10", "lair": "11"}.
+func fiveToolsChallengeRating(raw json.RawMessage) (string, string, bool) {
+	var cr string
+	if err := json.Unmarshal(raw, &cr); err == nil {
+		return cr, "", true
+	}
+
+	var detail struct {
+		CR   string `json:"cr"`
+		Lair string `json:"lair"`
+	}
+	if err := json.Unmarshal(raw, &detail); err != nil || detail.CR == "" {
+		return "", "", false
+	}
+	return detail.CR, detail.Lair, true
+}
+
+func fiveToolsEntries(entries []fiveToolsEntry) []monster.NamedDescription {
+	if len(entries) == 0 {
+		return nil
+	}
+	result := make([]monster.NamedDescription, len(entries))
+	for i, e := range entries {
+		result[i] = monster.NamedDescription{
+			Name:        strings.TrimSpace(stripTags(e.Name)),
+			Description: flattenEntries(e.Entries),
+		}
+	}
+	return result
+}
+
+// flattenEntries joins nested 5etools entries (strings, lists and named sub-entries) into plain text.
+func flattenEntries(entries []json.RawMessage) string {
+	var parts []string
+	for _, raw := range entries {
+		var text string
+		if json.Unmarshal(raw, &text) == nil {
+			parts = append(parts, stripTags(text))
+			continue
+		}
+
+		var nested struct {
+			Name    string            `json:"name"`
+			Entry   json.RawMessage   `json:"entry"`
+			Entries []json.RawMessage `json:"entries"`
+			Items   []json.RawMessage `json:"items"`
+		}
+		if json.Unmarshal(raw, &nested) != nil {
+			continue
+		}
+		children := append(nested.Entries, nested.Items...)
+		if len(nested.Entry) > 0 {
+			children = append(children, nested.Entry)
+		}
+		body := flattenEntries(children)
+		if nested.Name != "" {
+			body = stripTags(nested.Name) + ". " + body
+		}
+		if body != "" {
+			parts = append(parts, body)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,172 @@
+// Package monsterimport maps monster JSON from other sources onto monster.Monster.
+//
+// Two shapes are supported: the SRD one used by the 5e SRD API and Open5e
+// (snake_case fields such as challenge_rating and armor_class) and the
+// 5etools one (a {"monster": [...]} document with short keys such as cr and ac).
+// Values are translated to the Italian vocabulary of the embedded dataset;
+// whatever cannot be mapped is kept as is and listed in the Report.
+package monsterimport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// ErrUnknownFormat is returned when a document matches none of the supported shapes.
+var ErrUnknownFormat = errors.New("unknown monster JSON format")
+
+// Format identifies the shape of an imported document.
+type Format string
+
+// Supported formats.
+const (
+	FormatSRD     Format = "srd"
+	Format5etools Format = "5etools"
+)
+
+// Issue describes a field that could not be mapped.
+type Issue struct {
+	Monster string `json:"monster"`
+	Field   string `json:"field"`
+	Value   string `json:"value,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// Report summarises an import: how many monsters were mapped and what failed.
+type Report struct {
+	Source   string  `json:"source"`
+	Format   Format  `json:"format"`
+	Imported int     `json:"imported"`
+	Skipped  int     `json:"skipped"`
+	Issues   []Issue `json:"issues"`
+}
+
+// Result holds the imported monsters and the report of the import.
+type Result struct {
+	Monsters []monster.Monster
+	Report   Report
+}
+
+// ImportFile imports the monsters in the JSON file at path, named after the file.
+func ImportFile(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	source := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	result, err := Import(data, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// Import detects the format of data and maps its monsters, tagging them with source.
+func Import(data []byte, source string) (*Result, error) {
+	format, records, err := detect(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Report: Report{Source: source, Format: format, Issues: []Issue{}}}
+	seen := make(map[string]bool)
+
+	for _, raw := range records {
+		var (
+			m      monster.Monster
+			mapper *mapper
+			err    error
+		)
+		switch format {
+		case FormatSRD:
+			m, mapper, err = convertSRD(raw)
+		case Format5etools:
+			m, mapper, err = convert5etools(raw)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !mapper.skipped {
+			m.ID = uniqueID(m, mapper, seen)
+		}
+		result.Report.Issues = append(result.Report.Issues, mapper.issues...)
+		if mapper.skipped {
+			result.Report.Skipped++
+			continue
+		}
+
+		m.Source = source
+		seen[m.ID] = true
+		result.Monsters = append(result.Monsters, m)
+	}
+
+	result.Report.Imported = len(result.Monsters)
+	return result, nil
+}
+
+// detect returns the format of data and its monster records.
+func detect(data []byte) (Format, []json.RawMessage, error) {
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(data, &document); err == nil {
+		if raw, ok := document["monster"]; ok {
+			records, err := records(raw)
+			return Format5etools, records, err
+		}
+		if raw, ok := document["results"]; ok {
+			records, err := records(raw)
+			return FormatSRD, records, err
+		}
+		return "", nil, ErrUnknownFormat
+	}
+
+	list, err := records(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(list) == 0 {
+		return FormatSRD, nil, nil
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(list[0], &keys); err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+	if _, ok := keys["challenge_rating"]; ok {
+		return FormatSRD, list, nil
+	}
+	if _, ok := keys["cr"]; ok {
+		return Format5etools, list, nil
+	}
+	return "", nil, ErrUnknownFormat
+}
+
+func records(data []byte) ([]json.RawMessage, error) {
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+	return list, nil
+}
+
+// uniqueID returns the monster slug, qualified by its book when it is already taken in this import.
+func uniqueID(m monster.Monster, mapper *mapper, seen map[string]bool) string {
+	id := slugify(m.Name)
+	if !seen[id] {
+		return id
+	}
+	if mapper.book != "" {
+		if qualified := id + "-" + slugify(mapper.book); !seen[qualified] {
+			return qualified
+		}
+	}
+	mapper.skip("name", m.Name, "duplicate monster name")
+	return ""
+}
@@ -0,0 +1,283 @@
+package monsterimport
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+func importFile(t *testing.T, path string) *Result {
+	t.Helper()
+	result, err := ImportFile(path)
+	if err != nil {
+		t.Fatalf("ImportFile(%s): %v", path, err)
+	}
+	return result
+}
+
+func findMonster(t *testing.T, monsters []monster.Monster, id string) monster.Monster {
+	t.Helper()
+	for _, m := range monsters {
+		if m.ID == id {
+			return m
+		}
+	}
+	t.Fatalf("monster %q not imported", id)
+	return monster.Monster{}
+}
+
+func TestImportFile_MapsFields(t *testing.T) {
+	tests := []struct {
+		file     string
+		id       string
+		expected monster.Monster
+	}{
+		{
+			file: "testdata/srd.json",
+			id:   "aboleth",
+			expected: monster.Monster{
+				Name: "Aboleth", Type: "Aberrazione", Size: "Grande", Alignment: "legale malvagio",
+				CR: "10", XP: 5900, AC: "17", HP: "135 (18d10 + 36)", Initiative: "-1 (9)",
+				Speed: "3 m, nuoto 12 m", Skills: "Percezione +10, Storia +12",
+				Senses: "Percezione passiva 20; scurovisione 36 m", Languages: "Deep Speech, telepathy 36 m",
+				CRDetail: "10 (PE 5.900; BC +4)", Source: "srd",
+			},
+		},
+		{
+			file: "testdata/srd.json",
+			id:   "swarm-of-bats",
+			expected: monster.Monster{
+				Name: "Swarm of Bats", Type: "Sciame", Size: "Media", Alignment: "senza allineamento",
+				CR: "1/4", XP: 50, AC: "12", HP: "22 (5d8)", Initiative: "+2 (12)",
+				Speed: "0 m, volo 9 m (fluttuare)", Senses: "Percezione passiva 11; vista cieca 18 m",
+				Resistances: "contundente, perforante, tagliente", ConditionImmunities: "affascinato, spaventato",
+				CRDetail: "1/4 (PE 50; BC +2)", Source: "srd",
+			},
+		},
+		{
+			file: "testdata/open5e.json",
+			id:   "goblin",
+			expected: monster.Monster{
+				Name: "Goblin", Type: "Umanoide", Subtype: "goblinoid", Size: "Piccola", Alignment: "neutrale malvagio",
+				CR: "1/4", XP: 50, AC: "15", HP: "7 (2d6)", Initiative: "+2 (12)",
+				Speed: "9 m", Skills: "Furtività +6", Senses: "Percezione passiva 9; scurovisione 18 m",
+				Languages: "Common, Goblin", CRDetail: "1/4 (PE 50; BC +2)", Source: "open5e",
+			},
+		},
+		{
+			file: "testdata/5etools.json",
+			id:   "young-red-dragon",
+			expected: monster.Monster{
+				Name: "Young Red Dragon", Type: "Drago", Size: "Grande", Alignment: "caotico malvagio",
+				CR: "10", XP: 5900, AC: "18", HP: "178 (17d10 + 85)", Initiative: "+0 (10)",
+				Speed: "12 m, scalata 12 m, volo 24 m", Skills: "Furtività +4, Percezione +8",
+				Senses: "Percezione passiva 18; vista cieca 9 m; scurovisione 36 m", Languages: "Common, Draconic",
+				DamageImmunities: "fuoco", CRDetail: "10 (PE 5.900; BC +4)", Source: "5etools",
+			},
+		},
+		{
+			file: "testdata/5etools.json",
+			id:   "aboleth",
+			expected: monster.Monster{
+				Name: "Aboleth", Type: "Aberrazione", Size: "Grande", Alignment: "legale malvagio",
+				CR: "10", XP: 5900, AC: "17", HP: "150 (20d10 + 40)", Initiative: "-1 (9)",
+				Speed: "3 m, nuoto 12 m", Skills: "Percezione +10, Storia +12",
+				Senses: "Percezione passiva 20; scurovisione 36 m", Languages: "Deep Speech, telepathy 36 m",
+				CRDetail: "10 (PE 5.900, o 7.200 nella tana; BC +4)", Source: "5etools",
+			},
+		},
+		{
+			file: "testdata/5etools.json",
+			id:   "swarm-of-rats",
+			expected: monster.Monster{
+				Name: "Swarm of Rats", Type: "Sciame", Size: "Media", Alignment: "senza allineamento",
+				CR: "1/4", XP: 50, AC: "10", HP: "24 (7d8 - 7)", Initiative: "+0 (10)",
+				Speed: "9 m", Senses: "Percezione passiva 10; scurovisione 9 m",
+				Resistances:         "contundente, perforante, tagliente",
+				ConditionImmunities: "affascinato, spaventato, afferrato, paralizzato, pietrificato, prono, trattenuto, stordito",
+				CRDetail:            "1/4 (PE 50; BC +2)", Source: "5etools",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file+"/"+tt.id, func(t *testing.T) {
+			got := findMonster(t, importFile(t, tt.file).Monsters, tt.id)
+
+			for _, field := range []struct{ name, got, expected string }{
+				{"Name", got.Name, tt.expected.Name},
+				{"Type", got.Type, tt.expected.Type},
+				{"Subtype", got.Subtype, tt.expected.Subtype},
+				{"Size", got.Size, tt.expected.Size},
+				{"Alignment", got.Alignment, tt.expected.Alignment},
+				{"CR", got.CR, tt.expected.CR},
+				{"AC", got.AC, tt.expected.AC},
+				{"HP", got.HP, tt.expected.HP},
+				{"Initiative", got.Initiative, tt.expected.Initiative},
+				{"Speed", got.Speed, tt.expected.Speed},
+				{"Skills", got.Skills, tt.expected.Skills},
+				{"Senses", got.Senses, tt.expected.Senses},
+				{"Languages", got.Languages, tt.expected.Languages},
+				{"Resistances", got.Resistances, tt.expected.Resistances},
+				{"DamageImmunities", got.DamageImmunities, tt.expected.DamageImmunities},
+				{"ConditionImmunities", got.ConditionImmunities, tt.expected.ConditionImmunities},
+				{"CRDetail", got.CRDetail, tt.expected.CRDetail},
+				{"Source", got.Source, tt.expected.Source},
+			} {
+				if field.got != field.expected {
+					t.Errorf("%s = %q, expected %q", field.name, field.got, field.expected)
+				}
+			}
+			if got.XP != tt.expected.XP {
+				t.Errorf("XP = %d, expected %d", got.XP, tt.expected.XP)
+			}
+		})
+	}
+}
+
+func TestImportFile_AbilitiesAndSaves(t *testing.T) {
+	dragon := findMonster(t, importFile(t, "testdata/5etools.json").Monsters, "young-red-dragon")
+
+	if dragon.AbilityScores.Strength != 23 || dragon.AbilityMods.Strength != 6 {
+		t.Errorf("Strength = %d (%d), expected 23 (+6)", dragon.AbilityScores.Strength, dragon.AbilityMods.Strength)
+	}
+	if dragon.AbilityMods.Wisdom != 0 {
+		t.Errorf("Wisdom modifier = %d, expected 0", dragon.AbilityMods.Wisdom)
+	}
+	// Proficient saves come from the source, the others from the modifier
+	if dragon.SavingThrows.Constitution != "+9" || dragon.SavingThrows.Strength != "+6" {
+		t.Errorf("saves = %+v", dragon.SavingThrows)
+	}
+}
+
+func TestImportFile_StripsTags(t *testing.T) {
+	dragon := findMonster(t, importFile(t, "testdata/5etools.json").Monsters, "young-red-dragon")
+
+	if len(dragon.Actions) != 3 {
+		t.Fatalf("expected 3 actions, got %d", len(dragon.Actions))
+	}
+	bite := dragon.Actions[1].Description
+	expected := "Melee Weapon Attack: +10 to hit, reach 10 ft., one target. Hit: 17 (2d10 + 6) piercing damage plus 3 (1d6) fire damage."
+	if bite != expected {
+		t.Errorf("Bite = %q, expected %q", bite, expected)
+	}
+	if name := dragon.Actions[2].Name; name != "Fire Breath (Recharge 5–6)" {
+		t.Errorf("breath name = %q", name)
+	}
+
+	swarm := findMonster(t, importFile(t, "testdata/5etools.json").Monsters, "swarm-of-rats")
+	expected = "Melee Weapon Attack: +2 to hit, reach 0 ft., one target in the swarm's space. Hit: 7 (2d6) piercing damage. 3 (1d6) piercing damage if the swarm has half of its hit points or fewer."
+	if got := swarm.Actions[0].Description; got != expected {
+		t.Errorf("Bites = %q, expected %q", got, expected)
+	}
+}
+
+func TestImportFile_Report(t *testing.T) {
+	tests := []struct {
+		file     string
+		format   Format
+		imported int
+		skipped  int
+		issues   []Issue
+	}{
+		{
+			file:     "testdata/srd.json",
+			format:   FormatSRD,
+			imported: 3,
+			skipped:  1,
+			issues: []Issue{
+				{Monster: "Gargoyle", Field: "damage_resistances", Value: "slashing from nonmagical attacks that aren't adamantine", Reason: "no Italian equivalent"},
+				{Monster: "Gargoyle", Field: "damage_vulnerabilities", Value: "thunder", Reason: "no matching field"},
+				{Monster: "Mystery Beast", Field: "challenge_rating", Value: "unknown", Reason: "unknown challenge rating; monster skipped"},
+			},
+		},
+		{
+			file:     "testdata/open5e.json",
+			format:   FormatSRD,
+			imported: 1,
+			issues:   []Issue{},
+		},
+		{
+			file:     "testdata/5etools.json",
+			format:   Format5etools,
+			imported: 4,
+			skipped:  1,
+			issues: []Issue{
+				{Monster: "Aboleth", Field: "_copy", Reason: "entries copied from another monster are not supported; monster skipped"},
+				{Monster: "Swarm of Rats", Field: "resist", Value: "while in dim light", Reason: "note not translated"},
+				{Monster: "Hobgoblin Captain", Field: "speed", Value: "(with fly)", Reason: "condition not translated"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			result := importFile(t, tt.file)
+			report := result.Report
+
+			if report.Format != tt.format {
+				t.Errorf("Format = %q, expected %q", report.Format, tt.format)
+			}
+			if report.Imported != tt.imported || len(result.Monsters) != tt.imported {
+				t.Errorf("Imported = %d (%d monsters), expected %d", report.Imported, len(result.Monsters), tt.imported)
+			}
+			if report.Skipped != tt.skipped {
+				t.Errorf("Skipped = %d, expected %d", report.Skipped, tt.skipped)
+			}
+			if len(report.Issues) != len(tt.issues) {
+				t.Fatalf("issues = %+v, expected %+v", report.Issues, tt.issues)
+			}
+			for i, issue := range report.Issues {
+				if issue != tt.issues[i] {
+					t.Errorf("issue %d = %+v, expected %+v", i, issue, tt.issues[i])
+				}
+			}
+		})
+	}
+}
+
+func TestImport_DuplicateNames(t *testing.T) {
+	data := []byte(`{"monster": [
+		{"name": "Goblin", "source": "MM", "size": ["S"], "type": "humanoid", "ac": [15], "hp": {"average": 7, "formula": "2d6"}, "cr": "1/4"},
+		{"name": "Goblin", "source": "XMM", "size": ["S"], "type": "humanoid", "ac": [15], "hp": {"average": 10, "formula": "3d6"}, "cr": "1/4"},
+		{"name": "Goblin", "source": "XMM", "size": ["S"], "type": "humanoid", "ac": [15], "hp": {"average": 10, "formula": "3d6"}, "cr": "1/4"}
+	]}`)
+
+	result, err := Import(data, "bestiary")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Monsters) != 2 {
+		t.Fatalf("expected 2 monsters, got %d", len(result.Monsters))
+	}
+	if result.Monsters[0].ID != "goblin" || result.Monsters[1].ID != "goblin-xmm" {
+		t.Errorf("IDs = %q, %q", result.Monsters[0].ID, result.Monsters[1].ID)
+	}
+	if result.Report.Skipped != 1 {
+		t.Errorf("Skipped = %d, expected 1", result.Report.Skipped)
+	}
+}
+
+func TestImport_UnknownFormat(t *testing.T) {
+	tests := map[string]string{
+		"object":      `{"creatures": []}`,
+		"array":       `[{"title": "Goblin"}]`,
+		"not JSON":    `monsters`,
+		"bad records": `{"monster": {"name": "Goblin"}}`,
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Import([]byte(data), "test"); !errors.Is(err, ErrUnknownFormat) {
+				t.Errorf("expected ErrUnknownFormat, got %v", err)
+			}
+		})
+	}
+}
+
+func TestImportFile_Missing(t *testing.T) {
+	if _, err := ImportFile("testdata/missing.json"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,233 @@
+package monsterimport
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+var (
+	senseRegex   = regexp.MustCompile(`(?i)^(darkvision|blindsight|tremorsense|truesight)\s+(\d+)\s*ft\.?\s*(.*)$`)
+	passiveRegex = regexp.MustCompile(`(?i)^passive perception\s+(\d+)$`)
+	listSplit    = regexp.MustCompile(`\s*(?:[,;]|\band\b)\s*`)
+)
+
+// speedModeOrder is the order movement modes are listed in.
+var speedModeOrder = []string{"walk", "burrow", "climb", "fly", "swim"}
+
+// mapper collects the issues found while converting one monster.
+type mapper struct {
+	name    string
+	book    string
+	issues  []Issue
+	skipped bool
+}
+
+func newMapper(name string) *mapper {
+	return &mapper{name: name}
+}
+
+func (m *mapper) issue(field, value, reason string) {
+	m.issues = append(m.issues, Issue{Monster: m.name, Field: field, Value: value, Reason: reason})
+}
+
+// skip records an issue that prevents the monster from being imported.
+func (m *mapper) skip(field, value, reason string) {
+	m.issue(field, value, reason+"; monster skipped")
+	m.skipped = true
+}
+
+// translate maps term through vocabulary, keeping it untranslated when it is unknown.
+func (m *mapper) translate(field string, vocabulary map[string]string, term string) string {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return ""
+	}
+	if v, ok := translate(vocabulary, term); ok {
+		return v
+	}
+	m.issue(field, term, "no Italian equivalent")
+	return term
+}
+
+// translateList translates a list of terms, splitting entries like "cold, fire and poison".
+func (m *mapper) translateList(field string, vocabulary map[string]string, terms []string) string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, entry := range terms {
+		for _, term := range listSplit.Split(entry, -1) {
+			translated := m.translate(field, vocabulary, term)
+			if translated != "" && !seen[translated] {
+				seen[translated] = true
+				result = append(result, translated)
+			}
+		}
+	}
+	return strings.Join(result, ", ")
+}
+
+// speed is one movement mode of a monster.
+type speed struct {
+	feet  int
+	hover bool
+	note  string
+}
+
+// speeds formats movement modes like the embedded dataset ("9 m, volo 18 m (fluttuare)").
+func (m *mapper) speeds(modes map[string]speed) string {
+	for _, mode := range sortedKeys(modes) {
+		if _, ok := speedModes[mode]; !ok {
+			m.issue("speed", mode, "unknown movement mode")
+		}
+	}
+
+	var parts []string
+	for _, mode := range speedModeOrder {
+		s, ok := modes[mode]
+		if !ok {
+			continue
+		}
+		part := metres(s.feet)
+		if label := speedModes[mode]; label != "" {
+			part = label + " " + part
+		}
+		if s.hover {
+			part += " (fluttuare)"
+		}
+		if s.note != "" {
+			m.issue("speed", s.note, "condition not translated")
+			part += " " + s.note
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// senses formats senses like the embedded dataset ("Percezione passiva 20; scurovisione 36 m").
+func (m *mapper) senses(entries []string, passive int) string {
+	var parts []string
+	if passive > 0 {
+		parts = append(parts, fmt.Sprintf("Percezione passiva %d", passive))
+	}
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if match := passiveRegex.FindStringSubmatch(entry); match != nil {
+			if passive == 0 {
+				parts = append([]string{"Percezione passiva " + match[1]}, parts...)
+			}
+			continue
+		}
+		match := senseRegex.FindStringSubmatch(entry)
+		if match == nil {
+			m.issue("senses", entry, "unknown sense")
+			parts = append(parts, entry)
+			continue
+		}
+		feet, _ := strconv.Atoi(match[2])
+		part := senses[strings.ToLower(match[1])] + " " + metres(feet)
+		if note := strings.TrimSpace(match[3]); note != "" {
+			m.issue("senses", note, "condition not translated")
+			part += " " + note
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// skillList formats skill bonuses keyed by English skill name ("Percezione +10, Storia +12").
+func (m *mapper) skillList(bonuses map[string]string) string {
+	parts := make([]string, 0, len(bonuses))
+	for _, name := range sortedKeys(bonuses) {
+		parts = append(parts, m.translate("skills", skills, name)+" "+bonuses[name])
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// challenge sets CR, XP and a cr_detail in the format of the embedded dataset.
+// XP and proficiency bonus are derived from the CR when the source omits them.
+func (m *mapper) challenge(mon *monster.Monster, field, cr, lairCR string, xp, pb int) {
+	baseXP, ok := monster.XPForCR(cr)
+	if !ok {
+		m.skip(field, cr, "unknown challenge rating")
+		return
+	}
+	if xp == 0 {
+		xp = baseXP
+	}
+	if pb == 0 {
+		pb, _ = monster.ProficiencyBonusForCR(cr)
+	}
+
+	detail := fmt.Sprintf("%s (PE %s", cr, formatThousands(xp))
+	if lairCR != "" {
+		if lairXP, ok := monster.XPForCR(lairCR); ok {
+			detail += fmt.Sprintf(", o %s nella tana", formatThousands(lairXP))
+		} else {
+			m.issue(field, lairCR, "unknown lair challenge rating")
+		}
+	}
+	detail += fmt.Sprintf("; BC %s)", formatModifier(pb))
+
+	mon.CR = cr
+	mon.XP = xp
+	mon.CRDetail = detail
+}
+
+// abilities sets scores, modifiers, saving throws and initiative.
+// Saves are keyed by the three-letter ability ("dex"); missing ones default to the modifier.
+func abilities(mon *monster.Monster, scores monster.AbilityScores, saves map[string]string) {
+	mods := monster.AbilityScores{
+		Strength:     modifier(scores.Strength),
+		Dexterity:    modifier(scores.Dexterity),
+		Constitution: modifier(scores.Constitution),
+		Intelligence: modifier(scores.Intelligence),
+		Wisdom:       modifier(scores.Wisdom),
+		Charisma:     modifier(scores.Charisma),
+	}
+
+	save := func(key string, mod int) string {
+		if v, ok := saves[key]; ok {
+			return v
+		}
+		return formatModifier(mod)
+	}
+
+	mon.AbilityScores = scores
+	mon.AbilityMods = mods
+	mon.SavingThrows = monster.SavingThrows{
+		Strength:     save("str", mods.Strength),
+		Dexterity:    save("dex", mods.Dexterity),
+		Constitution: save("con", mods.Constitution),
+		Intelligence: save("int", mods.Intelligence),
+		Wisdom:       save("wis", mods.Wisdom),
+		Charisma:     save("cha", mods.Charisma),
+	}
+	if mon.Initiative == "" {
+		mon.Initiative = fmt.Sprintf("%s (%d)", formatModifier(mods.Dexterity), 10+mods.Dexterity)
+	}
+}
+
+func modifier(score int) int {
+	if score < 10 {
+		return (score - 11) / 2
+	}
+	return (score - 10) / 2
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
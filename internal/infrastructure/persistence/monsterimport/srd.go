@@ -0,0 +1,349 @@
+package monsterimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+)
+
+// srdMonster covers both the 5e SRD API and Open5e: fields whose shape differs
+// between the two are decoded from raw JSON.
+type srdMonster struct {
+	Name      string `json:"name"`
+	Size      string `json:"size"`
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype"`
+	Group     string `json:"group"`
+	Alignment string `json:"alignment"`
+
+	ArmorClass    json.RawMessage `json:"armor_class"`
+	HitPoints     int             `json:"hit_points"`
+	HitDice       string          `json:"hit_dice"`
+	HitPointsRoll string          `json:"hit_points_roll"`
+	Speed         json.RawMessage `json:"speed"`
+
+	Strength     int `json:"strength"`
+	Dexterity    int `json:"dexterity"`
+	Constitution int `json:"constitution"`
+	Intelligence int `json:"intelligence"`
+	Wisdom       int `json:"wisdom"`
+	Charisma     int `json:"charisma"`
+
+	// 5e SRD API: saves and skills are proficiencies
+	Proficiencies []srdProficiency `json:"proficiencies"`
+	// Open5e: saves are separate fields and skills a map
+	StrengthSave     *int           `json:"strength_save"`
+	DexteritySave    *int           `json:"dexterity_save"`
+	ConstitutionSave *int           `json:"constitution_save"`
+	IntelligenceSave *int           `json:"intelligence_save"`
+	WisdomSave       *int           `json:"wisdom_save"`
+	CharismaSave     *int           `json:"charisma_save"`
+	Skills           map[string]int `json:"skills"`
+
+	DamageVulnerabilities json.RawMessage `json:"damage_vulnerabilities"`
+	DamageResistances     json.RawMessage `json:"damage_resistances"`
+	DamageImmunities      json.RawMessage `json:"damage_immunities"`
+	ConditionImmunities   json.RawMessage `json:"condition_immunities"`
+	Senses                json.RawMessage `json:"senses"`
+	Languages             string          `json:"languages"`
+
+	ChallengeRating  json.RawMessage `json:"challenge_rating"`
+	ProficiencyBonus int             `json:"proficiency_bonus"`
+	XP               int             `json:"xp"`
+
+	SpecialAbilities []srdEntry `json:"special_abilities"`
+	Actions          []srdEntry `json:"actions"`
+	BonusActions     []srdEntry `json:"bonus_actions"`
+	Reactions        []srdEntry `json:"reactions"`
+	LegendaryActions []srdEntry `json:"legendary_actions"`
+}
+
+type srdProficiency struct {
+	Value       int `json:"value"`
+	Proficiency struct {
+		Index string `json:"index"`
+		Name  string `json:"name"`
+	} `json:"proficiency"`
+}
+
+type srdEntry struct {
+	Name string `json:"name"`
+	Desc string `json:"desc"`
+}
+
+func convertSRD(raw json.RawMessage) (monster.Monster, *mapper, error) {
+	var src srdMonster
+	if err := json.Unmarshal(raw, &src); err != nil {
+		return monster.Monster{}, nil, fmt.Errorf("invalid SRD monster: %w", err)
+	}
+
+	m := newMapper(src.Name)
+	if src.Name == "" {
+		m.skip("name", "", "missing name")
+		return monster.Monster{}, m, nil
+	}
+
+	mon := monster.Monster{
+		Name:      src.Name,
+		Size:      m.translate("size", sizes, src.Size),
+		Subtype:   src.Subtype,
+		Group:     src.Group,
+		Alignment: m.translate("alignment", alignments, src.Alignment),
+		AC:        m.srdArmorClass(src.ArmorClass),
+		HP:        formatHP(src.HitPoints, firstNonEmpty(src.HitPointsRoll, src.HitDice)),
+		Speed:     m.srdSpeed(src.Speed),
+		Languages: convertDistances(src.Languages),
+
+		Resistances:         m.translateList("damage_resistances", damageTypes, m.srdList("damage_resistances", src.DamageResistances)),
+		DamageImmunities:    m.translateList("damage_immunities", damageTypes, m.srdList("damage_immunities", src.DamageImmunities)),
+		ConditionImmunities: m.translateList("condition_immunities", conditions, m.srdList("condition_immunities", src.ConditionImmunities)),
+
+		Traits:           srdEntries(src.SpecialAbilities),
+		Actions:          srdEntries(src.Actions),
+		BonusActions:     srdEntries(src.BonusActions),
+		Reactions:        srdEntries(src.Reactions),
+		LegendaryActions: srdEntries(src.LegendaryActions),
+	}
+
+	// Swarms are typed "swarm of Tiny beasts"
+	if strings.HasPrefix(strings.ToLower(src.Type), "swarm") {
+		mon.Type = creatureTypes["swarm"]
+	} else {
+		mon.Type = m.translate("type", creatureTypes, src.Type)
+	}
+
+	if vulnerabilities := m.srdList("damage_vulnerabilities", src.DamageVulnerabilities); len(vulnerabilities) > 0 {
+		m.issue("damage_vulnerabilities", strings.Join(vulnerabilities, ", "), "no matching field")
+	}
+
+	saves, skillBonuses := m.srdProficiencies(src)
+	mon.Skills = m.skillList(skillBonuses)
+	mon.Senses = m.srdSenses(src.Senses)
+
+	abilities(&mon, monster.AbilityScores{
+		Strength:     src.Strength,
+		Dexterity:    src.Dexterity,
+		Constitution: src.Constitution,
+		Intelligence: src.Intelligence,
+		Wisdom:       src.Wisdom,
+		Charisma:     src.Charisma,
+	}, saves)
+
+	cr, ok := srdChallengeRating(src.ChallengeRating)
+	if !ok {
+		m.skip("challenge_rating", string(src.ChallengeRating), "unreadable challenge rating")
+		return mon, m, nil
+	}
+	m.challenge(&mon, "challenge_rating", cr, "", src.XP, src.ProficiencyBonus)
+
+	return mon, m, nil
+}
+
+// srdArmorClass reads an AC given as a number or as a list of {"value": n}.
+func (m *mapper) srdArmorClass(raw json.RawMessage) string {
+	var value int
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return strconv.Itoa(value)
+	}
+
+	var list []struct {
+		Value int `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &list); err == nil && len(list) > 0 {
+		return strconv.Itoa(list[0].Value)
+	}
+
+	m.issue("armor_class", string(raw), "unreadable armor class")
+	return ""
+}
+
+// srdSpeed reads speeds given as "30 ft." strings or as numbers, with an optional hover flag.
+func (m *mapper) srdSpeed(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		m.issue("speed", string(raw), "unreadable speed")
+		return ""
+	}
+
+	var hover bool
+	if flag, ok := fields["hover"]; ok {
+		_ = json.Unmarshal(flag, &hover)
+		delete(fields, "hover")
+	}
+
+	modes := make(map[string]speed)
+	for _, mode := range sortedKeys(fields) {
+		value := fields[mode]
+		feet, note, ok := srdDistance(value)
+		if !ok {
+			m.issue("speed", string(value), "unreadable speed")
+			continue
+		}
+		modes[mode] = speed{feet: feet, hover: hover && mode == "fly", note: note}
+	}
+	return m.speeds(modes)
+}
+
+// srdDistance reads a distance given as a number of feet or as "40 ft. (hover)".
+func srdDistance(raw json.RawMessage) (int, string, bool) {
+	var feet int
+	if err := json.Unmarshal(raw, &feet); err == nil {
+		return feet, "", true
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return 0, "", false
+	}
+	number, rest, _ := strings.Cut(strings.TrimSpace(text), " ")
+	feet, err := strconv.Atoi(number)
+	if err != nil {
+		return 0, "", false
+	}
+	rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), "ft."))
+	return feet, rest, true
+}
+
+// srdList reads a list given as a string, a list of strings or a list of {"name": ...}.
+func (m *mapper) srdList(field string, raw json.RawMessage) []string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if text == "" {
+			return nil
+		}
+		return []string{text}
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+
+	var named []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &named); err == nil {
+		for _, n := range named {
+			list = append(list, n.Name)
+		}
+		return list
+	}
+
+	m.issue(field, string(raw), "unreadable list")
+	return nil
+}
+
+// srdSenses reads senses given as a string or as an object of distances and passive_perception.
+func (m *mapper) srdSenses(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return m.senses(strings.Split(text, ","), 0)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		m.issue("senses", string(raw), "unreadable senses")
+		return ""
+	}
+
+	var passive int
+	var entries []string
+	for _, name := range []string{"blindsight", "darkvision", "tremorsense", "truesight"} {
+		var distance string
+		if value, ok := fields[name]; ok && json.Unmarshal(value, &distance) == nil {
+			entries = append(entries, name+" "+distance)
+		}
+		delete(fields, name)
+	}
+	if value, ok := fields["passive_perception"]; ok {
+		_ = json.Unmarshal(value, &passive)
+		delete(fields, "passive_perception")
+	}
+	for _, name := range sortedKeys(fields) {
+		m.issue("senses", name+" "+string(fields[name]), "unknown sense")
+	}
+	return m.senses(entries, passive)
+}
+
+// srdProficiencies returns saving throws keyed by ability and skill bonuses keyed by English name.
+func (m *mapper) srdProficiencies(src srdMonster) (map[string]string, map[string]string) {
+	saves := make(map[string]string)
+	skillBonuses := make(map[string]string)
+
+	for _, p := range src.Proficiencies {
+		switch index := p.Proficiency.Index; {
+		case strings.HasPrefix(index, "saving-throw-"):
+			saves[strings.TrimPrefix(index, "saving-throw-")] = formatModifier(p.Value)
+		case strings.HasPrefix(index, "skill-"):
+			skillBonuses[strings.TrimPrefix(p.Proficiency.Name, "Skill: ")] = formatModifier(p.Value)
+		default:
+			m.issue("proficiencies", index, "unknown proficiency")
+		}
+	}
+
+	for key, value := range map[string]*int{
+		"str": src.StrengthSave,
+		"dex": src.DexteritySave,
+		"con": src.ConstitutionSave,
+		"int": src.IntelligenceSave,
+		"wis": src.WisdomSave,
+		"cha": src.CharismaSave,
+	} {
+		if value != nil {
+			saves[key] = formatModifier(*value)
+		}
+	}
+	for name, bonus := range src.Skills {
+		skillBonuses[name] = formatModifier(bonus)
+	}
+
+	return saves, skillBonuses
+}
+
+// srdChallengeRating reads a CR given as a number (0.25) or a string ("1/4").
+func srdChallengeRating(raw json.RawMessage) (string, bool) {
+	var number float64
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return crFromNumber(number), true
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil && text != "" {
+		return text, true
+	}
+	return "", false
+}
+
+func srdEntries(entries []srdEntry) []monster.NamedDescription {
+	if len(entries) == 0 {
+		return nil
+	}
+	result := make([]monster.NamedDescription, len(entries))
+	for i, e := range entries {
+		result[i] = monster.NamedDescription{Name: e.Name, Description: e.Desc}
+	}
+	return result
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
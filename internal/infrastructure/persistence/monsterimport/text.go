@@ -0,0 +1,177 @@
+package monsterimport
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	distanceRegex = regexp.MustCompile(`(\d+(?:,\d{3})*)\s*(?:ft\.?|feet|foot)`)
+	formulaRegex  = regexp.MustCompile(`\s*([+-])\s*`)
+	tagRegex      = regexp.MustCompile(`\{@(\w+)\s*([^{}]*)\}`)
+)
+
+// slugify turns a monster name into an ID like the embedded ones ("Drago d'ottone" → "drago-dottone").
+func slugify(name string) string {
+	replacer := strings.NewReplacer("'", "", "’", "", "à", "a", "è", "e", "é", "e", "ì", "i", "ò", "o", "ù", "u")
+	name = replacer.Replace(strings.ToLower(name))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// metres converts feet to the metres used by the Italian books (30 ft. → "9 m").
+func metres(feet int) string {
+	value := strconv.FormatFloat(float64(feet*3)/10, 'f', -1, 64)
+	return strings.Replace(value, ".", ",", 1) + " m"
+}
+
+// convertDistances rewrites every distance in feet found in s as metres.
+func convertDistances(s string) string {
+	return distanceRegex.ReplaceAllStringFunc(s, func(match string) string {
+		digits := strings.ReplaceAll(distanceRegex.FindStringSubmatch(match)[1], ",", "")
+		feet, err := strconv.Atoi(digits)
+		if err != nil {
+			return match
+		}
+		return metres(feet)
+	})
+}
+
+// formatModifier formats a bonus with its sign ("+5", "-1").
+func formatModifier(n int) string {
+	if n < 0 {
+		return strconv.Itoa(n)
+	}
+	return "+" + strconv.Itoa(n)
+}
+
+// formatThousands formats n with the Italian thousands separator (5900 → "5.900").
+func formatThousands(n int) string {
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// formatHP formats hit points like the embedded dataset ("135 (18d10 + 36)").
+func formatHP(average int, formula string) string {
+	formula = strings.TrimSpace(formulaRegex.ReplaceAllString(formula, " $1 "))
+	if formula == "" {
+		return strconv.Itoa(average)
+	}
+	return fmt.Sprintf("%d (%s)", average, formula)
+}
+
+// crFromNumber formats a numeric challenge rating ("0.25" → "1/4").
+func crFromNumber(cr float64) string {
+	switch cr {
+	case 0.125:
+		return "1/8"
+	case 0.25:
+		return "1/4"
+	case 0.5:
+		return "1/2"
+	default:
+		return strconv.FormatFloat(cr, 'f', -1, 64)
+	}
+}
+
+var attackTags = map[string]string{
+	"mw":    "Melee Weapon Attack:",
+	"rw":    "Ranged Weapon Attack:",
+	"mw,rw": "Melee or Ranged Weapon Attack:",
+	"ms":    "Melee Spell Attack:",
+	"rs":    "Ranged Spell Attack:",
+	"ms,rs": "Melee or Ranged Spell Attack:",
+	"m":     "Melee Attack Roll:",
+	"r":     "Ranged Attack Roll:",
+	"m,r":   "Melee or Ranged Attack Roll:",
+}
+
+var abilityNames = map[string]string{
+	"str": "Strength",
+	"dex": "Dexterity",
+	"con": "Constitution",
+	"int": "Intelligence",
+	"wis": "Wisdom",
+	"cha": "Charisma",
+}
+
+// stripTags replaces 5etools inline tags ("{@hit 5}", "{@damage 2d6}") with plain text.
+func stripTags(s string) string {
+	for strings.Contains(s, "{@") {
+		replaced := tagRegex.ReplaceAllStringFunc(s, func(match string) string {
+			parts := tagRegex.FindStringSubmatch(match)
+			return tagText(parts[1], strings.TrimSpace(parts[2]))
+		})
+		if replaced == s {
+			break
+		}
+		s = replaced
+	}
+	return s
+}
+
+func tagText(tag, text string) string {
+	switch tag {
+	case "atk", "atkr":
+		if label, ok := attackTags[text]; ok {
+			return label
+		}
+		return text
+	case "hit":
+		if n, err := strconv.Atoi(text); err == nil {
+			return formatModifier(n)
+		}
+		return text
+	case "h":
+		return "Hit: "
+	case "hom":
+		return "Hit or Miss: "
+	case "dc":
+		return "DC " + text
+	case "recharge":
+		if text == "" || text == "6" {
+			return "(Recharge 6)"
+		}
+		return "(Recharge " + text + "–6)"
+	case "actSave":
+		if name, ok := abilityNames[text]; ok {
+			return name + " Saving Throw:"
+		}
+		return text + " Saving Throw:"
+	case "actSaveFail":
+		return "Failure:"
+	case "actSaveSuccess":
+		return "Success:"
+	case "actSaveSuccessOrFail":
+		return "Failure or Success:"
+	}
+
+	// Generic tags are "{@tag text|source|display text}"
+	parts := strings.Split(text, "|")
+	if len(parts) >= 3 && parts[2] != "" {
+		return parts[2]
+	}
+	return parts[0]
+}
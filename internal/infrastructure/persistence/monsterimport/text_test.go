@@ -0,0 +1,62 @@
+package monsterimport
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Young Red Dragon":        "young-red-dragon",
+		"Drago d'ottone cucciolo": "drago-dottone-cucciolo",
+		"Mind Flayer (Psion)":     "mind-flayer-psion",
+		"Mostruosità":             "mostruosita",
+	}
+	for name, expected := range tests {
+		if got := slugify(name); got != expected {
+			t.Errorf("slugify(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestConvertDistances(t *testing.T) {
+	tests := map[string]string{
+		"telepathy 120 ft.": "telepathy 36 m",
+		"15 feet":           "4,5 m",
+		"5 ft. and 1 foot":  "1,5 m and 0,3 m",
+		"1,000 ft.":         "300 m",
+		"no distance":       "no distance",
+	}
+	for text, expected := range tests {
+		if got := convertDistances(text); got != expected {
+			t.Errorf("convertDistances(%q) = %q, expected %q", text, got, expected)
+		}
+	}
+}
+
+func TestFormatThousands(t *testing.T) {
+	tests := map[int]string{
+		0:      "0",
+		450:    "450",
+		5900:   "5.900",
+		155000: "155.000",
+	}
+	for n, expected := range tests {
+		if got := formatThousands(n); got != expected {
+			t.Errorf("formatThousands(%d) = %q, expected %q", n, got, expected)
+		}
+	}
+}
+
+func TestStripTags(t *testing.T) {
+	tests := map[string]string{
+		"{@atk mw,rw} {@hit 4} to hit": "Melee or Ranged Weapon Attack: +4 to hit",
+		"{@actSave dex} {@dc 15}":      "Dexterity Saving Throw: DC 15",
+		"{@recharge}":                  "(Recharge 6)",
+		"{@spell fireball}":            "fireball",
+		"{@item longsword|phb|swords}": "swords",
+		"{@damage {@dice 1d6}}":        "1d6",
+	}
+	for text, expected := range tests {
+		if got := stripTags(text); got != expected {
+			t.Errorf("stripTags(%q) = %q, expected %q", text, got, expected)
+		}
+	}
+}
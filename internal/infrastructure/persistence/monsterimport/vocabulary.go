@@ -0,0 +1,146 @@
+package monsterimport
+
+import "strings"
+
+// The embedded dataset is in Italian: imported values are translated to the
+// same vocabulary so that facets and filters treat both sources alike.
+
+var sizes = map[string]string{
+	"tiny":       "Minuscola",
+	"small":      "Piccola",
+	"medium":     "Media",
+	"large":      "Grande",
+	"huge":       "Enorme",
+	"gargantuan": "Mastodontica",
+}
+
+// sizeCodes maps the single-letter sizes used by 5etools.
+var sizeCodes = map[string]string{
+	"T": "tiny",
+	"S": "small",
+	"M": "medium",
+	"L": "large",
+	"H": "huge",
+	"G": "gargantuan",
+}
+
+var creatureTypes = map[string]string{
+	"aberration":  "Aberrazione",
+	"beast":       "Bestia",
+	"celestial":   "Celestiale",
+	"construct":   "Costrutto",
+	"dragon":      "Drago",
+	"elemental":   "Elementale",
+	"fey":         "Folletto",
+	"fiend":       "Immondo",
+	"giant":       "Gigante",
+	"humanoid":    "Umanoide",
+	"monstrosity": "Mostruosità",
+	"ooze":        "Melma",
+	"plant":       "Vegetale",
+	"undead":      "Non morto",
+	"swarm":       "Sciame",
+}
+
+var alignments = map[string]string{
+	"lawful good":     "legale buono",
+	"neutral good":    "neutrale buono",
+	"chaotic good":    "caotico buono",
+	"lawful neutral":  "legale neutrale",
+	"neutral":         "neutrale",
+	"true neutral":    "neutrale",
+	"chaotic neutral": "caotico neutrale",
+	"lawful evil":     "legale malvagio",
+	"neutral evil":    "neutrale malvagio",
+	"chaotic evil":    "caotico malvagio",
+	"unaligned":       "senza allineamento",
+	"any alignment":   "qualsiasi allineamento",
+}
+
+// alignmentCodes maps the letters used by 5etools alignments.
+var alignmentCodes = map[string]string{
+	"L": "lawful",
+	"N": "neutral",
+	"C": "chaotic",
+	"G": "good",
+	"E": "evil",
+	"U": "unaligned",
+	"A": "any alignment",
+}
+
+var damageTypes = map[string]string{
+	"acid":        "acido",
+	"bludgeoning": "contundente",
+	"cold":        "freddo",
+	"fire":        "fuoco",
+	"force":       "forza",
+	"lightning":   "fulmine",
+	"necrotic":    "necrotico",
+	"piercing":    "perforante",
+	"poison":      "veleno",
+	"psychic":     "psichico",
+	"radiant":     "radioso",
+	"slashing":    "tagliente",
+	"thunder":     "tuono",
+}
+
+var conditions = map[string]string{
+	"blinded":       "accecato",
+	"charmed":       "affascinato",
+	"deafened":      "assordato",
+	"exhaustion":    "indebolimento",
+	"frightened":    "spaventato",
+	"grappled":      "afferrato",
+	"incapacitated": "incapacitato",
+	"invisible":     "invisibile",
+	"paralyzed":     "paralizzato",
+	"petrified":     "pietrificato",
+	"poisoned":      "avvelenato",
+	"prone":         "prono",
+	"restrained":    "trattenuto",
+	"stunned":       "stordito",
+	"unconscious":   "privo di sensi",
+}
+
+// speedModes maps movement modes; walking speed has no label.
+var speedModes = map[string]string{
+	"walk":   "",
+	"fly":    "volo",
+	"swim":   "nuoto",
+	"climb":  "scalata",
+	"burrow": "scavo",
+}
+
+var senses = map[string]string{
+	"darkvision":  "scurovisione",
+	"blindsight":  "vista cieca",
+	"tremorsense": "percezione tellurica",
+	"truesight":   "vista pura",
+}
+
+var skills = map[string]string{
+	"acrobatics":      "Acrobazia",
+	"animal handling": "Addestrare Animali",
+	"arcana":          "Arcano",
+	"athletics":       "Atletica",
+	"deception":       "Inganno",
+	"history":         "Storia",
+	"insight":         "Intuizione",
+	"intimidation":    "Intimidire",
+	"investigation":   "Indagare",
+	"medicine":        "Medicina",
+	"nature":          "Natura",
+	"perception":      "Percezione",
+	"performance":     "Intrattenere",
+	"persuasion":      "Persuasione",
+	"religion":        "Religione",
+	"sleight of hand": "Rapidità di Mano",
+	"stealth":         "Furtività",
+	"survival":        "Sopravvivenza",
+}
+
+// translate looks up an English term, ignoring case and surrounding spaces.
+func translate(vocabulary map[string]string, term string) (string, bool) {
+	v, ok := vocabulary[strings.ToLower(strings.TrimSpace(term))]
+	return v, ok
+}
@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+)
+
+// CampaignRepository implements the campaign.CampaignRepository interface on SQLite
+type CampaignRepository struct {
+	db *sql.DB
+}
+
+// NewCampaignRepository creates a new SQLite campaign repository
+func NewCampaignRepository(db *sql.DB) *CampaignRepository {
+	return &CampaignRepository{db: db}
+}
+
+// Save inserts or replaces the campaign and its links to parties and encounters
+func (r *CampaignRepository) Save(c *campaign.Campaign) error {
+	if c.ID == "" {
+		return fmt.Errorf("campaign ID cannot be empty")
+	}
+
+	return inTx(r.db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT INTO campaigns (id, name) VALUES (?, ?)
+			ON CONFLICT (id) DO UPDATE SET name = excluded.name`, c.ID, c.Name); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM campaign_parties WHERE campaign_id = ?`, c.ID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM campaign_encounters WHERE campaign_id = ?`, c.ID); err != nil {
+			return err
+		}
+		for i, id := range c.PartyIDs {
+			if _, err := tx.Exec(`INSERT INTO campaign_parties (campaign_id, party_id, position) VALUES (?, ?, ?)`, c.ID, id, i); err != nil {
+				return err
+			}
+		}
+		for i, id := range c.EncounterIDs {
+			if _, err := tx.Exec(`INSERT INTO campaign_encounters (campaign_id, encounter_id, position) VALUES (?, ?, ?)`, c.ID, id, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindByID returns the campaign with the IDs of its parties and encounters
+func (r *CampaignRepository) FindByID(id string) (*campaign.Campaign, error) {
+	c := campaign.Campaign{ID: id}
+	err := r.db.QueryRow(`SELECT name FROM campaigns WHERE id = ?`, id).Scan(&c.Name)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", campaign.ErrCampaignNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaign %s: %w", id, err)
+	}
+
+	if c.PartyIDs, err = queryIDs(r.db, `SELECT party_id FROM campaign_parties WHERE campaign_id = ? ORDER BY position`, id); err != nil {
+		return nil, fmt.Errorf("failed to load parties of campaign %s: %w", id, err)
+	}
+	if c.EncounterIDs, err = queryIDs(r.db, `SELECT encounter_id FROM campaign_encounters WHERE campaign_id = ? ORDER BY position`, id); err != nil {
+		return nil, fmt.Errorf("failed to load encounters of campaign %s: %w", id, err)
+	}
+	return &c, nil
+}
+
+// FindAll returns every campaign ordered by name
+func (r *CampaignRepository) FindAll() ([]campaign.Campaign, error) {
+	ids, err := queryIDs(r.db, `SELECT id FROM campaigns ORDER BY name, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list campaigns: %w", err)
+	}
+
+	campaigns := make([]campaign.Campaign, 0, len(ids))
+	for _, id := range ids {
+		c, err := r.FindByID(id)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, *c)
+	}
+	return campaigns, nil
+}
+
+// Delete removes the campaign; the parties and encounters it grouped are kept
+func (r *CampaignRepository) Delete(id string) error {
+	return deleteByID(r.db, `DELETE FROM campaigns WHERE id = ?`, id, campaign.ErrCampaignNotFound)
+}
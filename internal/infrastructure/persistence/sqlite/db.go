@@ -0,0 +1,32 @@
+// Package sqlite stores parties, saved encounters and campaigns in a SQLite database.
+// It uses a pure-Go driver so the application builds with CGO_ENABLED=0.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "modernc.org/sqlite"
+)
+
+// Open opens the SQLite database at path, creating it if needed, and applies pending migrations
+func Open(path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?" + url.Values{
+		"_pragma": {"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"},
+	}.Encode()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+
+	// SQLite allows a single writer; one connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	if err := Migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
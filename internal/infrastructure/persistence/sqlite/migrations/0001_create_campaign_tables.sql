@@ -0,0 +1,46 @@
+CREATE TABLE parties (
+    id   TEXT PRIMARY KEY,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE party_members (
+    party_id TEXT    NOT NULL REFERENCES parties (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    name     TEXT    NOT NULL,
+    level    INTEGER NOT NULL CHECK (level BETWEEN 1 AND 20),
+    PRIMARY KEY (party_id, position)
+);
+
+CREATE TABLE saved_encounters (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    ruleset    TEXT NOT NULL,
+    difficulty TEXT NOT NULL
+);
+
+CREATE TABLE saved_encounter_monsters (
+    encounter_id TEXT    NOT NULL REFERENCES saved_encounters (id) ON DELETE CASCADE,
+    position     INTEGER NOT NULL,
+    monster_id   TEXT    NOT NULL,
+    quantity     INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (encounter_id, position)
+);
+
+CREATE TABLE campaigns (
+    id   TEXT PRIMARY KEY,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE campaign_parties (
+    campaign_id TEXT    NOT NULL REFERENCES campaigns (id) ON DELETE CASCADE,
+    party_id    TEXT    NOT NULL REFERENCES parties (id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    PRIMARY KEY (campaign_id, party_id)
+);
+
+CREATE TABLE campaign_encounters (
+    campaign_id  TEXT    NOT NULL REFERENCES campaigns (id) ON DELETE CASCADE,
+    encounter_id TEXT    NOT NULL REFERENCES saved_encounters (id) ON DELETE CASCADE,
+    position     INTEGER NOT NULL,
+    PRIMARY KEY (campaign_id, encounter_id)
+);
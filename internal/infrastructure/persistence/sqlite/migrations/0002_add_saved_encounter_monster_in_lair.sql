@@ -0,0 +1 @@
+ALTER TABLE saved_encounter_monsters ADD COLUMN in_lair INTEGER NOT NULL DEFAULT 0 CHECK (in_lair IN (0, 1));
@@ -0,0 +1,8 @@
+ALTER TABLE saved_encounters ADD COLUMN count_weak_monsters INTEGER NOT NULL DEFAULT 0 CHECK (count_weak_monsters IN (0, 1));
+
+CREATE TABLE saved_encounter_levels (
+    encounter_id TEXT    NOT NULL REFERENCES saved_encounters (id) ON DELETE CASCADE,
+    position     INTEGER NOT NULL,
+    level        INTEGER NOT NULL CHECK (level BETWEEN 1 AND 20),
+    PRIMARY KEY (encounter_id, position)
+);
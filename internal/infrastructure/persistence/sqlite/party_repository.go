@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+)
+
+// PartyRepository implements the campaign.PartyRepository interface on SQLite
+type PartyRepository struct {
+	db *sql.DB
+}
+
+// NewPartyRepository creates a new SQLite party repository
+func NewPartyRepository(db *sql.DB) *PartyRepository {
+	return &PartyRepository{db: db}
+}
+
+// Save inserts or replaces the party and its members
+func (r *PartyRepository) Save(party *campaign.Party) error {
+	if party.ID == "" {
+		return fmt.Errorf("party ID cannot be empty")
+	}
+
+	return inTx(r.db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT INTO parties (id, name) VALUES (?, ?)
+			ON CONFLICT (id) DO UPDATE SET name = excluded.name`, party.ID, party.Name); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM party_members WHERE party_id = ?`, party.ID); err != nil {
+			return err
+		}
+		for i, m := range party.Members {
+			if _, err := tx.Exec(`INSERT INTO party_members (party_id, position, name, level) VALUES (?, ?, ?, ?)`,
+				party.ID, i, m.Name, m.Level); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindByID returns the party with its members
+func (r *PartyRepository) FindByID(id string) (*campaign.Party, error) {
+	party := campaign.Party{ID: id}
+	err := r.db.QueryRow(`SELECT name FROM parties WHERE id = ?`, id).Scan(&party.Name)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", campaign.ErrPartyNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load party %s: %w", id, err)
+	}
+
+	if party.Members, err = r.members(id); err != nil {
+		return nil, err
+	}
+	return &party, nil
+}
+
+// FindAll returns every party ordered by name
+func (r *PartyRepository) FindAll() ([]campaign.Party, error) {
+	ids, err := queryIDs(r.db, `SELECT id FROM parties ORDER BY name, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list parties: %w", err)
+	}
+
+	parties := make([]campaign.Party, 0, len(ids))
+	for _, id := range ids {
+		party, err := r.FindByID(id)
+		if err != nil {
+			return nil, err
+		}
+		parties = append(parties, *party)
+	}
+	return parties, nil
+}
+
+// Delete removes the party; its members and campaign links are removed by cascade
+func (r *PartyRepository) Delete(id string) error {
+	return deleteByID(r.db, `DELETE FROM parties WHERE id = ?`, id, campaign.ErrPartyNotFound)
+}
+
+func (r *PartyRepository) members(partyID string) ([]campaign.Member, error) {
+	rows, err := r.db.Query(`SELECT name, level FROM party_members WHERE party_id = ? ORDER BY position`, partyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load members of party %s: %w", partyID, err)
+	}
+	defer rows.Close()
+
+	var members []campaign.Member
+	for rows.Next() {
+		var m campaign.Member
+		if err := rows.Scan(&m.Name, &m.Level); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
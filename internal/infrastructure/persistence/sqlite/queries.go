@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// inTx runs fn in a transaction, committing only if it succeeds
+func inTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// queryIDs returns the single string column selected by query
+func queryIDs(db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// deleteByID runs a delete statement and returns notFound when no row matched
+func deleteByID(db *sql.DB, query, id string, notFound error) error {
+	result, err := db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", id, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %s", notFound, id)
+	}
+	return nil
+}
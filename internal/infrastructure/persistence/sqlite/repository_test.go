@@ -0,0 +1,195 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+)
+
+func openTestDB(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, path
+}
+
+func TestMigrate(t *testing.T) {
+	db, path := openTestDB(t)
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var version int
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != migrations[len(migrations)-1].version {
+		t.Errorf("expected schema version %d, got %d", migrations[len(migrations)-1].version, version)
+	}
+
+	// Reopening an up-to-date database must not apply migrations again
+	db.Close()
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer reopened.Close()
+
+	var applied int
+	if err := reopened.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if applied != len(migrations) {
+		t.Errorf("expected %d applied migrations, got %d", len(migrations), applied)
+	}
+}
+
+func TestPartyRepository(t *testing.T) {
+	db, _ := openTestDB(t)
+	repo := NewPartyRepository(db)
+
+	party := &campaign.Party{ID: "p1", Name: "La Compagnia", Members: []campaign.Member{{Name: "Aria", Level: 5}, {Name: "Borin", Level: 6}}}
+	if err := repo.Save(party); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(&campaign.Party{ID: "p2", Name: "Avventurieri", Members: []campaign.Member{{Name: "Cora", Level: 1}}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, err := repo.FindByID("p1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(found, party) {
+		t.Errorf("expected %+v, got %+v", party, found)
+	}
+
+	party.Name = "La Compagnia dell'Anello"
+	party.Members = party.Members[:1]
+	if err := repo.Save(party); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found, _ = repo.FindByID("p1")
+	if !reflect.DeepEqual(found, party) {
+		t.Errorf("expected the update to replace the party, got %+v", found)
+	}
+
+	all, err := repo.FindAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != "p2" || all[1].ID != "p1" {
+		t.Errorf("expected parties ordered by name, got %+v", all)
+	}
+
+	if err := repo.Delete("p1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.FindByID("p1"); !errors.Is(err, campaign.ErrPartyNotFound) {
+		t.Errorf("expected ErrPartyNotFound, got %v", err)
+	}
+	if err := repo.Delete("p1"); !errors.Is(err, campaign.ErrPartyNotFound) {
+		t.Errorf("expected ErrPartyNotFound, got %v", err)
+	}
+}
+
+func TestSavedEncounterRepository(t *testing.T) {
+	db, _ := openTestDB(t)
+	repo := NewSavedEncounterRepository(db)
+
+	saved := &campaign.SavedEncounter{
+		ID:         "e1",
+		Name:       "Ogre al ponte",
+		Ruleset:    encounter.Ruleset2014,
+		Difficulty: encounter.DifficultyHard,
+		Levels:     []int{3, 3, 4},
+		Monsters:   []campaign.EncounterMonster{{MonsterID: "ogre", Quantity: 2}, {MonsterID: "aboleth", Quantity: 1, InLair: true}},
+
+		CountWeakMonsters: true,
+	}
+	if err := repo.Save(saved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, err := repo.FindByID("e1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(found, saved) {
+		t.Errorf("expected %+v, got %+v", saved, found)
+	}
+
+	all, err := repo.FindAll()
+	if err != nil || len(all) != 1 {
+		t.Errorf("expected one encounter, got %+v (%v)", all, err)
+	}
+
+	if err := repo.Delete("e1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.FindByID("e1"); !errors.Is(err, campaign.ErrEncounterNotFound) {
+		t.Errorf("expected ErrEncounterNotFound, got %v", err)
+	}
+}
+
+func TestCampaignRepository(t *testing.T) {
+	db, _ := openTestDB(t)
+	parties := NewPartyRepository(db)
+	encounters := NewSavedEncounterRepository(db)
+	repo := NewCampaignRepository(db)
+
+	for _, id := range []string{"p1", "p2"} {
+		if err := parties.Save(&campaign.Party{ID: id, Name: id, Members: []campaign.Member{{Name: "Aria", Level: 3}}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := encounters.Save(&campaign.SavedEncounter{ID: "e1", Name: "e1", Ruleset: encounter.Ruleset2024, Difficulty: encounter.DifficultyLow}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := &campaign.Campaign{ID: "c1", Name: "La Maledizione", PartyIDs: []string{"p2", "p1"}, EncounterIDs: []string{"e1"}}
+	if err := repo.Save(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, err := repo.FindByID("c1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(found, c) {
+		t.Errorf("expected %+v, got %+v", c, found)
+	}
+
+	// Deleting a party removes it from the campaigns that grouped it
+	if err := parties.Delete("p2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found, _ = repo.FindByID("c1")
+	if !reflect.DeepEqual(found.PartyIDs, []string{"p1"}) {
+		t.Errorf("expected [p1] after deleting p2, got %v", found.PartyIDs)
+	}
+
+	if err := repo.Save(&campaign.Campaign{ID: "c2", Name: "Orfana", PartyIDs: []string{"missing"}}); err == nil {
+		t.Error("expected an error linking a missing party")
+	}
+
+	if err := repo.Delete("c1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := parties.FindByID("p1"); err != nil {
+		t.Errorf("expected parties to outlive their campaign, got %v", err)
+	}
+	if _, err := repo.FindByID("c1"); !errors.Is(err, campaign.ErrCampaignNotFound) {
+		t.Errorf("expected ErrCampaignNotFound, got %v", err)
+	}
+}
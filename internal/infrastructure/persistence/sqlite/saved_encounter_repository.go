@@ -0,0 +1,137 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+)
+
+// SavedEncounterRepository implements the campaign.EncounterRepository interface on SQLite
+type SavedEncounterRepository struct {
+	db *sql.DB
+}
+
+// NewSavedEncounterRepository creates a new SQLite saved encounter repository
+func NewSavedEncounterRepository(db *sql.DB) *SavedEncounterRepository {
+	return &SavedEncounterRepository{db: db}
+}
+
+// Save inserts or replaces the encounter with its party levels and monsters
+func (r *SavedEncounterRepository) Save(e *campaign.SavedEncounter) error {
+	if e.ID == "" {
+		return fmt.Errorf("encounter ID cannot be empty")
+	}
+
+	return inTx(r.db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT INTO saved_encounters (id, name, ruleset, difficulty, count_weak_monsters) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET name = excluded.name, ruleset = excluded.ruleset, difficulty = excluded.difficulty,
+				count_weak_monsters = excluded.count_weak_monsters`,
+			e.ID, e.Name, e.Ruleset.String(), e.Difficulty.String(), e.CountWeakMonsters); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM saved_encounter_levels WHERE encounter_id = ?`, e.ID); err != nil {
+			return err
+		}
+		for i, level := range e.Levels {
+			if _, err := tx.Exec(`INSERT INTO saved_encounter_levels (encounter_id, position, level) VALUES (?, ?, ?)`,
+				e.ID, i, level); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`DELETE FROM saved_encounter_monsters WHERE encounter_id = ?`, e.ID); err != nil {
+			return err
+		}
+		for i, m := range e.Monsters {
+			if _, err := tx.Exec(`INSERT INTO saved_encounter_monsters (encounter_id, position, monster_id, quantity, in_lair) VALUES (?, ?, ?, ?, ?)`,
+				e.ID, i, m.MonsterID, m.Quantity, m.InLair); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindByID returns the encounter with its party levels and monsters
+func (r *SavedEncounterRepository) FindByID(id string) (*campaign.SavedEncounter, error) {
+	e := campaign.SavedEncounter{ID: id}
+	var ruleset, difficulty string
+	err := r.db.QueryRow(`SELECT name, ruleset, difficulty, count_weak_monsters FROM saved_encounters WHERE id = ?`, id).
+		Scan(&e.Name, &ruleset, &difficulty, &e.CountWeakMonsters)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", campaign.ErrEncounterNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encounter %s: %w", id, err)
+	}
+	e.Ruleset = encounter.Ruleset(ruleset)
+	e.Difficulty = encounter.Difficulty(difficulty)
+
+	if e.Levels, err = r.levels(id); err != nil {
+		return nil, err
+	}
+	if e.Monsters, err = r.monsters(id); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// FindAll returns every encounter ordered by name
+func (r *SavedEncounterRepository) FindAll() ([]campaign.SavedEncounter, error) {
+	ids, err := queryIDs(r.db, `SELECT id FROM saved_encounters ORDER BY name, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list encounters: %w", err)
+	}
+
+	encounters := make([]campaign.SavedEncounter, 0, len(ids))
+	for _, id := range ids {
+		e, err := r.FindByID(id)
+		if err != nil {
+			return nil, err
+		}
+		encounters = append(encounters, *e)
+	}
+	return encounters, nil
+}
+
+// Delete removes the encounter; its monsters and campaign links are removed by cascade
+func (r *SavedEncounterRepository) Delete(id string) error {
+	return deleteByID(r.db, `DELETE FROM saved_encounters WHERE id = ?`, id, campaign.ErrEncounterNotFound)
+}
+
+func (r *SavedEncounterRepository) levels(encounterID string) ([]int, error) {
+	rows, err := r.db.Query(`SELECT level FROM saved_encounter_levels WHERE encounter_id = ? ORDER BY position`, encounterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load party of encounter %s: %w", encounterID, err)
+	}
+	defer rows.Close()
+
+	var levels []int
+	for rows.Next() {
+		var level int
+		if err := rows.Scan(&level); err != nil {
+			return nil, err
+		}
+		levels = append(levels, level)
+	}
+	return levels, rows.Err()
+}
+
+func (r *SavedEncounterRepository) monsters(encounterID string) ([]campaign.EncounterMonster, error) {
+	rows, err := r.db.Query(`SELECT monster_id, quantity, in_lair FROM saved_encounter_monsters WHERE encounter_id = ? ORDER BY position`, encounterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load monsters of encounter %s: %w", encounterID, err)
+	}
+	defer rows.Close()
+
+	var monsters []campaign.EncounterMonster
+	for rows.Next() {
+		var m campaign.EncounterMonster
+		if err := rows.Scan(&m.MonsterID, &m.Quantity, &m.InLair); err != nil {
+			return nil, err
+		}
+		monsters = append(monsters, m)
+	}
+	return monsters, rows.Err()
+}
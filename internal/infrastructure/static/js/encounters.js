@@ -40,6 +40,7 @@ document.addEventListener('DOMContentLoaded', function() {
     document.addEventListener('htmx:beforeRequest', function(evt) {
         const target = evt.target;
         if (target.classList.contains('btn')) {
+            target.dataset.originalText = target.textContent;
             target.style.opacity = '0.7';
             target.style.pointerEvents = 'none';
             target.textContent = 'Caricamento...';
@@ -51,12 +52,20 @@ document.addEventListener('DOMContentLoaded', function() {
         if (target.classList.contains('btn')) {
             target.style.opacity = '1';
             target.style.pointerEvents = 'auto';
+            if (target.dataset.originalText !== undefined) {
+                target.textContent = target.dataset.originalText;
+                delete target.dataset.originalText;
+            }
         }
     });
 
     // Handle errors gracefully
     document.addEventListener('htmx:responseError', function(evt) {
         console.error('HTMX Request failed:', evt.detail);
+        if (evt.detail.xhr && evt.detail.xhr.status === 422) {
+            showNotification('Nessun incontro trovato nel budget. Allarga i filtri o la tolleranza.', 'error');
+            return;
+        }
         showNotification('Errore nel caricamento. Riprova.', 'error');
     });
 });
@@ -128,51 +137,6 @@ window.encountersUtils = {
 // Make showNotification available globally
 window.showNotification = window.encountersUtils.showNotification;
 
-// Monster selection tracking
-window.selectedMonsters = [];
-
-function addMonster(btn) {
-    const row = btn.closest('.monster-row');
-    const name = row.dataset.name;
-    const xp = parseInt(row.dataset.xp, 10);
-    const id = row.dataset.id;
-
-    window.selectedMonsters.push({ id, name, xp });
-    updateSelectedMonstersUI();
-}
-
-function removeMonster(index) {
-    window.selectedMonsters.splice(index, 1);
-    updateSelectedMonstersUI();
-}
-
-function updateSelectedMonstersUI() {
-    const list = document.getElementById('selected-monsters-list');
-    const countEl = document.getElementById('selected-count');
-    const usedEl = document.getElementById('xp-used');
-    const remainingEl = document.getElementById('xp-remaining');
-
-    if (!list) return;
-
-    const totalUsed = window.selectedMonsters.reduce((sum, m) => sum + m.xp, 0);
-    const maxXPInput = document.querySelector('input[name="max_xp"]');
-    const budget = maxXPInput ? parseInt(maxXPInput.value, 10) : 0;
-
-    countEl.textContent = window.selectedMonsters.length;
-    usedEl.textContent = window.encountersUtils.formatXP(totalUsed);
-
-    const remaining = budget - totalUsed;
-    remainingEl.innerHTML = 'Rimanenti: <strong>' + window.encountersUtils.formatXP(remaining) + '</strong>';
-    remainingEl.classList.toggle('over-budget', remaining < 0);
-
-    list.innerHTML = window.selectedMonsters.map((m, i) =>
-        '<div class="selected-monster-item">' +
-            '<span>' + m.name + ' (PE ' + window.encountersUtils.formatXP(m.xp) + ')</span>' +
-            '<button type="button" onclick="removeMonster(' + i + ')">✕</button>' +
-        '</div>'
-    ).join('');
-}
-
 // Monster row accordion expand/collapse
 document.addEventListener('click', function(evt) {
     // Don't toggle when clicking the + button
@@ -199,9 +163,66 @@ document.addEventListener('click', function(evt) {
     }
 });
 
-// Reset selected monsters when a new calculation is made
+
+// Monster name autocomplete
+function closeMonsterSuggestions() {
+    var list = document.getElementById('monster-suggestions');
+    if (!list) return;
+    list.innerHTML = '';
+    document.getElementById('monster-search-input').setAttribute('aria-expanded', 'false');
+}
+
 document.addEventListener('htmx:afterSwap', function(evt) {
-    if (evt.detail.target && evt.detail.target.id === 'result-container') {
-        window.selectedMonsters = [];
+    if (evt.target.id !== 'monster-suggestions') return;
+    var expanded = evt.target.children.length > 0;
+    document.getElementById('monster-search-input').setAttribute('aria-expanded', expanded ? 'true' : 'false');
+});
+
+document.addEventListener('click', function(evt) {
+    var suggestion = evt.target.closest('.monster-suggestion');
+    if (!suggestion) {
+        if (!evt.target.closest('.monster-search-bar')) closeMonsterSuggestions();
+        return;
     }
-});
\ No newline at end of file
+    var input = document.getElementById('monster-search-input');
+    input.value = suggestion.dataset.name;
+    closeMonsterSuggestions();
+    input.focus();
+    htmx.trigger(input, 'search');
+});
+
+document.addEventListener('keydown', function(evt) {
+    var list = document.getElementById('monster-suggestions');
+    if (!list || !evt.target.closest('.monster-search-bar')) return;
+
+    if (evt.key === 'Escape') {
+        closeMonsterSuggestions();
+        document.getElementById('monster-search-input').focus();
+        return;
+    }
+    if (evt.key !== 'ArrowDown' && evt.key !== 'ArrowUp') return;
+
+    var options = Array.from(list.querySelectorAll('.monster-suggestion'));
+    if (options.length === 0) return;
+    evt.preventDefault();
+    var i = options.indexOf(document.activeElement);
+    if (evt.key === 'ArrowDown') {
+        options[Math.min(i + 1, options.length - 1)].focus();
+    } else if (i > 0) {
+        options[i - 1].focus();
+    } else {
+        document.getElementById('monster-search-input').focus();
+    }
+});
+
+// Combat tracker: N and P move to the next and previous turn
+document.addEventListener('keydown', function(evt) {
+    if (evt.ctrlKey || evt.metaKey || evt.altKey) return;
+    if (evt.target.closest('input, textarea, select')) return;
+
+    var id = { n: 'combat-next', p: 'combat-previous' }[evt.key.toLowerCase()];
+    var button = id && document.getElementById(id);
+    if (!button) return;
+    evt.preventDefault();
+    button.click();
+});
@@ -0,0 +1,489 @@
+package handlers
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/encounter"
+	monsterApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/dice"
+	encounterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	monsterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/export/foundry"
+)
+
+// openAPISpec is the OpenAPI 3 description of the /api/v1 endpoints
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// Error codes of the /api/v1 error body
+const (
+	apiErrorInvalidRequest   = "invalid_request"
+	apiErrorNotFound         = "not_found"
+	apiErrorMethodNotAllowed = "method_not_allowed"
+	apiErrorInternal         = "internal_error"
+)
+
+const (
+	maxRollCount = 100
+	maxRollSeed  = 1 << 53 // seeds stay exact as JSON numbers
+)
+
+// APIHandler serves the versioned JSON API under /api/v1
+type APIHandler struct {
+	service        *encounter.Service
+	queryHandler   *encounter.QueryHandler
+	monsterService *monsterApp.Service
+	logger         *slog.Logger
+}
+
+// NewAPIHandler creates a new JSON API handler
+func NewAPIHandler(service *encounter.Service, queryHandler *encounter.QueryHandler, monsterService *monsterApp.Service, logger *slog.Logger) *APIHandler {
+	return &APIHandler{
+		service:        service,
+		queryHandler:   queryHandler,
+		monsterService: monsterService,
+		logger:         logger,
+	}
+}
+
+// Routes returns the /api/v1 router, answering unknown routes with JSON errors
+func (h *APIHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		h.writeError(w, r, http.StatusNotFound, apiErrorNotFound, "no such endpoint: "+r.URL.Path)
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		h.writeError(w, r, http.StatusMethodNotAllowed, apiErrorMethodNotAllowed, r.Method+" is not allowed on "+r.URL.Path)
+	})
+
+	r.Get("/openapi.json", h.OpenAPIHandler)
+	r.Post("/encounters/calculate", h.CalculateHandler)
+	r.Post("/encounters/foundry", h.EncounterFoundryHandler)
+	r.Get("/thresholds", h.ThresholdsHandler)
+	r.Post("/roll", h.RollHandler)
+	r.Get("/monsters", h.SearchMonstersHandler)
+	r.Get("/monsters/facets", h.FacetsHandler)
+	r.Get("/monsters/{monsterID}", h.GetMonsterHandler)
+	r.Get("/monsters/{monsterID}/foundry", h.MonsterFoundryHandler)
+
+	return r
+}
+
+// apiError is the body of every /api/v1 error response
+type apiError struct {
+	Error apiErrorDetail `json:"error"`
+}
+
+type apiErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// apiMonsterQuantity is a monster group of a calculation request
+type apiMonsterQuantity struct {
+	ID       string `json:"id"`
+	Quantity int    `json:"quantity"`
+	InLair   bool   `json:"in_lair"`
+}
+
+// apiCalculateRequest mirrors encounter.CalculateXPRequest with monsters referenced by ID
+type apiCalculateRequest struct {
+	Ruleset           string               `json:"ruleset"`
+	PartyMode         string               `json:"party_mode"`
+	Difficulty        string               `json:"difficulty"`
+	CharacterLevels   []int                `json:"character_levels"`
+	Monsters          []apiMonsterQuantity `json:"monsters"`
+	CountWeakMonsters bool                 `json:"count_weak_monsters"`
+}
+
+// apiFoundryRequest lists the monsters of an encounter to export
+type apiFoundryRequest struct {
+	Monsters []apiMonsterQuantity `json:"monsters"`
+}
+
+// apiRollRequest is a dice expression to roll count times; seed 0 picks a random seed
+type apiRollRequest struct {
+	Expression string `json:"expression"`
+	Count      int    `json:"count"`
+	Seed       int64  `json:"seed"`
+}
+
+type apiRollResponse struct {
+	Expression string    `json:"expression"`
+	Seed       int64     `json:"seed"`
+	Average    float64   `json:"average"`
+	Min        int       `json:"min"`
+	Max        int       `json:"max"`
+	Rolls      []apiRoll `json:"rolls"`
+}
+
+type apiRoll struct {
+	Total int           `json:"total"`
+	Terms []apiTermRoll `json:"terms"`
+}
+
+// apiTermRoll is one term of a roll; dice and kept are empty for constants
+type apiTermRoll struct {
+	Term  string `json:"term"`
+	Dice  []int  `json:"dice"`
+	Kept  []bool `json:"kept"`
+	Value int    `json:"value"`
+}
+
+type apiThreshold struct {
+	Difficulty string `json:"difficulty"`
+	XP         int    `json:"xp"`
+}
+
+// apiAdjustedXP is the 2014 evaluation of the monsters of a calculation request
+type apiAdjustedXP struct {
+	RawXP            int                               `json:"raw_xp"`
+	MonsterCount     int                               `json:"monster_count"`
+	BaseMultiplier   float64                           `json:"base_multiplier"`
+	MultiplierShift  int                               `json:"multiplier_shift"`
+	Multiplier       float64                           `json:"multiplier"`
+	AdjustedXP       int                               `json:"adjusted_xp"`
+	Difficulty       string                            `json:"difficulty"`
+	Thresholds       []apiThreshold                    `json:"thresholds"`
+	ExcludedMonsters []encounterDomain.ExcludedMonster `json:"excluded_monsters"`
+}
+
+type apiCalculateResponse struct {
+	Ruleset         string         `json:"ruleset"`
+	Difficulty      string         `json:"difficulty"`
+	TotalXP         int            `json:"total_xp"`
+	PartySize       int            `json:"party_size"`
+	CharacterLevels []int          `json:"character_levels"`
+	Adjusted2014    *apiAdjustedXP `json:"adjusted_2014,omitempty"`
+}
+
+type apiMonsterList struct {
+	Count    int                         `json:"count"`
+	Monsters []monsterApp.MonsterSummary `json:"monsters"`
+}
+
+type apiFacets struct {
+	Types []string `json:"types"`
+	Sizes []string `json:"sizes"`
+	CRs   []string `json:"crs"`
+}
+
+// OpenAPIHandler serves the OpenAPI document of the API.
+// GET /api/v1/openapi.json
+func (h *APIHandler) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(openAPISpec); err != nil {
+		h.logger.Error("Failed to write OpenAPI document", "request_id", middleware.GetReqID(r.Context()), "error", err)
+	}
+}
+
+// CalculateHandler calculates the XP budget of an encounter and, for 2014 rules, evaluates its monsters.
+// POST /api/v1/encounters/calculate
+func (h *APIHandler) CalculateHandler(w http.ResponseWriter, r *http.Request) {
+	var body apiCalculateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+
+	if body.PartyMode != "" {
+		if _, err := encounterDomain.NewPartyMode(body.PartyMode); err != nil {
+			h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	req := encounter.CalculateXPRequest{
+		Ruleset:           body.Ruleset,
+		PartyMode:         body.PartyMode,
+		Difficulty:        body.Difficulty,
+		CharacterLevels:   body.CharacterLevels,
+		CountWeakMonsters: body.CountWeakMonsters,
+	}
+	groups, ok := h.monsterGroups(w, r, body.Monsters)
+	if !ok {
+		return
+	}
+	req.Monsters = groups
+
+	result, err := h.service.CalculateXP(req)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+
+	response := apiCalculateResponse{
+		Ruleset:         result.Ruleset.String(),
+		Difficulty:      body.Difficulty,
+		TotalXP:         result.TotalXP,
+		PartySize:       result.PartySize,
+		CharacterLevels: result.CharacterLevels,
+	}
+	if a := result.Adjusted2014; a != nil {
+		response.Adjusted2014 = &apiAdjustedXP{
+			RawXP:            a.RawXP,
+			MonsterCount:     a.MonsterCount,
+			BaseMultiplier:   a.MultiplierStep.BaseMultiplier,
+			MultiplierShift:  a.MultiplierStep.Shift,
+			Multiplier:       a.Multiplier,
+			AdjustedXP:       a.AdjustedXP,
+			Difficulty:       a.Difficulty.String(),
+			Thresholds:       make([]apiThreshold, len(a.Thresholds)),
+			ExcludedMonsters: a.ExcludedMonsters,
+		}
+		for i, t := range a.Thresholds {
+			response.Adjusted2014.Thresholds[i] = apiThreshold{Difficulty: t.Difficulty.String(), XP: t.XP}
+		}
+		if response.Adjusted2014.ExcludedMonsters == nil {
+			response.Adjusted2014.ExcludedMonsters = []encounterDomain.ExcludedMonster{}
+		}
+	}
+
+	h.writeJSON(w, r, response)
+}
+
+// EncounterFoundryHandler exports the monsters of an encounter as a zip of Foundry VTT actors.
+// POST /api/v1/encounters/foundry
+func (h *APIHandler) EncounterFoundryHandler(w http.ResponseWriter, r *http.Request) {
+	var body apiFoundryRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	if len(body.Monsters) == 0 {
+		h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, "monsters must not be empty")
+		return
+	}
+
+	groups, ok := h.monsterGroups(w, r, body.Monsters)
+	if !ok {
+		return
+	}
+
+	var archive bytes.Buffer
+	if err := foundry.WriteEncounter(&archive, groups); err != nil {
+		h.writeInternalError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="incontro.zip"`)
+	if _, err := archive.WriteTo(w); err != nil {
+		h.logger.Error("Failed to write Foundry archive", "request_id", middleware.GetReqID(r.Context()), "error", err)
+	}
+}
+
+// ThresholdsHandler returns the XP thresholds of every level for a ruleset.
+// GET /api/v1/thresholds?ruleset=R
+func (h *APIHandler) ThresholdsHandler(w http.ResponseWriter, r *http.Request) {
+	table, err := h.queryHandler.GetThresholdTable(r.URL.Query().Get("ruleset"))
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writeJSON(w, r, table)
+}
+
+// RollHandler rolls a dice expression, reporting every die along with the average, min and max.
+// POST /api/v1/roll
+func (h *APIHandler) RollHandler(w http.ResponseWriter, r *http.Request) {
+	var body apiRollRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+
+	expression, err := dice.Parse(body.Expression)
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, err.Error())
+		return
+	}
+	if body.Count == 0 {
+		body.Count = 1
+	}
+	if body.Count < 1 || body.Count > maxRollCount {
+		h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, fmt.Sprintf("count must be between 1 and %d", maxRollCount))
+		return
+	}
+	if body.Seed < 0 || body.Seed >= maxRollSeed {
+		h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, fmt.Sprintf("seed must be between 0 and %d", int64(maxRollSeed-1)))
+		return
+	}
+	if body.Seed == 0 {
+		body.Seed = rand.Int64N(maxRollSeed-1) + 1
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(body.Seed), 0))
+	response := apiRollResponse{
+		Expression: expression.String(),
+		Seed:       body.Seed,
+		Average:    expression.Average(),
+		Min:        expression.Min(),
+		Max:        expression.Max(),
+		Rolls:      make([]apiRoll, body.Count),
+	}
+	for i := range response.Rolls {
+		roll := expression.Roll(rng)
+		response.Rolls[i] = apiRoll{Total: roll.Total, Terms: make([]apiTermRoll, len(roll.Terms))}
+		for j, t := range roll.Terms {
+			term := t.Term.String()
+			if t.Term.Negative {
+				term = "−" + term
+			}
+			response.Rolls[i].Terms[j] = apiTermRoll{Term: term, Dice: t.Dice, Kept: t.Kept, Value: t.Value}
+			if t.Dice == nil {
+				response.Rolls[i].Terms[j].Dice, response.Rolls[i].Terms[j].Kept = []int{}, []bool{}
+			}
+		}
+	}
+
+	h.writeJSON(w, r, response)
+}
+
+// SearchMonstersHandler returns the monsters matching every search filter.
+// GET /api/v1/monsters?q=Q&max_xp=N&type=T&size=S&cr_min=X&cr_max=Y&fly=B&swim_min=M&blindsight=B&pp_max=N&resist=D&no_resist=D&immune=D&no_immune=D&condition_immune=C&no_condition_immune=C&target_ac=N&target_save=N&dpr_min=N&dpr_max=N&sort=O
+func (h *APIHandler) SearchMonstersHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	maxXP := 1_000_000
+	if v := query.Get("max_xp"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, "max_xp must be a non-negative integer")
+			return
+		}
+		maxXP = parsed
+	}
+
+	filters := monsterDomain.SearchFilters{
+		Query: query.Get("q"),
+		MaxXP: maxXP,
+		Type:  query.Get("type"),
+		Size:  query.Get("size"),
+		CRMin: query.Get("cr_min"),
+		CRMax: query.Get("cr_max"),
+	}
+	if err := parseStatblockFilters(query, &filters); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, err.Error())
+		return
+	}
+
+	results := h.monsterService.SearchMonstersRanked(filters)
+
+	response := apiMonsterList{Count: len(results), Monsters: make([]monsterApp.MonsterSummary, len(results))}
+	for i, found := range results {
+		response.Monsters[i] = monsterApp.NewSearchSummary(found)
+	}
+	h.writeJSON(w, r, response)
+}
+
+// GetMonsterHandler returns the full statblock of a monster.
+// GET /api/v1/monsters/{monsterID}
+func (h *APIHandler) GetMonsterHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "monsterID")
+	m, ok := h.monsterService.GetMonster(id)
+	if !ok {
+		h.writeError(w, r, http.StatusNotFound, apiErrorNotFound, fmt.Sprintf("%v: %s", monsterDomain.ErrNotFound, id))
+		return
+	}
+	h.writeJSON(w, r, monsterApp.NewMonsterDetail(m))
+}
+
+// MonsterFoundryHandler returns a monster as a Foundry VTT dnd5e actor.
+// GET /api/v1/monsters/{monsterID}/foundry
+func (h *APIHandler) MonsterFoundryHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "monsterID")
+	m, ok := h.monsterService.GetMonster(id)
+	if !ok {
+		h.writeError(w, r, http.StatusNotFound, apiErrorNotFound, fmt.Sprintf("%v: %s", monsterDomain.ErrNotFound, id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := foundry.Encode(w, foundry.NewActor(m)); err != nil {
+		h.logger.Error("Failed to encode Foundry actor", "request_id", middleware.GetReqID(r.Context()), "error", err)
+	}
+}
+
+// FacetsHandler returns the values available to the monster search filters.
+// GET /api/v1/monsters/facets
+func (h *APIHandler) FacetsHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, r, apiFacets{
+		Types: h.monsterService.AvailableTypes(),
+		Sizes: h.monsterService.AvailableSizes(),
+		CRs:   h.monsterService.AvailableCRs(),
+	})
+}
+
+// monsterGroups resolves the monsters of a request, writing the error response when one is unknown,
+// has an invalid quantity or is placed in a lair it does not have
+func (h *APIHandler) monsterGroups(w http.ResponseWriter, r *http.Request, monsters []apiMonsterQuantity) ([]encounterDomain.MonsterGroup, bool) {
+	var groups []encounterDomain.MonsterGroup
+	for _, m := range monsters {
+		found, ok := h.monsterService.GetMonster(m.ID)
+		if !ok {
+			h.writeError(w, r, http.StatusNotFound, apiErrorNotFound, fmt.Sprintf("%v: %s", monsterDomain.ErrNotFound, m.ID))
+			return nil, false
+		}
+		if m.Quantity < 1 || m.Quantity > encounterDomain.MaxGroupQuantity {
+			h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, fmt.Sprintf("quantity of %s must be between 1 and %d", m.ID, encounterDomain.MaxGroupQuantity))
+			return nil, false
+		}
+		if m.InLair && !found.HasLair() {
+			h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, fmt.Sprintf("%v: %s", encounterDomain.ErrNoLairVariant, m.ID))
+			return nil, false
+		}
+		groups = append(groups, encounterDomain.MonsterGroup{Monster: found, Quantity: m.Quantity, InLair: m.InLair})
+	}
+	return groups, true
+}
+
+func (h *APIHandler) writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("Failed to encode API response", "request_id", middleware.GetReqID(r.Context()), "error", err)
+	}
+}
+
+// writeServiceError answers an encounter service error: invalid requests with their message,
+// anything else as an internal error
+func (h *APIHandler) writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, encounter.ErrInvalidRequest) {
+		h.writeError(w, r, http.StatusBadRequest, apiErrorInvalidRequest, err.Error())
+		return
+	}
+	h.writeInternalError(w, r, err)
+}
+
+// writeInternalError logs err and answers with a generic message, keeping server failures out of the response
+func (h *APIHandler) writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.Error("API request failed", "request_id", middleware.GetReqID(r.Context()), "status", http.StatusInternalServerError, "error", err)
+	h.writeErrorBody(w, r, http.StatusInternalServerError, apiErrorInternal, "internal server error")
+}
+
+// writeError writes the JSON error body shared by every /api/v1 endpoint
+func (h *APIHandler) writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	h.logger.Error("API request failed", "request_id", middleware.GetReqID(r.Context()), "status", status, "error", message)
+	h.writeErrorBody(w, r, status, code, message)
+}
+
+func (h *APIHandler) writeErrorBody(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(apiError{Error: apiErrorDetail{Code: code, Message: message}}); err != nil {
+		h.logger.Error("Failed to encode API error", "request_id", middleware.GetReqID(r.Context()), "error", err)
+	}
+}
@@ -0,0 +1,535 @@
+package handlers
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/encounter"
+	monsterApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/monster"
+	encounterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/persistence/memory"
+)
+
+func newTestAPI(t *testing.T) http.Handler {
+	t.Helper()
+	return newTestAPIWith(t, memory.NewEncounterRepository())
+}
+
+func newTestAPIWith(t *testing.T, repo encounterDomain.Repository) http.Handler {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+	monsters := monsterApp.NewService(memory.NewMonsterRepository())
+	return NewAPIHandler(encounter.NewService(logger, repo), encounter.NewQueryHandler(logger, repo), monsters, logger).Routes()
+}
+
+// brokenEncounterRepository fails every threshold lookup, like a storage the server cannot read
+type brokenEncounterRepository struct {
+	encounterDomain.Repository
+}
+
+func (brokenEncounterRepository) GetXPFor2024(int, encounterDomain.Difficulty) (int, error) {
+	return 0, errors.New("threshold table is corrupted")
+}
+
+// openAPIDocument is the subset of OpenAPI 3 the tests check responses against
+type openAPIDocument struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas   map[string]*jsonSchema     `json:"schemas"`
+		Responses map[string]openAPIResponse `json:"responses"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	Responses map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIResponse struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema *jsonSchema `json:"schema"`
+	} `json:"content"`
+}
+
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Items                *jsonSchema            `json:"items"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties"`
+	AllOf                []*jsonSchema          `json:"allOf"`
+	Enum                 []any                  `json:"enum"`
+}
+
+func loadOpenAPI(t *testing.T) openAPIDocument {
+	t.Helper()
+	var doc openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("invalid OpenAPI document: %v", err)
+	}
+	return doc
+}
+
+// response returns the response documented for an operation and status
+func (d openAPIDocument) response(method, path string, status int) (openAPIResponse, error) {
+	op, ok := d.Paths[path][strings.ToLower(method)]
+	if !ok {
+		return openAPIResponse{}, fmt.Errorf("%s %s is not documented", method, path)
+	}
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return openAPIResponse{}, fmt.Errorf("%s %s does not document status %d", method, path, status)
+	}
+	if response.Ref != "" {
+		response = d.Components.Responses[strings.TrimPrefix(response.Ref, "#/components/responses/")]
+	}
+	return response, nil
+}
+
+// responseSchema returns the JSON schema documented for an operation and status
+func (d openAPIDocument) responseSchema(method, path string, status int) (*jsonSchema, error) {
+	response, err := d.response(method, path, status)
+	if err != nil {
+		return nil, err
+	}
+	content, ok := response.Content["application/json"]
+	if !ok || content.Schema == nil {
+		return nil, fmt.Errorf("%s %s %d has no JSON schema", method, path, status)
+	}
+	return content.Schema, nil
+}
+
+// validate checks value against schema, returning every mismatch with its JSON path
+func (d openAPIDocument) validate(schema *jsonSchema, value any, at string) []string {
+	if schema.Ref != "" {
+		return d.validate(d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")], value, at)
+	}
+
+	if len(schema.AllOf) > 0 {
+		return d.validate(d.merge(schema.AllOf), value, at)
+	}
+
+	var problems []string
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, value) {
+		problems = append(problems, fmt.Sprintf("%s: %v is not one of %v", at, value, schema.Enum))
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: expected object, got %T", at, value))
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required %q", at, name))
+			}
+		}
+		for name, v := range obj {
+			if s, ok := schema.Properties[name]; ok {
+				problems = append(problems, d.validate(s, v, at+"."+name)...)
+			} else if schema.AdditionalProperties != nil {
+				problems = append(problems, d.validate(schema.AdditionalProperties, v, at+"."+name)...)
+			} else if len(schema.Properties) > 0 {
+				problems = append(problems, fmt.Sprintf("%s: undocumented property %q", at, name))
+			}
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: expected array, got %T", at, value))
+		}
+		for i, v := range arr {
+			problems = append(problems, d.validate(schema.Items, v, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			problems = append(problems, fmt.Sprintf("%s: expected string, got %T", at, value))
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			problems = append(problems, fmt.Sprintf("%s: expected integer, got %v", at, value))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			problems = append(problems, fmt.Sprintf("%s: expected number, got %T", at, value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s: expected boolean, got %T", at, value))
+		}
+	}
+	return problems
+}
+
+// merge combines the object schemas of an allOf into one, so properties of every part are documented
+func (d openAPIDocument) merge(parts []*jsonSchema) *jsonSchema {
+	merged := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}}
+	for _, part := range parts {
+		if part.Ref != "" {
+			part = d.Components.Schemas[strings.TrimPrefix(part.Ref, "#/components/schemas/")]
+		}
+		merged.Required = append(merged.Required, part.Required...)
+		for name, s := range part.Properties {
+			merged.Properties[name] = s
+		}
+	}
+	return merged
+}
+
+func TestAPIHandler_MatchesOpenAPI(t *testing.T) {
+	api := newTestAPI(t)
+	doc := loadOpenAPI(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string // documented path template
+		url    string
+		body   string
+		status int
+		binary string // content type of a non-JSON response
+	}{
+		{name: "openapi document", method: http.MethodGet, path: "/openapi.json", url: "/openapi.json", status: http.StatusOK},
+		{
+			name: "calculate 2024", method: http.MethodPost, path: "/encounters/calculate", url: "/encounters/calculate",
+			body:   `{"ruleset":"2024","party_mode":"same","difficulty":"Moderate","character_levels":[5,5,5,5]}`,
+			status: http.StatusOK,
+		},
+		{
+			name: "calculate 2014 with monsters", method: http.MethodPost, path: "/encounters/calculate", url: "/encounters/calculate",
+			body:   `{"ruleset":"2014","difficulty":"Difficile","character_levels":[3,3,3],"monsters":[{"id":"ogre","quantity":1},{"id":"goblin-guerriero","quantity":3}]}`,
+			status: http.StatusOK,
+		},
+		{
+			name: "calculate with invalid difficulty", method: http.MethodPost, path: "/encounters/calculate", url: "/encounters/calculate",
+			body:   `{"ruleset":"2024","difficulty":"Letale","character_levels":[5]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "calculate with malformed JSON", method: http.MethodPost, path: "/encounters/calculate", url: "/encounters/calculate",
+			body:   `{"ruleset":`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "calculate 2014 with a monster in its lair", method: http.MethodPost, path: "/encounters/calculate", url: "/encounters/calculate",
+			body:   `{"ruleset":"2014","difficulty":"Difficile","character_levels":[10,10,10,10],"monsters":[{"id":"aboleth","quantity":1,"in_lair":true}]}`,
+			status: http.StatusOK,
+		},
+		{
+			name: "calculate with a lair the monster does not have", method: http.MethodPost, path: "/encounters/calculate", url: "/encounters/calculate",
+			body:   `{"ruleset":"2024","difficulty":"High","character_levels":[10],"monsters":[{"id":"ogre","quantity":1,"in_lair":true}]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "calculate with unknown monster", method: http.MethodPost, path: "/encounters/calculate", url: "/encounters/calculate",
+			body:   `{"ruleset":"2014","difficulty":"Media","character_levels":[3],"monsters":[{"id":"not-a-monster","quantity":1}]}`,
+			status: http.StatusNotFound,
+		},
+		{name: "thresholds 2024", method: http.MethodGet, path: "/thresholds", url: "/thresholds?ruleset=2024", status: http.StatusOK},
+		{name: "thresholds 2014", method: http.MethodGet, path: "/thresholds", url: "/thresholds?ruleset=2014", status: http.StatusOK},
+		{name: "thresholds without ruleset", method: http.MethodGet, path: "/thresholds", url: "/thresholds", status: http.StatusBadRequest},
+		{
+			name: "roll", method: http.MethodPost, path: "/roll", url: "/roll",
+			body:   `{"expression":"4d6kh3 - 1d4 + 2","count":3,"seed":42}`,
+			status: http.StatusOK,
+		},
+		{
+			name: "roll with invalid expression", method: http.MethodPost, path: "/roll", url: "/roll",
+			body:   `{"expression":"4d6kh5"}`,
+			status: http.StatusBadRequest,
+		},
+		{name: "search with every filter", method: http.MethodGet, path: "/monsters", url: "/monsters?q=o&max_xp=2000&type=Gigante&size=Grande&cr_min=1&cr_max=5", status: http.StatusOK},
+		{name: "search everything", method: http.MethodGet, path: "/monsters", url: "/monsters", status: http.StatusOK},
+		{name: "search with invalid max_xp", method: http.MethodGet, path: "/monsters", url: "/monsters?max_xp=many", status: http.StatusBadRequest},
+		{name: "search by movement and senses", method: http.MethodGet, path: "/monsters", url: "/monsters?fly=true&swim_min=9&blindsight=true&pp_max=20", status: http.StatusOK},
+		{name: "search with invalid swim_min", method: http.MethodGet, path: "/monsters", url: "/monsters?swim_min=fast", status: http.StatusBadRequest},
+		{name: "search by defenses", method: http.MethodGet, path: "/monsters", url: "/monsters?no_immune=fire,poison&resist=cold&no_condition_immune=charmed", status: http.StatusOK},
+		{name: "search with unknown damage type", method: http.MethodGet, path: "/monsters", url: "/monsters?no_immune=sonic", status: http.StatusBadRequest},
+		{name: "search by damage per round", method: http.MethodGet, path: "/monsters", url: "/monsters?cr_min=3&cr_max=3&target_ac=13&target_save=2&dpr_min=10&sort=dpr_desc", status: http.StatusOK},
+		{name: "search with invalid target_ac", method: http.MethodGet, path: "/monsters", url: "/monsters?target_ac=0", status: http.StatusBadRequest},
+		{name: "search with unknown sort", method: http.MethodGet, path: "/monsters", url: "/monsters?sort=name", status: http.StatusBadRequest},
+		{name: "facets", method: http.MethodGet, path: "/monsters/facets", url: "/monsters/facets", status: http.StatusOK},
+		{name: "monster", method: http.MethodGet, path: "/monsters/{monsterID}", url: "/monsters/aboleth", status: http.StatusOK},
+		{name: "unknown monster", method: http.MethodGet, path: "/monsters/{monsterID}", url: "/monsters/not-a-monster", status: http.StatusNotFound},
+		{name: "foundry actor", method: http.MethodGet, path: "/monsters/{monsterID}/foundry", url: "/monsters/aboleth/foundry", status: http.StatusOK},
+		{name: "foundry actor of unknown monster", method: http.MethodGet, path: "/monsters/{monsterID}/foundry", url: "/monsters/not-a-monster/foundry", status: http.StatusNotFound},
+		{
+			name: "foundry encounter", method: http.MethodPost, path: "/encounters/foundry", url: "/encounters/foundry",
+			body:   `{"monsters":[{"id":"ogre","quantity":1},{"id":"goblin-guerriero","quantity":3}]}`,
+			status: http.StatusOK, binary: "application/zip",
+		},
+		{
+			name: "foundry encounter without monsters", method: http.MethodPost, path: "/encounters/foundry", url: "/encounters/foundry",
+			body:   `{"monsters":[]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "foundry encounter with unknown monster", method: http.MethodPost, path: "/encounters/foundry", url: "/encounters/foundry",
+			body:   `{"monsters":[{"id":"not-a-monster","quantity":1}]}`,
+			status: http.StatusNotFound,
+		},
+	}
+
+	covered := map[string]bool{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			covered[tt.method+" "+tt.path] = true
+
+			if tt.binary != "" {
+				if ct := rec.Header().Get("Content-Type"); ct != tt.binary {
+					t.Errorf("expected a %s response, got %q", tt.binary, ct)
+				}
+				response, err := doc.response(tt.method, tt.path, tt.status)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, ok := response.Content[tt.binary]; !ok {
+					t.Errorf("%s %s %d does not document %s", tt.method, tt.path, tt.status, tt.binary)
+				}
+				return
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				t.Errorf("expected a JSON response, got %q", ct)
+			}
+
+			schema, err := doc.responseSchema(tt.method, tt.path, tt.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var body any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON body: %v", err)
+			}
+			for _, problem := range doc.validate(schema, body, "$") {
+				t.Error(problem)
+			}
+		})
+	}
+
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			if op := strings.ToUpper(method) + " " + path; !covered[op] {
+				t.Errorf("documented operation %s is not exercised", op)
+			}
+		}
+	}
+}
+
+func TestAPIHandler_Roll(t *testing.T) {
+	api := newTestAPI(t)
+
+	roll := func(body string) apiRollResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/roll", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var response apiRollResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("invalid JSON body: %v", err)
+		}
+		return response
+	}
+
+	first := roll(`{"expression":"d20adv + 5","count":5,"seed":7}`)
+	if first.Expression != "2d20kh1 + 5" || first.Min != 6 || first.Max != 25 || first.Average != 18.825 {
+		t.Errorf("unexpected expression summary %+v", first)
+	}
+	if len(first.Rolls) != 5 {
+		t.Fatalf("expected 5 rolls, got %d", len(first.Rolls))
+	}
+	for _, r := range first.Rolls {
+		d20 := r.Terms[0]
+		if len(d20.Dice) != 2 || d20.Value != max(d20.Dice[0], d20.Dice[1]) || r.Total != d20.Value+5 {
+			t.Errorf("expected the highest of two d20 plus 5, got %+v", r)
+		}
+	}
+
+	// The same seed rolls the same dice; without a seed one is picked and reported
+	if again := roll(`{"expression":"d20adv + 5","count":5,"seed":7}`); !slices.EqualFunc(first.Rolls, again.Rolls, func(a, b apiRoll) bool { return a.Total == b.Total }) {
+		t.Errorf("expected seed 7 to repeat the rolls, got %+v and %+v", first.Rolls, again.Rolls)
+	}
+	if random := roll(`{"expression":"3"}`); random.Seed == 0 || len(random.Rolls) != 1 || random.Rolls[0].Total != 3 {
+		t.Errorf("expected a single roll with a random seed, got %+v", random)
+	}
+
+	for _, body := range []string{`{"expression":"1d6","count":101}`, `{"expression":"1d6","seed":-1}`, `{"expression":""}`} {
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/roll", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestAPIHandler_SearchByDamagePerRound(t *testing.T) {
+	api := newTestAPI(t)
+
+	search := func(url string) apiMonsterList {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var response apiMonsterList
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("invalid JSON body: %v", err)
+		}
+		return response
+	}
+
+	hardest := search("/monsters?cr_min=3&cr_max=3&sort=dpr_desc")
+	if hardest.Count == 0 {
+		t.Fatal("expected CR 3 monsters")
+	}
+	if !slices.IsSortedFunc(hardest.Monsters, func(a, b monsterApp.MonsterSummary) int { return cmp.Compare(b.DamagePerRound, a.DamagePerRound) }) {
+		t.Errorf("expected the hardest hitters first, got %+v", hardest.Monsters)
+	}
+
+	// A weaker target takes more damage from the same monster
+	weak := search("/monsters?q=ogre&target_ac=8&target_save=-1")
+	strong := search("/monsters?q=ogre&target_ac=20&target_save=8")
+	dpr := func(list apiMonsterList) float64 {
+		for _, m := range list.Monsters {
+			if m.ID == "ogre" {
+				return m.DamagePerRound
+			}
+		}
+		t.Fatalf("expected the ogre in %+v", list.Monsters)
+		return 0
+	}
+	if dpr(weak) <= dpr(strong) {
+		t.Errorf("expected more damage against AC 8 than AC 20, got %v and %v", dpr(weak), dpr(strong))
+	}
+}
+
+func TestAPIHandler_RoutesAreDocumented(t *testing.T) {
+	doc := loadOpenAPI(t)
+	routes := map[string]bool{}
+
+	err := chi.Walk(newTestAPI(t).(chi.Router), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes[method+" "+route] = true
+		if _, ok := doc.Paths[route][strings.ToLower(method)]; !ok {
+			t.Errorf("route %s %s is not in the OpenAPI document", method, route)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			if !routes[strings.ToUpper(method)+" "+path] {
+				t.Errorf("documented operation %s %s has no route", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+func TestAPIHandler_Errors(t *testing.T) {
+	api := newTestAPI(t)
+	doc := loadOpenAPI(t)
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		status int
+		code   string
+	}{
+		{name: "unknown endpoint", method: http.MethodGet, url: "/spells", status: http.StatusNotFound, code: apiErrorNotFound},
+		{name: "wrong method", method: http.MethodDelete, url: "/monsters", status: http.StatusMethodNotAllowed, code: apiErrorMethodNotAllowed},
+		{name: "invalid ruleset", method: http.MethodGet, url: "/thresholds?ruleset=3.5", status: http.StatusBadRequest, code: apiErrorInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.url, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			var body map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON body: %v", err)
+			}
+			for _, problem := range doc.validate(doc.Components.Schemas["Error"], body, "$") {
+				t.Error(problem)
+			}
+			if code := body["error"].(map[string]any)["code"]; code != tt.code {
+				t.Errorf("expected code %q, got %v", tt.code, code)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_InternalErrorsAreHidden(t *testing.T) {
+	api := newTestAPIWith(t, brokenEncounterRepository{memory.NewEncounterRepository()})
+	doc := loadOpenAPI(t)
+
+	tests := []struct {
+		name string
+		url  string
+		path string
+		body string
+	}{
+		{
+			name: "calculate", url: "/encounters/calculate", path: "/encounters/calculate",
+			body: `{"ruleset":"2024","difficulty":"Moderate","character_levels":[5]}`,
+		},
+		{name: "thresholds", url: "/thresholds?ruleset=2024", path: "/thresholds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := http.MethodGet
+			if tt.body != "" {
+				method = http.MethodPost
+			}
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, httptest.NewRequest(method, tt.url, strings.NewReader(tt.body)))
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusInternalServerError, rec.Code, rec.Body)
+			}
+			if _, err := doc.response(method, tt.path, rec.Code); err != nil {
+				t.Error(err)
+			}
+			if strings.Contains(rec.Body.String(), "corrupted") {
+				t.Errorf("response leaks the internal error: %s", rec.Body)
+			}
+			var body apiError
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON body: %v", err)
+			}
+			if body.Error.Code != apiErrorInternal {
+				t.Errorf("expected code %q, got %q", apiErrorInternal, body.Error.Code)
+			}
+		})
+	}
+}
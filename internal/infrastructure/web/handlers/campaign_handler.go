@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	campaignApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/campaign"
+	campaignDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+	encounterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/web/templates"
+)
+
+// CampaignHandler handles HTTP requests for saved parties, saved encounters and campaigns
+type CampaignHandler struct {
+	service *campaignApp.Service
+	logger  *slog.Logger
+}
+
+// NewCampaignHandler creates a new campaign HTTP handler
+func NewCampaignHandler(service *campaignApp.Service, logger *slog.Logger) *CampaignHandler {
+	return &CampaignHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListPartiesHandler returns every saved party.
+// GET /api/parties
+func (h *CampaignHandler) ListPartiesHandler(w http.ResponseWriter, r *http.Request) {
+	parties, err := h.service.ListParties()
+	h.writeJSON(w, r, http.StatusOK, parties, err)
+}
+
+// CreatePartyHandler saves a party from a JSON body {"name", "members": [{"name", "level"}]}.
+// POST /api/parties
+func (h *CampaignHandler) CreatePartyHandler(w http.ResponseWriter, r *http.Request) {
+	var req campaignApp.CreatePartyRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	party, err := h.service.CreateParty(req)
+	h.writeJSON(w, r, http.StatusCreated, party, err)
+}
+
+// GetPartyHandler returns a saved party.
+// GET /api/parties/{partyID}
+func (h *CampaignHandler) GetPartyHandler(w http.ResponseWriter, r *http.Request) {
+	party, err := h.service.GetParty(chi.URLParam(r, "partyID"))
+	h.writeJSON(w, r, http.StatusOK, party, err)
+}
+
+// DeletePartyHandler deletes a saved party.
+// DELETE /api/parties/{partyID}
+func (h *CampaignHandler) DeletePartyHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeNoContent(w, r, h.service.DeleteParty(chi.URLParam(r, "partyID")))
+}
+
+// ListEncountersHandler returns every saved encounter.
+// GET /api/saved-encounters
+func (h *CampaignHandler) ListEncountersHandler(w http.ResponseWriter, r *http.Request) {
+	encounters, err := h.service.ListEncounters()
+	h.writeJSON(w, r, http.StatusOK, encounters, err)
+}
+
+// GetEncounterHandler returns a saved encounter.
+// GET /api/saved-encounters/{encounterID}
+func (h *CampaignHandler) GetEncounterHandler(w http.ResponseWriter, r *http.Request) {
+	saved, err := h.service.GetEncounter(chi.URLParam(r, "encounterID"))
+	h.writeJSON(w, r, http.StatusOK, saved, err)
+}
+
+// DeleteEncounterHandler deletes a saved encounter.
+// DELETE /api/saved-encounters/{encounterID}
+func (h *CampaignHandler) DeleteEncounterHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeNoContent(w, r, h.service.DeleteEncounter(chi.URLParam(r, "encounterID")))
+}
+
+// SaveCompositionHandler saves a composition under a name and renders a confirmation.
+// POST /compositions/{compositionID}/save (name)
+func (h *CampaignHandler) SaveCompositionHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.logger.Error("Failed to parse form", "request_id", requestID, "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	saved, err := h.service.SaveComposition(chi.URLParam(r, "compositionID"), r.FormValue("name"))
+	if err != nil {
+		h.logger.Error("Failed to save composition", "request_id", requestID, "error", err)
+		writeServiceError(w, err, campaignErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := templates.SavedEncounterNotice(saved).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render saved encounter", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// ListCampaignsHandler returns every campaign.
+// GET /api/campaigns
+func (h *CampaignHandler) ListCampaignsHandler(w http.ResponseWriter, r *http.Request) {
+	campaigns, err := h.service.ListCampaigns()
+	h.writeJSON(w, r, http.StatusOK, campaigns, err)
+}
+
+// CreateCampaignHandler creates a campaign from a JSON body {"name"}.
+// POST /api/campaigns
+func (h *CampaignHandler) CreateCampaignHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if !h.decode(w, r, &req) {
+		return
+	}
+	c, err := h.service.CreateCampaign(req.Name)
+	h.writeJSON(w, r, http.StatusCreated, c, err)
+}
+
+// GetCampaignHandler returns a campaign with its parties and encounters.
+// GET /api/campaigns/{campaignID}
+func (h *CampaignHandler) GetCampaignHandler(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.GetCampaign(chi.URLParam(r, "campaignID"))
+	h.writeJSON(w, r, http.StatusOK, c, err)
+}
+
+// DeleteCampaignHandler deletes a campaign, keeping its parties and encounters.
+// DELETE /api/campaigns/{campaignID}
+func (h *CampaignHandler) DeleteCampaignHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeNoContent(w, r, h.service.DeleteCampaign(chi.URLParam(r, "campaignID")))
+}
+
+// AddPartyHandler adds a saved party to a campaign from a JSON body {"party_id"}.
+// POST /api/campaigns/{campaignID}/parties
+func (h *CampaignHandler) AddPartyHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PartyID string `json:"party_id"`
+	}
+	if !h.decode(w, r, &req) {
+		return
+	}
+	c, err := h.service.AddParty(chi.URLParam(r, "campaignID"), req.PartyID)
+	h.writeJSON(w, r, http.StatusOK, c, err)
+}
+
+// AddEncounterHandler adds a saved encounter to a campaign from a JSON body {"encounter_id"}.
+// POST /api/campaigns/{campaignID}/encounters
+func (h *CampaignHandler) AddEncounterHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		EncounterID string `json:"encounter_id"`
+	}
+	if !h.decode(w, r, &req) {
+		return
+	}
+	c, err := h.service.AddEncounter(chi.URLParam(r, "campaignID"), req.EncounterID)
+	h.writeJSON(w, r, http.StatusOK, c, err)
+}
+
+// decode reads a JSON request body, writing a 400 response when it is malformed
+func (h *CampaignHandler) decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		h.logger.Error("Invalid JSON body", "request_id", middleware.GetReqID(r.Context()), "error", err)
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeJSON writes v with the given status or maps the service error to an HTTP status
+func (h *CampaignHandler) writeJSON(w http.ResponseWriter, r *http.Request, status int, v any, err error) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err != nil {
+		h.logger.Error("Campaign request failed", "request_id", requestID, "error", err)
+		writeServiceError(w, err, campaignErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("Failed to encode response", "request_id", requestID, "error", err)
+	}
+}
+
+func (h *CampaignHandler) writeNoContent(w http.ResponseWriter, r *http.Request, err error) {
+	if err != nil {
+		h.logger.Error("Campaign request failed", "request_id", middleware.GetReqID(r.Context()), "error", err)
+		writeServiceError(w, err, campaignErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// campaignErrorStatus maps campaign service errors to HTTP statuses
+func campaignErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, campaignDomain.ErrPartyNotFound),
+		errors.Is(err, campaignDomain.ErrEncounterNotFound),
+		errors.Is(err, campaignDomain.ErrCampaignNotFound),
+		errors.Is(err, encounterDomain.ErrCompositionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, campaignApp.ErrInvalidRequest):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	combatApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/combat"
+	combatDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/combat"
+	encounterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/web/templates"
+)
+
+// CombatHandler handles HTTP requests for the combat tracker
+type CombatHandler struct {
+	service *combatApp.Service
+	logger  *slog.Logger
+}
+
+// NewCombatHandler creates a new combat tracker HTTP handler
+func NewCombatHandler(service *combatApp.Service, logger *slog.Logger) *CombatHandler {
+	return &CombatHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// StartHandler starts a combat with the party and monsters of a composition and redirects to its tracker.
+// POST /compositions/{compositionID}/combat
+func (h *CombatHandler) StartHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	started, err := h.service.Start(chi.URLParam(r, "compositionID"))
+	if err != nil {
+		h.logger.Error("Failed to start combat", "request_id", requestID, "error", err)
+		writeServiceError(w, err, combatErrorStatus(err))
+		return
+	}
+
+	http.Redirect(w, r, "/combats/"+started.ID, http.StatusSeeOther)
+}
+
+// PageHandler renders the combat tracker; reloading it shows the fight as it was left.
+// GET /combats/{combatID}
+func (h *CombatHandler) PageHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	c, err := h.service.Get(chi.URLParam(r, "combatID"))
+	if err != nil {
+		h.logger.Error("Failed to load combat", "request_id", requestID, "error", err)
+		writeServiceError(w, err, combatErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.CombatPage(c).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render combat page", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// NextHandler passes the turn to the following combatant.
+// POST /combats/{combatID}/next
+func (h *CombatHandler) NextHandler(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.Next(chi.URLParam(r, "combatID"))
+	h.render(w, r, c, err)
+}
+
+// PreviousHandler gives the turn back to the preceding combatant.
+// POST /combats/{combatID}/previous
+func (h *CombatHandler) PreviousHandler(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.Previous(chi.URLParam(r, "combatID"))
+	h.render(w, r, c, err)
+}
+
+// RollInitiativeHandler rolls the initiative of every monster.
+// POST /combats/{combatID}/roll-initiative
+func (h *CombatHandler) RollInitiativeHandler(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.RollInitiative(chi.URLParam(r, "combatID"))
+	h.render(w, r, c, err)
+}
+
+// RollHitPointsHandler rolls the hit points of every monster.
+// POST /combats/{combatID}/roll-hit-points
+func (h *CombatHandler) RollHitPointsHandler(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.RollHitPoints(chi.URLParam(r, "combatID"))
+	h.render(w, r, c, err)
+}
+
+// InitiativeHandler enters the initiative of a combatant.
+// PUT /combats/{combatID}/combatants/{combatantID}/initiative (initiative)
+func (h *CombatHandler) InitiativeHandler(w http.ResponseWriter, r *http.Request) {
+	values, ok := h.parseInts(w, r, "initiative")
+	if !ok {
+		return
+	}
+	c, err := h.service.SetInitiative(chi.URLParam(r, "combatID"), chi.URLParam(r, "combatantID"), values[0])
+	h.render(w, r, c, err)
+}
+
+// DamageHandler takes hit points from a combatant.
+// POST /combats/{combatID}/combatants/{combatantID}/damage (amount)
+func (h *CombatHandler) DamageHandler(w http.ResponseWriter, r *http.Request) {
+	values, ok := h.parseInts(w, r, "amount")
+	if !ok {
+		return
+	}
+	c, err := h.service.Damage(chi.URLParam(r, "combatID"), chi.URLParam(r, "combatantID"), values[0])
+	h.render(w, r, c, err)
+}
+
+// HealHandler restores hit points to a combatant.
+// POST /combats/{combatID}/combatants/{combatantID}/heal (amount)
+func (h *CombatHandler) HealHandler(w http.ResponseWriter, r *http.Request) {
+	values, ok := h.parseInts(w, r, "amount")
+	if !ok {
+		return
+	}
+	c, err := h.service.Heal(chi.URLParam(r, "combatID"), chi.URLParam(r, "combatantID"), values[0])
+	h.render(w, r, c, err)
+}
+
+// TempHPHandler gives temporary hit points to a combatant.
+// PUT /combats/{combatID}/combatants/{combatantID}/temp-hp (amount)
+func (h *CombatHandler) TempHPHandler(w http.ResponseWriter, r *http.Request) {
+	values, ok := h.parseInts(w, r, "amount")
+	if !ok {
+		return
+	}
+	c, err := h.service.SetTempHP(chi.URLParam(r, "combatID"), chi.URLParam(r, "combatantID"), values[0])
+	h.render(w, r, c, err)
+}
+
+// HitPointsHandler enters the current and maximum hit points of a combatant; without hp it starts unhurt.
+// PUT /combats/{combatID}/combatants/{combatantID}/hit-points (max_hp, hp)
+func (h *CombatHandler) HitPointsHandler(w http.ResponseWriter, r *http.Request) {
+	values, ok := h.parseInts(w, r, "max_hp")
+	if !ok {
+		return
+	}
+	maxHP, hp := values[0], values[0]
+	if r.FormValue("hp") != "" {
+		if values, ok = h.parseInts(w, r, "hp"); !ok {
+			return
+		}
+		hp = values[0]
+	}
+	c, err := h.service.SetHitPoints(chi.URLParam(r, "combatID"), chi.URLParam(r, "combatantID"), hp, maxHP)
+	h.render(w, r, c, err)
+}
+
+// parseInts reads the named integer form fields, writing a bad request response when one is missing or invalid
+func (h *CombatHandler) parseInts(w http.ResponseWriter, r *http.Request, names ...string) ([]int, bool) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.logger.Error("Failed to parse form", "request_id", requestID, "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return nil, false
+	}
+
+	values := make([]int, len(names))
+	for i, name := range names {
+		v, err := strconv.Atoi(r.FormValue(name))
+		if err != nil {
+			h.logger.Error("Invalid "+name, "request_id", requestID, "error", err)
+			http.Error(w, "Invalid "+name, http.StatusBadRequest)
+			return nil, false
+		}
+		values[i] = v
+	}
+	return values, true
+}
+
+// render writes the combat tracker or maps the service error to an HTTP status
+func (h *CombatHandler) render(w http.ResponseWriter, r *http.Request, c *combatApp.CombatResponse, err error) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err != nil {
+		h.logger.Error("Combat request failed", "request_id", requestID, "error", err)
+		writeServiceError(w, err, combatErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := templates.CombatTracker(c).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render combat tracker", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// combatErrorStatus maps combat service errors to HTTP statuses
+func combatErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, combatDomain.ErrCombatNotFound),
+		errors.Is(err, combatDomain.ErrCombatantNotFound),
+		errors.Is(err, encounterDomain.ErrCompositionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, combatDomain.ErrNoCombatants):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, combatApp.ErrInvalidRequest):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/encounter"
+	encounterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	monsterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/export/foundry"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/web/templates"
+)
+
+// CompositionHandler handles HTTP requests for building an encounter composition
+type CompositionHandler struct {
+	service *encounter.CompositionService
+	logger  *slog.Logger
+}
+
+// NewCompositionHandler creates a new composition HTTP handler
+func NewCompositionHandler(service *encounter.CompositionService, logger *slog.Logger) *CompositionHandler {
+	return &CompositionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetHandler renders the current state of a composition.
+// GET /compositions/{compositionID}
+func (h *CompositionHandler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	composition, err := h.service.Get(chi.URLParam(r, "compositionID"))
+	h.render(w, r, composition, err)
+}
+
+// AddMonsterHandler adds monsters to a composition.
+// POST /compositions/{compositionID}/monsters (monster_id, quantity)
+func (h *CompositionHandler) AddMonsterHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.logger.Error("Failed to parse form", "request_id", requestID, "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	quantity := 1
+	if v := r.FormValue("quantity"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			h.logger.Error("Invalid quantity", "request_id", requestID, "error", err)
+			http.Error(w, "Invalid quantity", http.StatusBadRequest)
+			return
+		}
+		quantity = parsed
+	}
+
+	composition, err := h.service.AddMonster(chi.URLParam(r, "compositionID"), r.FormValue("monster_id"), quantity)
+	h.render(w, r, composition, err)
+}
+
+// SetQuantityHandler changes the quantity of a monster group in a composition.
+// PUT /compositions/{compositionID}/monsters/{monsterID} (quantity)
+func (h *CompositionHandler) SetQuantityHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.logger.Error("Failed to parse form", "request_id", requestID, "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	quantity, err := strconv.Atoi(r.FormValue("quantity"))
+	if err != nil {
+		h.logger.Error("Invalid quantity", "request_id", requestID, "error", err)
+		http.Error(w, "Invalid quantity", http.StatusBadRequest)
+		return
+	}
+
+	composition, err := h.service.SetQuantity(chi.URLParam(r, "compositionID"), chi.URLParam(r, "monsterID"), quantity)
+	h.render(w, r, composition, err)
+}
+
+// RemoveMonsterHandler removes a monster group from a composition.
+// DELETE /compositions/{compositionID}/monsters/{monsterID}
+func (h *CompositionHandler) RemoveMonsterHandler(w http.ResponseWriter, r *http.Request) {
+	composition, err := h.service.RemoveMonster(chi.URLParam(r, "compositionID"), chi.URLParam(r, "monsterID"))
+	h.render(w, r, composition, err)
+}
+
+// InLairHandler places a monster group of a composition in its lair or outside it.
+// PUT /compositions/{compositionID}/monsters/{monsterID}/lair (in_lair, set when checked)
+func (h *CompositionHandler) InLairHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.logger.Error("Failed to parse form", "request_id", requestID, "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	inLair := r.FormValue("in_lair") != ""
+	composition, err := h.service.SetInLair(chi.URLParam(r, "compositionID"), chi.URLParam(r, "monsterID"), inLair)
+	h.render(w, r, composition, err)
+}
+
+// CountWeakMonstersHandler turns the 2014 weak monster rule on or off for a composition.
+// PUT /compositions/{compositionID}/count-weak-monsters (count_weak_monsters, set when checked)
+func (h *CompositionHandler) CountWeakMonstersHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.logger.Error("Failed to parse form", "request_id", requestID, "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	countWeak := r.FormValue("count_weak_monsters") != ""
+	composition, err := h.service.SetCountWeakMonsters(chi.URLParam(r, "compositionID"), countWeak)
+	h.render(w, r, composition, err)
+}
+
+// GenerateHandler replaces the monsters of a composition with a random encounter.
+// POST /compositions/{compositionID}/generate (type, size, cr_min, cr_max, seed, tolerance, max_groups)
+func (h *CompositionHandler) GenerateHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.logger.Error("Failed to parse form", "request_id", requestID, "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseGenerateOptions(r)
+	if err != nil {
+		h.logger.Error("Invalid generate options", "request_id", requestID, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	composition, err := h.service.Generate(chi.URLParam(r, "compositionID"), opts)
+	h.render(w, r, composition, err)
+}
+
+// FoundryHandler downloads a composition as a zip of Foundry VTT actors, one per monster.
+// GET /compositions/{compositionID}/foundry.zip
+func (h *CompositionHandler) FoundryHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	id := chi.URLParam(r, "compositionID")
+	groups, err := h.service.Groups(id)
+	if err != nil {
+		h.render(w, r, nil, err)
+		return
+	}
+
+	var archive bytes.Buffer
+	if err := foundry.WriteEncounter(&archive, groups); err != nil {
+		h.logger.Error("Failed to export composition", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "incontro-"+id+".zip"))
+	if _, err := archive.WriteTo(w); err != nil {
+		h.logger.Error("Failed to write Foundry archive", "request_id", requestID, "error", err)
+	}
+}
+
+// render writes the composition panel or maps the service error to an HTTP status
+func (h *CompositionHandler) render(w http.ResponseWriter, r *http.Request, composition *encounter.CompositionResponse, err error) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err != nil {
+		h.logger.Error("Composition request failed", "request_id", requestID, "error", err)
+		writeServiceError(w, err, encounterErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := templates.CompositionPanel(composition).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render composition panel", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// encounterErrorStatus maps the errors of the composition, generator and day plan services to HTTP statuses
+func encounterErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, encounterDomain.ErrCompositionNotFound),
+		errors.Is(err, encounterDomain.ErrMonsterNotInComposition),
+		errors.Is(err, encounterDomain.ErrArchetypeNotFound),
+		errors.Is(err, monsterDomain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, encounter.ErrNoEncounterGenerated):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, encounter.ErrInvalidRequest):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
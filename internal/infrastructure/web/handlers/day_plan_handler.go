@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/encounter"
+	encounterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/web/templates"
+)
+
+// defaultDayEncounters is the number of encounters shown when the planner opens
+const defaultDayEncounters = 3
+
+// DayPlanHandler handles HTTP requests for the adventuring day planner
+type DayPlanHandler struct {
+	service      *encounter.DayPlanService
+	queryHandler *encounter.QueryHandler
+	logger       *slog.Logger
+}
+
+// NewDayPlanHandler creates a new day plan HTTP handler
+func NewDayPlanHandler(service *encounter.DayPlanService, queryHandler *encounter.QueryHandler, logger *slog.Logger) *DayPlanHandler {
+	return &DayPlanHandler{
+		service:      service,
+		queryHandler: queryHandler,
+		logger:       logger,
+	}
+}
+
+// PageHandler renders the day planner page.
+// GET /day-planner
+func (h *DayPlanHandler) PageHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	difficulties := h.queryHandler.GetDifficultyOptions("2024")
+	if err := templates.DayPlanner(difficulties, defaultDayEncounters).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render day planner", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// EncounterFieldsHandler renders one difficulty field per planned encounter.
+// GET /day-planner/encounters?ruleset=R&encounters=N
+func (h *DayPlanHandler) EncounterFieldsHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	count, err := strconv.Atoi(r.URL.Query().Get("encounters"))
+	if err != nil || count < 1 {
+		http.Error(w, "Invalid encounters parameter", http.StatusBadRequest)
+		return
+	}
+	count = min(count, encounterDomain.MaxDayEncounters)
+
+	difficulties := h.queryHandler.GetDifficultyOptions(r.URL.Query().Get("ruleset"))
+	if len(difficulties) == 0 {
+		http.Error(w, "Invalid ruleset", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := templates.DayPlanEncounterFields(difficulties, count).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render day plan fields", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// PlanHandler renders the day plan for the submitted party and difficulties.
+// POST /day-planner (ruleset, levels, difficulty repeated per encounter)
+func (h *DayPlanHandler) PlanHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.logger.Error("Failed to parse form", "request_id", requestID, "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	plan, err := h.plan(r.FormValue("ruleset"), r.FormValue("levels"), r.Form["difficulty"])
+	if err != nil {
+		h.logger.Error("Day planning failed", "request_id", requestID, "error", err)
+		writeServiceError(w, err, encounterErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := templates.DayPlanResult(plan).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render day plan", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// APIHandler returns the day plan as JSON.
+// GET /api/day-plan?ruleset=R&levels=5,5,5&difficulties=Media,Media,Difficile
+func (h *DayPlanHandler) APIHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	var difficulties []string
+	if v := r.URL.Query().Get("difficulties"); v != "" {
+		for _, d := range strings.Split(v, ",") {
+			difficulties = append(difficulties, strings.TrimSpace(d))
+		}
+	}
+
+	plan, err := h.plan(r.URL.Query().Get("ruleset"), r.URL.Query().Get("levels"), difficulties)
+	if err != nil {
+		h.logger.Error("Day planning failed", "request_id", requestID, "error", err)
+		writeServiceError(w, err, encounterErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(plan); err != nil {
+		h.logger.Error("Failed to encode day plan", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func (h *DayPlanHandler) plan(ruleset, levels string, difficulties []string) (*encounter.DayPlanResponse, error) {
+	characterLevels, err := parseCharacterLevels(levels)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid levels: %w", encounter.ErrInvalidRequest, err)
+	}
+
+	return h.service.PlanDay(encounter.PlanDayRequest{
+		Ruleset:         ruleset,
+		CharacterLevels: characterLevels,
+		Difficulties:    difficulties,
+	})
+}
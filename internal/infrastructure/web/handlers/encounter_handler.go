@@ -14,24 +14,29 @@ import (
 
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/encounter"
 	monsterApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/monster"
+	monsterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/web/templates"
 )
 
 // EncounterHandler handles HTTP requests for encounter-related operations
 type EncounterHandler struct {
-	service        *encounter.Service
-	queryHandler   *encounter.QueryHandler
-	monsterService *monsterApp.Service
-	logger         *slog.Logger
+	service            *encounter.Service
+	queryHandler       *encounter.QueryHandler
+	compositionService *encounter.CompositionService
+	generatorService   *encounter.GeneratorService
+	monsterService     *monsterApp.Service
+	logger             *slog.Logger
 }
 
 // NewEncounterHandler creates a new encounter HTTP handler
-func NewEncounterHandler(service *encounter.Service, queryHandler *encounter.QueryHandler, monsterService *monsterApp.Service, logger *slog.Logger) *EncounterHandler {
+func NewEncounterHandler(service *encounter.Service, queryHandler *encounter.QueryHandler, compositionService *encounter.CompositionService, generatorService *encounter.GeneratorService, monsterService *monsterApp.Service, logger *slog.Logger) *EncounterHandler {
 	return &EncounterHandler{
-		service:        service,
-		queryHandler:   queryHandler,
-		monsterService: monsterService,
-		logger:         logger,
+		service:            service,
+		queryHandler:       queryHandler,
+		compositionService: compositionService,
+		generatorService:   generatorService,
+		monsterService:     monsterService,
+		logger:             logger,
 	}
 }
 
@@ -56,7 +61,6 @@ type Calculate2014Form struct {
 	Ruleset         string `json:"ruleset" validate:"required,eq=2014"`
 	PartyMode       string `json:"party_mode" validate:"required,oneof=same different"`
 	Difficulty2014  string `json:"difficulty_2014" validate:"required"`
-	NumMonsters     int    `json:"num_monsters_2014" validate:"min=0"`
 	CharacterLevels []int  `json:"character_levels" validate:"required,min=1"`
 }
 
@@ -131,14 +135,6 @@ func (h *EncounterHandler) CalculateHandler(w http.ResponseWriter, r *http.Reque
 		req.Difficulty = r.FormValue("difficulty_2024")
 	case "2014":
 		req.Difficulty = r.FormValue("difficulty_2014")
-		if numMonstersStr := r.FormValue("num_monsters_2014"); numMonstersStr != "" {
-			req.NumMonsters, err = strconv.Atoi(numMonstersStr)
-			if err != nil {
-				h.logger.Error("Invalid number of monsters", "request_id", requestID, "error", err)
-				http.Error(w, "Invalid number of monsters", http.StatusBadRequest)
-				return
-			}
-		}
 	default:
 		http.Error(w, "Invalid ruleset", http.StatusBadRequest)
 		return
@@ -152,20 +148,144 @@ func (h *EncounterHandler) CalculateHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Start a server-side composition for the monsters the DM will pick
+	composition, err := h.compositionService.Create(encounter.CreateCompositionRequest{
+		Ruleset:         req.Ruleset,
+		Difficulty:      req.Difficulty,
+		CharacterLevels: characterLevels,
+		Budget:          result.TotalXP,
+	})
+	if err != nil {
+		h.logger.Error("Failed to create composition", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	// Return HTML response for HTMX
 	w.Header().Set("Content-Type", "text/html")
 	facets := templates.MonsterFacets{
-		Types: h.monsterService.AvailableTypes(),
-		Sizes: h.monsterService.AvailableSizes(),
-		CRs:   h.monsterService.AvailableCRs(),
+		Types:      h.monsterService.AvailableTypes(),
+		Sizes:      h.monsterService.AvailableSizes(),
+		CRs:        h.monsterService.AvailableCRs(),
+		Archetypes: h.queryHandler.GetArchetypeOptions(),
 	}
-	if err := templates.Result(result, facets).Render(r.Context(), w); err != nil {
+	if err := templates.Result(result, composition, facets).Render(r.Context(), w); err != nil {
 		h.logger.Error("Failed to render result template", "request_id", requestID, "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 }
 
+// GenerateHandler generates a random encounter that fills an XP budget.
+// GET /api/encounters/generate?ruleset=R&levels=5,5,5&budget=N|difficulty=D&archetype=A&type=T&size=S&cr_min=X&cr_max=Y&seed=N&tolerance=F&max_groups=N&count_weak_monsters=1
+func (h *EncounterHandler) GenerateHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+	query := r.URL.Query()
+
+	levels, err := parseCharacterLevels(query.Get("levels"))
+	if err != nil {
+		h.logger.Error("Invalid character levels", "request_id", requestID, "error", err)
+		http.Error(w, fmt.Sprintf("Invalid levels: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseGenerateOptions(r)
+	if err != nil {
+		h.logger.Error("Invalid generate options", "request_id", requestID, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := encounter.GenerateRequest{
+		Ruleset:           query.Get("ruleset"),
+		CharacterLevels:   levels,
+		CountWeakMonsters: query.Get("count_weak_monsters") != "",
+		GenerateOptions:   opts,
+	}
+
+	// Without an explicit budget, use the XP budget of the requested difficulty
+	if v := query.Get("budget"); v != "" {
+		req.Budget, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid budget parameter", http.StatusBadRequest)
+			return
+		}
+	} else {
+		result, err := h.service.CalculateXP(encounter.CalculateXPRequest{
+			Ruleset:         req.Ruleset,
+			Difficulty:      query.Get("difficulty"),
+			CharacterLevels: levels,
+		})
+		if err != nil {
+			h.logger.Error("XP calculation failed", "request_id", requestID, "error", err)
+			writeServiceError(w, fmt.Errorf("Calculation error: %w", err), encounterErrorStatus(err))
+			return
+		}
+		req.Budget = result.TotalXP
+	}
+
+	result, err := h.generatorService.Generate(req)
+	if err != nil {
+		h.logger.Error("Encounter generation failed", "request_id", requestID, "error", err)
+		writeServiceError(w, err, encounterErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.Error("Failed to encode generated encounter", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// parseGenerateOptions reads the generator filters and options from the query or form
+func parseGenerateOptions(r *http.Request) (encounter.GenerateOptions, error) {
+	opts := encounter.GenerateOptions{
+		Filters: monsterDomain.SearchFilters{
+			Query: r.FormValue("q"),
+			Type:  r.FormValue("type"),
+			Size:  r.FormValue("size"),
+			CRMin: r.FormValue("cr_min"),
+			CRMax: r.FormValue("cr_max"),
+		},
+		Archetype: r.FormValue("archetype"),
+	}
+	if err := parseStatblockFilters(r.Form, &opts.Filters); err != nil {
+		return opts, err
+	}
+
+	var err error
+	if v := r.FormValue("seed"); v != "" {
+		if opts.Seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return opts, fmt.Errorf("invalid seed parameter")
+		}
+	}
+	if v := r.FormValue("tolerance"); v != "" {
+		if opts.Tolerance, err = strconv.ParseFloat(v, 64); err != nil {
+			return opts, fmt.Errorf("invalid tolerance parameter")
+		}
+	}
+	if v := r.FormValue("max_groups"); v != "" {
+		if opts.MaxGroups, err = strconv.Atoi(v); err != nil {
+			return opts, fmt.Errorf("invalid max_groups parameter")
+		}
+	}
+	return opts, nil
+}
+
+// GetArchetypesHandler returns the encounter archetypes available to the generator
+func (h *EncounterHandler) GetArchetypesHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.queryHandler.GetArchetypeOptions()); err != nil {
+		h.logger.Error("Failed to encode archetypes response", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
 // PartyInputHandler handles party input form requests
 func (h *EncounterHandler) PartyInputHandler(w http.ResponseWriter, r *http.Request) {
 	requestID := middleware.GetReqID(r.Context())
@@ -202,8 +322,8 @@ func (h *EncounterHandler) PartyInputHandler(w http.ResponseWriter, r *http.Requ
 	}
 }
 
-// Helper function to parse character levels from comma-separated string
-func (h *EncounterHandler) parseCharacterLevels(levelsStr string) ([]int, error) {
+// parseCharacterLevels parses character levels from a comma-separated string
+func parseCharacterLevels(levelsStr string) ([]int, error) {
 	if levelsStr == "" {
 		return nil, fmt.Errorf("character levels cannot be empty")
 	}
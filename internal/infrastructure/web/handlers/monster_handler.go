@@ -1,17 +1,29 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
 	monsterApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/monster"
 	monsterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/export/foundry"
 	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/web/templates"
 )
 
+// Number of names offered by the autocomplete, by default and at most
+const (
+	defaultSuggestions = 8
+	maxSuggestions     = 20
+)
+
 // MonsterHandler handles HTTP requests for monster browsing.
 type MonsterHandler struct {
 	service *monsterApp.Service
@@ -27,7 +39,7 @@ func NewMonsterHandler(service *monsterApp.Service, logger *slog.Logger) *Monste
 }
 
 // SearchHandler handles monster search requests via HTMX.
-// GET /api/monsters?max_xp=N&q=search&type=T&size=S&cr_min=X&cr_max=Y
+// GET /api/monsters?max_xp=N&q=search&type=T&size=S&cr_min=X&cr_max=Y&fly=B&swim_min=M&blindsight=B&pp_max=N&resist=D&no_resist=D&immune=D&no_immune=D&condition_immune=C&no_condition_immune=C&target_ac=N&target_save=N&dpr_min=N&dpr_max=N&sort=O&composition_id=C
 func (h *MonsterHandler) SearchHandler(w http.ResponseWriter, r *http.Request) {
 	requestID := middleware.GetReqID(r.Context())
 
@@ -50,12 +62,325 @@ func (h *MonsterHandler) SearchHandler(w http.ResponseWriter, r *http.Request) {
 		CRMin: r.URL.Query().Get("cr_min"),
 		CRMax: r.URL.Query().Get("cr_max"),
 	}
+	if err := parseStatblockFilters(r.URL.Query(), &filters); err != nil {
+		h.logger.Error("Invalid search filters", "request_id", requestID, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	monsters := h.service.SearchMonstersWithFilters(filters)
+	results := h.service.SearchMonstersRanked(filters)
 
 	w.Header().Set("Content-Type", "text/html")
-	if err := templates.MonsterList(monsters, maxXP).Render(r.Context(), w); err != nil {
+	if err := templates.MonsterList(results, maxXP, r.URL.Query().Get("composition_id")).Render(r.Context(), w); err != nil {
 		h.logger.Error("Failed to render monster list", "request_id", requestID, "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
+
+// SuggestHandler renders the monster names closest to a partial, possibly misspelt, query
+// for the autocomplete of the monster browser.
+// GET /api/monsters/suggest?q=search&limit=N
+func (h *MonsterHandler) SuggestHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	limit := defaultSuggestions
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxSuggestions {
+			h.logger.Error("Invalid limit", "request_id", requestID, "limit", v)
+			http.Error(w, fmt.Sprintf("Invalid limit parameter: must be between 1 and %d", maxSuggestions), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions := h.service.SuggestMonsters(r.URL.Query().Get("q"), limit)
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := templates.MonsterSuggestions(suggestions).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render monster suggestions", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// HomebrewPageHandler renders the homebrew monster CR calculator.
+// GET /homebrew
+func (h *MonsterHandler) HomebrewPageHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.HomebrewCalculator().Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render homebrew calculator", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// HomebrewHandler renders the challenge rating of the submitted homebrew monster.
+// POST /homebrew (hp, ac, attack_bonus, save_dc, dpr)
+func (h *MonsterHandler) HomebrewHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		h.logger.Error("Failed to parse form", "request_id", requestID, "error", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	challenge, err := h.calculateChallenge(r.Form)
+	if err != nil {
+		h.logger.Error("Challenge calculation failed", "request_id", requestID, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := templates.HomebrewResult(challenge).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render homebrew result", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// ChallengeAPIHandler returns the challenge rating of a homebrew monster as JSON.
+// GET /api/monsters/challenge?hp=N&ac=N&attack_bonus=N&save_dc=N&dpr=N
+func (h *MonsterHandler) ChallengeAPIHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	challenge, err := h.calculateChallenge(r.URL.Query())
+	if err != nil {
+		h.logger.Error("Challenge calculation failed", "request_id", requestID, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(challenge); err != nil {
+		h.logger.Error("Failed to encode challenge", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func (h *MonsterHandler) calculateChallenge(values url.Values) (monsterDomain.Challenge, error) {
+	stats, err := parseHomebrewStats(values)
+	if err != nil {
+		return monsterDomain.Challenge{}, err
+	}
+	return h.service.CalculateChallenge(stats)
+}
+
+// parseHomebrewStats reads homebrew statistics; attack_bonus and save_dc are optional.
+func parseHomebrewStats(values url.Values) (monsterDomain.HomebrewStats, error) {
+	var stats monsterDomain.HomebrewStats
+	var err error
+
+	required := []struct {
+		name   string
+		target *int
+	}{
+		{"hp", &stats.HP},
+		{"ac", &stats.AC},
+		{"dpr", &stats.DamagePerRound},
+	}
+	for _, f := range required {
+		if *f.target, err = strconv.Atoi(values.Get(f.name)); err != nil {
+			return stats, fmt.Errorf("invalid %s parameter: %w", f.name, err)
+		}
+	}
+
+	if stats.AttackBonus, err = optionalInt(values.Get("attack_bonus")); err != nil {
+		return stats, fmt.Errorf("invalid attack_bonus parameter: %w", err)
+	}
+	if stats.SaveDC, err = optionalInt(values.Get("save_dc")); err != nil {
+		return stats, fmt.Errorf("invalid save_dc parameter: %w", err)
+	}
+	return stats, nil
+}
+
+func optionalInt(v string) (*int, error) {
+	if v == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// parseStatblockFilters reads the optional movement, sense, defense and offense search parameters:
+// fly, swim_min, blindsight and pp_max, the comma separated damage types of resist, no_resist,
+// immune and no_immune and conditions of condition_immune and no_condition_immune, and dpr_min,
+// dpr_max and sort, with the damage per round estimated against target_ac and target_save.
+func parseStatblockFilters(values url.Values, filters *monsterDomain.SearchFilters) error {
+	var err error
+	if err := parseOffenseFilters(values, filters); err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		name   string
+		target *bool
+	}{
+		{"fly", &filters.CanFly},
+		{"blindsight", &filters.HasBlindsight},
+	} {
+		if v := values.Get(f.name); v != "" {
+			if *f.target, err = strconv.ParseBool(v); err != nil {
+				return fmt.Errorf("invalid %s parameter: %w", f.name, err)
+			}
+		}
+	}
+
+	if v := values.Get("swim_min"); v != "" {
+		if filters.MinSwim, err = strconv.ParseFloat(v, 64); err != nil || filters.MinSwim < 0 {
+			return fmt.Errorf("invalid swim_min parameter: %q", v)
+		}
+	}
+	if v := values.Get("pp_max"); v != "" {
+		if filters.MaxPassivePerception, err = strconv.Atoi(v); err != nil || filters.MaxPassivePerception < 0 {
+			return fmt.Errorf("invalid pp_max parameter: %q", v)
+		}
+	}
+
+	for _, f := range []struct {
+		name   string
+		target *[]monsterDomain.DamageType
+	}{
+		{"resist", &filters.WithResistances},
+		{"no_resist", &filters.WithoutResistances},
+		{"immune", &filters.WithImmunities},
+		{"no_immune", &filters.WithoutImmunities},
+	} {
+		for _, v := range values[f.name] {
+			types, err := monsterDomain.ParseDamageTypes(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s parameter: %w", f.name, err)
+			}
+			*f.target = append(*f.target, types...)
+		}
+	}
+	for _, f := range []struct {
+		name   string
+		target *[]monsterDomain.Condition
+	}{
+		{"condition_immune", &filters.WithConditionImmunities},
+		{"no_condition_immune", &filters.WithoutConditionImmunities},
+	} {
+		for _, v := range values[f.name] {
+			conditions, err := monsterDomain.ParseConditions(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s parameter: %w", f.name, err)
+			}
+			*f.target = append(*f.target, conditions...)
+		}
+	}
+	return nil
+}
+
+// parseOffenseFilters reads the target, the damage per round bounds and the sort order,
+// filling the target fields that are missing from monsterDomain.DefaultTarget.
+func parseOffenseFilters(values url.Values, filters *monsterDomain.SearchFilters) error {
+	var err error
+	filters.Target = monsterDomain.DefaultTarget
+	if v := values.Get("target_ac"); v != "" {
+		if filters.Target.AC, err = strconv.Atoi(v); err != nil || filters.Target.AC < 1 {
+			return fmt.Errorf("invalid target_ac parameter: %q", v)
+		}
+	}
+	if v := values.Get("target_save"); v != "" {
+		if filters.Target.SaveBonus, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid target_save parameter: %q", v)
+		}
+	}
+
+	for _, f := range []struct {
+		name   string
+		target *float64
+	}{
+		{"dpr_min", &filters.MinDamagePerRound},
+		{"dpr_max", &filters.MaxDamagePerRound},
+	} {
+		if v := values.Get(f.name); v != "" {
+			if *f.target, err = strconv.ParseFloat(v, 64); err != nil || *f.target < 0 {
+				return fmt.Errorf("invalid %s parameter: %q", f.name, v)
+			}
+		}
+	}
+
+	if filters.Sort, err = monsterDomain.ParseSortOrder(values.Get("sort")); err != nil {
+		return fmt.Errorf("invalid sort parameter: %w", err)
+	}
+	return nil
+}
+
+// ScalePageHandler renders a monster rescaled to another CR next to the original statblock.
+// GET /monsters/{monsterID}/scale?cr=X
+func (h *MonsterHandler) ScalePageHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	result, ok := h.scale(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.MonsterScale(result, h.service.ChallengeRatings()).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render scaled monster", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// ScaleAPIHandler returns a monster rescaled to another CR as JSON.
+// GET /api/monsters/{monsterID}/scale?cr=X
+func (h *MonsterHandler) ScaleAPIHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	result, ok := h.scale(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.Error("Failed to encode scaled monster", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// scale rescales the requested monster, writing the error response when it fails
+// FoundryHandler downloads a monster as a Foundry VTT actor.
+// GET /monsters/{monsterID}/foundry.json
+func (h *MonsterHandler) FoundryHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	id := chi.URLParam(r, "monsterID")
+	m, ok := h.service.GetMonster(id)
+	if !ok {
+		h.logger.Error("Monster not found", "request_id", requestID, "monster_id", id)
+		http.Error(w, fmt.Sprintf("%v: %s", monsterDomain.ErrNotFound, id), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", m.ID+".json"))
+	if err := foundry.Encode(w, foundry.NewActor(m)); err != nil {
+		h.logger.Error("Failed to write Foundry actor", "request_id", requestID, "error", err)
+	}
+}
+
+func (h *MonsterHandler) scale(w http.ResponseWriter, r *http.Request) (monsterApp.ScaledMonster, bool) {
+	requestID := middleware.GetReqID(r.Context())
+
+	result, err := h.service.ScaleMonster(chi.URLParam(r, "monsterID"), r.URL.Query().Get("cr"))
+	if err != nil {
+		h.logger.Error("Monster scaling failed", "request_id", requestID, "error", err)
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, monsterDomain.ErrNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, monsterDomain.ErrInvalidCR):
+			status = http.StatusBadRequest
+		}
+		writeServiceError(w, err, status)
+		return monsterApp.ScaledMonster{}, false
+	}
+	return result, true
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import "net/http"
+
+// writeServiceError answers a failed service call with the status mapped from its error.
+// Client errors show the error message; server errors were logged by the caller and get a
+// generic message, so storage and other internal failures never reach the client.
+func writeServiceError(w http.ResponseWriter, err error, status int) {
+	if status >= http.StatusInternalServerError {
+		http.Error(w, "Internal server error", status)
+		return
+	}
+	http.Error(w, err.Error(), status)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	campaignApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/campaign"
+	combatApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/combat"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/encounter"
+	campaignDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/campaign"
+	combatDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/combat"
+	encounterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+)
+
+func TestServiceErrorStatus(t *testing.T) {
+	storage := errors.New("failed to save composition: disk I/O error")
+
+	tests := []struct {
+		name   string
+		status func(error) int
+		err    error
+		want   int
+	}{
+		{"composition not found", encounterErrorStatus, fmt.Errorf("%w: abc", encounterDomain.ErrCompositionNotFound), http.StatusNotFound},
+		{"no encounter generated", encounterErrorStatus, encounter.ErrNoEncounterGenerated, http.StatusUnprocessableEntity},
+		{"invalid composition request", encounterErrorStatus, fmt.Errorf("%w: budget cannot be negative", encounter.ErrInvalidRequest), http.StatusBadRequest},
+		{"composition storage failure", encounterErrorStatus, storage, http.StatusInternalServerError},
+		{"campaign not found", campaignErrorStatus, campaignDomain.ErrCampaignNotFound, http.StatusNotFound},
+		{"invalid campaign", campaignErrorStatus, fmt.Errorf("%w: invalid campaign: %w", campaignApp.ErrInvalidRequest, campaignDomain.ErrInvalidName), http.StatusBadRequest},
+		{"campaign storage failure", campaignErrorStatus, storage, http.StatusInternalServerError},
+		{"no combatants", combatErrorStatus, combatDomain.ErrNoCombatants, http.StatusUnprocessableEntity},
+		{"negative damage", combatErrorStatus, fmt.Errorf("%w: damage cannot be negative", combatApp.ErrInvalidRequest), http.StatusBadRequest},
+		{"combat storage failure", combatErrorStatus, storage, http.StatusInternalServerError},
+		{"tampered share code", shareErrorStatus, fmt.Errorf("%w: signature mismatch", encounterDomain.ErrInvalidShareCode), http.StatusBadRequest},
+		{"missing share key", shareErrorStatus, encounterDomain.ErrMissingShareKey, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status(tt.err); got != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWriteServiceError(t *testing.T) {
+	err := errors.New("failed to save combat: database is locked")
+
+	rec := httptest.NewRecorder()
+	writeServiceError(rec, err, http.StatusInternalServerError)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "database") {
+		t.Errorf("response leaks the internal error: %s", rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	writeServiceError(rec, err, http.StatusBadRequest)
+	if !strings.Contains(rec.Body.String(), err.Error()) {
+		t.Errorf("expected the error message in a client error, got %q", rec.Body)
+	}
+}
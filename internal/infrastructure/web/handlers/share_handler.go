@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/encounter"
+	monsterApp "github.com/emiliopalmerini/due-draghi-combattimenti/internal/application/monster"
+	encounterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/encounter"
+	monsterDomain "github.com/emiliopalmerini/due-draghi-combattimenti/internal/domain/monster"
+	"github.com/emiliopalmerini/due-draghi-combattimenti/internal/infrastructure/web/templates"
+)
+
+// ShareHandler handles HTTP requests for shareable encounter links
+type ShareHandler struct {
+	service        *encounter.ShareService
+	queryHandler   *encounter.QueryHandler
+	monsterService *monsterApp.Service
+	logger         *slog.Logger
+}
+
+// NewShareHandler creates a new share HTTP handler
+func NewShareHandler(service *encounter.ShareService, queryHandler *encounter.QueryHandler, monsterService *monsterApp.Service, logger *slog.Logger) *ShareHandler {
+	return &ShareHandler{
+		service:        service,
+		queryHandler:   queryHandler,
+		monsterService: monsterService,
+		logger:         logger,
+	}
+}
+
+// ShareHandler renders the shareable link of a composition.
+// POST /compositions/{compositionID}/share
+func (h *ShareHandler) ShareHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	code, err := h.service.Share(chi.URLParam(r, "compositionID"))
+	if err != nil {
+		h.logger.Error("Failed to share composition", "request_id", requestID, "error", err)
+		writeServiceError(w, err, shareErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := templates.ShareLink(shareURL(r, code)).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render share link", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// OpenHandler shows the result and monster selection of a shared encounter without storing it.
+// GET /e/{code}
+func (h *ShareHandler) OpenHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	shared, err := h.service.Open(chi.URLParam(r, "code"))
+	if err != nil {
+		h.logger.Error("Failed to open shared encounter", "request_id", requestID, "error", err)
+		writeServiceError(w, fmt.Errorf("Link non valido: %w", err), shareErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.SharedEncounter(shared).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render shared encounter", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// EditHandler stores a shared encounter as a new composition and renders it for editing.
+// POST /e/{code}
+func (h *ShareHandler) EditHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetReqID(r.Context())
+
+	shared, err := h.service.Edit(chi.URLParam(r, "code"))
+	if err != nil {
+		h.logger.Error("Failed to edit shared encounter", "request_id", requestID, "error", err)
+		writeServiceError(w, fmt.Errorf("Link non valido: %w", err), shareErrorStatus(err))
+		return
+	}
+
+	facets := templates.MonsterFacets{
+		Types:      h.monsterService.AvailableTypes(),
+		Sizes:      h.monsterService.AvailableSizes(),
+		CRs:        h.monsterService.AvailableCRs(),
+		Archetypes: h.queryHandler.GetArchetypeOptions(),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.Result(shared.Result, shared.Composition, facets).Render(r.Context(), w); err != nil {
+		h.logger.Error("Failed to render shared encounter", "request_id", requestID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// shareURL returns the absolute link of a share code, honouring a TLS-terminating proxy
+func shareURL(r *http.Request, code string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/e/" + code
+}
+
+// shareErrorStatus maps share service errors to HTTP statuses
+func shareErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, encounterDomain.ErrCompositionNotFound),
+		errors.Is(err, monsterDomain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, encounterDomain.ErrInvalidShareCode),
+		errors.Is(err, encounterDomain.ErrUnsupportedShareVersion),
+		errors.Is(err, encounter.ErrInvalidRequest):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}